@@ -0,0 +1,484 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+)
+
+// alertMetric アラートで評価できる指標
+type alertMetric struct {
+	label        string // 表示名
+	value        string // 値の式（銘柄コードはm.code）
+	from         string // FROM句
+	dateColumn   string // 評価日と比較する日付の列
+	paramColumn  string // パラメータと比較する列（パラメータがない指標は空）
+	defaultParam string
+}
+
+// alertMetrics 指標名ごとの評価方法
+var alertMetrics = map[string]alertMetric{
+	"deviation_from_max": {
+		label: "最高値からの乖離率(%)", value: "m.deviation_from_max", from: "assessment_window_metrics m",
+		dateColumn: "m.last_trade_date", paramColumn: "m.window_label", defaultParam: "3M",
+	},
+	"deviation_from_min": {
+		label: "最低値からの乖離率(%)", value: "m.deviation_from_min", from: "assessment_window_metrics m",
+		dateColumn: "m.last_trade_date", paramColumn: "m.window_label", defaultParam: "3M",
+	},
+	"forecast_dividend_yield": {
+		label: "予想配当利回り(%)", value: "m.forecast_dividend_yield", from: "valuations m", dateColumn: "m.trade_date",
+	},
+	"forecast_per": {
+		label: "予想PER", value: "m.forecast_per", from: "valuations m", dateColumn: "m.trade_date",
+	},
+	"actual_per": {
+		label: "実績PER", value: "m.actual_per", from: "valuations m", dateColumn: "m.trade_date",
+	},
+	"pbr": {
+		label: "PBR", value: "m.pbr", from: "valuations m", dateColumn: "m.trade_date",
+	},
+	"market_cap": {
+		label: "時価総額", value: "m.market_cap", from: "valuations m", dateColumn: "m.trade_date",
+	},
+	"close": {
+		label: "終値", value: "m.close", from: "daily_quotes m", dateColumn: "m.trade_date",
+	},
+	"volume": {
+		label: "出来高", value: "m.volume", from: "daily_quotes m", dateColumn: "m.trade_date",
+	},
+	"change_pct": {
+		label: "前日比(%)",
+		value: "ROUND((m.adjustment_close / prev.adjustment_close - 1) * 100, 2)",
+		from: `daily_quotes m
+			JOIN daily_quotes prev
+				ON prev.code = m.code
+				AND prev.trade_date = (SELECT MAX(trade_date) FROM daily_quotes WHERE code = m.code AND trade_date < m.trade_date)
+				AND prev.adjustment_close > 0`,
+		dateColumn: "m.trade_date",
+	},
+	"indicator": {
+		label: "テクニカル指標", value: "m.value", from: "indicators m", dateColumn: "m.trade_date", paramColumn: "m.name",
+	},
+}
+
+// alertOperators 比較演算子
+var alertOperators = map[string]bool{"<": true, "<=": true, ">": true, ">=": true}
+
+// AlertMetricNames アラートで評価できる指標名を名前順に取得
+func AlertMetricNames() []string {
+	names := make([]string, 0, len(alertMetrics))
+	for name := range alertMetrics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// AlertRule アラートルール
+type AlertRule struct {
+	ID        int64
+	Name      string
+	Metric    string
+	Param     string // 期間別評価指標の期間（例: 3M）、テクニカル指標の指標名（例: rsi_14）
+	Operator  string
+	Threshold float64
+	Code      string     // 対象の銘柄コード（空の場合はFilterの条件、どちらもない場合は全銘柄）
+	Filter    CodeFilter // 対象のウォッチリスト・タグ
+	Enabled   bool
+	CreatedAt time.Time
+}
+
+// MetricLabel 指標の表示名（例: 3M 最低値からの乖離率(%)）
+func (r *AlertRule) MetricLabel() string {
+	label := r.Metric
+	if metric, ok := alertMetrics[r.Metric]; ok {
+		label = metric.label
+	}
+	if r.Param != "" {
+		label = r.Param + " " + label
+	}
+	return label
+}
+
+// Condition ルールの条件を表示用の文字列に変換（例: 3M 最低値からの乖離率(%) < 3）
+func (r *AlertRule) Condition() string {
+	return fmt.Sprintf("%s %s %s", r.MetricLabel(), r.Operator, formatNumber(r.Threshold))
+}
+
+// Target ルールの対象を表示用の文字列に変換
+func (r *AlertRule) Target() string {
+	var targets []string
+	if r.Code != "" {
+		targets = append(targets, r.Code)
+	}
+	if r.Filter.Watchlist != "" {
+		targets = append(targets, "ウォッチリスト:"+r.Filter.Watchlist)
+	}
+	if r.Filter.Tag != "" {
+		targets = append(targets, "タグ:"+r.Filter.Tag)
+	}
+	if len(targets) == 0 {
+		return "全銘柄"
+	}
+	return strings.Join(targets, " ")
+}
+
+// ValidateAlertRule ルールの指標・比較演算子を検証し、パラメータを補完
+func ValidateAlertRule(r *AlertRule) error {
+	if strings.TrimSpace(r.Name) == "" {
+		return fmt.Errorf("ルール名を指定してください")
+	}
+	metric, ok := alertMetrics[r.Metric]
+	if !ok {
+		return fmt.Errorf("サポートされていない指標です（%s）: '%s'", strings.Join(AlertMetricNames(), ", "), r.Metric)
+	}
+	if !alertOperators[r.Operator] {
+		return fmt.Errorf("サポートされていない比較演算子です（<, <=, >, >=）: '%s'", r.Operator)
+	}
+
+	switch {
+	case metric.paramColumn == "":
+		if r.Param != "" {
+			return fmt.Errorf("指標%sにはパラメータを指定できません", r.Metric)
+		}
+	case metric.paramColumn == "m.window_label":
+		if r.Param == "" {
+			r.Param = metric.defaultParam
+		}
+		window, err := ParseAssessmentWindow(r.Param)
+		if err != nil {
+			return err
+		}
+		r.Param = window.Label
+	case r.Param == "":
+		return fmt.Errorf("指標%sにはパラメータを指定してください", r.Metric)
+	}
+
+	return nil
+}
+
+// AlertEvent 条件に一致した銘柄（ルール・銘柄・取引日ごとに1件）
+type AlertEvent struct {
+	Rule        *AlertRule
+	Code        string
+	CompanyName string
+	TradeDate   time.Time
+	Value       float64
+	Threshold   float64 // 評価時のしきい値
+	NotifiedAt  *time.Time
+	CreatedAt   time.Time
+}
+
+// Message 通知用の1行のメッセージ（例: [near-low] 72030 トヨタ自動車: 3M 最低値からの乖離率(%) 2.5 < 3）
+func (e *AlertEvent) Message() string {
+	return fmt.Sprintf("[%s] %s %s: %s %s %s %s",
+		e.Rule.Name, e.Code, e.CompanyName, e.Rule.MetricLabel(), formatNumber(e.Value), e.Rule.Operator, formatNumber(e.Threshold))
+}
+
+// formatNumber しきい値・値を末尾の0を除いて文字列に変換
+func formatNumber(v float64) string {
+	return strings.TrimSuffix(strings.TrimRight(fmt.Sprintf("%.4f", v), "0"), ".")
+}
+
+// AlertRepository アラートのリポジトリ
+type AlertRepository struct {
+	conn *Connection
+}
+
+// NewAlertRepository 新しいリポジトリを作成
+func NewAlertRepository(conn *Connection) *AlertRepository {
+	return &AlertRepository{conn: conn}
+}
+
+// AddRule ルールを追加
+func (r *AlertRepository) AddRule(rule *AlertRule) error {
+	if err := ValidateAlertRule(rule); err != nil {
+		return err
+	}
+
+	tx, cleanup := BeginTransaction(r.conn.GetDB())
+	defer cleanup()
+
+	if rule.Code != "" {
+		if _, err := getListedCompanyName(tx, rule.Code); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	var exists int
+	if err := tx.QueryRow("SELECT COUNT(*) FROM alert_rules WHERE name = ?", rule.Name).Scan(&exists); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("アラートルール取得エラー: %v", err)
+	}
+	if exists > 0 {
+		tx.Rollback()
+		return fmt.Errorf("同じ名前のルールが既に存在します: %s", rule.Name)
+	}
+
+	result, err := tx.Exec(`
+		INSERT INTO alert_rules (name, metric, param, operator, threshold, code, watchlist, tag, enabled)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, rule.Name, rule.Metric, nullIfEmptyString(rule.Param), rule.Operator, rule.Threshold,
+		nullIfEmptyString(rule.Code), nullIfEmptyString(rule.Filter.Watchlist), nullIfEmptyString(rule.Filter.Tag), rule.Enabled)
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("アラートルール保存エラー: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("トランザクションコミットエラー: %v", err)
+	}
+
+	rule.ID, _ = result.LastInsertId()
+	return nil
+}
+
+// GetRule 名前でルールを取得（存在しない場合はnil）
+func (r *AlertRepository) GetRule(name string) (*AlertRule, error) {
+	rules, err := r.queryRules("WHERE name = ?", name)
+	if err != nil || len(rules) == 0 {
+		return nil, err
+	}
+	return rules[0], nil
+}
+
+// GetRules ルールを名前順に取得（enabledOnly=trueの場合は有効なルールのみ）
+func (r *AlertRepository) GetRules(enabledOnly bool) ([]*AlertRule, error) {
+	if enabledOnly {
+		return r.queryRules("WHERE enabled = TRUE")
+	}
+	return r.queryRules("")
+}
+
+func (r *AlertRepository) queryRules(where string, args ...interface{}) ([]*AlertRule, error) {
+	rows, err := r.conn.GetDB().Query(`
+		SELECT id, name, metric, param, operator, threshold, code, watchlist, tag, enabled, created_at
+		FROM alert_rules
+		`+where+`
+		ORDER BY name
+	`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("アラートルール取得エラー: %v", err)
+	}
+	defer rows.Close()
+
+	var rules []*AlertRule
+	for rows.Next() {
+		rule := &AlertRule{}
+		var param, code, watchlist, tag sql.NullString
+		err := rows.Scan(
+			&rule.ID, &rule.Name, &rule.Metric, &param, &rule.Operator, &rule.Threshold,
+			&code, &watchlist, &tag, &rule.Enabled, &rule.CreatedAt,
+		)
+		if err != nil {
+			log.Printf("アラートルールスキャンエラー: %v", err)
+			continue
+		}
+		rule.Param = param.String
+		rule.Code = code.String
+		rule.Filter = CodeFilter{Watchlist: watchlist.String, Tag: tag.String}
+		rules = append(rules, rule)
+	}
+
+	return rules, nil
+}
+
+// DeleteRule ルールを発生履歴ごと削除
+func (r *AlertRepository) DeleteRule(ruleID int64) error {
+	if _, err := r.conn.GetDB().Exec("DELETE FROM alert_rules WHERE id = ?", ruleID); err != nil {
+		return fmt.Errorf("アラートルール削除エラー: %v", err)
+	}
+	return nil
+}
+
+// SetRuleEnabled ルールの有効・無効を切り替え
+func (r *AlertRepository) SetRuleEnabled(ruleID int64, enabled bool) error {
+	if _, err := r.conn.GetDB().Exec("UPDATE alert_rules SET enabled = ? WHERE id = ?", enabled, ruleID); err != nil {
+		return fmt.Errorf("アラートルール更新エラー: %v", err)
+	}
+	return nil
+}
+
+// GetLatestTradeDate daily_quotesの最新取引日を取得（データがない場合はnil）
+func (r *AlertRepository) GetLatestTradeDate() (*time.Time, error) {
+	var date sql.NullTime
+	if err := r.conn.GetDB().QueryRow("SELECT MAX(trade_date) FROM daily_quotes").Scan(&date); err != nil {
+		return nil, fmt.Errorf("最新取引日取得エラー: %v", err)
+	}
+	if !date.Valid {
+		return nil, nil
+	}
+	return &date.Time, nil
+}
+
+// Evaluate 指定した取引日にルールの条件に一致する銘柄をコード順に取得
+func (r *AlertRepository) Evaluate(rule *AlertRule, date time.Time) ([]*AlertEvent, error) {
+	if err := ValidateAlertRule(rule); err != nil {
+		return nil, err
+	}
+	metric := alertMetrics[rule.Metric]
+
+	query := fmt.Sprintf(`
+		SELECT m.code, COALESCE(li.company_name, ''), %s
+		FROM %s
+		LEFT JOIN listed_info li ON li.code = m.code
+	`, metric.value, metric.from)
+
+	conditions, args := rule.Filter.conditions("m.code")
+	conditions = append(conditions, metric.dateColumn+" = ?", metric.value+" IS NOT NULL", metric.value+" "+rule.Operator+" ?")
+	args = append(args, date.Format("2006-01-02"), rule.Threshold)
+	if metric.paramColumn != "" {
+		conditions = append(conditions, metric.paramColumn+" = ?")
+		args = append(args, rule.Param)
+	}
+	if rule.Code != "" {
+		conditions = append(conditions, "m.code = ?")
+		args = append(args, rule.Code)
+	}
+	query += " WHERE " + strings.Join(conditions, " AND ") + " ORDER BY m.code"
+
+	rows, err := r.conn.GetDB().Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("アラート評価エラー（%s）: %v", rule.Name, err)
+	}
+	defer rows.Close()
+
+	var events []*AlertEvent
+	for rows.Next() {
+		e := &AlertEvent{Rule: rule, TradeDate: date, Threshold: rule.Threshold}
+		if err := rows.Scan(&e.Code, &e.CompanyName, &e.Value); err != nil {
+			log.Printf("アラート評価スキャンエラー: %v", err)
+			continue
+		}
+		events = append(events, e)
+	}
+
+	return events, nil
+}
+
+// SaveEvents 発生履歴を保存し、新たに保存した件数を返す（同じルール・銘柄・取引日の発生履歴は読み飛ばす）
+func (r *AlertRepository) SaveEvents(events []*AlertEvent) (int, error) {
+	tx, cleanup := BeginTransaction(r.conn.GetDB())
+	defer cleanup()
+
+	added := 0
+	for _, e := range events {
+		result, err := tx.Exec(
+			"INSERT IGNORE INTO alert_events (rule_id, code, trade_date, value, threshold) VALUES (?, ?, ?, ?, ?)",
+			e.Rule.ID, e.Code, e.TradeDate.Format("2006-01-02"), e.Value, e.Threshold,
+		)
+		if err != nil {
+			tx.Rollback()
+			return 0, fmt.Errorf("アラート発生履歴保存エラー: %v", err)
+		}
+		if n, _ := result.RowsAffected(); n > 0 {
+			added++
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("トランザクションコミットエラー: %v", err)
+	}
+
+	return added, nil
+}
+
+// GetPendingEvents 未通知の発生履歴を取引日・ルール名・コード順に取得
+func (r *AlertRepository) GetPendingEvents() ([]*AlertEvent, error) {
+	return r.queryEvents("WHERE e.notified_at IS NULL", nil, "e.trade_date, ar.name, e.code", 0)
+}
+
+// GetEvents 発生履歴を取引日の新しい順に取得
+// ruleName: ルール名（空の場合は全ルール）、from: 取引日の開始日（YYYY-MM-DD形式、空の場合は全期間）、filter: ウォッチリスト・タグによる絞り込み、limit: 0の場合は全件
+func (r *AlertRepository) GetEvents(ruleName, from string, filter CodeFilter, limit int) ([]*AlertEvent, error) {
+	conditions, args := filter.conditions("e.code")
+	if ruleName != "" {
+		conditions = append(conditions, "ar.name = ?")
+		args = append(args, ruleName)
+	}
+	if from != "" {
+		conditions = append(conditions, "e.trade_date >= ?")
+		args = append(args, from)
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+	return r.queryEvents(where, args, "e.trade_date DESC, ar.name, e.code", limit)
+}
+
+func (r *AlertRepository) queryEvents(where string, args []interface{}, order string, limit int) ([]*AlertEvent, error) {
+	query := `
+		SELECT
+			ar.id, ar.name, ar.metric, ar.param, ar.operator, ar.threshold, ar.code, ar.watchlist, ar.tag, ar.enabled, ar.created_at,
+			e.code, COALESCE(li.company_name, ''), e.trade_date, e.value, e.threshold, e.notified_at, e.created_at
+		FROM alert_events e
+		JOIN alert_rules ar ON ar.id = e.rule_id
+		LEFT JOIN listed_info li ON li.code = e.code
+		` + where + `
+		ORDER BY ` + order
+	if limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", limit)
+	}
+
+	rows, err := r.conn.GetDB().Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("アラート発生履歴取得エラー: %v", err)
+	}
+	defer rows.Close()
+
+	var events []*AlertEvent
+	for rows.Next() {
+		rule := &AlertRule{}
+		e := &AlertEvent{Rule: rule}
+		var param, code, watchlist, tag sql.NullString
+		var notifiedAt sql.NullTime
+		err := rows.Scan(
+			&rule.ID, &rule.Name, &rule.Metric, &param, &rule.Operator, &rule.Threshold,
+			&code, &watchlist, &tag, &rule.Enabled, &rule.CreatedAt,
+			&e.Code, &e.CompanyName, &e.TradeDate, &e.Value, &e.Threshold, &notifiedAt, &e.CreatedAt,
+		)
+		if err != nil {
+			log.Printf("アラート発生履歴スキャンエラー: %v", err)
+			continue
+		}
+		rule.Param = param.String
+		rule.Code = code.String
+		rule.Filter = CodeFilter{Watchlist: watchlist.String, Tag: tag.String}
+		if notifiedAt.Valid {
+			e.NotifiedAt = &notifiedAt.Time
+		}
+		events = append(events, e)
+	}
+
+	return events, nil
+}
+
+// MarkNotified 発生履歴を通知済みにする
+func (r *AlertRepository) MarkNotified(events []*AlertEvent) error {
+	tx, cleanup := BeginTransaction(r.conn.GetDB())
+	defer cleanup()
+
+	for _, e := range events {
+		_, err := tx.Exec(
+			"UPDATE alert_events SET notified_at = CURRENT_TIMESTAMP WHERE rule_id = ? AND code = ? AND trade_date = ?",
+			e.Rule.ID, e.Code, e.TradeDate.Format("2006-01-02"),
+		)
+		if err != nil {
+			tx.Rollback()
+			return fmt.Errorf("アラート発生履歴更新エラー: %v", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("トランザクションコミットエラー: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,373 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultAssessmentWindows 期間の設定がない場合の標準の期間
+const defaultAssessmentWindows = "1M,3M,52W,3Y"
+
+// AssessmentWindow 銘柄評価の期間（D: 日, W: 週, M: 月, Y: 年）
+type AssessmentWindow struct {
+	Label  string
+	Amount int
+	Unit   byte
+}
+
+var assessmentWindowPattern = regexp.MustCompile(`^([1-9][0-9]*)([DWMY])$`)
+
+// ParseAssessmentWindow "3M"や"52W"の形式の期間を解析
+func ParseAssessmentWindow(s string) (AssessmentWindow, error) {
+	label := strings.ToUpper(strings.TrimSpace(s))
+	m := assessmentWindowPattern.FindStringSubmatch(label)
+	if m == nil {
+		return AssessmentWindow{}, fmt.Errorf("期間の形式が正しくありません（例: 1M, 3M, 52W, 3Y）: '%s'", s)
+	}
+	amount, _ := strconv.Atoi(m[1])
+	return AssessmentWindow{Label: label, Amount: amount, Unit: m[2][0]}, nil
+}
+
+// ParseAssessmentWindows カンマ区切りの期間を解析
+func ParseAssessmentWindows(s string) ([]AssessmentWindow, error) {
+	var windows []AssessmentWindow
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(s, ",") {
+		window, err := ParseAssessmentWindow(part)
+		if err != nil {
+			return nil, err
+		}
+		if seen[window.Label] {
+			continue
+		}
+		seen[window.Label] = true
+		windows = append(windows, window)
+	}
+	return windows, nil
+}
+
+// NewAssessmentWindowsFromEnv 環境変数ASSESSMENT_WINDOWSから期間を読み込む（未設定の場合は1M,3M,52W,3Y）
+func NewAssessmentWindowsFromEnv() ([]AssessmentWindow, error) {
+	value := os.Getenv("ASSESSMENT_WINDOWS")
+	if value == "" {
+		value = defaultAssessmentWindows
+	}
+	windows, err := ParseAssessmentWindows(value)
+	if err != nil {
+		return nil, fmt.Errorf("ASSESSMENT_WINDOWSの値が無効です: %v", err)
+	}
+	return windows, nil
+}
+
+// StartDate 終了日を含む期間の開始日
+func (w AssessmentWindow) StartDate(end time.Time) time.Time {
+	switch w.Unit {
+	case 'D':
+		return end.AddDate(0, 0, -w.Amount+1)
+	case 'W':
+		return end.AddDate(0, 0, -7*w.Amount+1)
+	case 'M':
+		return monthsBefore(end, w.Amount).AddDate(0, 0, 1)
+	default:
+		return monthsBefore(end, 12*w.Amount).AddDate(0, 0, 1)
+	}
+}
+
+// monthsBefore 指定日のnか月前の同じ日（その月にない日の場合は月末、例: 3/31の1か月前は2/28）
+func monthsBefore(date time.Time, n int) time.Time {
+	first := time.Date(date.Year(), date.Month()-time.Month(n), 1, 0, 0, 0, 0, date.Location())
+	lastDay := first.AddDate(0, 1, -1).Day()
+	day := date.Day()
+	if day > lastDay {
+		day = lastDay
+	}
+	return time.Date(first.Year(), first.Month(), day, date.Hour(), date.Minute(), date.Second(), date.Nanosecond(), date.Location())
+}
+
+// AssessmentWindowMetric 期間別の銘柄評価指標
+type AssessmentWindowMetric struct {
+	Code                string
+	CompanyName         string // 取得時のみ（listed_infoから結合）
+	WindowLabel         string
+	WindowStartDate     time.Time
+	LastTradeDate       time.Time
+	LastAdjustmentClose float64
+	MaxClose            float64
+	MaxCloseDate        time.Time
+	MinClose            float64
+	MinCloseDate        time.Time
+	DeviationFromMax    *float64
+	DeviationFromMin    *float64
+	TradingDays         int
+}
+
+// AssessmentWindowRepository 期間別の銘柄評価指標のリポジトリ
+type AssessmentWindowRepository struct {
+	conn *Connection
+}
+
+// NewAssessmentWindowRepository 新しいリポジトリを作成
+func NewAssessmentWindowRepository(conn *Connection) *AssessmentWindowRepository {
+	return &AssessmentWindowRepository{conn: conn}
+}
+
+// closeQuote 調整後終値
+type closeQuote struct {
+	tradeDate time.Time
+	close     float64
+}
+
+// BuildWindowMetrics 銘柄ごとの最終取引日を終了日として、期間別の最高・最低調整終値と乖離率を算出して置き換え
+// localCodes: 対象銘柄コード（空の場合は株価データのある全銘柄）
+func (r *AssessmentWindowRepository) BuildWindowMetrics(localCodes []string, windows []AssessmentWindow) error {
+	if len(windows) == 0 {
+		return fmt.Errorf("期間が指定されていません")
+	}
+
+	tx, cleanup := BeginTransaction(r.conn.GetDB())
+	defer cleanup()
+
+	if len(localCodes) == 0 {
+		codes, err := selectDistinctQuoteCodes(tx)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		localCodes = codes
+	}
+
+	processedCount := 0
+	for _, code := range localCodes {
+		if err := r.processLocalCode(tx, code, windows); err != nil {
+			log.Printf("銘柄 %s の期間別評価指標算出でエラー: %v", code, err)
+			continue
+		}
+		processedCount++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("トランザクションコミットエラー: %v", err)
+	}
+
+	log.Printf("期間別評価指標の作成完了: %d銘柄処理", processedCount)
+	return nil
+}
+
+// processLocalCode 個別銘柄の期間別評価指標を算出して置き換え
+func (r *AssessmentWindowRepository) processLocalCode(tx *sql.Tx, code string, windows []AssessmentWindow) error {
+	var latest sql.NullTime
+	err := tx.QueryRow(
+		"SELECT MAX(trade_date) FROM daily_quotes WHERE code = ? AND adjustment_close IS NOT NULL AND adjustment_close > 0",
+		code,
+	).Scan(&latest)
+	if err != nil {
+		return fmt.Errorf("最終取引日取得エラー: %v", err)
+	}
+
+	if _, err := tx.Exec("DELETE FROM assessment_window_metrics WHERE code = ?", code); err != nil {
+		return fmt.Errorf("既存データ削除エラー: %v", err)
+	}
+	if !latest.Valid {
+		return nil
+	}
+
+	earliest := latest.Time
+	for _, window := range windows {
+		if start := window.StartDate(latest.Time); start.Before(earliest) {
+			earliest = start
+		}
+	}
+
+	quotes, err := getCloseQuotes(tx, code, earliest)
+	if err != nil {
+		return err
+	}
+
+	for _, window := range windows {
+		metric := calculateWindowMetric(code, window, latest.Time, quotes)
+		if metric == nil {
+			continue
+		}
+		if err := r.insertMetric(tx, metric); err != nil {
+			return fmt.Errorf("期間別評価指標挿入エラー (期間: %s): %v", window.Label, err)
+		}
+	}
+
+	return nil
+}
+
+// getCloseQuotes 指定日以降の調整後終値を取引日の古い順に取得
+func getCloseQuotes(q sqlQueryer, code string, from time.Time) ([]closeQuote, error) {
+	rows, err := q.Query(`
+		SELECT trade_date, adjustment_close FROM daily_quotes
+		WHERE code = ? AND trade_date >= ? AND adjustment_close IS NOT NULL AND adjustment_close > 0
+		ORDER BY trade_date
+	`, code, from)
+	if err != nil {
+		return nil, fmt.Errorf("株価データ取得エラー: %v", err)
+	}
+	defer rows.Close()
+
+	var quotes []closeQuote
+	for rows.Next() {
+		var quote closeQuote
+		if err := rows.Scan(&quote.tradeDate, &quote.close); err != nil {
+			log.Printf("株価データスキャンエラー: %v", err)
+			continue
+		}
+		quotes = append(quotes, quote)
+	}
+
+	return quotes, nil
+}
+
+// calculateWindowMetric 期間中の最高・最低調整終値と、最終取引日の調整後終値の乖離率を算出（期間中に株価がない場合はnil）
+func calculateWindowMetric(code string, window AssessmentWindow, end time.Time, quotes []closeQuote) *AssessmentWindowMetric {
+	start := window.StartDate(end)
+
+	var metric *AssessmentWindowMetric
+	for _, quote := range quotes {
+		if quote.tradeDate.Before(start) || quote.tradeDate.After(end) {
+			continue
+		}
+		if metric == nil {
+			metric = &AssessmentWindowMetric{
+				Code:            code,
+				WindowLabel:     window.Label,
+				WindowStartDate: start,
+				MaxClose:        quote.close,
+				MaxCloseDate:    quote.tradeDate,
+				MinClose:        quote.close,
+				MinCloseDate:    quote.tradeDate,
+			}
+		}
+		// 同値の場合は直近の日付を採用
+		if quote.close >= metric.MaxClose {
+			metric.MaxClose, metric.MaxCloseDate = quote.close, quote.tradeDate
+		}
+		if quote.close <= metric.MinClose {
+			metric.MinClose, metric.MinCloseDate = quote.close, quote.tradeDate
+		}
+		metric.LastTradeDate = quote.tradeDate
+		metric.LastAdjustmentClose = quote.close
+		metric.TradingDays++
+	}
+	if metric == nil {
+		return nil
+	}
+
+	metric.DeviationFromMax = deviationPct(metric.LastAdjustmentClose, metric.MaxClose)
+	metric.DeviationFromMin = deviationPct(metric.LastAdjustmentClose, metric.MinClose)
+	return metric
+}
+
+// deviationPct 基準値からの乖離率(%)（基準値が0以下の場合はnil）
+func deviationPct(value, base float64) *float64 {
+	if base <= 0 {
+		return nil
+	}
+	v := math.Round((value-base)/base*10000) / 100
+	return &v
+}
+
+// insertMetric 期間別評価指標データを挿入
+func (r *AssessmentWindowRepository) insertMetric(tx *sql.Tx, metric *AssessmentWindowMetric) error {
+	query := `
+		INSERT INTO assessment_window_metrics (
+			code, window_label, window_start_date, last_trade_date, last_adjustment_close,
+			max_close, max_close_date, min_close, min_close_date,
+			deviation_from_max, deviation_from_min, trading_days
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	_, err := tx.Exec(query,
+		metric.Code,
+		metric.WindowLabel,
+		metric.WindowStartDate,
+		metric.LastTradeDate,
+		metric.LastAdjustmentClose,
+		metric.MaxClose,
+		metric.MaxCloseDate,
+		metric.MinClose,
+		metric.MinCloseDate,
+		metric.DeviationFromMax,
+		metric.DeviationFromMin,
+		metric.TradingDays,
+	)
+
+	return err
+}
+
+// GetWindowMetrics 期間別評価指標を取得
+// code: 銘柄コード（指定した場合はその銘柄の全期間を期間の短い順）
+// windowLabel: 期間（codeが空の場合は必須、上場中の全銘柄を最高値からの乖離率の大きい順）
+func (r *AssessmentWindowRepository) GetWindowMetrics(code, windowLabel string, filter CodeFilter, limit int) ([]*AssessmentWindowMetric, error) {
+	query := `
+		SELECT
+			m.code, COALESCE(li.company_name, ''), m.window_label, m.window_start_date, m.last_trade_date,
+			m.last_adjustment_close, m.max_close, m.max_close_date, m.min_close, m.min_close_date,
+			m.deviation_from_max, m.deviation_from_min, m.trading_days
+		FROM assessment_window_metrics m
+		LEFT JOIN listed_info li ON li.code = m.code
+	`
+	conditions, args := filter.conditions("m.code")
+	if code != "" {
+		conditions = append(conditions, "m.code = ?")
+		args = append(args, code)
+	} else {
+		conditions = append(conditions, "li.delisted_date IS NULL")
+	}
+	if windowLabel != "" {
+		conditions = append(conditions, "m.window_label = ?")
+		args = append(args, windowLabel)
+	}
+	query += " WHERE " + strings.Join(conditions, " AND ")
+	if code != "" {
+		query += " ORDER BY m.window_start_date DESC"
+	} else {
+		query += " ORDER BY m.deviation_from_max IS NULL, m.deviation_from_max DESC, m.code"
+	}
+	if limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", limit)
+	}
+
+	rows, err := r.conn.GetDB().Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("期間別評価指標データ取得エラー: %v", err)
+	}
+	defer rows.Close()
+
+	var metrics []*AssessmentWindowMetric
+	for rows.Next() {
+		m := &AssessmentWindowMetric{}
+		err := rows.Scan(
+			&m.Code,
+			&m.CompanyName,
+			&m.WindowLabel,
+			&m.WindowStartDate,
+			&m.LastTradeDate,
+			&m.LastAdjustmentClose,
+			&m.MaxClose,
+			&m.MaxCloseDate,
+			&m.MinClose,
+			&m.MinCloseDate,
+			&m.DeviationFromMax,
+			&m.DeviationFromMin,
+			&m.TradingDays,
+		)
+		if err != nil {
+			log.Printf("期間別評価指標データスキャンエラー: %v", err)
+			continue
+		}
+		metrics = append(metrics, m)
+	}
+
+	return metrics, nil
+}
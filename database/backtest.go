@@ -0,0 +1,119 @@
+package database
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// DailyBar バックテスト用の日足（調整前の四本値と、その取引日時点の株価指標）
+type DailyBar struct {
+	Code             string
+	TradeDate        time.Time
+	Open             float64
+	High             float64
+	Low              float64
+	Close            float64
+	Volume           float64
+	AdjustmentFactor float64 // 1以外の場合はこの取引日に株式分割・併合の権利落ち
+	AdjustmentClose  float64
+
+	// 取引日時点で開示済みの財務情報による株価指標（valuationsがない場合はnil）
+	MarketCap             *int64
+	ActualPER             *float64
+	ForecastPER           *float64
+	PBR                   *float64
+	ForecastDividendYield *float64
+}
+
+// BacktestRepository バックテスト用データのリポジトリ
+type BacktestRepository struct {
+	conn *Connection
+}
+
+// NewBacktestRepository 新しいリポジトリを作成
+func NewBacktestRepository(conn *Connection) *BacktestRepository {
+	return &BacktestRepository{conn: conn}
+}
+
+// GetTradeDates 期間内のdaily_quotesの取引日を古い順に取得
+func (r *BacktestRepository) GetTradeDates(from, to time.Time) ([]time.Time, error) {
+	rows, err := r.conn.GetDB().Query(
+		"SELECT DISTINCT trade_date FROM daily_quotes WHERE trade_date BETWEEN ? AND ? ORDER BY trade_date",
+		from.Format("2006-01-02"), to.Format("2006-01-02"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("取引日取得エラー: %v", err)
+	}
+	defer rows.Close()
+
+	var dates []time.Time
+	for rows.Next() {
+		var date time.Time
+		if err := rows.Scan(&date); err != nil {
+			log.Printf("取引日スキャンエラー: %v", err)
+			continue
+		}
+		dates = append(dates, date)
+	}
+
+	return dates, nil
+}
+
+// GetDailyBars 取引日の日足を取得
+// codes: 対象銘柄コード（空の場合はその取引日に株価のある全銘柄）
+func (r *BacktestRepository) GetDailyBars(tradeDate time.Time, codes []string) ([]*DailyBar, error) {
+	query := `
+		SELECT
+			dq.code, dq.trade_date,
+			COALESCE(dq.open, 0), COALESCE(dq.high, 0), COALESCE(dq.low, 0), COALESCE(dq.close, 0),
+			COALESCE(dq.volume, 0), COALESCE(dq.adjustment_factor, 1), COALESCE(dq.adjustment_close, 0),
+			v.market_cap, v.actual_per, v.forecast_per, v.pbr, v.forecast_dividend_yield
+		FROM daily_quotes dq
+		LEFT JOIN valuations v ON v.code = dq.code AND v.trade_date = dq.trade_date
+		WHERE dq.trade_date = ?
+	`
+	args := []interface{}{tradeDate.Format("2006-01-02")}
+	if len(codes) > 0 {
+		query += " AND dq.code IN (?" + strings.Repeat(", ?", len(codes)-1) + ")"
+		for _, code := range codes {
+			args = append(args, code)
+		}
+	}
+	query += " ORDER BY dq.code"
+
+	rows, err := r.conn.GetDB().Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("日足データ取得エラー: %v", err)
+	}
+	defer rows.Close()
+
+	var bars []*DailyBar
+	for rows.Next() {
+		bar := &DailyBar{}
+		err := rows.Scan(
+			&bar.Code,
+			&bar.TradeDate,
+			&bar.Open,
+			&bar.High,
+			&bar.Low,
+			&bar.Close,
+			&bar.Volume,
+			&bar.AdjustmentFactor,
+			&bar.AdjustmentClose,
+			&bar.MarketCap,
+			&bar.ActualPER,
+			&bar.ForecastPER,
+			&bar.PBR,
+			&bar.ForecastDividendYield,
+		)
+		if err != nil {
+			log.Printf("日足データスキャンエラー: %v", err)
+			continue
+		}
+		bars = append(bars, bar)
+	}
+
+	return bars, nil
+}
@@ -0,0 +1,223 @@
+package database
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// CodeNote 銘柄の調査メモ
+type CodeNote struct {
+	ID          int64
+	Code        string
+	CompanyName string
+	Note        string
+	CreatedAt   time.Time
+}
+
+// CodeTag 銘柄のタグ
+type CodeTag struct {
+	Code        string
+	CompanyName string
+	Tag         string
+	CreatedAt   time.Time
+}
+
+// TagCount タグごとの銘柄数
+type TagCount struct {
+	Tag   string
+	Codes int
+}
+
+// CodeNoteRepository 銘柄メモ・タグのリポジトリ
+type CodeNoteRepository struct {
+	conn *Connection
+}
+
+// NewCodeNoteRepository 新しいリポジトリを作成
+func NewCodeNoteRepository(conn *Connection) *CodeNoteRepository {
+	return &CodeNoteRepository{conn: conn}
+}
+
+// AddNote 銘柄にメモを追加
+func (r *CodeNoteRepository) AddNote(code, note string) (*CodeNote, error) {
+	note = strings.TrimSpace(note)
+	if note == "" {
+		return nil, fmt.Errorf("メモを指定してください")
+	}
+
+	tx, cleanup := BeginTransaction(r.conn.GetDB())
+	defer cleanup()
+
+	companyName, err := getListedCompanyName(tx, code)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	result, err := tx.Exec("INSERT INTO code_notes (code, note) VALUES (?, ?)", code, note)
+	if err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("メモ保存エラー: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("トランザクションコミットエラー: %v", err)
+	}
+
+	id, _ := result.LastInsertId()
+	return &CodeNote{ID: id, Code: code, CompanyName: companyName, Note: note, CreatedAt: time.Now()}, nil
+}
+
+// GetNotes メモを新しい順に取得
+// code: 銘柄コード（空の場合は全銘柄）、filter: ウォッチリスト・タグによる絞り込み、limit: 0の場合は全件
+func (r *CodeNoteRepository) GetNotes(code string, filter CodeFilter, limit int) ([]*CodeNote, error) {
+	query := `
+		SELECT n.id, n.code, COALESCE(li.company_name, ''), n.note, n.created_at
+		FROM code_notes n
+		LEFT JOIN listed_info li ON li.code = n.code
+	`
+	conditions, args := filter.conditions("n.code")
+	if code != "" {
+		conditions = append(conditions, "n.code = ?")
+		args = append(args, code)
+	}
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY n.created_at DESC, n.id DESC"
+	if limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", limit)
+	}
+
+	rows, err := r.conn.GetDB().Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("メモ取得エラー: %v", err)
+	}
+	defer rows.Close()
+
+	var notes []*CodeNote
+	for rows.Next() {
+		n := &CodeNote{}
+		if err := rows.Scan(&n.ID, &n.Code, &n.CompanyName, &n.Note, &n.CreatedAt); err != nil {
+			log.Printf("メモスキャンエラー: %v", err)
+			continue
+		}
+		notes = append(notes, n)
+	}
+
+	return notes, nil
+}
+
+// AddTags 銘柄にタグを追加し、追加したタグ数を返す（追加済みのタグは読み飛ばす）
+func (r *CodeNoteRepository) AddTags(code string, tags []string) (int, error) {
+	tx, cleanup := BeginTransaction(r.conn.GetDB())
+	defer cleanup()
+
+	if _, err := getListedCompanyName(tx, code); err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	added := 0
+	for _, tag := range tags {
+		result, err := tx.Exec("INSERT IGNORE INTO code_tags (code, tag) VALUES (?, ?)", code, tag)
+		if err != nil {
+			tx.Rollback()
+			return 0, fmt.Errorf("タグ保存エラー: %v", err)
+		}
+		if n, _ := result.RowsAffected(); n > 0 {
+			added++
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("トランザクションコミットエラー: %v", err)
+	}
+
+	return added, nil
+}
+
+// RemoveTags 銘柄からタグを削除し、削除したタグ数を返す
+func (r *CodeNoteRepository) RemoveTags(code string, tags []string) (int, error) {
+	if len(tags) == 0 {
+		return 0, nil
+	}
+
+	args := []interface{}{code}
+	for _, tag := range tags {
+		args = append(args, tag)
+	}
+	result, err := r.conn.GetDB().Exec(
+		"DELETE FROM code_tags WHERE code = ? AND tag IN (?"+strings.Repeat(", ?", len(tags)-1)+")", args...,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("タグ削除エラー: %v", err)
+	}
+	n, _ := result.RowsAffected()
+	return int(n), nil
+}
+
+// GetTags 銘柄のタグをコード・タグ順に取得
+// code: 銘柄コード（空の場合は全銘柄）、tag: タグ（空の場合は全タグ）
+func (r *CodeNoteRepository) GetTags(code, tag string) ([]*CodeTag, error) {
+	query := `
+		SELECT t.code, COALESCE(li.company_name, ''), t.tag, t.created_at
+		FROM code_tags t
+		LEFT JOIN listed_info li ON li.code = t.code
+	`
+	var conditions []string
+	var args []interface{}
+	if code != "" {
+		conditions = append(conditions, "t.code = ?")
+		args = append(args, code)
+	}
+	if tag != "" {
+		conditions = append(conditions, "t.tag = ?")
+		args = append(args, tag)
+	}
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY t.code, t.tag"
+
+	rows, err := r.conn.GetDB().Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("タグ取得エラー: %v", err)
+	}
+	defer rows.Close()
+
+	var tags []*CodeTag
+	for rows.Next() {
+		t := &CodeTag{}
+		if err := rows.Scan(&t.Code, &t.CompanyName, &t.Tag, &t.CreatedAt); err != nil {
+			log.Printf("タグスキャンエラー: %v", err)
+			continue
+		}
+		tags = append(tags, t)
+	}
+
+	return tags, nil
+}
+
+// GetTagCounts タグごとの銘柄数をタグ順に取得
+func (r *CodeNoteRepository) GetTagCounts() ([]*TagCount, error) {
+	rows, err := r.conn.GetDB().Query("SELECT tag, COUNT(*) FROM code_tags GROUP BY tag ORDER BY tag")
+	if err != nil {
+		return nil, fmt.Errorf("タグ取得エラー: %v", err)
+	}
+	defer rows.Close()
+
+	var counts []*TagCount
+	for rows.Next() {
+		c := &TagCount{}
+		if err := rows.Scan(&c.Tag, &c.Codes); err != nil {
+			log.Printf("タグスキャンエラー: %v", err)
+			continue
+		}
+		counts = append(counts, c)
+	}
+
+	return counts, nil
+}
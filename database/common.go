@@ -29,6 +29,14 @@ func NullIfEmptyFloat(s string) interface{} {
 	return nil
 }
 
+// nullIfEmptyString 空文字列の場合はnilを返すヘルパー関数（文字列用）
+func nullIfEmptyString(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
 // BeginTransaction トランザクションを開始し、パニック時の自動ロールバックを設定
 func BeginTransaction(db *sql.DB) (*sql.Tx, func()) {
 	tx, err := db.Begin()
@@ -0,0 +1,259 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"time"
+
+	"stock-automation/schema"
+)
+
+// コーポレートアクションの種別
+const (
+	ActionSplit        = "split"
+	ActionReverseSplit = "reverse_split"
+)
+
+// CorporateAction 株式分割・併合の構造体
+type CorporateAction struct {
+	Code             string
+	CompanyName      string // 取得時のみ（listed_infoから結合）
+	ExDate           time.Time
+	ActionType       string
+	AdjustmentFactor float64
+	SplitRatio       float64
+	ReadjustedAt     *time.Time
+}
+
+// CorporateActionRepository コーポレートアクションのリポジトリ
+type CorporateActionRepository struct {
+	conn *Connection
+}
+
+// NewCorporateActionRepository 新しいリポジトリを作成
+func NewCorporateActionRepository(conn *Connection) *CorporateActionRepository {
+	return &CorporateActionRepository{conn: conn}
+}
+
+// RecordFromQuotes 取得した四本値のうち調整係数が1以外のものを記録し、新たに記録した銘柄コードを返す
+func (r *CorporateActionRepository) RecordFromQuotes(quotes []schema.DailyQuote) ([]string, error) {
+	var splits []splitEventWithCode
+	for _, quote := range quotes {
+		if quote.AdjustmentFactor == 1 || quote.AdjustmentFactor <= 0 {
+			continue
+		}
+		tradeDate, err := time.ParseInLocation("2006-01-02", quote.Date, time.Local)
+		if err != nil {
+			log.Printf("取引日の形式が正しくありません (銘柄: %s, 日付: %s): %v", quote.Code, quote.Date, err)
+			continue
+		}
+		splits = append(splits, splitEventWithCode{code: quote.Code, splitEvent: splitEvent{tradeDate: tradeDate, factor: quote.AdjustmentFactor}})
+	}
+
+	return r.recordActions(splits)
+}
+
+// DetectCorporateActions daily_quotesから未記録の株式分割・併合を検出して記録し、新たに記録した銘柄コードを返す
+// localCodes: 対象銘柄コード（空の場合は全銘柄）
+func (r *CorporateActionRepository) DetectCorporateActions(localCodes []string) ([]string, error) {
+	query := `
+		SELECT dq.code, dq.trade_date, dq.adjustment_factor
+		FROM daily_quotes dq
+		LEFT JOIN corporate_actions ca ON ca.code = dq.code AND ca.ex_date = dq.trade_date
+		WHERE dq.adjustment_factor IS NOT NULL AND dq.adjustment_factor <> 1 AND dq.adjustment_factor > 0
+			AND ca.code IS NULL
+	`
+	var args []interface{}
+	if len(localCodes) > 0 {
+		query += " AND dq.code IN (?" + strings.Repeat(", ?", len(localCodes)-1) + ")"
+		for _, code := range localCodes {
+			args = append(args, code)
+		}
+	}
+	query += " ORDER BY dq.code, dq.trade_date"
+
+	rows, err := r.conn.GetDB().Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("株式分割データ取得エラー: %v", err)
+	}
+	defer rows.Close()
+
+	var splits []splitEventWithCode
+	for rows.Next() {
+		var split splitEventWithCode
+		if err := rows.Scan(&split.code, &split.tradeDate, &split.factor); err != nil {
+			log.Printf("株式分割データスキャンエラー: %v", err)
+			continue
+		}
+		splits = append(splits, split)
+	}
+
+	return r.recordActions(splits)
+}
+
+// splitEventWithCode 銘柄コード付きの株式分割・併合
+type splitEventWithCode struct {
+	code string
+	splitEvent
+}
+
+// recordActions 株式分割・併合を記録（記録済みのものは無視）し、新たに記録した銘柄コードを返す
+func (r *CorporateActionRepository) recordActions(splits []splitEventWithCode) ([]string, error) {
+	if len(splits) == 0 {
+		return nil, nil
+	}
+
+	query := `
+		INSERT IGNORE INTO corporate_actions (code, ex_date, action_type, adjustment_factor, split_ratio)
+		VALUES (?, ?, ?, ?, ?)
+	`
+
+	seen := make(map[string]bool)
+	var codes []string
+	for _, split := range splits {
+		actionType := ActionSplit
+		if split.factor > 1 {
+			actionType = ActionReverseSplit
+		}
+		splitRatio := math.Round(1/split.factor*1000000) / 1000000
+
+		result, err := r.conn.GetDB().Exec(query, split.code, split.tradeDate, actionType, split.factor, splitRatio)
+		if err != nil {
+			return codes, fmt.Errorf("コーポレートアクション挿入エラー (銘柄: %s, 日付: %s): %v", split.code, split.tradeDate.Format("2006-01-02"), err)
+		}
+		if affected, _ := result.RowsAffected(); affected > 0 && !seen[split.code] {
+			seen[split.code] = true
+			codes = append(codes, split.code)
+			log.Printf("株式分割・併合を検出: 銘柄 %s, 権利落ち日 %s, 調整係数 %g", split.code, split.tradeDate.Format("2006-01-02"), split.factor)
+		}
+	}
+
+	return codes, nil
+}
+
+// ReadjustPrices 銘柄の調整後四本値・出来高を、調整前の値と記録済みの調整係数から全期間再計算
+// 調整後株価 = 株価 × その取引日より後の調整係数の累積積、調整後出来高 = 出来高 ÷ 累積積
+func (r *CorporateActionRepository) ReadjustPrices(code string) error {
+	tx, cleanup := BeginTransaction(r.conn.GetDB())
+	defer cleanup()
+
+	splits, err := getSplitEvents(tx, code)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// 新しい区間から順に、区間内の取引日に同じ累積係数を適用
+	multiplier := 1.0
+	var upper *time.Time
+	for i := len(splits) - 1; i >= 0; i-- {
+		if err := readjustSegment(tx, code, &splits[i].tradeDate, upper, multiplier); err != nil {
+			tx.Rollback()
+			return err
+		}
+		multiplier *= splits[i].factor
+		upper = &splits[i].tradeDate
+	}
+	if err := readjustSegment(tx, code, nil, upper, multiplier); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if _, err := tx.Exec("UPDATE corporate_actions SET readjusted_at = ? WHERE code = ?", time.Now(), code); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("再計算日時更新エラー: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("トランザクションコミットエラー: %v", err)
+	}
+
+	log.Printf("銘柄 %s の調整後株価を再計算: 株式分割・併合 %d件", code, len(splits))
+	return nil
+}
+
+// readjustSegment from以上to未満（nilは制限なし）の取引日の調整後四本値・出来高を累積係数で再計算
+func readjustSegment(tx *sql.Tx, code string, from, to *time.Time, multiplier float64) error {
+	query := `
+		UPDATE daily_quotes SET
+			adjustment_open = ROUND(open * ?, 2),
+			adjustment_high = ROUND(high * ?, 2),
+			adjustment_low = ROUND(low * ?, 2),
+			adjustment_close = ROUND(close * ?, 2),
+			adjustment_volume = ROUND(volume / ?, 0)
+		WHERE code = ?
+	`
+	args := []interface{}{multiplier, multiplier, multiplier, multiplier, multiplier, code}
+	if from != nil {
+		query += " AND trade_date >= ?"
+		args = append(args, *from)
+	}
+	if to != nil {
+		query += " AND trade_date < ?"
+		args = append(args, *to)
+	}
+
+	if _, err := tx.Exec(query, args...); err != nil {
+		return fmt.Errorf("調整後株価更新エラー: %v", err)
+	}
+	return nil
+}
+
+// GetCorporateActions コーポレートアクションを権利落ち日の新しい順に取得
+// code: 銘柄コード（空の場合は全銘柄）
+// from: 権利落ち日の開始日（YYYY-MM-DD形式、空の場合は全期間）
+func (r *CorporateActionRepository) GetCorporateActions(code, from string, filter CodeFilter, limit int) ([]*CorporateAction, error) {
+	query := `
+		SELECT
+			ca.code, COALESCE(li.company_name, ''), ca.ex_date, ca.action_type,
+			ca.adjustment_factor, ca.split_ratio, ca.readjusted_at
+		FROM corporate_actions ca
+		LEFT JOIN listed_info li ON li.code = ca.code
+	`
+	conditions, args := filter.conditions("ca.code")
+	if code != "" {
+		conditions = append(conditions, "ca.code = ?")
+		args = append(args, code)
+	}
+	if from != "" {
+		conditions = append(conditions, "ca.ex_date >= ?")
+		args = append(args, from)
+	}
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY ca.ex_date DESC, ca.code"
+	if limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", limit)
+	}
+
+	rows, err := r.conn.GetDB().Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("コーポレートアクションデータ取得エラー: %v", err)
+	}
+	defer rows.Close()
+
+	var actions []*CorporateAction
+	for rows.Next() {
+		action := &CorporateAction{}
+		err := rows.Scan(
+			&action.Code,
+			&action.CompanyName,
+			&action.ExDate,
+			&action.ActionType,
+			&action.AdjustmentFactor,
+			&action.SplitRatio,
+			&action.ReadjustedAt,
+		)
+		if err != nil {
+			log.Printf("コーポレートアクションデータスキャンエラー: %v", err)
+			continue
+		}
+		actions = append(actions, action)
+	}
+
+	return actions, nil
+}
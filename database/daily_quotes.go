@@ -2,7 +2,9 @@ package database
 
 import (
 	"fmt"
+	"log"
 	"log/slog"
+	"strings"
 	"time"
 
 	"stock-automation/schema"
@@ -77,3 +79,45 @@ func (r *DailyQuotesRepository) GetDailyQuotes(code, date string) ([]schema.Dail
 
 	return quotes, nil
 }
+
+// ClosePrice 取引日の終値
+type ClosePrice struct {
+	Date  time.Time
+	Close float64
+}
+
+// GetAdjustedCloses 銘柄ごとの期間内の調整後終値を古い順に取得（調整後終値がない日は除く）
+func (r *DailyQuotesRepository) GetAdjustedCloses(codes []string, from, to time.Time) (map[string][]*ClosePrice, error) {
+	result := make(map[string][]*ClosePrice)
+	if len(codes) == 0 {
+		return result, nil
+	}
+
+	args := []interface{}{from.Format("2006-01-02"), to.Format("2006-01-02")}
+	for _, code := range codes {
+		args = append(args, code)
+	}
+	rows, err := r.conn.GetDB().Query(`
+		SELECT code, trade_date, adjustment_close
+		FROM daily_quotes
+		WHERE trade_date BETWEEN ? AND ? AND adjustment_close > 0
+			AND code IN (?`+strings.Repeat(", ?", len(codes)-1)+`)
+		ORDER BY code, trade_date
+	`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("調整後終値取得エラー: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var code string
+		price := &ClosePrice{}
+		if err := rows.Scan(&code, &price.Date, &price.Close); err != nil {
+			log.Printf("調整後終値スキャンエラー: %v", err)
+			continue
+		}
+		result[code] = append(result[code], price)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,355 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"stock-automation/schema"
+)
+
+// DisclosureDigest 開示の主要数値の要約
+type DisclosureDigest struct {
+	LocalCode           string
+	CompanyName         string
+	Watchlists          []string // 銘柄を登録しているウォッチリスト名
+	DisclosedDate       string
+	DisclosedTime       string
+	TypeOfDocument      string
+	TypeOfCurrentPeriod string
+
+	// 実績（期首からの累計）と前年同期比(%)
+	NetSales           *float64
+	OperatingProfit    *float64
+	NetSalesYoY        *float64
+	OperatingProfitYoY *float64
+
+	// 開示した通期予想と前回予想比(%)（決算短信の通期決算は翌期予想）
+	ForecastFiscalYearEnd         *time.Time
+	ForecastNetSales              *float64
+	ForecastOperatingProfit       *float64
+	ForecastNetSalesChange        *float64
+	ForecastOperatingProfitChange *float64
+	ForecastInitial               bool // 会計年度の最初の予想の場合はtrue
+}
+
+// DisclosureRepository 開示の要約のリポジトリ
+type DisclosureRepository struct {
+	conn *Connection
+}
+
+// NewDisclosureRepository 新しいリポジトリを作成
+func NewDisclosureRepository(conn *Connection) *DisclosureRepository {
+	return &DisclosureRepository{conn: conn}
+}
+
+// BuildDigests 保存済みの財務情報から開示の要約を作成（前年同期・前回予想はstatementsから取得）
+func (r *DisclosureRepository) BuildDigests(statements []schema.FinancialStatement) ([]*DisclosureDigest, error) {
+	var codes []string
+	seen := make(map[string]bool)
+	for _, stmt := range statements {
+		if !seen[stmt.LocalCode] {
+			seen[stmt.LocalCode] = true
+			codes = append(codes, stmt.LocalCode)
+		}
+	}
+
+	watchlists, err := NewWatchlistRepository(r.conn).GetWatchlistNames(codes)
+	if err != nil {
+		return nil, err
+	}
+	companyNames, err := NewListedInfoRepository(r.conn).GetCompanyNames()
+	if err != nil {
+		return nil, err
+	}
+
+	db := r.conn.GetDB()
+	actualsByCode := make(map[string][]*actualPoint)
+	forecastsByCode := make(map[string][]*forecastPoint)
+
+	var digests []*DisclosureDigest
+	for _, stmt := range statements {
+		if _, ok := actualsByCode[stmt.LocalCode]; !ok {
+			actuals, err := getActualHistory(db, stmt.LocalCode)
+			if err != nil {
+				return nil, err
+			}
+			forecasts, err := getForecastHistory(db, stmt.LocalCode)
+			if err != nil {
+				return nil, err
+			}
+			actualsByCode[stmt.LocalCode] = actuals
+			forecastsByCode[stmt.LocalCode] = forecasts
+		}
+
+		d := &DisclosureDigest{
+			LocalCode:           stmt.LocalCode,
+			CompanyName:         companyNames[stmt.LocalCode],
+			Watchlists:          watchlists[stmt.LocalCode],
+			DisclosedDate:       stmt.DisclosedDate,
+			DisclosedTime:       stmt.DisclosedTime,
+			TypeOfDocument:      stmt.TypeOfDocument,
+			TypeOfCurrentPeriod: stmt.TypeOfCurrentPeriod,
+			NetSales:            parseFloatPtr(stmt.NetSales),
+			OperatingProfit:     parseFloatPtr(stmt.OperatingProfit),
+		}
+		d.setYoY(stmt, actualsByCode[stmt.LocalCode])
+		d.setForecast(stmt, forecastsByCode[stmt.LocalCode])
+		digests = append(digests, d)
+	}
+
+	return digests, nil
+}
+
+// GetDigestsByDate 開示日の全ての開示の要約を開示時刻・コード順に取得
+func (r *DisclosureRepository) GetDigestsByDate(date string) ([]*DisclosureDigest, error) {
+	rows, err := r.conn.GetDB().Query(`
+		SELECT
+			DATE_FORMAT(disclosed_date, '%Y-%m-%d'), disclosed_time, local_code,
+			COALESCE(disclosure_number, ''), COALESCE(type_of_document, ''), COALESCE(type_of_current_period, ''),
+			COALESCE(DATE_FORMAT(current_fiscal_year_start_date, '%Y-%m-%d'), ''),
+			net_sales, operating_profit
+		FROM statements
+		WHERE disclosed_date = ?
+		ORDER BY disclosed_time, local_code
+	`, date)
+	if err != nil {
+		return nil, fmt.Errorf("開示取得エラー: %v", err)
+	}
+	defer rows.Close()
+
+	var statements []schema.FinancialStatement
+	for rows.Next() {
+		var stmt schema.FinancialStatement
+		var disclosedTime, netSales, operatingProfit sql.NullString
+		err := rows.Scan(
+			&stmt.DisclosedDate,
+			&disclosedTime,
+			&stmt.LocalCode,
+			&stmt.DisclosureNumber,
+			&stmt.TypeOfDocument,
+			&stmt.TypeOfCurrentPeriod,
+			&stmt.CurrentFiscalYearStartDate,
+			&netSales,
+			&operatingProfit,
+		)
+		if err != nil {
+			log.Printf("開示スキャンエラー: %v", err)
+			continue
+		}
+		stmt.DisclosedTime = disclosedTime.String
+		stmt.NetSales = netSales.String
+		stmt.OperatingProfit = operatingProfit.String
+		statements = append(statements, stmt)
+	}
+	rows.Close()
+
+	if len(statements) == 0 {
+		return nil, nil
+	}
+	return r.BuildDigests(statements)
+}
+
+// setYoY 前年度の同じ期間の実績と比較
+func (d *DisclosureDigest) setYoY(stmt schema.FinancialStatement, actuals []*actualPoint) {
+	start, err := time.Parse("2006-01-02", stmt.CurrentFiscalYearStartDate)
+	if err != nil {
+		return
+	}
+	prevEnd := start.AddDate(0, 0, -1).Format("2006-01-02")
+
+	for _, a := range actuals {
+		if a.periodType != stmt.TypeOfCurrentPeriod || a.fiscalYearEnd == nil || a.fiscalYearEnd.Format("2006-01-02") != prevEnd {
+			continue
+		}
+		if d.NetSales != nil && a.values[MetricNetSales] != nil {
+			d.NetSalesYoY = changePct(*d.NetSales, *a.values[MetricNetSales])
+		}
+		if d.OperatingProfit != nil && a.values[MetricOperatingProfit] != nil {
+			d.OperatingProfitYoY = changePct(*d.OperatingProfit, *a.values[MetricOperatingProfit])
+		}
+		return
+	}
+}
+
+// setForecast この開示の通期予想（複数の会計年度の予想がある場合は最も新しい会計年度）を、同じ会計年度の直前の予想と比較
+func (d *DisclosureDigest) setForecast(stmt schema.FinancialStatement, forecasts []*forecastPoint) {
+	var current *forecastPoint
+	for _, p := range forecasts {
+		if p.disclosedDate.Format("2006-01-02") != stmt.DisclosedDate || p.disclosedTime != stmt.DisclosedTime {
+			continue
+		}
+		if current == nil || p.fiscalYearStart.After(current.fiscalYearStart) {
+			current = p
+		}
+	}
+	if current == nil {
+		return
+	}
+
+	d.ForecastFiscalYearEnd = current.fiscalYearEnd
+	d.ForecastNetSales = current.values[MetricNetSales]
+	d.ForecastOperatingProfit = current.values[MetricOperatingProfit]
+
+	var previous *forecastPoint
+	for _, p := range forecasts {
+		if p.fiscalYearStart.Equal(current.fiscalYearStart) &&
+			disclosedBefore(p.disclosedDate, p.disclosedTime, current.disclosedDate, current.disclosedTime) {
+			previous = p
+		}
+	}
+	if previous == nil {
+		d.ForecastInitial = true
+		return
+	}
+
+	if d.ForecastNetSales != nil && previous.values[MetricNetSales] != nil {
+		d.ForecastNetSalesChange = changePct(*d.ForecastNetSales, *previous.values[MetricNetSales])
+	}
+	if d.ForecastOperatingProfit != nil && previous.values[MetricOperatingProfit] != nil {
+		d.ForecastOperatingProfitChange = changePct(*d.ForecastOperatingProfit, *previous.values[MetricOperatingProfit])
+	}
+}
+
+// 要約の言語
+const (
+	LangJapanese = "ja"
+	LangEnglish  = "en"
+)
+
+// localize 言語に応じて日本語・英語の文字列を選択
+func localize(lang, ja, en string) string {
+	if lang == LangEnglish {
+		return en
+	}
+	return ja
+}
+
+// DocumentName 開示書類の種類の表示名（例: 第1四半期決算短信、Q1 earnings report）
+func (d *DisclosureDigest) DocumentName(lang string) string {
+	doc := d.TypeOfDocument
+	switch {
+	case strings.Contains(doc, "EarnForecastRevision"):
+		return localize(lang, "業績予想の修正", "Earnings forecast revision")
+	case strings.Contains(doc, "DividendForecastRevision"):
+		return localize(lang, "配当予想の修正", "Dividend forecast revision")
+	case strings.Contains(doc, "FinancialStatements"):
+		switch d.TypeOfCurrentPeriod {
+		case "1Q", "2Q", "3Q":
+			return localize(lang, "第"+d.TypeOfCurrentPeriod[:1]+"四半期決算短信", "Q"+d.TypeOfCurrentPeriod[:1]+" earnings report")
+		case "FY":
+			return localize(lang, "通期決算短信", "Full-year earnings report")
+		}
+		return localize(lang, "決算短信", "Earnings report")
+	}
+	return doc
+}
+
+// Summary 開示の要約を日本語（ja）または英語（en）の複数行の文字列に変換
+func (d *DisclosureDigest) Summary(lang string) string {
+	var b strings.Builder
+	en := lang == LangEnglish
+
+	disclosedAt := d.DisclosedDate
+	if len(d.DisclosedTime) >= 5 {
+		disclosedAt += " " + d.DisclosedTime[:5]
+	}
+	if en {
+		fmt.Fprintf(&b, "%s %s: %s (%s)\n", d.LocalCode, d.CompanyName, d.DocumentName(lang), disclosedAt)
+	} else {
+		fmt.Fprintf(&b, "%s %s %s（%s）\n", d.LocalCode, d.CompanyName, d.DocumentName(lang), disclosedAt)
+	}
+
+	if d.NetSales != nil || d.OperatingProfit != nil {
+		if en {
+			fmt.Fprintf(&b, "  Sales %s (YoY %s), Operating profit %s (YoY %s)\n",
+				formatYen(d.NetSales, lang), formatChangePct(d.NetSalesYoY),
+				formatYen(d.OperatingProfit, lang), formatChangePct(d.OperatingProfitYoY))
+		} else {
+			fmt.Fprintf(&b, "  売上高 %s（前年同期比 %s）、営業利益 %s（前年同期比 %s）\n",
+				formatYen(d.NetSales, lang), formatChangePct(d.NetSalesYoY),
+				formatYen(d.OperatingProfit, lang), formatChangePct(d.OperatingProfitYoY))
+		}
+	}
+
+	if d.ForecastNetSales != nil || d.ForecastOperatingProfit != nil {
+		fiscalYear := ""
+		if d.ForecastFiscalYearEnd != nil {
+			fiscalYear = d.ForecastFiscalYearEnd.Format("2006/01")
+		}
+		salesChange, profitChange := formatChangePct(d.ForecastNetSalesChange), formatChangePct(d.ForecastOperatingProfitChange)
+		if d.ForecastInitial {
+			salesChange = localize(lang, "新規", "new")
+			profitChange = salesChange
+		}
+		if en {
+			fmt.Fprintf(&b, "  FY%s forecast: Sales %s (vs prev. %s), Operating profit %s (vs prev. %s)\n",
+				fiscalYear, formatYen(d.ForecastNetSales, lang), salesChange, formatYen(d.ForecastOperatingProfit, lang), profitChange)
+		} else {
+			fmt.Fprintf(&b, "  %s期予想 売上高 %s（前回比 %s）、営業利益 %s（前回比 %s）\n",
+				fiscalYear, formatYen(d.ForecastNetSales, lang), salesChange, formatYen(d.ForecastOperatingProfit, lang), profitChange)
+		}
+	}
+
+	if len(d.Watchlists) > 0 {
+		if en {
+			fmt.Fprintf(&b, "  Watchlist: %s\n", strings.Join(d.Watchlists, ", "))
+		} else {
+			fmt.Fprintf(&b, "  ウォッチリスト: %s\n", strings.Join(d.Watchlists, ", "))
+		}
+	}
+
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// formatYen 金額（円）を日本語は億円、英語は十億円（bn）単位で桁区切りして表示
+func formatYen(v *float64, lang string) string {
+	if v == nil {
+		return "-"
+	}
+	if lang == LangEnglish {
+		return "JPY " + formatWithCommas(*v/1e9, 1) + "bn"
+	}
+	digits := 0
+	if math.Abs(*v) < 1e10 {
+		digits = 1
+	}
+	return formatWithCommas(*v/1e8, digits) + "億円"
+}
+
+// formatWithCommas 小数点以下の桁数を指定して整数部を3桁区切りで表示
+func formatWithCommas(v float64, digits int) string {
+	s := strconv.FormatFloat(math.Abs(v), 'f', digits, 64)
+	intPart, fracPart := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		intPart, fracPart = s[:i], s[i:]
+	}
+	for i := len(intPart) - 3; i > 0; i -= 3 {
+		intPart = intPart[:i] + "," + intPart[i:]
+	}
+	if v < 0 && strings.Trim(s, "0.") != "" {
+		intPart = "-" + intPart
+	}
+	return intPart + fracPart
+}
+
+// formatChangePct 増減率を符号付きで表示
+func formatChangePct(v *float64) string {
+	if v == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%+.1f%%", *v)
+}
+
+// parseFloatPtr APIの数値文字列をfloat64ポインタに変換（空・不正な値はnil）
+func parseFloatPtr(s string) *float64 {
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return nil
+	}
+	return &v
+}
@@ -0,0 +1,329 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"math"
+	"time"
+)
+
+// DividendRecord 配当履歴の構造体
+type DividendRecord struct {
+	LocalCode                string
+	FiscalYearStartDate      time.Time
+	FiscalYearEndDate        *time.Time
+	DisclosedDate            time.Time
+	IsForecast               bool
+	DividendPerShare         float64
+	AdjustmentRatio          float64
+	AdjustedDividendPerShare float64
+	ChangePct                *float64
+	IncreaseStreak           int
+	NoCutStreak              int
+	DPSCAGR5Y                *float64
+}
+
+// DividendHistoryRepository 配当履歴のリポジトリ
+type DividendHistoryRepository struct {
+	conn *Connection
+}
+
+// NewDividendHistoryRepository 新しいリポジトリを作成
+func NewDividendHistoryRepository(conn *Connection) *DividendHistoryRepository {
+	return &DividendHistoryRepository{conn: conn}
+}
+
+// splitEvent 株式分割・併合（daily_quotesの調整係数が1以外の日）
+type splitEvent struct {
+	tradeDate time.Time
+	factor    float64
+}
+
+// BuildDividendHistory 通期実績・予想から配当履歴を作成して保存
+// localCodes: 対象銘柄コード（空の場合は全銘柄）
+func (r *DividendHistoryRepository) BuildDividendHistory(localCodes []string) error {
+	tx, cleanup := BeginTransaction(r.conn.GetDB())
+	defer cleanup()
+
+	if len(localCodes) == 0 {
+		codes, err := selectDistinctLocalCodes(tx)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		localCodes = codes
+	}
+
+	processedCount := 0
+	for _, code := range localCodes {
+		if err := r.processLocalCode(tx, code); err != nil {
+			log.Printf("銘柄 %s の配当履歴作成でエラー: %v", code, err)
+			continue
+		}
+		processedCount++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("トランザクションコミットエラー: %v", err)
+	}
+
+	log.Printf("配当履歴の作成完了: %d銘柄処理", processedCount)
+	return nil
+}
+
+// processLocalCode 個別銘柄の配当履歴を作成して置き換え
+func (r *DividendHistoryRepository) processLocalCode(tx *sql.Tx, localCode string) error {
+	records, err := getAnnualDividends(tx, localCode)
+	if err != nil {
+		return err
+	}
+
+	forecasts, err := getForecastHistory(tx, localCode)
+	if err != nil {
+		return err
+	}
+	records = appendForecastDividends(localCode, records, forecasts)
+
+	splits, err := getSplitEvents(tx, localCode)
+	if err != nil {
+		return err
+	}
+
+	calculateDividendHistory(records, splits)
+
+	if _, err := tx.Exec("DELETE FROM dividend_history WHERE local_code = ?", localCode); err != nil {
+		return fmt.Errorf("既存データ削除エラー: %v", err)
+	}
+
+	for _, record := range records {
+		if err := r.insertRecord(tx, record); err != nil {
+			return fmt.Errorf("配当履歴挿入エラー (年度: %s): %v", record.FiscalYearStartDate.Format("2006-01-02"), err)
+		}
+	}
+
+	return nil
+}
+
+// getAnnualDividends 会計年度ごとの最新の通期実績の年間配当を会計年度の古い順に取得
+func getAnnualDividends(tx *sql.Tx, localCode string) ([]*DividendRecord, error) {
+	query := `
+		SELECT
+			current_fiscal_year_start_date,
+			current_fiscal_year_end_date,
+			disclosed_date,
+			result_dps_annual
+		FROM statements
+		WHERE local_code = ?
+			AND type_of_current_period = 'FY'
+			AND current_fiscal_year_start_date IS NOT NULL
+			AND result_dps_annual IS NOT NULL
+		ORDER BY current_fiscal_year_start_date, disclosed_date, disclosed_time
+	`
+
+	rows, err := tx.Query(query, localCode)
+	if err != nil {
+		return nil, fmt.Errorf("配当実績データ取得エラー: %v", err)
+	}
+	defer rows.Close()
+
+	var records []*DividendRecord
+	for rows.Next() {
+		record := &DividendRecord{LocalCode: localCode}
+		err := rows.Scan(
+			&record.FiscalYearStartDate,
+			&record.FiscalYearEndDate,
+			&record.DisclosedDate,
+			&record.DividendPerShare,
+		)
+		if err != nil {
+			log.Printf("配当実績データスキャンエラー: %v", err)
+			continue
+		}
+
+		// 同一会計年度は後の開示で置き換える
+		if n := len(records); n > 0 && records[n-1].FiscalYearStartDate.Equal(record.FiscalYearStartDate) {
+			records[n-1] = record
+			continue
+		}
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+// appendForecastDividends 最後の実績より後の会計年度について、最新の予想年間配当を追加
+func appendForecastDividends(localCode string, records []*DividendRecord, forecasts []*forecastPoint) []*DividendRecord {
+	var lastActual *time.Time
+	if n := len(records); n > 0 {
+		lastActual = &records[n-1].FiscalYearStartDate
+	}
+
+	// forecastsは会計年度・開示順のため、同一年度は後の予想で置き換える
+	var result []*DividendRecord
+	for _, f := range forecasts {
+		dps := f.values[MetricDPSAnnual]
+		if dps == nil || (lastActual != nil && !f.fiscalYearStart.After(*lastActual)) {
+			continue
+		}
+
+		record := &DividendRecord{
+			LocalCode:           localCode,
+			FiscalYearStartDate: f.fiscalYearStart,
+			FiscalYearEndDate:   f.fiscalYearEnd,
+			DisclosedDate:       f.disclosedDate,
+			IsForecast:          true,
+			DividendPerShare:    *dps,
+		}
+		if n := len(result); n > 0 && result[n-1].FiscalYearStartDate.Equal(record.FiscalYearStartDate) {
+			result[n-1] = record
+			continue
+		}
+		result = append(result, record)
+	}
+
+	return append(records, result...)
+}
+
+// getSplitEvents 株式分割・併合の履歴を取得
+func getSplitEvents(q sqlQueryer, code string) ([]splitEvent, error) {
+	rows, err := q.Query(`
+		SELECT trade_date, adjustment_factor FROM daily_quotes
+		WHERE code = ? AND adjustment_factor IS NOT NULL AND adjustment_factor <> 1 AND adjustment_factor > 0
+		ORDER BY trade_date
+	`, code)
+	if err != nil {
+		return nil, fmt.Errorf("株式分割データ取得エラー: %v", err)
+	}
+	defer rows.Close()
+
+	var splits []splitEvent
+	for rows.Next() {
+		var split splitEvent
+		if err := rows.Scan(&split.tradeDate, &split.factor); err != nil {
+			log.Printf("株式分割データスキャンエラー: %v", err)
+			continue
+		}
+		splits = append(splits, split)
+	}
+
+	return splits, nil
+}
+
+// calculateDividendHistory 会計年度の古い順に並んだ配当履歴に分割調整・前期比・連続年数・CAGRを設定
+// 期末日より後に権利落ちした株式分割・併合で、過去の配当を現在の株式数ベースに調整する
+func calculateDividendHistory(records []*DividendRecord, splits []splitEvent) {
+	byStart := make(map[string]*DividendRecord, len(records))
+
+	for _, record := range records {
+		record.AdjustmentRatio = 1
+		for _, split := range splits {
+			if record.FiscalYearEndDate != nil && split.tradeDate.After(*record.FiscalYearEndDate) {
+				record.AdjustmentRatio *= split.factor
+			}
+		}
+		record.AdjustedDividendPerShare = math.Round(record.DividendPerShare*record.AdjustmentRatio*10000) / 10000
+		byStart[record.FiscalYearStartDate.Format("2006-01-02")] = record
+	}
+
+	for _, record := range records {
+		current := record.AdjustedDividendPerShare
+		prev := byStart[record.FiscalYearStartDate.AddDate(-1, 0, 0).Format("2006-01-02")]
+
+		if prev != nil {
+			base := prev.AdjustedDividendPerShare
+			record.ChangePct = changePct(current, base)
+
+			// 端数の誤差を除くため小数2桁で比較
+			cur, prv := math.Round(current*100), math.Round(base*100)
+			if cur > prv {
+				record.IncreaseStreak = prev.IncreaseStreak + 1
+			}
+			if cur >= prv && cur > 0 {
+				record.NoCutStreak = prev.NoCutStreak + 1
+			}
+		}
+
+		if base := byStart[record.FiscalYearStartDate.AddDate(-5, 0, 0).Format("2006-01-02")]; base != nil &&
+			base.AdjustedDividendPerShare > 0 && current > 0 {
+			v := math.Round((math.Pow(current/base.AdjustedDividendPerShare, 1.0/5)-1)*10000) / 100
+			record.DPSCAGR5Y = &v
+		}
+	}
+}
+
+// insertRecord 配当履歴データを挿入
+func (r *DividendHistoryRepository) insertRecord(tx *sql.Tx, record *DividendRecord) error {
+	query := `
+		INSERT INTO dividend_history (
+			local_code, fiscal_year_start_date, fiscal_year_end_date, disclosed_date, is_forecast,
+			dividend_per_share, adjustment_ratio, adjusted_dividend_per_share, change_pct,
+			increase_streak, no_cut_streak, dps_cagr_5y
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	_, err := tx.Exec(query,
+		record.LocalCode,
+		record.FiscalYearStartDate,
+		record.FiscalYearEndDate,
+		record.DisclosedDate,
+		record.IsForecast,
+		record.DividendPerShare,
+		record.AdjustmentRatio,
+		record.AdjustedDividendPerShare,
+		record.ChangePct,
+		record.IncreaseStreak,
+		record.NoCutStreak,
+		record.DPSCAGR5Y,
+	)
+
+	return err
+}
+
+// GetDividendHistory 銘柄の配当履歴を会計年度の新しい順に取得
+func (r *DividendHistoryRepository) GetDividendHistory(localCode string, limit int) ([]*DividendRecord, error) {
+	query := `
+		SELECT
+			local_code, fiscal_year_start_date, fiscal_year_end_date, disclosed_date, is_forecast,
+			dividend_per_share, adjustment_ratio, adjusted_dividend_per_share, change_pct,
+			increase_streak, no_cut_streak, dps_cagr_5y
+		FROM dividend_history
+		WHERE local_code = ?
+		ORDER BY fiscal_year_start_date DESC
+	`
+	if limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", limit)
+	}
+
+	rows, err := r.conn.GetDB().Query(query, localCode)
+	if err != nil {
+		return nil, fmt.Errorf("配当履歴データ取得エラー: %v", err)
+	}
+	defer rows.Close()
+
+	var records []*DividendRecord
+	for rows.Next() {
+		record := &DividendRecord{}
+		err := rows.Scan(
+			&record.LocalCode,
+			&record.FiscalYearStartDate,
+			&record.FiscalYearEndDate,
+			&record.DisclosedDate,
+			&record.IsForecast,
+			&record.DividendPerShare,
+			&record.AdjustmentRatio,
+			&record.AdjustedDividendPerShare,
+			&record.ChangePct,
+			&record.IncreaseStreak,
+			&record.NoCutStreak,
+			&record.DPSCAGR5Y,
+		)
+		if err != nil {
+			log.Printf("配当履歴データスキャンエラー: %v", err)
+			continue
+		}
+		records = append(records, record)
+	}
+
+	return records, nil
+}
@@ -0,0 +1,413 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"math"
+	"time"
+)
+
+// 予想比の判定
+const (
+	SurpriseBeat   = "beat"
+	SurpriseMiss   = "miss"
+	SurpriseInline = "inline"
+)
+
+// SurpriseMetrics 決算サプライズを算出する指標
+var SurpriseMetrics = []string{
+	MetricNetSales,
+	MetricOperatingProfit,
+	MetricOrdinaryProfit,
+	MetricProfit,
+	MetricEPS,
+}
+
+// EarningsSurprise 決算サプライズの構造体
+type EarningsSurprise struct {
+	LocalCode             string
+	CompanyName           string // 取得時のみ（listed_infoから結合）
+	FiscalYearStartDate   time.Time
+	FiscalYearEndDate     *time.Time
+	TypeOfCurrentPeriod   string
+	Metric                string
+	DisclosedDate         time.Time
+	DisclosedTime         string
+	ActualValue           float64
+	ForecastValue         *float64 // FYは通期予想、2Qは第2四半期累計予想
+	ForecastDisclosedDate *time.Time
+	SurprisePct           *float64
+	Result                string // 予想がない場合は空文字列
+	FullYearForecastValue *float64
+	ProgressPct           *float64
+}
+
+// EarningsSurpriseRepository 決算サプライズのリポジトリ
+type EarningsSurpriseRepository struct {
+	conn *Connection
+}
+
+// NewEarningsSurpriseRepository 新しいリポジトリを作成
+func NewEarningsSurpriseRepository(conn *Connection) *EarningsSurpriseRepository {
+	return &EarningsSurpriseRepository{conn: conn}
+}
+
+// actualPoint ある期間の実績（期首からの累計）
+type actualPoint struct {
+	fiscalYearStart time.Time
+	fiscalYearEnd   *time.Time
+	periodType      string
+	disclosedDate   time.Time
+	disclosedTime   string
+	values          map[string]*float64
+}
+
+// BuildSurprises 財務データから決算サプライズを算出して保存
+// statements_summaryは会計年度ごとに最新の1件しか保持しないため、開示前の予想との比較はstatementsから直接行う
+// localCodes: 対象銘柄コード（空の場合は全銘柄）
+func (r *EarningsSurpriseRepository) BuildSurprises(localCodes []string) error {
+	tx, cleanup := BeginTransaction(r.conn.GetDB())
+	defer cleanup()
+
+	if len(localCodes) == 0 {
+		codes, err := selectDistinctLocalCodes(tx)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		localCodes = codes
+	}
+
+	processedCount := 0
+	for _, code := range localCodes {
+		if err := r.processLocalCode(tx, code); err != nil {
+			log.Printf("銘柄 %s の決算サプライズ算出でエラー: %v", code, err)
+			continue
+		}
+		processedCount++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("トランザクションコミットエラー: %v", err)
+	}
+
+	log.Printf("決算サプライズの作成完了: %d銘柄処理", processedCount)
+	return nil
+}
+
+// processLocalCode 個別銘柄の決算サプライズを算出して置き換え
+func (r *EarningsSurpriseRepository) processLocalCode(tx *sql.Tx, localCode string) error {
+	actuals, err := getActualHistory(tx, localCode)
+	if err != nil {
+		return err
+	}
+
+	forecasts, err := getForecastHistory(tx, localCode)
+	if err != nil {
+		return err
+	}
+
+	surprises := calculateSurprises(localCode, actuals, forecasts)
+
+	if _, err := tx.Exec("DELETE FROM earnings_surprises WHERE local_code = ?", localCode); err != nil {
+		return fmt.Errorf("既存データ削除エラー: %v", err)
+	}
+
+	for _, surprise := range surprises {
+		if err := r.insertSurprise(tx, surprise); err != nil {
+			return fmt.Errorf("決算サプライズ挿入エラー (年度: %s, 期間: %s, 指標: %s): %v",
+				surprise.FiscalYearStartDate.Format("2006-01-02"), surprise.TypeOfCurrentPeriod, surprise.Metric, err)
+		}
+	}
+
+	return nil
+}
+
+// getActualHistory 会計年度・期間ごとの最初の実績開示を取得（訂正開示は対象外）
+func getActualHistory(q sqlQueryer, localCode string) ([]*actualPoint, error) {
+	query := `
+		SELECT
+			current_fiscal_year_start_date,
+			current_fiscal_year_end_date,
+			type_of_current_period,
+			disclosed_date,
+			disclosed_time,
+			net_sales,
+			operating_profit,
+			ordinary_profit,
+			profit,
+			eps
+		FROM statements
+		WHERE local_code = ?
+			AND type_of_current_period IN ('1Q', '2Q', '3Q', 'FY')
+			AND current_fiscal_year_start_date IS NOT NULL
+			AND (net_sales IS NOT NULL OR operating_profit IS NOT NULL OR profit IS NOT NULL)
+		ORDER BY disclosed_date, disclosed_time
+	`
+
+	rows, err := q.Query(query, localCode)
+	if err != nil {
+		return nil, fmt.Errorf("実績データ取得エラー: %v", err)
+	}
+	defer rows.Close()
+
+	seen := make(map[string]bool)
+	var actuals []*actualPoint
+	for rows.Next() {
+		a := &actualPoint{}
+		var disclosedTime sql.NullString
+		var netSales, operatingProfit, ordinaryProfit, profit, eps *float64
+
+		err := rows.Scan(
+			&a.fiscalYearStart,
+			&a.fiscalYearEnd,
+			&a.periodType,
+			&a.disclosedDate,
+			&disclosedTime,
+			&netSales,
+			&operatingProfit,
+			&ordinaryProfit,
+			&profit,
+			&eps,
+		)
+		if err != nil {
+			log.Printf("実績データスキャンエラー: %v", err)
+			continue
+		}
+
+		key := a.fiscalYearStart.Format("2006-01-02") + "/" + a.periodType
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		a.disclosedTime = disclosedTime.String
+		a.values = map[string]*float64{
+			MetricNetSales:        netSales,
+			MetricOperatingProfit: operatingProfit,
+			MetricOrdinaryProfit:  ordinaryProfit,
+			MetricProfit:          profit,
+			MetricEPS:             eps,
+		}
+		actuals = append(actuals, a)
+	}
+
+	return actuals, nil
+}
+
+// disclosedBefore 開示日時aが開示日時bより前かを判定（時刻が不明な場合は0時扱い）
+func disclosedBefore(aDate time.Time, aTime string, bDate time.Time, bTime string) bool {
+	if !aDate.Equal(bDate) {
+		return aDate.Before(bDate)
+	}
+	if aTime == "" {
+		aTime = "00:00:00"
+	}
+	if bTime == "" {
+		bTime = "00:00:00"
+	}
+	return aTime < bTime
+}
+
+// calculateSurprises 実績ごとに開示前の最新予想と比較（forecastsは会計年度・開示順）
+func calculateSurprises(localCode string, actuals []*actualPoint, forecasts []*forecastPoint) []*EarningsSurprise {
+	var surprises []*EarningsSurprise
+
+	for _, a := range actuals {
+		for _, metric := range SurpriseMetrics {
+			actual := a.values[metric]
+			if actual == nil {
+				continue
+			}
+
+			// 開示前の最新予想（通期・第2四半期累計）
+			var fullYear, halfYear *forecastPoint
+			for _, f := range forecasts {
+				if !f.fiscalYearStart.Equal(a.fiscalYearStart) ||
+					!disclosedBefore(f.disclosedDate, f.disclosedTime, a.disclosedDate, a.disclosedTime) {
+					continue
+				}
+				if f.values[metric] != nil {
+					fullYear = f
+				}
+				if f.halfYearValues[metric] != nil {
+					halfYear = f
+				}
+			}
+
+			surprise := &EarningsSurprise{
+				LocalCode:           localCode,
+				FiscalYearStartDate: a.fiscalYearStart,
+				FiscalYearEndDate:   a.fiscalYearEnd,
+				TypeOfCurrentPeriod: a.periodType,
+				Metric:              metric,
+				DisclosedDate:       a.disclosedDate,
+				DisclosedTime:       a.disclosedTime,
+				ActualValue:         *actual,
+			}
+
+			// 比較対象の予想（FYは通期予想、2Qは第2四半期累計予想）
+			var comparable *forecastPoint
+			var comparableValue *float64
+			switch a.periodType {
+			case "FY":
+				if fullYear != nil {
+					comparable, comparableValue = fullYear, fullYear.values[metric]
+				}
+			case "2Q":
+				if halfYear != nil {
+					comparable, comparableValue = halfYear, halfYear.halfYearValues[metric]
+				}
+			}
+
+			if comparable != nil {
+				surprise.ForecastValue = comparableValue
+				surprise.ForecastDisclosedDate = &comparable.disclosedDate
+				surprise.SurprisePct = changePct(*actual, *comparableValue)
+				switch {
+				case *actual > *comparableValue:
+					surprise.Result = SurpriseBeat
+				case *actual < *comparableValue:
+					surprise.Result = SurpriseMiss
+				default:
+					surprise.Result = SurpriseInline
+				}
+			}
+
+			// 通期予想に対する進捗率（通期予想が正の場合のみ）
+			if fullYear != nil {
+				surprise.FullYearForecastValue = fullYear.values[metric]
+				if forecast := *fullYear.values[metric]; forecast > 0 {
+					progress := math.Round(*actual/forecast*10000) / 100
+					surprise.ProgressPct = &progress
+				}
+			}
+
+			surprises = append(surprises, surprise)
+		}
+	}
+
+	return surprises
+}
+
+// insertSurprise 決算サプライズデータを挿入
+func (r *EarningsSurpriseRepository) insertSurprise(tx *sql.Tx, surprise *EarningsSurprise) error {
+	query := `
+		INSERT INTO earnings_surprises (
+			local_code, fiscal_year_start_date, fiscal_year_end_date, type_of_current_period, metric,
+			disclosed_date, disclosed_time, actual_value,
+			forecast_value, forecast_disclosed_date, surprise_pct, result,
+			full_year_forecast_value, progress_pct
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	var disclosedTime, result interface{}
+	if surprise.DisclosedTime != "" {
+		disclosedTime = surprise.DisclosedTime
+	}
+	if surprise.Result != "" {
+		result = surprise.Result
+	}
+
+	_, err := tx.Exec(query,
+		surprise.LocalCode,
+		surprise.FiscalYearStartDate,
+		surprise.FiscalYearEndDate,
+		surprise.TypeOfCurrentPeriod,
+		surprise.Metric,
+		surprise.DisclosedDate,
+		disclosedTime,
+		surprise.ActualValue,
+		surprise.ForecastValue,
+		surprise.ForecastDisclosedDate,
+		surprise.SurprisePct,
+		result,
+		surprise.FullYearForecastValue,
+		surprise.ProgressPct,
+	)
+
+	return err
+}
+
+// GetSurprises 期間内に開示された予想比のある決算サプライズを取得
+// metric, result, period: 空の場合は絞り込まない
+// 予想比の大きい順（resultがmissの場合は小さい順）に並べる
+func (r *EarningsSurpriseRepository) GetSurprises(from, to, metric, result, period string, filter CodeFilter, limit int) ([]*EarningsSurprise, error) {
+	query := `
+		SELECT
+			es.local_code, COALESCE(li.company_name, ''), es.fiscal_year_start_date, es.fiscal_year_end_date,
+			es.type_of_current_period, es.metric, es.disclosed_date, es.disclosed_time, es.actual_value,
+			es.forecast_value, es.forecast_disclosed_date, es.surprise_pct, COALESCE(es.result, ''),
+			es.full_year_forecast_value, es.progress_pct
+		FROM earnings_surprises es
+		LEFT JOIN listed_info li ON li.code = es.local_code
+		WHERE es.disclosed_date BETWEEN ? AND ?
+			AND es.surprise_pct IS NOT NULL
+	`
+	args := []interface{}{from, to}
+
+	if metric != "" {
+		query += " AND es.metric = ?"
+		args = append(args, metric)
+	}
+	if result != "" {
+		query += " AND es.result = ?"
+		args = append(args, result)
+	}
+	if period != "" {
+		query += " AND es.type_of_current_period = ?"
+		args = append(args, period)
+	}
+	filterConditions, filterArgs := filter.conditions("es.local_code")
+	for _, condition := range filterConditions {
+		query += " AND " + condition
+	}
+	args = append(args, filterArgs...)
+
+	if result == SurpriseMiss {
+		query += " ORDER BY es.surprise_pct ASC, es.disclosed_date DESC, es.local_code"
+	} else {
+		query += " ORDER BY es.surprise_pct DESC, es.disclosed_date DESC, es.local_code"
+	}
+	if limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", limit)
+	}
+
+	rows, err := r.conn.GetDB().Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("決算サプライズデータ取得エラー: %v", err)
+	}
+	defer rows.Close()
+
+	var surprises []*EarningsSurprise
+	for rows.Next() {
+		surprise := &EarningsSurprise{}
+		var disclosedTime sql.NullString
+		err := rows.Scan(
+			&surprise.LocalCode,
+			&surprise.CompanyName,
+			&surprise.FiscalYearStartDate,
+			&surprise.FiscalYearEndDate,
+			&surprise.TypeOfCurrentPeriod,
+			&surprise.Metric,
+			&surprise.DisclosedDate,
+			&disclosedTime,
+			&surprise.ActualValue,
+			&surprise.ForecastValue,
+			&surprise.ForecastDisclosedDate,
+			&surprise.SurprisePct,
+			&surprise.Result,
+			&surprise.FullYearForecastValue,
+			&surprise.ProgressPct,
+		)
+		if err != nil {
+			log.Printf("決算サプライズデータスキャンエラー: %v", err)
+			continue
+		}
+		surprise.DisclosedTime = disclosedTime.String
+		surprises = append(surprises, surprise)
+	}
+
+	return surprises, nil
+}
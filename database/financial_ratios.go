@@ -0,0 +1,371 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"math"
+	"time"
+)
+
+// FinancialRatio 財務比率の構造体
+type FinancialRatio struct {
+	LocalCode           string
+	FiscalYearStartDate time.Time
+	FiscalYearEndDate   *time.Time
+	DisclosedDate       time.Time
+	NetSales            *int64
+	OperatingProfit     *int64
+	Profit              *int64
+	TotalAssets         *int64
+	Equity              *int64
+	EPS                 *float64
+	DividendPerShare    *float64
+	CFOperating         *int64
+	CFInvesting         *int64
+	ROE                 *float64
+	ROA                 *float64
+	OperatingMargin     *float64
+	NetMargin           *float64
+	EquityRatio         *float64
+	EquityRatioChange   *float64 // 前期差（ポイント）
+	PayoutRatio         *float64
+	NetSalesCAGR3Y      *float64
+	NetSalesCAGR5Y      *float64
+	ProfitCAGR3Y        *float64
+	ProfitCAGR5Y        *float64
+	FreeCashFlow        *int64
+}
+
+// FinancialRatioRepository 財務比率のリポジトリ
+type FinancialRatioRepository struct {
+	conn *Connection
+}
+
+// NewFinancialRatioRepository 新しいリポジトリを作成
+func NewFinancialRatioRepository(conn *Connection) *FinancialRatioRepository {
+	return &FinancialRatioRepository{conn: conn}
+}
+
+// BuildRatios 通期実績から財務比率を算出して保存
+// localCodes: 対象銘柄コード（空の場合は全銘柄）
+func (r *FinancialRatioRepository) BuildRatios(localCodes []string) error {
+	tx, cleanup := BeginTransaction(r.conn.GetDB())
+	defer cleanup()
+
+	if len(localCodes) == 0 {
+		codes, err := selectDistinctLocalCodes(tx)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		localCodes = codes
+	}
+
+	processedCount := 0
+	for _, code := range localCodes {
+		if err := r.processLocalCode(tx, code); err != nil {
+			log.Printf("銘柄 %s の財務比率算出でエラー: %v", code, err)
+			continue
+		}
+		processedCount++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("トランザクションコミットエラー: %v", err)
+	}
+
+	log.Printf("財務比率の作成完了: %d銘柄処理", processedCount)
+	return nil
+}
+
+// processLocalCode 個別銘柄の財務比率を算出して置き換え
+func (r *FinancialRatioRepository) processLocalCode(tx *sql.Tx, localCode string) error {
+	ratios, err := r.getAnnualActuals(tx, localCode)
+	if err != nil {
+		return err
+	}
+
+	calculateRatios(ratios)
+
+	if _, err := tx.Exec("DELETE FROM financial_ratios WHERE local_code = ?", localCode); err != nil {
+		return fmt.Errorf("既存データ削除エラー: %v", err)
+	}
+
+	for _, ratio := range ratios {
+		if err := r.insertRatio(tx, ratio); err != nil {
+			return fmt.Errorf("財務比率挿入エラー (年度: %s): %v", ratio.FiscalYearStartDate.Format("2006-01-02"), err)
+		}
+	}
+
+	return nil
+}
+
+// getAnnualActuals 会計年度ごとの最新の通期実績を会計年度の古い順に取得（訂正開示がある場合は訂正後を採用）
+func (r *FinancialRatioRepository) getAnnualActuals(tx *sql.Tx, localCode string) ([]*FinancialRatio, error) {
+	query := `
+		SELECT
+			current_fiscal_year_start_date,
+			current_fiscal_year_end_date,
+			disclosed_date,
+			net_sales,
+			operating_profit,
+			profit,
+			total_assets,
+			equity,
+			eps,
+			result_dps_annual,
+			cf_operating,
+			cf_investing
+		FROM statements
+		WHERE local_code = ?
+			AND type_of_current_period = 'FY'
+			AND current_fiscal_year_start_date IS NOT NULL
+			AND (net_sales IS NOT NULL OR operating_profit IS NOT NULL OR profit IS NOT NULL)
+		ORDER BY current_fiscal_year_start_date, disclosed_date, disclosed_time
+	`
+
+	rows, err := tx.Query(query, localCode)
+	if err != nil {
+		return nil, fmt.Errorf("通期実績データ取得エラー: %v", err)
+	}
+	defer rows.Close()
+
+	var ratios []*FinancialRatio
+	for rows.Next() {
+		ratio := &FinancialRatio{LocalCode: localCode}
+		err := rows.Scan(
+			&ratio.FiscalYearStartDate,
+			&ratio.FiscalYearEndDate,
+			&ratio.DisclosedDate,
+			&ratio.NetSales,
+			&ratio.OperatingProfit,
+			&ratio.Profit,
+			&ratio.TotalAssets,
+			&ratio.Equity,
+			&ratio.EPS,
+			&ratio.DividendPerShare,
+			&ratio.CFOperating,
+			&ratio.CFInvesting,
+		)
+		if err != nil {
+			log.Printf("通期実績データスキャンエラー: %v", err)
+			continue
+		}
+
+		// 同一会計年度は後の開示で置き換える
+		if n := len(ratios); n > 0 && ratios[n-1].FiscalYearStartDate.Equal(ratio.FiscalYearStartDate) {
+			ratios[n-1] = ratio
+			continue
+		}
+		ratios = append(ratios, ratio)
+	}
+
+	return ratios, nil
+}
+
+// calculateRatios 会計年度の古い順に並んだ通期実績から財務比率を算出
+func calculateRatios(ratios []*FinancialRatio) {
+	byStart := make(map[string]*FinancialRatio, len(ratios))
+	for _, ratio := range ratios {
+		byStart[ratio.FiscalYearStartDate.Format("2006-01-02")] = ratio
+	}
+
+	// 基準年度（n年前の会計年度）を取得
+	yearsAgo := func(ratio *FinancialRatio, n int) *FinancialRatio {
+		return byStart[ratio.FiscalYearStartDate.AddDate(-n, 0, 0).Format("2006-01-02")]
+	}
+
+	for _, ratio := range ratios {
+		prev := yearsAgo(ratio, 1)
+
+		// 収益性（ROE・ROAは期首・期末の平均）
+		if prev != nil {
+			ratio.ROE = percentOf(ratio.Profit, averageInt64(ratio.Equity, prev.Equity))
+			ratio.ROA = percentOf(ratio.Profit, averageInt64(ratio.TotalAssets, prev.TotalAssets))
+		} else {
+			ratio.ROE = percentOf(ratio.Profit, int64ToFloat64(ratio.Equity))
+			ratio.ROA = percentOf(ratio.Profit, int64ToFloat64(ratio.TotalAssets))
+		}
+		ratio.OperatingMargin = percentOf(ratio.OperatingProfit, int64ToFloat64(ratio.NetSales))
+		ratio.NetMargin = percentOf(ratio.Profit, int64ToFloat64(ratio.NetSales))
+
+		// 安全性
+		ratio.EquityRatio = percentOf(ratio.Equity, int64ToFloat64(ratio.TotalAssets))
+		if prev != nil {
+			prevEquityRatio := percentOf(prev.Equity, int64ToFloat64(prev.TotalAssets))
+			if ratio.EquityRatio != nil && prevEquityRatio != nil {
+				change := math.Round((*ratio.EquityRatio-*prevEquityRatio)*100) / 100
+				ratio.EquityRatioChange = &change
+			}
+		}
+
+		// 配当性向（EPSが正の場合のみ）
+		if ratio.DividendPerShare != nil && ratio.EPS != nil && *ratio.EPS > 0 {
+			payout := math.Round(*ratio.DividendPerShare / *ratio.EPS * 10000) / 100
+			ratio.PayoutRatio = &payout
+		}
+
+		// 成長性
+		if base := yearsAgo(ratio, 3); base != nil {
+			ratio.NetSalesCAGR3Y = cagr(ratio.NetSales, base.NetSales, 3)
+			ratio.ProfitCAGR3Y = cagr(ratio.Profit, base.Profit, 3)
+		}
+		if base := yearsAgo(ratio, 5); base != nil {
+			ratio.NetSalesCAGR5Y = cagr(ratio.NetSales, base.NetSales, 5)
+			ratio.ProfitCAGR5Y = cagr(ratio.Profit, base.Profit, 5)
+		}
+
+		// フリーキャッシュフロー
+		if ratio.CFOperating != nil && ratio.CFInvesting != nil {
+			fcf := *ratio.CFOperating + *ratio.CFInvesting
+			ratio.FreeCashFlow = &fcf
+		}
+	}
+}
+
+// int64ToFloat64 整数値を小数値に変換（NULLの場合はNULL）
+func int64ToFloat64(v *int64) *float64 {
+	if v == nil {
+		return nil
+	}
+	f := float64(*v)
+	return &f
+}
+
+// averageInt64 2つの値の平均（どちらかがNULLの場合はNULL）
+func averageInt64(a, b *int64) *float64 {
+	if a == nil || b == nil {
+		return nil
+	}
+	v := (float64(*a) + float64(*b)) / 2
+	return &v
+}
+
+// percentOf 分子÷分母の百分率（分母が0以下またはNULLの場合はNULL）
+func percentOf(numerator *int64, denominator *float64) *float64 {
+	if numerator == nil || denominator == nil || *denominator <= 0 {
+		return nil
+	}
+	v := math.Round(float64(*numerator) / *denominator * 10000) / 100
+	return &v
+}
+
+// cagr 年平均成長率（%）を計算（当年度・基準年度とも正の場合のみ）
+func cagr(current, base *int64, years int) *float64 {
+	if current == nil || base == nil || *current <= 0 || *base <= 0 {
+		return nil
+	}
+	v := math.Round((math.Pow(float64(*current)/float64(*base), 1/float64(years))-1)*10000) / 100
+	return &v
+}
+
+// insertRatio 財務比率データを挿入
+func (r *FinancialRatioRepository) insertRatio(tx *sql.Tx, ratio *FinancialRatio) error {
+	query := `
+		INSERT INTO financial_ratios (
+			local_code, fiscal_year_start_date, fiscal_year_end_date, disclosed_date,
+			net_sales, operating_profit, profit, total_assets, equity, eps, dividend_per_share,
+			cf_operating, cf_investing,
+			roe, roa, operating_margin, net_margin,
+			equity_ratio, equity_ratio_change, payout_ratio,
+			net_sales_cagr_3y, net_sales_cagr_5y, profit_cagr_3y, profit_cagr_5y,
+			free_cash_flow
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	_, err := tx.Exec(query,
+		ratio.LocalCode,
+		ratio.FiscalYearStartDate,
+		ratio.FiscalYearEndDate,
+		ratio.DisclosedDate,
+		ratio.NetSales,
+		ratio.OperatingProfit,
+		ratio.Profit,
+		ratio.TotalAssets,
+		ratio.Equity,
+		ratio.EPS,
+		ratio.DividendPerShare,
+		ratio.CFOperating,
+		ratio.CFInvesting,
+		ratio.ROE,
+		ratio.ROA,
+		ratio.OperatingMargin,
+		ratio.NetMargin,
+		ratio.EquityRatio,
+		ratio.EquityRatioChange,
+		ratio.PayoutRatio,
+		ratio.NetSalesCAGR3Y,
+		ratio.NetSalesCAGR5Y,
+		ratio.ProfitCAGR3Y,
+		ratio.ProfitCAGR5Y,
+		ratio.FreeCashFlow,
+	)
+
+	return err
+}
+
+// GetRatiosByCode 銘柄の財務比率を会計年度の新しい順に取得
+func (r *FinancialRatioRepository) GetRatiosByCode(localCode string, limit int) ([]*FinancialRatio, error) {
+	query := `
+		SELECT
+			local_code, fiscal_year_start_date, fiscal_year_end_date, disclosed_date,
+			net_sales, operating_profit, profit, total_assets, equity, eps, dividend_per_share,
+			cf_operating, cf_investing,
+			roe, roa, operating_margin, net_margin,
+			equity_ratio, equity_ratio_change, payout_ratio,
+			net_sales_cagr_3y, net_sales_cagr_5y, profit_cagr_3y, profit_cagr_5y,
+			free_cash_flow
+		FROM financial_ratios
+		WHERE local_code = ?
+		ORDER BY fiscal_year_start_date DESC
+	`
+	if limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", limit)
+	}
+
+	rows, err := r.conn.GetDB().Query(query, localCode)
+	if err != nil {
+		return nil, fmt.Errorf("財務比率データ取得エラー: %v", err)
+	}
+	defer rows.Close()
+
+	var ratios []*FinancialRatio
+	for rows.Next() {
+		ratio := &FinancialRatio{}
+		err := rows.Scan(
+			&ratio.LocalCode,
+			&ratio.FiscalYearStartDate,
+			&ratio.FiscalYearEndDate,
+			&ratio.DisclosedDate,
+			&ratio.NetSales,
+			&ratio.OperatingProfit,
+			&ratio.Profit,
+			&ratio.TotalAssets,
+			&ratio.Equity,
+			&ratio.EPS,
+			&ratio.DividendPerShare,
+			&ratio.CFOperating,
+			&ratio.CFInvesting,
+			&ratio.ROE,
+			&ratio.ROA,
+			&ratio.OperatingMargin,
+			&ratio.NetMargin,
+			&ratio.EquityRatio,
+			&ratio.EquityRatioChange,
+			&ratio.PayoutRatio,
+			&ratio.NetSalesCAGR3Y,
+			&ratio.NetSalesCAGR5Y,
+			&ratio.ProfitCAGR3Y,
+			&ratio.ProfitCAGR5Y,
+			&ratio.FreeCashFlow,
+		)
+		if err != nil {
+			log.Printf("財務比率データスキャンエラー: %v", err)
+			continue
+		}
+		ratios = append(ratios, ratio)
+	}
+
+	return ratios, nil
+}
@@ -0,0 +1,453 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"time"
+)
+
+// 予想修正の対象指標
+const (
+	MetricNetSales        = "net_sales"
+	MetricOperatingProfit = "operating_profit"
+	MetricOrdinaryProfit  = "ordinary_profit"
+	MetricProfit          = "profit"
+	MetricEPS             = "eps"
+	MetricDPSAnnual       = "dps_annual"
+)
+
+// ForecastMetrics 予想修正を追跡する指標（表示順）
+var ForecastMetrics = []string{
+	MetricNetSales,
+	MetricOperatingProfit,
+	MetricOrdinaryProfit,
+	MetricProfit,
+	MetricEPS,
+	MetricDPSAnnual,
+}
+
+// MetricNames 指標の表示名
+var MetricNames = map[string]string{
+	MetricNetSales:        "売上高",
+	MetricOperatingProfit: "営業利益",
+	MetricOrdinaryProfit:  "経常利益",
+	MetricProfit:          "純利益",
+	MetricEPS:             "EPS",
+	MetricDPSAnnual:       "年間配当",
+}
+
+// 修正の方向
+const (
+	DirectionUp   = "up"
+	DirectionDown = "down"
+)
+
+// ForecastRevision 業績予想修正の構造体
+type ForecastRevision struct {
+	LocalCode           string
+	CompanyName         string // 取得時のみ（listed_infoから結合）
+	FiscalYearStartDate time.Time
+	FiscalYearEndDate   *time.Time
+	Metric              string
+	DisclosedDate       time.Time
+	DisclosedTime       string
+	PrevDisclosedDate   time.Time
+	OldValue            float64
+	NewValue            float64
+	ChangePct           *float64
+	Direction           string
+}
+
+// forecastPoint ある開示時点の通期予想・第2四半期累計予想
+type forecastPoint struct {
+	fiscalYearStart time.Time
+	fiscalYearEnd   *time.Time
+	disclosedDate   time.Time
+	disclosedTime   string
+	values          map[string]*float64 // 通期予想
+	halfYearValues  map[string]*float64 // 第2四半期累計予想（配当は含まない）
+}
+
+// sqlQueryer *sql.DBと*sql.Txに共通のクエリ実行インターフェース
+type sqlQueryer interface {
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// getForecastHistory 銘柄の通期予想（当期予想・翌期予想）を開示順に取得
+func getForecastHistory(q sqlQueryer, localCode string) ([]*forecastPoint, error) {
+	query := `
+		SELECT * FROM (
+			SELECT
+				current_fiscal_year_start_date AS fiscal_year_start_date,
+				current_fiscal_year_end_date AS fiscal_year_end_date,
+				disclosed_date,
+				disclosed_time,
+				fc_net_sales AS net_sales,
+				fc_operating_profit AS operating_profit,
+				fc_ordinary_profit AS ordinary_profit,
+				fc_profit AS profit,
+				fc_eps AS eps,
+				fc_dps_annual AS dps_annual,
+				fc_net_sales_2q AS net_sales_2q,
+				fc_operating_profit_2q AS operating_profit_2q,
+				fc_ordinary_profit_2q AS ordinary_profit_2q,
+				fc_profit_2q AS profit_2q,
+				fc_eps_2q AS eps_2q
+			FROM statements
+			WHERE local_code = ?
+				AND current_fiscal_year_start_date IS NOT NULL
+				AND (fc_net_sales IS NOT NULL OR fc_operating_profit IS NOT NULL OR fc_profit IS NOT NULL
+					OR fc_eps IS NOT NULL OR fc_dps_annual IS NOT NULL
+					OR fc_net_sales_2q IS NOT NULL OR fc_operating_profit_2q IS NOT NULL OR fc_profit_2q IS NOT NULL)
+			UNION ALL
+			SELECT
+				next_fiscal_year_start_date AS fiscal_year_start_date,
+				next_fiscal_year_end_date AS fiscal_year_end_date,
+				disclosed_date,
+				disclosed_time,
+				ny_fc_net_sales AS net_sales,
+				ny_fc_operating_profit AS operating_profit,
+				ny_fc_ordinary_profit AS ordinary_profit,
+				ny_fc_profit AS profit,
+				ny_fc_eps AS eps,
+				` + nextYearDividendAnnualExpr + ` AS dps_annual,
+				ny_fc_net_sales_2q AS net_sales_2q,
+				ny_fc_operating_profit_2q AS operating_profit_2q,
+				ny_fc_ordinary_profit_2q AS ordinary_profit_2q,
+				ny_fc_profit_2q AS profit_2q,
+				ny_fc_eps_2q AS eps_2q
+			FROM statements
+			WHERE local_code = ?
+				AND next_fiscal_year_start_date IS NOT NULL
+				AND (ny_fc_net_sales IS NOT NULL OR ny_fc_operating_profit IS NOT NULL OR ny_fc_profit IS NOT NULL
+					OR ny_fc_eps IS NOT NULL
+					OR ny_fc_net_sales_2q IS NOT NULL OR ny_fc_operating_profit_2q IS NOT NULL OR ny_fc_profit_2q IS NOT NULL)
+		) forecasts
+		ORDER BY fiscal_year_start_date, disclosed_date, disclosed_time
+	`
+
+	rows, err := q.Query(query, localCode, localCode)
+	if err != nil {
+		return nil, fmt.Errorf("予想データ取得エラー: %v", err)
+	}
+	defer rows.Close()
+
+	var points []*forecastPoint
+	for rows.Next() {
+		p := &forecastPoint{}
+		var disclosedTime sql.NullString
+		var netSales, operatingProfit, ordinaryProfit, profit, eps, dps *float64
+		var netSales2Q, operatingProfit2Q, ordinaryProfit2Q, profit2Q, eps2Q *float64
+
+		err := rows.Scan(
+			&p.fiscalYearStart,
+			&p.fiscalYearEnd,
+			&p.disclosedDate,
+			&disclosedTime,
+			&netSales,
+			&operatingProfit,
+			&ordinaryProfit,
+			&profit,
+			&eps,
+			&dps,
+			&netSales2Q,
+			&operatingProfit2Q,
+			&ordinaryProfit2Q,
+			&profit2Q,
+			&eps2Q,
+		)
+		if err != nil {
+			log.Printf("予想データスキャンエラー: %v", err)
+			continue
+		}
+
+		p.disclosedTime = disclosedTime.String
+		p.values = map[string]*float64{
+			MetricNetSales:        netSales,
+			MetricOperatingProfit: operatingProfit,
+			MetricOrdinaryProfit:  ordinaryProfit,
+			MetricProfit:          profit,
+			MetricEPS:             eps,
+			MetricDPSAnnual:       dps,
+		}
+		p.halfYearValues = map[string]*float64{
+			MetricNetSales:        netSales2Q,
+			MetricOperatingProfit: operatingProfit2Q,
+			MetricOrdinaryProfit:  ordinaryProfit2Q,
+			MetricProfit:          profit2Q,
+			MetricEPS:             eps2Q,
+		}
+		points = append(points, p)
+	}
+
+	return points, nil
+}
+
+// changePct 変化率（%）を計算（基準値が0の場合はNULL、基準値が負の場合は絶対値で割る）
+func changePct(current, base float64) *float64 {
+	if base == 0 {
+		return nil
+	}
+	v := math.Round((current-base)/math.Abs(base)*10000) / 100
+	return &v
+}
+
+// ForecastRevisionRepository 業績予想修正のリポジトリ
+type ForecastRevisionRepository struct {
+	conn *Connection
+}
+
+// NewForecastRevisionRepository 新しいリポジトリを作成
+func NewForecastRevisionRepository(conn *Connection) *ForecastRevisionRepository {
+	return &ForecastRevisionRepository{conn: conn}
+}
+
+// BuildRevisions 財務データから業績予想修正を検出して保存
+// localCodes: 対象銘柄コード（空の場合は全銘柄）
+func (r *ForecastRevisionRepository) BuildRevisions(localCodes []string) error {
+	tx, cleanup := BeginTransaction(r.conn.GetDB())
+	defer cleanup()
+
+	if len(localCodes) == 0 {
+		codes, err := selectDistinctLocalCodes(tx)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		localCodes = codes
+	}
+
+	processedCount, revisionCount := 0, 0
+	for _, code := range localCodes {
+		count, err := r.processLocalCode(tx, code)
+		if err != nil {
+			log.Printf("銘柄 %s の予想修正検出でエラー: %v", code, err)
+			continue
+		}
+		processedCount++
+		revisionCount += count
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("トランザクションコミットエラー: %v", err)
+	}
+
+	log.Printf("業績予想修正の作成完了: %d銘柄処理, %d件", processedCount, revisionCount)
+	return nil
+}
+
+// processLocalCode 個別銘柄の予想修正を検出して置き換え
+func (r *ForecastRevisionRepository) processLocalCode(tx *sql.Tx, localCode string) (int, error) {
+	points, err := getForecastHistory(tx, localCode)
+	if err != nil {
+		return 0, err
+	}
+
+	revisions := detectRevisions(localCode, points)
+
+	if _, err := tx.Exec("DELETE FROM forecast_revisions WHERE local_code = ?", localCode); err != nil {
+		return 0, fmt.Errorf("既存データ削除エラー: %v", err)
+	}
+
+	for _, revision := range revisions {
+		if err := r.insertRevision(tx, revision); err != nil {
+			return 0, fmt.Errorf("予想修正挿入エラー (年度: %s, 指標: %s): %v",
+				revision.FiscalYearStartDate.Format("2006-01-02"), revision.Metric, err)
+		}
+	}
+
+	return len(revisions), nil
+}
+
+// detectRevisions 同一会計年度の予想を開示順に比較し、値が変化したものを修正として抽出（pointsは会計年度・開示順）
+func detectRevisions(localCode string, points []*forecastPoint) []*ForecastRevision {
+	type lastValue struct {
+		value         float64
+		disclosedDate time.Time
+	}
+
+	var revisions []*ForecastRevision
+	var currentFiscalYear time.Time
+	var last map[string]*lastValue
+
+	for _, p := range points {
+		if last == nil || !p.fiscalYearStart.Equal(currentFiscalYear) {
+			currentFiscalYear = p.fiscalYearStart
+			last = make(map[string]*lastValue)
+		}
+
+		for _, metric := range ForecastMetrics {
+			v := p.values[metric]
+			if v == nil {
+				continue
+			}
+
+			prev, exists := last[metric]
+			last[metric] = &lastValue{value: *v, disclosedDate: p.disclosedDate}
+			if !exists || prev.value == *v || !p.disclosedDate.After(prev.disclosedDate) {
+				continue
+			}
+
+			direction := DirectionUp
+			if *v < prev.value {
+				direction = DirectionDown
+			}
+
+			revisions = append(revisions, &ForecastRevision{
+				LocalCode:           localCode,
+				FiscalYearStartDate: p.fiscalYearStart,
+				FiscalYearEndDate:   p.fiscalYearEnd,
+				Metric:              metric,
+				DisclosedDate:       p.disclosedDate,
+				DisclosedTime:       p.disclosedTime,
+				PrevDisclosedDate:   prev.disclosedDate,
+				OldValue:            prev.value,
+				NewValue:            *v,
+				ChangePct:           changePct(*v, prev.value),
+				Direction:           direction,
+			})
+		}
+	}
+
+	return revisions
+}
+
+// insertRevision 予想修正データを挿入
+func (r *ForecastRevisionRepository) insertRevision(tx *sql.Tx, revision *ForecastRevision) error {
+	query := `
+		INSERT INTO forecast_revisions (
+			local_code, fiscal_year_start_date, fiscal_year_end_date, metric,
+			disclosed_date, disclosed_time, prev_disclosed_date,
+			old_value, new_value, change_pct, direction
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	var disclosedTime interface{}
+	if revision.DisclosedTime != "" {
+		disclosedTime = revision.DisclosedTime
+	}
+
+	_, err := tx.Exec(query,
+		revision.LocalCode,
+		revision.FiscalYearStartDate,
+		revision.FiscalYearEndDate,
+		revision.Metric,
+		revision.DisclosedDate,
+		disclosedTime,
+		revision.PrevDisclosedDate,
+		revision.OldValue,
+		revision.NewValue,
+		revision.ChangePct,
+		revision.Direction,
+	)
+
+	return err
+}
+
+// revisionSelect 業績予想修正を企業名とともに取得するSELECT句（queryRevisionsで読み込む）
+const revisionSelect = `
+		SELECT
+			fr.local_code, COALESCE(li.company_name, ''), fr.fiscal_year_start_date, fr.fiscal_year_end_date,
+			fr.metric, fr.disclosed_date, fr.disclosed_time, fr.prev_disclosed_date,
+			fr.old_value, fr.new_value, fr.change_pct, fr.direction
+		FROM forecast_revisions fr
+		LEFT JOIN listed_info li ON li.code = fr.local_code`
+
+// GetRevisions 指定日以降に開示された業績予想修正を取得（開示日の新しい順）
+// direction, metric, localCode: 空の場合は絞り込まない
+// limit: 0以下の場合は全件
+func (r *ForecastRevisionRepository) GetRevisions(since, direction, metric, localCode string, filter CodeFilter, limit int) ([]*ForecastRevision, error) {
+	query := revisionSelect + `
+		WHERE fr.disclosed_date >= ?
+	`
+	args := []interface{}{since}
+
+	if direction != "" {
+		query += " AND fr.direction = ?"
+		args = append(args, direction)
+	}
+	if metric != "" {
+		query += " AND fr.metric = ?"
+		args = append(args, metric)
+	}
+	if localCode != "" {
+		query += " AND fr.local_code = ?"
+		args = append(args, localCode)
+	}
+	filterConditions, filterArgs := filter.conditions("fr.local_code")
+	for _, condition := range filterConditions {
+		query += " AND " + condition
+	}
+	args = append(args, filterArgs...)
+
+	query += " ORDER BY fr.disclosed_date DESC, fr.local_code, fr.fiscal_year_start_date, fr.metric"
+	if limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", limit)
+	}
+
+	return r.queryRevisions(query, args...)
+}
+
+// GetLargestRevisions 開示日の業績予想修正を変化率の絶対値の大きい順に取得（変化率を計算できない修正は除く）
+// metrics: 空の場合は全指標
+// limit: 0以下の場合は全件
+func (r *ForecastRevisionRepository) GetLargestRevisions(date string, metrics []string, limit int) ([]*ForecastRevision, error) {
+	query := revisionSelect + `
+		WHERE fr.disclosed_date = ? AND fr.change_pct IS NOT NULL
+	`
+	args := []interface{}{date}
+
+	if len(metrics) > 0 {
+		query += " AND fr.metric IN (?" + strings.Repeat(", ?", len(metrics)-1) + ")"
+		for _, metric := range metrics {
+			args = append(args, metric)
+		}
+	}
+
+	query += " ORDER BY ABS(fr.change_pct) DESC, fr.local_code, fr.metric"
+	if limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", limit)
+	}
+
+	return r.queryRevisions(query, args...)
+}
+
+// queryRevisions 業績予想修正を取得
+func (r *ForecastRevisionRepository) queryRevisions(query string, args ...interface{}) ([]*ForecastRevision, error) {
+	rows, err := r.conn.GetDB().Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("予想修正データ取得エラー: %v", err)
+	}
+	defer rows.Close()
+
+	var revisions []*ForecastRevision
+	for rows.Next() {
+		revision := &ForecastRevision{}
+		var disclosedTime sql.NullString
+		err := rows.Scan(
+			&revision.LocalCode,
+			&revision.CompanyName,
+			&revision.FiscalYearStartDate,
+			&revision.FiscalYearEndDate,
+			&revision.Metric,
+			&revision.DisclosedDate,
+			&disclosedTime,
+			&revision.PrevDisclosedDate,
+			&revision.OldValue,
+			&revision.NewValue,
+			&revision.ChangePct,
+			&revision.Direction,
+		)
+		if err != nil {
+			log.Printf("予想修正データスキャンエラー: %v", err)
+			continue
+		}
+		revision.DisclosedTime = disclosedTime.String
+		revisions = append(revisions, revision)
+	}
+
+	return revisions, nil
+}
@@ -0,0 +1,616 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// IndicatorConfig テクニカル指標の算出設定（期間が0または空の指標は算出しない）
+type IndicatorConfig struct {
+	SMAPeriods       []int   // 終値の単純移動平均の期間
+	VolumeSMAPeriods []int   // 出来高の単純移動平均の期間
+	RSIPeriods       []int   // RSI（Wilder）の期間
+	MACDFast         int     // MACDの短期EMAの期間
+	MACDSlow         int     // MACDの長期EMAの期間
+	MACDSignal       int     // MACDのシグナルの期間
+	BollingerPeriod  int     // ボリンジャーバンドの期間
+	BollingerK       float64 // ボリンジャーバンドの標準偏差の倍率
+}
+
+// DefaultIndicatorConfig 標準のテクニカル指標の算出設定
+func DefaultIndicatorConfig() IndicatorConfig {
+	return IndicatorConfig{
+		SMAPeriods:       []int{5, 25, 75, 200},
+		VolumeSMAPeriods: []int{5, 25},
+		RSIPeriods:       []int{14},
+		MACDFast:         12,
+		MACDSlow:         26,
+		MACDSignal:       9,
+		BollingerPeriod:  20,
+		BollingerK:       2,
+	}
+}
+
+// NewIndicatorConfigFromEnv 環境変数からテクニカル指標の算出設定を読み込む（未設定の項目は標準の設定）
+// INDICATOR_SMA_PERIODS, INDICATOR_VOLUME_SMA_PERIODS, INDICATOR_RSI_PERIODS: カンマ区切りの期間（例: 5,25,75）
+// INDICATOR_MACD: 短期,長期,シグナルの期間（例: 12,26,9）
+// INDICATOR_BOLLINGER: 期間,標準偏差の倍率（例: 20,2）
+func NewIndicatorConfigFromEnv() (IndicatorConfig, error) {
+	config := DefaultIndicatorConfig()
+
+	periodLists := []struct {
+		key    string
+		target *[]int
+	}{
+		{"INDICATOR_SMA_PERIODS", &config.SMAPeriods},
+		{"INDICATOR_VOLUME_SMA_PERIODS", &config.VolumeSMAPeriods},
+		{"INDICATOR_RSI_PERIODS", &config.RSIPeriods},
+	}
+	for _, p := range periodLists {
+		value := os.Getenv(p.key)
+		if value == "" {
+			continue
+		}
+		periods, err := parsePeriods(value)
+		if err != nil {
+			return config, fmt.Errorf("%sの値が無効です: %v", p.key, err)
+		}
+		*p.target = periods
+	}
+
+	if value := os.Getenv("INDICATOR_MACD"); value != "" {
+		periods, err := parsePeriods(value)
+		if err != nil || len(periods) != 3 {
+			return config, fmt.Errorf("INDICATOR_MACDの値が無効です（短期,長期,シグナルの形式で指定してください）: '%s'", value)
+		}
+		config.MACDFast, config.MACDSlow, config.MACDSignal = periods[0], periods[1], periods[2]
+	}
+
+	if value := os.Getenv("INDICATOR_BOLLINGER"); value != "" {
+		parts := strings.Split(value, ",")
+		if len(parts) != 2 {
+			return config, fmt.Errorf("INDICATOR_BOLLINGERの値が無効です（期間,倍率の形式で指定してください）: '%s'", value)
+		}
+		period, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+		if err != nil || period < 2 {
+			return config, fmt.Errorf("INDICATOR_BOLLINGERの期間が無効です: '%s'", parts[0])
+		}
+		k, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+		if err != nil || k <= 0 {
+			return config, fmt.Errorf("INDICATOR_BOLLINGERの倍率が無効です: '%s'", parts[1])
+		}
+		config.BollingerPeriod, config.BollingerK = period, k
+	}
+
+	return config, nil
+}
+
+// parsePeriods カンマ区切りの期間を解析
+func parsePeriods(value string) ([]int, error) {
+	var periods []int
+	for _, part := range strings.Split(value, ",") {
+		period, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || period < 1 {
+			return nil, fmt.Errorf("期間は1以上の整数で指定してください: '%s'", part)
+		}
+		periods = append(periods, period)
+	}
+	return periods, nil
+}
+
+// Names 設定から算出される指標名を一覧表示用の順に取得
+func (c IndicatorConfig) Names() []string {
+	var names []string
+	for _, period := range c.SMAPeriods {
+		names = append(names, fmt.Sprintf("sma_%d", period))
+	}
+	for _, period := range c.VolumeSMAPeriods {
+		names = append(names, fmt.Sprintf("volume_sma_%d", period))
+	}
+	for _, period := range c.RSIPeriods {
+		names = append(names, fmt.Sprintf("rsi_%d", period))
+	}
+	if c.macdEnabled() {
+		suffix := c.macdSuffix()
+		names = append(names, "macd_"+suffix, "macd_signal_"+suffix, "macd_hist_"+suffix)
+	}
+	if c.bollingerEnabled() {
+		suffix := c.bollingerSuffix()
+		names = append(names, "bb_upper_"+suffix, "bb_lower_"+suffix, "bb_pctb_"+suffix)
+	}
+	return names
+}
+
+func (c IndicatorConfig) macdEnabled() bool {
+	return c.MACDFast > 0 && c.MACDSlow > c.MACDFast && c.MACDSignal > 0
+}
+
+func (c IndicatorConfig) macdSuffix() string {
+	return fmt.Sprintf("%d_%d_%d", c.MACDFast, c.MACDSlow, c.MACDSignal)
+}
+
+func (c IndicatorConfig) bollingerEnabled() bool {
+	return c.BollingerPeriod > 1 && c.BollingerK > 0
+}
+
+func (c IndicatorConfig) bollingerSuffix() string {
+	return fmt.Sprintf("%d_%s", c.BollingerPeriod, strconv.FormatFloat(c.BollingerK, 'f', -1, 64))
+}
+
+// IndicatorValue テクニカル指標の値
+type IndicatorValue struct {
+	Code      string
+	TradeDate time.Time
+	Name      string
+	Value     float64
+}
+
+// IndicatorRepository テクニカル指標のリポジトリ
+type IndicatorRepository struct {
+	conn *Connection
+}
+
+// NewIndicatorRepository 新しいリポジトリを作成
+func NewIndicatorRepository(conn *Connection) *IndicatorRepository {
+	return &IndicatorRepository{conn: conn}
+}
+
+// indicatorQuote テクニカル指標の算出に使う日次株価
+type indicatorQuote struct {
+	tradeDate        time.Time
+	close            float64
+	volume           float64
+	adjustmentFactor *float64
+}
+
+// BuildIndicators 調整後終値・調整後出来高からテクニカル指標を算出して保存
+// localCodes: 対象銘柄コード（空の場合は株価データのある全銘柄）
+// rebuild: trueの場合は全期間を再作成、falseの場合は指標ごとに保存済みの最終取引日より後の日のみ追加
+func (r *IndicatorRepository) BuildIndicators(localCodes []string, config IndicatorConfig, rebuild bool) error {
+	tx, cleanup := BeginTransaction(r.conn.GetDB())
+	defer cleanup()
+
+	if len(localCodes) == 0 {
+		codes, err := selectDistinctQuoteCodes(tx)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		localCodes = codes
+	}
+
+	processedCount := 0
+	for _, code := range localCodes {
+		if err := r.processLocalCode(tx, code, config, rebuild); err != nil {
+			log.Printf("銘柄 %s のテクニカル指標算出でエラー: %v", code, err)
+			continue
+		}
+		processedCount++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("トランザクションコミットエラー: %v", err)
+	}
+
+	log.Printf("テクニカル指標の作成完了: %d銘柄処理", processedCount)
+	return nil
+}
+
+// selectDistinctQuoteCodes daily_quotesにある銘柄コードを取得
+func selectDistinctQuoteCodes(tx *sql.Tx) ([]string, error) {
+	rows, err := tx.Query("SELECT DISTINCT code FROM daily_quotes ORDER BY code")
+	if err != nil {
+		return nil, fmt.Errorf("銘柄コード取得エラー: %v", err)
+	}
+	defer rows.Close()
+
+	var codes []string
+	for rows.Next() {
+		var code string
+		if err := rows.Scan(&code); err != nil {
+			log.Printf("銘柄コードスキャンエラー: %v", err)
+			continue
+		}
+		codes = append(codes, code)
+	}
+
+	return codes, nil
+}
+
+// processLocalCode 個別銘柄のテクニカル指標を算出して保存
+// 保存済みの期間より後に株式分割・併合があった場合は、調整後株価の基準が変わるため全期間を再作成する
+func (r *IndicatorRepository) processLocalCode(tx *sql.Tx, code string, config IndicatorConfig, rebuild bool) error {
+	quotes, err := getIndicatorQuotes(tx, code)
+	if err != nil {
+		return err
+	}
+
+	lastDates := make(map[string]time.Time)
+	if !rebuild {
+		lastDates, err = getLastIndicatorDates(tx, code)
+		if err != nil {
+			return err
+		}
+		if hasSplitAfter(quotes, lastDates) {
+			log.Printf("銘柄 %s は株式分割・併合があったため全期間を再作成します", code)
+			rebuild = true
+			lastDates = make(map[string]time.Time)
+		}
+	}
+
+	if rebuild {
+		if _, err := tx.Exec("DELETE FROM indicators WHERE code = ?", code); err != nil {
+			return fmt.Errorf("既存データ削除エラー: %v", err)
+		}
+	}
+
+	var values []*IndicatorValue
+	for _, v := range calculateIndicators(code, quotes, config) {
+		if last, ok := lastDates[v.Name]; ok && !v.TradeDate.After(last) {
+			continue
+		}
+		values = append(values, v)
+	}
+
+	// バッチサイズを制限（MySQLのプレースホルダー制限を回避）
+	const batchSize = 1000
+	for i := 0; i < len(values); i += batchSize {
+		end := i + batchSize
+		if end > len(values) {
+			end = len(values)
+		}
+		if err := r.insertIndicators(tx, values[i:end]); err != nil {
+			return fmt.Errorf("テクニカル指標挿入エラー (バッチ %d-%d): %v", i+1, end, err)
+		}
+	}
+
+	return nil
+}
+
+// getIndicatorQuotes 調整後終値のある日次株価を取引日の古い順に取得
+func getIndicatorQuotes(q sqlQueryer, code string) ([]indicatorQuote, error) {
+	rows, err := q.Query(`
+		SELECT trade_date, adjustment_close, COALESCE(adjustment_volume, 0), adjustment_factor
+		FROM daily_quotes
+		WHERE code = ? AND adjustment_close IS NOT NULL AND adjustment_close > 0
+		ORDER BY trade_date
+	`, code)
+	if err != nil {
+		return nil, fmt.Errorf("株価データ取得エラー: %v", err)
+	}
+	defer rows.Close()
+
+	var quotes []indicatorQuote
+	for rows.Next() {
+		var quote indicatorQuote
+		if err := rows.Scan(&quote.tradeDate, &quote.close, &quote.volume, &quote.adjustmentFactor); err != nil {
+			log.Printf("株価データスキャンエラー: %v", err)
+			continue
+		}
+		quotes = append(quotes, quote)
+	}
+
+	return quotes, nil
+}
+
+// getLastIndicatorDates 指標ごとの保存済みの最終取引日を取得
+func getLastIndicatorDates(q sqlQueryer, code string) (map[string]time.Time, error) {
+	rows, err := q.Query("SELECT name, MAX(trade_date) FROM indicators WHERE code = ? GROUP BY name", code)
+	if err != nil {
+		return nil, fmt.Errorf("保存済みテクニカル指標取得エラー: %v", err)
+	}
+	defer rows.Close()
+
+	lastDates := make(map[string]time.Time)
+	for rows.Next() {
+		var name string
+		var last time.Time
+		if err := rows.Scan(&name, &last); err != nil {
+			log.Printf("保存済みテクニカル指標スキャンエラー: %v", err)
+			continue
+		}
+		lastDates[name] = last
+	}
+
+	return lastDates, nil
+}
+
+// hasSplitAfter 保存済みの最も古い最終取引日より後に株式分割・併合があるかを判定
+func hasSplitAfter(quotes []indicatorQuote, lastDates map[string]time.Time) bool {
+	if len(lastDates) == 0 {
+		return false
+	}
+
+	var earliest time.Time
+	for _, last := range lastDates {
+		if earliest.IsZero() || last.Before(earliest) {
+			earliest = last
+		}
+	}
+
+	for _, quote := range quotes {
+		if quote.tradeDate.After(earliest) && quote.adjustmentFactor != nil && *quote.adjustmentFactor != 1 {
+			return true
+		}
+	}
+	return false
+}
+
+// calculateIndicators 取引日の古い順に並んだ日次株価から設定された全指標を算出
+func calculateIndicators(code string, quotes []indicatorQuote, config IndicatorConfig) []*IndicatorValue {
+	closes := make([]float64, len(quotes))
+	volumes := make([]float64, len(quotes))
+	for i, quote := range quotes {
+		closes[i] = quote.close
+		volumes[i] = quote.volume
+	}
+
+	series := make(map[string][]*float64)
+	for _, period := range config.SMAPeriods {
+		series[fmt.Sprintf("sma_%d", period)] = simpleMovingAverage(closes, period)
+	}
+	for _, period := range config.VolumeSMAPeriods {
+		series[fmt.Sprintf("volume_sma_%d", period)] = simpleMovingAverage(volumes, period)
+	}
+	for _, period := range config.RSIPeriods {
+		series[fmt.Sprintf("rsi_%d", period)] = relativeStrengthIndex(closes, period)
+	}
+	if config.macdEnabled() {
+		suffix := config.macdSuffix()
+		macd, signal, hist := movingAverageConvergenceDivergence(closes, config.MACDFast, config.MACDSlow, config.MACDSignal)
+		series["macd_"+suffix] = macd
+		series["macd_signal_"+suffix] = signal
+		series["macd_hist_"+suffix] = hist
+	}
+	if config.bollingerEnabled() {
+		suffix := config.bollingerSuffix()
+		upper, lower, pctb := bollingerBands(closes, config.BollingerPeriod, config.BollingerK)
+		series["bb_upper_"+suffix] = upper
+		series["bb_lower_"+suffix] = lower
+		series["bb_pctb_"+suffix] = pctb
+	}
+
+	var values []*IndicatorValue
+	for _, name := range config.Names() {
+		for i, v := range series[name] {
+			if v == nil {
+				continue
+			}
+			values = append(values, &IndicatorValue{
+				Code:      code,
+				TradeDate: quotes[i].tradeDate,
+				Name:      name,
+				Value:     math.Round(*v*10000) / 10000,
+			})
+		}
+	}
+
+	return values
+}
+
+// simpleMovingAverage 単純移動平均（期間に満たない日はnil）
+func simpleMovingAverage(values []float64, period int) []*float64 {
+	result := make([]*float64, len(values))
+	if period < 1 {
+		return result
+	}
+
+	sum := 0.0
+	for i, v := range values {
+		sum += v
+		if i >= period {
+			sum -= values[i-period]
+		}
+		if i >= period-1 {
+			avg := sum / float64(period)
+			result[i] = &avg
+		}
+	}
+	return result
+}
+
+// exponentialMovingAverage 指数移動平均（最初の期間の単純平均を初期値とし、期間に満たない日はnil）
+func exponentialMovingAverage(values []*float64, period int) []*float64 {
+	result := make([]*float64, len(values))
+	if period < 1 {
+		return result
+	}
+
+	alpha := 2.0 / float64(period+1)
+	var ema *float64
+	var seed []float64
+	for i, v := range values {
+		if v == nil {
+			continue
+		}
+		if ema == nil {
+			seed = append(seed, *v)
+			if len(seed) < period {
+				continue
+			}
+			sum := 0.0
+			for _, s := range seed {
+				sum += s
+			}
+			initial := sum / float64(period)
+			ema = &initial
+		} else {
+			next := *ema + alpha*(*v-*ema)
+			ema = &next
+		}
+		result[i] = ema
+	}
+	return result
+}
+
+// relativeStrengthIndex Wilderの平滑化によるRSI（期間に満たない日はnil）
+func relativeStrengthIndex(closes []float64, period int) []*float64 {
+	result := make([]*float64, len(closes))
+	if period < 1 || len(closes) <= period {
+		return result
+	}
+
+	var avgGain, avgLoss float64
+	for i := 1; i < len(closes); i++ {
+		change := closes[i] - closes[i-1]
+		gain, loss := math.Max(change, 0), math.Max(-change, 0)
+
+		if i <= period {
+			avgGain += gain / float64(period)
+			avgLoss += loss / float64(period)
+			if i < period {
+				continue
+			}
+		} else {
+			avgGain = (avgGain*float64(period-1) + gain) / float64(period)
+			avgLoss = (avgLoss*float64(period-1) + loss) / float64(period)
+		}
+
+		rsi := 100.0
+		if avgLoss > 0 {
+			rsi = 100 - 100/(1+avgGain/avgLoss)
+		} else if avgGain == 0 {
+			rsi = 50
+		}
+		result[i] = &rsi
+	}
+	return result
+}
+
+// movingAverageConvergenceDivergence MACD・シグナル・ヒストグラム
+func movingAverageConvergenceDivergence(closes []float64, fast, slow, signalPeriod int) (macd, signal, hist []*float64) {
+	values := make([]*float64, len(closes))
+	for i := range closes {
+		values[i] = &closes[i]
+	}
+
+	fastEMA := exponentialMovingAverage(values, fast)
+	slowEMA := exponentialMovingAverage(values, slow)
+
+	macd = make([]*float64, len(closes))
+	for i := range closes {
+		if fastEMA[i] != nil && slowEMA[i] != nil {
+			v := *fastEMA[i] - *slowEMA[i]
+			macd[i] = &v
+		}
+	}
+
+	signal = exponentialMovingAverage(macd, signalPeriod)
+
+	hist = make([]*float64, len(closes))
+	for i := range closes {
+		if macd[i] != nil && signal[i] != nil {
+			v := *macd[i] - *signal[i]
+			hist[i] = &v
+		}
+	}
+	return macd, signal, hist
+}
+
+// bollingerBands ボリンジャーバンドの上限・下限と%b（標準偏差は母標準偏差）
+func bollingerBands(closes []float64, period int, k float64) (upper, lower, pctb []*float64) {
+	upper = make([]*float64, len(closes))
+	lower = make([]*float64, len(closes))
+	pctb = make([]*float64, len(closes))
+
+	middle := simpleMovingAverage(closes, period)
+	for i := range closes {
+		if middle[i] == nil {
+			continue
+		}
+		variance := 0.0
+		for _, v := range closes[i-period+1 : i+1] {
+			variance += (v - *middle[i]) * (v - *middle[i])
+		}
+		deviation := math.Sqrt(variance / float64(period))
+
+		u := *middle[i] + k*deviation
+		l := *middle[i] - k*deviation
+		upper[i], lower[i] = &u, &l
+		if u > l {
+			b := (closes[i] - l) / (u - l)
+			pctb[i] = &b
+		}
+	}
+	return upper, lower, pctb
+}
+
+// insertIndicators テクニカル指標データをまとめて挿入
+func (r *IndicatorRepository) insertIndicators(tx *sql.Tx, values []*IndicatorValue) error {
+	if len(values) == 0 {
+		return nil
+	}
+
+	placeholders := make([]string, len(values))
+	args := make([]interface{}, 0, len(values)*4)
+	for i, v := range values {
+		placeholders[i] = "(?, ?, ?, ?)"
+		args = append(args, v.Code, v.TradeDate, v.Name, v.Value)
+	}
+
+	query := "INSERT INTO indicators (code, trade_date, name, value) VALUES " + strings.Join(placeholders, ", ")
+	_, err := tx.Exec(query, args...)
+	return err
+}
+
+// GetIndicators 銘柄の直近days取引日分のテクニカル指標を取引日の新しい順に取得（daysが0の場合は全期間）
+func (r *IndicatorRepository) GetIndicators(code string, days int) ([]*IndicatorValue, error) {
+	query := "SELECT code, trade_date, name, value FROM indicators WHERE code = ?"
+	args := []interface{}{code}
+	if days > 0 {
+		query += fmt.Sprintf(` AND trade_date >= (
+			SELECT COALESCE(MIN(trade_date), '1900-01-01') FROM (
+				SELECT DISTINCT trade_date FROM indicators WHERE code = ? ORDER BY trade_date DESC LIMIT %d
+			) recent
+		)`, days)
+		args = append(args, code)
+	}
+	query += " ORDER BY trade_date DESC, name"
+
+	rows, err := r.conn.GetDB().Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("テクニカル指標データ取得エラー: %v", err)
+	}
+	defer rows.Close()
+
+	var values []*IndicatorValue
+	for rows.Next() {
+		v := &IndicatorValue{}
+		if err := rows.Scan(&v.Code, &v.TradeDate, &v.Name, &v.Value); err != nil {
+			log.Printf("テクニカル指標データスキャンエラー: %v", err)
+			continue
+		}
+		values = append(values, v)
+	}
+
+	return values, nil
+}
+
+// SortIndicatorNames 指標名を設定の順に並べる（設定にない指標名は末尾に名前順）
+func SortIndicatorNames(names []string, config IndicatorConfig) []string {
+	order := make(map[string]int)
+	for i, name := range config.Names() {
+		order[name] = i
+	}
+
+	sorted := append([]string(nil), names...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		oi, iok := order[sorted[i]]
+		oj, jok := order[sorted[j]]
+		if iok != jok {
+			return iok
+		}
+		if iok {
+			return oi < oj
+		}
+		return sorted[i] < sorted[j]
+	})
+	return sorted
+}
@@ -61,11 +61,12 @@ func (r *ListedInfoRepository) SaveListedInfo(listedInfos []schema.ListedInfo) e
 	return nil
 }
 
-// GetListedInfo その他市場（0109）を除外して上場銘柄情報を取得（昇順）
+// GetListedInfo その他市場（0109）と上場廃止銘柄を除外して上場銘柄情報を取得（昇順）
 func (r *ListedInfoRepository) GetListedInfo(startCode string, limit int) ([]schema.ListedInfo, error) {
 	var infos []schema.ListedInfo
 	query := r.conn.GetGormDB().Model(&schema.ListedInfo{}).
-		Where("market_code != ?", "0109")
+		Where("market_code != ?", "0109").
+		Where("delisted_date IS NULL")
 
 	// startCodeが空文字列でない場合のみフィルターを適用
 	if startCode != "" {
@@ -86,3 +87,20 @@ func (r *ListedInfoRepository) GetListedInfo(startCode string, limit int) ([]sch
 
 	return infos, nil
 }
+
+// GetCompanyNames 上場廃止銘柄を含む全銘柄のコードと企業名の対応を取得
+func (r *ListedInfoRepository) GetCompanyNames() (map[string]string, error) {
+	var infos []storedListing
+	result := r.conn.GetGormDB().Model(&schema.ListedInfo{}).
+		Select("code", "company_name").
+		Find(&infos)
+	if result.Error != nil {
+		return nil, fmt.Errorf("データ取得エラー: %v", result.Error)
+	}
+
+	names := make(map[string]string, len(infos))
+	for _, info := range infos {
+		names[info.Code] = info.CompanyName
+	}
+	return names, nil
+}
@@ -0,0 +1,169 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"stock-automation/schema"
+
+	"gorm.io/gorm"
+)
+
+// ListedInfoHistory 上場銘柄情報の履歴（適用期間付き）
+type ListedInfoHistory struct {
+	Code               string     `gorm:"column:code;primaryKey"`
+	EffectiveFrom      time.Time  `gorm:"column:effective_from;primaryKey"`
+	EffectiveTo        *time.Time `gorm:"column:effective_to"`
+	CompanyName        string     `gorm:"column:company_name"`
+	CompanyNameEnglish string     `gorm:"column:company_name_english"`
+	Sector17Code       string     `gorm:"column:sector17_code"`
+	Sector33Code       string     `gorm:"column:sector33_code"`
+	ScaleCategory      string     `gorm:"column:scale_category"`
+	MarketCode         string     `gorm:"column:market_code"`
+	MarginCode         string     `gorm:"column:margin_code"`
+	MarginCodeName     string     `gorm:"column:margin_code_name"`
+	CreatedAt          time.Time  `gorm:"column:created_at"`
+	UpdatedAt          time.Time  `gorm:"column:updated_at"`
+}
+
+// TableName GORMのテーブル名を指定
+func (ListedInfoHistory) TableName() string {
+	return "listed_info_history"
+}
+
+// sameAttributes 履歴として管理する属性が同一かを判定
+func (h *ListedInfoHistory) sameAttributes(info *schema.ListedInfo) bool {
+	return h.CompanyName == info.CompanyName &&
+		h.CompanyNameEnglish == info.CompanyNameEnglish &&
+		h.Sector17Code == info.Sector17Code &&
+		h.Sector33Code == info.Sector33Code &&
+		h.ScaleCategory == info.ScaleCategory &&
+		h.MarketCode == info.MarketCode &&
+		h.MarginCode == info.MarginCode &&
+		h.MarginCodeName == info.MarginCodeName
+}
+
+// newListedInfoHistory 上場銘柄情報から履歴レコードを作成
+func newListedInfoHistory(info *schema.ListedInfo, effectiveFrom time.Time) *ListedInfoHistory {
+	return &ListedInfoHistory{
+		Code:               info.Code,
+		EffectiveFrom:      effectiveFrom,
+		CompanyName:        info.CompanyName,
+		CompanyNameEnglish: info.CompanyNameEnglish,
+		Sector17Code:       info.Sector17Code,
+		Sector33Code:       info.Sector33Code,
+		ScaleCategory:      info.ScaleCategory,
+		MarketCode:         info.MarketCode,
+		MarginCode:         info.MarginCode,
+		MarginCodeName:     info.MarginCodeName,
+	}
+}
+
+// SaveListedInfoHistory 上場銘柄情報の変更を履歴テーブルに反映
+// 属性が変化した銘柄は現在有効な履歴を前日で締め、新しい履歴を追加する
+func (r *ListedInfoRepository) SaveListedInfoHistory(listedInfos []schema.ListedInfo) error {
+	if len(listedInfos) == 0 {
+		return nil
+	}
+
+	err := r.conn.GetGormDB().Transaction(func(tx *gorm.DB) error {
+		// 現在有効な履歴をまとめて取得
+		var current []ListedInfoHistory
+		if err := tx.Where("effective_to IS NULL").Find(&current).Error; err != nil {
+			return fmt.Errorf("現在有効な履歴取得エラー: %v", err)
+		}
+
+		currentMap := make(map[string]*ListedInfoHistory, len(current))
+		for i := range current {
+			currentMap[current[i].Code] = &current[i]
+		}
+
+		inserted, closed := 0, 0
+		for i := range listedInfos {
+			info := &listedInfos[i]
+
+			effectiveFrom, err := time.ParseInLocation("2006-01-02", info.Date, time.Local)
+			if err != nil {
+				slog.Warn("適用日の形式が不正なため履歴をスキップ", "code", info.Code, "date", info.Date)
+				continue
+			}
+
+			existing, exists := currentMap[info.Code]
+			if !exists {
+				if err := tx.Create(newListedInfoHistory(info, effectiveFrom)).Error; err != nil {
+					return fmt.Errorf("履歴追加エラー (code: %s): %v", info.Code, err)
+				}
+				inserted++
+				continue
+			}
+
+			if existing.sameAttributes(info) {
+				continue
+			}
+
+			// 過去日付のデータで現在の履歴を上書きしない
+			if effectiveFrom.Before(existing.EffectiveFrom) {
+				slog.Debug("現在の履歴より古いデータのため履歴をスキップ",
+					"code", info.Code, "date", info.Date,
+					"effective_from", existing.EffectiveFrom.Format("2006-01-02"))
+				continue
+			}
+
+			// 同日の再取得は現在の履歴を置き換える
+			if effectiveFrom.Equal(existing.EffectiveFrom) {
+				result := tx.Model(&ListedInfoHistory{}).
+					Where("code = ? AND effective_from = ?", existing.Code, existing.EffectiveFrom).
+					Select("company_name", "company_name_english", "sector17_code", "sector33_code",
+						"scale_category", "market_code", "margin_code", "margin_code_name").
+					Updates(newListedInfoHistory(info, effectiveFrom))
+				if result.Error != nil {
+					return fmt.Errorf("履歴更新エラー (code: %s): %v", info.Code, result.Error)
+				}
+				continue
+			}
+
+			effectiveTo := effectiveFrom.AddDate(0, 0, -1)
+			result := tx.Model(&ListedInfoHistory{}).
+				Where("code = ? AND effective_from = ?", existing.Code, existing.EffectiveFrom).
+				Update("effective_to", effectiveTo)
+			if result.Error != nil {
+				return fmt.Errorf("履歴終了日更新エラー (code: %s): %v", info.Code, result.Error)
+			}
+			closed++
+
+			if err := tx.Create(newListedInfoHistory(info, effectiveFrom)).Error; err != nil {
+				return fmt.Errorf("履歴追加エラー (code: %s): %v", info.Code, err)
+			}
+			inserted++
+		}
+
+		slog.Debug("listed_info_history保存完了", "inserted", inserted, "closed", closed)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("上場銘柄情報履歴保存エラー: %v", err)
+	}
+
+	return nil
+}
+
+// GetAsOf 指定日時点で有効だった上場銘柄情報を取得（該当がない場合はnil）
+func (r *ListedInfoRepository) GetAsOf(code, date string) (*ListedInfoHistory, error) {
+	var history ListedInfoHistory
+	result := r.conn.GetGormDB().
+		Where("code = ?", code).
+		Where("effective_from <= ?", date).
+		Where("(effective_to IS NULL OR effective_to >= ?)", date).
+		Order("effective_from DESC").
+		First(&history)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("データ取得エラー: %v", result.Error)
+	}
+
+	return &history, nil
+}
@@ -0,0 +1,199 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"stock-automation/schema"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// イベント種別
+const (
+	ListingEventListing   = "listing"
+	ListingEventDelisting = "delisting"
+)
+
+// ListingEvent 新規上場・上場廃止イベント
+type ListingEvent struct {
+	Code        string    `gorm:"column:code;primaryKey"`
+	EventDate   time.Time `gorm:"column:event_date;primaryKey"`
+	EventType   string    `gorm:"column:event_type;primaryKey"`
+	CompanyName string    `gorm:"column:company_name"`
+	MarketCode  string    `gorm:"column:market_code"`
+	CreatedAt   time.Time `gorm:"column:created_at"`
+	UpdatedAt   time.Time `gorm:"column:updated_at"`
+}
+
+// TableName GORMのテーブル名を指定
+func (ListingEvent) TableName() string {
+	return "listing_events"
+}
+
+// storedListing 保存済みの銘柄と上場廃止日
+type storedListing struct {
+	Code         string     `gorm:"column:code"`
+	CompanyName  string     `gorm:"column:company_name"`
+	MarketCode   string     `gorm:"column:market_code"`
+	DelistedDate *time.Time `gorm:"column:delisted_date"`
+}
+
+// DetectListingEvents 全銘柄の取得結果と保存済みの銘柄を比較し、新規上場・上場廃止イベントを検出
+// 保存済みの銘柄が存在しない場合（初回取得）や、保存済みの最新の適用日より古いデータの場合はイベントを検出しない
+func (r *ListedInfoRepository) DetectListingEvents(listedInfos []schema.ListedInfo) ([]ListingEvent, error) {
+	if len(listedInfos) == 0 {
+		return nil, nil
+	}
+
+	// イベント日付は取得結果の日付（最新）とする
+	var eventDate time.Time
+	for _, info := range listedInfos {
+		date, err := time.ParseInLocation("2006-01-02", info.Date, time.Local)
+		if err != nil {
+			continue
+		}
+		if date.After(eventDate) {
+			eventDate = date
+		}
+	}
+	if eventDate.IsZero() {
+		return nil, fmt.Errorf("取得結果の日付が不正です")
+	}
+
+	// 過去日付のデータで上場廃止・新規上場を検出しない（保存済みの最新の適用日より古い場合はスキップ）
+	var latestDate sql.NullTime
+	if err := r.conn.GetDB().QueryRow("SELECT MAX(effective_date) FROM listed_info").Scan(&latestDate); err != nil {
+		return nil, fmt.Errorf("保存済み適用日取得エラー: %v", err)
+	}
+	if latestDate.Valid && eventDate.Before(latestDate.Time) {
+		slog.Info("保存済みの銘柄より古いデータのためイベント検出をスキップ",
+			"date", eventDate.Format("2006-01-02"), "latest", latestDate.Time.Format("2006-01-02"))
+		return nil, nil
+	}
+
+	var stored []storedListing
+	result := r.conn.GetGormDB().Model(&schema.ListedInfo{}).
+		Select("code", "company_name", "market_code", "delisted_date").
+		Find(&stored)
+	if result.Error != nil {
+		return nil, fmt.Errorf("保存済み銘柄取得エラー: %v", result.Error)
+	}
+
+	if len(stored) == 0 {
+		slog.Debug("保存済みの銘柄がないためイベント検出をスキップ")
+		return nil, nil
+	}
+
+	storedMap := make(map[string]*storedListing, len(stored))
+	for i := range stored {
+		storedMap[stored[i].Code] = &stored[i]
+	}
+
+	fetched := make(map[string]bool, len(listedInfos))
+	var events []ListingEvent
+
+	// 新規上場（未登録、または上場廃止済みからの再上場）
+	for _, info := range listedInfos {
+		fetched[info.Code] = true
+
+		existing, exists := storedMap[info.Code]
+		if exists && existing.DelistedDate == nil {
+			continue
+		}
+		events = append(events, ListingEvent{
+			Code:        info.Code,
+			EventDate:   eventDate,
+			EventType:   ListingEventListing,
+			CompanyName: info.CompanyName,
+			MarketCode:  info.MarketCode,
+		})
+	}
+
+	// 上場廃止（上場中の銘柄が取得結果から消えたもの）
+	for _, s := range stored {
+		if s.DelistedDate != nil || fetched[s.Code] {
+			continue
+		}
+		events = append(events, ListingEvent{
+			Code:        s.Code,
+			EventDate:   eventDate,
+			EventType:   ListingEventDelisting,
+			CompanyName: s.CompanyName,
+			MarketCode:  s.MarketCode,
+		})
+	}
+
+	slog.Debug("上場イベント検出完了", "date", eventDate.Format("2006-01-02"), "count", len(events))
+	return events, nil
+}
+
+// SaveListingEvents 上場イベントを保存し、上場銘柄情報の上場廃止日を更新
+// 新規上場イベントは上場銘柄情報の保存後に呼び出すこと（外部キー制約のため）
+func (r *ListedInfoRepository) SaveListingEvents(events []ListingEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	err := r.conn.GetGormDB().Transaction(func(tx *gorm.DB) error {
+		for i := range events {
+			event := &events[i]
+
+			result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(event)
+			if result.Error != nil {
+				return fmt.Errorf("イベント保存エラー (code: %s): %v", event.Code, result.Error)
+			}
+
+			var delistedDate interface{}
+			if event.EventType == ListingEventDelisting {
+				delistedDate = event.EventDate
+			}
+			result = tx.Model(&schema.ListedInfo{}).
+				Where("code = ?", event.Code).
+				Update("delisted_date", delistedDate)
+			if result.Error != nil {
+				return fmt.Errorf("上場廃止日更新エラー (code: %s): %v", event.Code, result.Error)
+			}
+
+			slog.Info("上場イベントを検出しました",
+				"code", event.Code,
+				"company_name", event.CompanyName,
+				"event_type", event.EventType,
+				"date", event.EventDate.Format("2006-01-02"))
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("上場イベント保存エラー: %v", err)
+	}
+
+	return nil
+}
+
+// GetListingEvents 上場イベントを新しい順に取得
+func (r *ListedInfoRepository) GetListingEvents(eventType string, filter CodeFilter, limit int) ([]ListingEvent, error) {
+	var events []ListingEvent
+	query := r.conn.GetGormDB().Model(&ListingEvent{})
+
+	if eventType != "" {
+		query = query.Where("event_type = ?", eventType)
+	}
+	query = query.Scopes(filter.Scope("code"))
+
+	query = query.Order("event_date DESC").Order("code")
+
+	// limitが0より大きい場合のみ制限を適用
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+
+	result := query.Find(&events)
+	if result.Error != nil {
+		return nil, fmt.Errorf("データ取得エラー: %v", result.Error)
+	}
+
+	return events, nil
+}
@@ -0,0 +1,268 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+)
+
+// MarketBreadth 取引日の騰落状況
+type MarketBreadth struct {
+	TradeDate      time.Time
+	PrevTradeDate  *time.Time // 前営業日（データがない場合はnil）
+	Advancers      int        // 値上がり銘柄数
+	Decliners      int        // 値下がり銘柄数
+	Unchanged      int        // 変わらずの銘柄数
+	LimitUp        int        // ストップ高の銘柄数
+	LimitDown      int        // ストップ安の銘柄数
+	TurnoverValue  float64    // 売買代金の合計
+	TopixClose     *float64
+	TopixChangePct *float64 // TOPIXの前日比(%)
+}
+
+// AdvanceDeclineRatio 騰落レシオ（値上がり銘柄数÷値下がり銘柄数、値下がりがない場合はnil）
+func (b *MarketBreadth) AdvanceDeclineRatio() *float64 {
+	if b.Decliners == 0 {
+		return nil
+	}
+	ratio := float64(b.Advancers) / float64(b.Decliners)
+	return &ratio
+}
+
+// MarketMover 取引日に値動き・出来高が目立った銘柄
+type MarketMover struct {
+	Code          string
+	CompanyName   string
+	Close         float64 // 調整後終値
+	ChangePct     *float64
+	Volume        float64 // 調整後出来高
+	TurnoverValue float64
+	AvgVolume     *float64 // 過去の平均出来高（出来高急増のみ）
+	VolumeRatio   *float64 // 平均出来高に対する倍率（出来高急増のみ）
+}
+
+// MarketReportRepository 市況レポートのリポジトリ
+type MarketReportRepository struct {
+	conn *Connection
+}
+
+// NewMarketReportRepository 新しいリポジトリを作成
+func NewMarketReportRepository(conn *Connection) *MarketReportRepository {
+	return &MarketReportRepository{conn: conn}
+}
+
+// GetLatestTradeDate daily_quotesの最新取引日を取得（データがない場合はnil）
+func (r *MarketReportRepository) GetLatestTradeDate() (*time.Time, error) {
+	var date sql.NullTime
+	if err := r.conn.GetDB().QueryRow("SELECT MAX(trade_date) FROM daily_quotes").Scan(&date); err != nil {
+		return nil, fmt.Errorf("最終取引日取得エラー: %v", err)
+	}
+	if !date.Valid {
+		return nil, nil
+	}
+	return &date.Time, nil
+}
+
+// HasQuotes 取引日の株価データがあるか確認
+func (r *MarketReportRepository) HasQuotes(date time.Time) (bool, error) {
+	var exists bool
+	err := r.conn.GetDB().QueryRow(
+		"SELECT EXISTS(SELECT 1 FROM daily_quotes WHERE trade_date = ?)", date.Format("2006-01-02"),
+	).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("株価データ確認エラー: %v", err)
+	}
+	return exists, nil
+}
+
+// GetBreadth 取引日の騰落状況を前営業日の調整後終値と比較して集計
+func (r *MarketReportRepository) GetBreadth(date time.Time) (*MarketBreadth, error) {
+	breadth := &MarketBreadth{TradeDate: date}
+	db := r.conn.GetDB()
+
+	prevDate, err := r.getPrevTradeDate(date)
+	if err != nil {
+		return nil, err
+	}
+	breadth.PrevTradeDate = prevDate
+
+	// 前営業日がない場合は比較せずに売買代金などのみ集計する
+	var prev interface{}
+	if prevDate != nil {
+		prev = prevDate.Format("2006-01-02")
+	}
+
+	var turnover sql.NullFloat64
+	err = db.QueryRow(`
+		SELECT
+			COALESCE(SUM(p.adjustment_close IS NOT NULL AND dq.adjustment_close > p.adjustment_close), 0),
+			COALESCE(SUM(p.adjustment_close IS NOT NULL AND dq.adjustment_close < p.adjustment_close), 0),
+			COALESCE(SUM(p.adjustment_close IS NOT NULL AND dq.adjustment_close = p.adjustment_close), 0),
+			COALESCE(SUM(dq.upper_limit = '1'), 0),
+			COALESCE(SUM(dq.lower_limit = '1'), 0),
+			SUM(dq.turnover_value)
+		FROM daily_quotes dq
+		LEFT JOIN daily_quotes p ON p.code = dq.code AND p.trade_date = ? AND p.adjustment_close > 0
+		WHERE dq.trade_date = ? AND dq.adjustment_close > 0
+	`, prev, date.Format("2006-01-02")).Scan(
+		&breadth.Advancers, &breadth.Decliners, &breadth.Unchanged,
+		&breadth.LimitUp, &breadth.LimitDown, &turnover,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("騰落状況集計エラー: %v", err)
+	}
+	breadth.TurnoverValue = turnover.Float64
+
+	// TOPIXは取得していない場合があるため、ない場合はnilのままにする
+	var topixClose, topixPrev sql.NullFloat64
+	err = db.QueryRow(`
+		SELECT
+			(SELECT close FROM topix WHERE trade_date = ? AND close > 0),
+			(SELECT close FROM topix WHERE trade_date < ? AND close > 0 ORDER BY trade_date DESC LIMIT 1)
+	`, date.Format("2006-01-02"), date.Format("2006-01-02")).Scan(&topixClose, &topixPrev)
+	if err != nil {
+		return nil, fmt.Errorf("TOPIX取得エラー: %v", err)
+	}
+	if topixClose.Valid {
+		breadth.TopixClose = &topixClose.Float64
+		if topixPrev.Valid {
+			breadth.TopixChangePct = changePct(topixClose.Float64, topixPrev.Float64)
+		}
+	}
+
+	return breadth, nil
+}
+
+// GetMovers 取引日の前日比の上位（gainers=trueは値上がり率、falseは値下がり率）の銘柄を取得
+// minTurnover: 売買代金の下限（流動性の低い銘柄を除く）
+func (r *MarketReportRepository) GetMovers(date time.Time, gainers bool, minTurnover float64, limit int) ([]*MarketMover, error) {
+	prevDate, err := r.getPrevTradeDate(date)
+	if err != nil || prevDate == nil {
+		return nil, err
+	}
+
+	order := "DESC"
+	condition := "dq.adjustment_close > p.adjustment_close"
+	if !gainers {
+		order = "ASC"
+		condition = "dq.adjustment_close < p.adjustment_close"
+	}
+
+	query := fmt.Sprintf(`
+		SELECT
+			dq.code, COALESCE(li.company_name, ''), dq.adjustment_close,
+			(dq.adjustment_close - p.adjustment_close) / p.adjustment_close * 100 AS change_pct,
+			COALESCE(dq.adjustment_volume, 0), COALESCE(dq.turnover_value, 0), NULL, NULL
+		FROM daily_quotes dq
+		JOIN daily_quotes p ON p.code = dq.code AND p.trade_date = ? AND p.adjustment_close > 0
+		LEFT JOIN listed_info li ON li.code = dq.code
+		WHERE dq.trade_date = ? AND dq.adjustment_close > 0 AND %s AND COALESCE(dq.turnover_value, 0) >= ?
+		ORDER BY change_pct %s, dq.code
+	`, condition, order)
+	if limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", limit)
+	}
+
+	return r.queryMovers(query, prevDate.Format("2006-01-02"), date.Format("2006-01-02"), minTurnover)
+}
+
+// GetVolumeSpikes 取引日の出来高が直前の営業日の平均出来高に比べて急増した銘柄を倍率の高い順に取得
+// lookback: 平均出来高を計算する営業日数（半分以上の営業日に出来高がある銘柄のみ対象）
+// minRatio: 平均出来高に対する倍率の下限
+// minTurnover: 売買代金の下限
+func (r *MarketReportRepository) GetVolumeSpikes(date time.Time, lookback int, minRatio, minTurnover float64, limit int) ([]*MarketMover, error) {
+	var from sql.NullTime
+	err := r.conn.GetDB().QueryRow(`
+		SELECT MIN(trade_date) FROM (
+			SELECT DISTINCT trade_date FROM daily_quotes WHERE trade_date < ? ORDER BY trade_date DESC LIMIT ?
+		) d
+	`, date.Format("2006-01-02"), lookback).Scan(&from)
+	if err != nil {
+		return nil, fmt.Errorf("平均出来高の期間取得エラー: %v", err)
+	}
+	if !from.Valid {
+		return nil, nil
+	}
+
+	query := `
+		SELECT
+			dq.code, COALESCE(li.company_name, ''), dq.adjustment_close,
+			(
+				SELECT (dq.adjustment_close - p.adjustment_close) / p.adjustment_close * 100 FROM daily_quotes p
+				WHERE p.code = dq.code AND p.trade_date < dq.trade_date AND p.adjustment_close > 0
+				ORDER BY p.trade_date DESC
+				LIMIT 1
+			),
+			dq.adjustment_volume, COALESCE(dq.turnover_value, 0),
+			av.avg_volume, dq.adjustment_volume / av.avg_volume AS ratio
+		FROM daily_quotes dq
+		JOIN (
+			SELECT code, AVG(adjustment_volume) AS avg_volume
+			FROM daily_quotes
+			WHERE trade_date >= ? AND trade_date < ? AND adjustment_volume > 0
+			GROUP BY code
+			HAVING COUNT(*) * 2 >= ?
+		) av ON av.code = dq.code
+		LEFT JOIN listed_info li ON li.code = dq.code
+		WHERE dq.trade_date = ? AND dq.adjustment_close > 0
+			AND dq.adjustment_volume >= av.avg_volume * ?
+			AND COALESCE(dq.turnover_value, 0) >= ?
+		ORDER BY ratio DESC, dq.code
+	`
+	if limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", limit)
+	}
+
+	return r.queryMovers(query,
+		from.Time.Format("2006-01-02"), date.Format("2006-01-02"), lookback,
+		date.Format("2006-01-02"), minRatio, minTurnover,
+	)
+}
+
+// getPrevTradeDate 取引日の前営業日を取得（データがない場合はnil）
+func (r *MarketReportRepository) getPrevTradeDate(date time.Time) (*time.Time, error) {
+	var prev sql.NullTime
+	err := r.conn.GetDB().QueryRow(
+		"SELECT MAX(trade_date) FROM daily_quotes WHERE trade_date < ?", date.Format("2006-01-02"),
+	).Scan(&prev)
+	if err != nil {
+		return nil, fmt.Errorf("前営業日取得エラー: %v", err)
+	}
+	if !prev.Valid {
+		return nil, nil
+	}
+	return &prev.Time, nil
+}
+
+// queryMovers 銘柄ごとの値動きを取得
+func (r *MarketReportRepository) queryMovers(query string, args ...interface{}) ([]*MarketMover, error) {
+	rows, err := r.conn.GetDB().Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("値動きデータ取得エラー: %v", err)
+	}
+	defer rows.Close()
+
+	var movers []*MarketMover
+	for rows.Next() {
+		m := &MarketMover{}
+		var change, avgVolume, ratio sql.NullFloat64
+		err := rows.Scan(&m.Code, &m.CompanyName, &m.Close, &change, &m.Volume, &m.TurnoverValue, &avgVolume, &ratio)
+		if err != nil {
+			log.Printf("値動きデータスキャンエラー: %v", err)
+			continue
+		}
+		if change.Valid {
+			m.ChangePct = &change.Float64
+		}
+		if avgVolume.Valid {
+			m.AvgVolume = &avgVolume.Float64
+		}
+		if ratio.Valid {
+			m.VolumeRatio = &ratio.Float64
+		}
+		movers = append(movers, m)
+	}
+
+	return movers, nil
+}
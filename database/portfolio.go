@@ -0,0 +1,442 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+)
+
+// DefaultPortfolioName ポートフォリオ名を指定しない場合のポートフォリオ
+const DefaultPortfolioName = "default"
+
+// Portfolio ポートフォリオ
+type Portfolio struct {
+	ID          int64
+	Name        string
+	Description string
+	CreatedAt   time.Time
+}
+
+// Holding 保有銘柄
+type Holding struct {
+	PortfolioID int64
+	Code        string
+	CompanyName string
+	Quantity    int64
+	AverageCost float64 // 平均取得単価（移動平均）
+	UpdatedAt   time.Time
+}
+
+// HoldingValuation 保有銘柄の評価（株価・予想配当がない場合はnil）
+type HoldingValuation struct {
+	Holding
+	Sector33Code string
+	Sector33Name string
+
+	PriceDate            *time.Time // 評価に使った終値の取引日
+	Close                *float64
+	CostValue            float64 // 取得金額
+	MarketValue          *float64
+	UnrealizedPnL        *float64
+	UnrealizedPnLPct     *float64 // 含み損益率(%)
+	Weight               *float64 // 評価額合計に占める割合(%)
+	ForecastDPS          *float64 // 今期の予想年間配当（1株あたり）
+	ForecastAnnualIncome *float64 // 予想年間配当金（税引前）
+}
+
+// SectorWeight 33業種ごとの評価額と構成比
+type SectorWeight struct {
+	Sector33Code string
+	Sector33Name string
+	MarketValue  float64
+	Weight       float64 // 評価額合計に占める割合(%)
+	Holdings     int
+}
+
+// PortfolioValuation ポートフォリオ全体の評価
+type PortfolioValuation struct {
+	Portfolio            *Portfolio
+	Holdings             []*HoldingValuation
+	Sectors              []*SectorWeight
+	CostValue            float64 // 株価のある銘柄の取得金額合計
+	MarketValue          float64
+	UnrealizedPnL        float64
+	UnrealizedPnLPct     *float64
+	ForecastAnnualIncome float64
+	ForecastYieldOnCost  *float64 // 取得金額に対する予想配当利回り(%)
+	UnpricedHoldings     int      // 株価がなく評価から除いた銘柄数
+}
+
+// PortfolioRepository ポートフォリオのリポジトリ
+type PortfolioRepository struct {
+	conn *Connection
+}
+
+// NewPortfolioRepository 新しいリポジトリを作成
+func NewPortfolioRepository(conn *Connection) *PortfolioRepository {
+	return &PortfolioRepository{conn: conn}
+}
+
+// GetPortfolio 名前でポートフォリオを取得（存在しない場合はnil）
+func (r *PortfolioRepository) GetPortfolio(name string) (*Portfolio, error) {
+	p := &Portfolio{}
+	var description sql.NullString
+	err := r.conn.GetDB().QueryRow(
+		"SELECT id, name, description, created_at FROM portfolio WHERE name = ?", name,
+	).Scan(&p.ID, &p.Name, &description, &p.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ポートフォリオ取得エラー: %v", err)
+	}
+	p.Description = description.String
+	return p, nil
+}
+
+// GetOrCreatePortfolio 名前でポートフォリオを取得し、存在しない場合は作成
+func (r *PortfolioRepository) GetOrCreatePortfolio(name string) (*Portfolio, error) {
+	p, err := r.GetPortfolio(name)
+	if err != nil || p != nil {
+		return p, err
+	}
+
+	if _, err := r.conn.GetDB().Exec("INSERT IGNORE INTO portfolio (name) VALUES (?)", name); err != nil {
+		return nil, fmt.Errorf("ポートフォリオ作成エラー: %v", err)
+	}
+	log.Printf("ポートフォリオを作成しました: %s", name)
+
+	return r.GetPortfolio(name)
+}
+
+// GetPortfolios 全ポートフォリオを名前順に取得
+func (r *PortfolioRepository) GetPortfolios() ([]*Portfolio, error) {
+	rows, err := r.conn.GetDB().Query("SELECT id, name, description, created_at FROM portfolio ORDER BY name")
+	if err != nil {
+		return nil, fmt.Errorf("ポートフォリオ取得エラー: %v", err)
+	}
+	defer rows.Close()
+
+	var portfolios []*Portfolio
+	for rows.Next() {
+		p := &Portfolio{}
+		var description sql.NullString
+		if err := rows.Scan(&p.ID, &p.Name, &description, &p.CreatedAt); err != nil {
+			log.Printf("ポートフォリオスキャンエラー: %v", err)
+			continue
+		}
+		p.Description = description.String
+		portfolios = append(portfolios, p)
+	}
+
+	return portfolios, nil
+}
+
+// AddHolding 保有銘柄を追加（保有済みの場合は株数を加算し、平均取得単価を移動平均で再計算）
+// price: 取得単価（手数料を含める場合は手数料込みの単価）
+func (r *PortfolioRepository) AddHolding(portfolioID int64, code string, quantity int64, price float64) (*Holding, error) {
+	tx, cleanup := BeginTransaction(r.conn.GetDB())
+	defer cleanup()
+
+	holding, err := addHolding(tx, portfolioID, code, quantity, price)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("トランザクションコミットエラー: %v", err)
+	}
+
+	return holding, nil
+}
+
+// RemoveHolding 保有銘柄を減らす（平均取得単価は変えない）。quantityが0または保有株数以上の場合は削除
+// 戻り値は減らした後の保有銘柄（削除した場合はnil）
+func (r *PortfolioRepository) RemoveHolding(portfolioID int64, code string, quantity int64) (*Holding, error) {
+	tx, cleanup := BeginTransaction(r.conn.GetDB())
+	defer cleanup()
+
+	remaining, err := removeHolding(tx, portfolioID, code, quantity)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("トランザクションコミットエラー: %v", err)
+	}
+
+	return remaining, nil
+}
+
+// addHolding トランザクション内で保有銘柄を追加
+func addHolding(tx *sql.Tx, portfolioID int64, code string, quantity int64, price float64) (*Holding, error) {
+	if quantity <= 0 {
+		return nil, fmt.Errorf("株数は1以上を指定してください: %d", quantity)
+	}
+	if price < 0 {
+		return nil, fmt.Errorf("取得単価は0以上を指定してください: %g", price)
+	}
+
+	companyName, err := getListedCompanyName(tx, code)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := getHoldingForUpdate(tx, portfolioID, code)
+	if err != nil {
+		return nil, err
+	}
+
+	holding := &Holding{PortfolioID: portfolioID, Code: code, CompanyName: companyName, Quantity: quantity, AverageCost: price}
+	if current != nil {
+		holding.Quantity = current.Quantity + quantity
+		holding.AverageCost = (current.AverageCost*float64(current.Quantity) + price*float64(quantity)) / float64(holding.Quantity)
+	}
+
+	if err := saveHolding(tx, holding); err != nil {
+		return nil, err
+	}
+	return holding, nil
+}
+
+// removeHolding トランザクション内で保有銘柄を減らす
+func removeHolding(tx *sql.Tx, portfolioID int64, code string, quantity int64) (*Holding, error) {
+	if quantity < 0 {
+		return nil, fmt.Errorf("株数は0以上を指定してください: %d", quantity)
+	}
+
+	current, err := getHoldingForUpdate(tx, portfolioID, code)
+	if err != nil {
+		return nil, err
+	}
+	if current == nil {
+		return nil, fmt.Errorf("保有していない銘柄です: %s", code)
+	}
+
+	if quantity == 0 || quantity >= current.Quantity {
+		if _, err := tx.Exec("DELETE FROM holdings WHERE portfolio_id = ? AND code = ?", portfolioID, code); err != nil {
+			return nil, fmt.Errorf("保有銘柄削除エラー: %v", err)
+		}
+		return nil, nil
+	}
+
+	current.Quantity -= quantity
+	if _, err := tx.Exec("UPDATE holdings SET quantity = ? WHERE portfolio_id = ? AND code = ?", current.Quantity, portfolioID, code); err != nil {
+		return nil, fmt.Errorf("保有銘柄更新エラー: %v", err)
+	}
+	return current, nil
+}
+
+// saveHolding 保有銘柄を保存（保存済みの場合は株数と平均取得単価を更新）
+func saveHolding(tx *sql.Tx, holding *Holding) error {
+	_, err := tx.Exec(`
+		INSERT INTO holdings (portfolio_id, code, quantity, average_cost)
+		VALUES (?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE
+			quantity = VALUES(quantity),
+			average_cost = VALUES(average_cost)
+	`, holding.PortfolioID, holding.Code, holding.Quantity, holding.AverageCost)
+	if err != nil {
+		return fmt.Errorf("保有銘柄保存エラー: %v", err)
+	}
+	return nil
+}
+
+// getListedCompanyName 上場銘柄情報の企業名を取得（存在しない銘柄コードの場合はエラー）
+func getListedCompanyName(tx *sql.Tx, code string) (string, error) {
+	var companyName string
+	err := tx.QueryRow("SELECT company_name FROM listed_info WHERE code = ?", code).Scan(&companyName)
+	if err == sql.ErrNoRows {
+		return "", fmt.Errorf("上場銘柄情報に存在しない銘柄コードです: %s", code)
+	}
+	if err != nil {
+		return "", fmt.Errorf("上場銘柄情報取得エラー: %v", err)
+	}
+	return companyName, nil
+}
+
+// getHoldingForUpdate 保有銘柄を行ロックして取得（保有していない場合はnil）
+func getHoldingForUpdate(tx *sql.Tx, portfolioID int64, code string) (*Holding, error) {
+	h := &Holding{}
+	err := tx.QueryRow(`
+		SELECT portfolio_id, code, quantity, average_cost, updated_at
+		FROM holdings
+		WHERE portfolio_id = ? AND code = ?
+		FOR UPDATE
+	`, portfolioID, code).Scan(&h.PortfolioID, &h.Code, &h.Quantity, &h.AverageCost, &h.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("保有銘柄取得エラー: %v", err)
+	}
+	return h, nil
+}
+
+// GetHoldings ポートフォリオの保有銘柄をコード順に取得
+func (r *PortfolioRepository) GetHoldings(portfolioID int64) ([]*Holding, error) {
+	rows, err := r.conn.GetDB().Query(`
+		SELECT h.portfolio_id, h.code, COALESCE(li.company_name, ''), h.quantity, h.average_cost, h.updated_at
+		FROM holdings h
+		LEFT JOIN listed_info li ON li.code = h.code
+		WHERE h.portfolio_id = ?
+		ORDER BY h.code
+	`, portfolioID)
+	if err != nil {
+		return nil, fmt.Errorf("保有銘柄取得エラー: %v", err)
+	}
+	defer rows.Close()
+
+	var holdings []*Holding
+	for rows.Next() {
+		h := &Holding{}
+		if err := rows.Scan(&h.PortfolioID, &h.Code, &h.CompanyName, &h.Quantity, &h.AverageCost, &h.UpdatedAt); err != nil {
+			log.Printf("保有銘柄スキャンエラー: %v", err)
+			continue
+		}
+		holdings = append(holdings, h)
+	}
+
+	return holdings, nil
+}
+
+// GetValuation 保有銘柄をdaily_quotesの最新の終値で評価し、含み損益・33業種別の構成比・予想年間配当金を集計
+// 予想配当はstatements_summaryの予想のうち、終値の取引日以降に終わる直近の会計年度の年間配当を使う
+func (r *PortfolioRepository) GetValuation(portfolio *Portfolio) (*PortfolioValuation, error) {
+	rows, err := r.conn.GetDB().Query(`
+		SELECT
+			h.portfolio_id, h.code, COALESCE(li.company_name, ''), h.quantity, h.average_cost, h.updated_at,
+			COALESCE(li.sector33_code, ''), COALESCE(s33.name, ''),
+			dq.trade_date, dq.close,
+			(
+				SELECT ss.dividend_per_share
+				FROM statements_summary ss
+				WHERE ss.local_code = h.code
+					AND ss.is_forecast = TRUE
+					AND ss.fiscal_year_end_date >= COALESCE(dq.trade_date, CURDATE())
+				ORDER BY ss.fiscal_year_end_date
+				LIMIT 1
+			) AS forecast_dps
+		FROM holdings h
+		LEFT JOIN listed_info li ON li.code = h.code
+		LEFT JOIN sector33_codes s33 ON s33.code = li.sector33_code
+		LEFT JOIN daily_quotes dq
+			ON dq.code = h.code
+			AND dq.trade_date = (
+				SELECT MAX(trade_date) FROM daily_quotes WHERE code = h.code AND close IS NOT NULL
+			)
+		WHERE h.portfolio_id = ?
+		ORDER BY h.code
+	`, portfolio.ID)
+	if err != nil {
+		return nil, fmt.Errorf("保有銘柄評価データ取得エラー: %v", err)
+	}
+	defer rows.Close()
+
+	valuation := &PortfolioValuation{Portfolio: portfolio}
+	for rows.Next() {
+		v := &HoldingValuation{}
+		var priceDate sql.NullTime
+		var close, forecastDPS sql.NullFloat64
+		err := rows.Scan(
+			&v.PortfolioID,
+			&v.Code,
+			&v.CompanyName,
+			&v.Quantity,
+			&v.AverageCost,
+			&v.UpdatedAt,
+			&v.Sector33Code,
+			&v.Sector33Name,
+			&priceDate,
+			&close,
+			&forecastDPS,
+		)
+		if err != nil {
+			log.Printf("保有銘柄評価データスキャンエラー: %v", err)
+			continue
+		}
+
+		v.CostValue = v.AverageCost * float64(v.Quantity)
+		if priceDate.Valid && close.Valid {
+			marketValue := close.Float64 * float64(v.Quantity)
+			pnl := marketValue - v.CostValue
+			v.PriceDate = &priceDate.Time
+			v.Close = &close.Float64
+			v.MarketValue = &marketValue
+			v.UnrealizedPnL = &pnl
+			if v.CostValue > 0 {
+				pct := pnl / v.CostValue * 100
+				v.UnrealizedPnLPct = &pct
+			}
+
+			valuation.CostValue += v.CostValue
+			valuation.MarketValue += marketValue
+		} else {
+			valuation.UnpricedHoldings++
+		}
+		if forecastDPS.Valid && forecastDPS.Float64 > 0 {
+			income := forecastDPS.Float64 * float64(v.Quantity)
+			v.ForecastDPS = &forecastDPS.Float64
+			v.ForecastAnnualIncome = &income
+			valuation.ForecastAnnualIncome += income
+		}
+
+		valuation.Holdings = append(valuation.Holdings, v)
+	}
+
+	valuation.UnrealizedPnL = valuation.MarketValue - valuation.CostValue
+	if valuation.CostValue > 0 {
+		pct := valuation.UnrealizedPnL / valuation.CostValue * 100
+		valuation.UnrealizedPnLPct = &pct
+	}
+	var totalCost float64
+	for _, v := range valuation.Holdings {
+		totalCost += v.CostValue
+	}
+	if totalCost > 0 {
+		yield := valuation.ForecastAnnualIncome / totalCost * 100
+		valuation.ForecastYieldOnCost = &yield
+	}
+
+	valuation.Sectors = sectorWeights(valuation.Holdings, valuation.MarketValue)
+	return valuation, nil
+}
+
+// sectorWeights 保有銘柄の構成比を設定し、33業種ごとに集計（評価額の大きい順）
+func sectorWeights(holdings []*HoldingValuation, total float64) []*SectorWeight {
+	sectorMap := make(map[string]*SectorWeight)
+	var sectors []*SectorWeight
+	for _, v := range holdings {
+		if v.MarketValue == nil {
+			continue
+		}
+		if total > 0 {
+			weight := *v.MarketValue / total * 100
+			v.Weight = &weight
+		}
+
+		sector, ok := sectorMap[v.Sector33Code]
+		if !ok {
+			sector = &SectorWeight{Sector33Code: v.Sector33Code, Sector33Name: v.Sector33Name}
+			sectorMap[v.Sector33Code] = sector
+			sectors = append(sectors, sector)
+		}
+		sector.MarketValue += *v.MarketValue
+		sector.Holdings++
+	}
+
+	for _, sector := range sectors {
+		if total > 0 {
+			sector.Weight = sector.MarketValue / total * 100
+		}
+	}
+	sort.SliceStable(sectors, func(i, j int) bool {
+		return sectors[i].MarketValue > sectors[j].MarketValue
+	})
+
+	return sectors
+}
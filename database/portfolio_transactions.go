@@ -0,0 +1,562 @@
+package database
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"time"
+)
+
+// 取引の種別
+const (
+	TransactionBuy      = "buy"
+	TransactionSell     = "sell"
+	TransactionDividend = "dividend"
+	TransactionSplit    = "split"
+)
+
+// TransactionSourceManual 手入力した取引の取り込み元
+const TransactionSourceManual = "manual"
+
+// settlementT2StartDate 受渡日がT+2になった日（それより前の約定はT+3）
+var settlementT2StartDate = time.Date(2019, 7, 16, 0, 0, 0, 0, time.Local)
+
+// PortfolioTransaction 取引履歴
+type PortfolioTransaction struct {
+	ID              int64
+	PortfolioID     int64
+	Code            string
+	CompanyName     string
+	TransactionType string
+	TradeDate       time.Time
+	SettlementDate  time.Time
+	Quantity        int64
+	Price           *float64 // 約定単価（売買の場合）
+	Fee             float64  // 手数料（消費税込み）
+	Amount          *float64 // 配当金額（税引前、配当の場合）
+	Tax             float64  // 源泉徴収税額
+	SplitRatio      *float64 // 1株あたりの分割・併合後の株数（株式分割・併合の場合）
+	Note            string
+	Source          string // 取り込み元（空の場合はmanual）
+	ImportKey       string // CSV取り込み時の重複判定用キー
+}
+
+// JournalEntry 取引履歴に移動平均法で計算した取引後の保有株数・平均取得単価・実現損益を付けたもの
+type JournalEntry struct {
+	*PortfolioTransaction
+	Auto        bool     // 取引履歴に記録されていない株式分割・併合をdaily_quotesの調整係数から補ったもの
+	Position    int64    // 取引後の保有株数
+	AverageCost float64  // 取引後の平均取得単価（手数料込み）
+	CostBasis   *float64 // 売却した株式の取得費（売りの場合、取得費が分からない場合はnil）
+	RealizedPnL *float64 // 実現損益（売りの場合、手数料控除後。取得費が分からない場合はnil）
+	UnknownCost bool     // 保有株数を超える売り（取り込み期間より前に買った株式など）で取得費が分からないもの
+}
+
+// YearlyPnL 年ごとの実現損益と配当（売買は受渡日、配当は支払日の年で集計）
+type YearlyPnL struct {
+	Year        int
+	Sells       int
+	Proceeds    float64 // 売却代金（手数料控除後）
+	CostBasis   float64 // 取得費
+	RealizedPnL float64
+	Dividends   float64 // 配当金額（税引前）
+	Tax         float64 // 源泉徴収税額
+
+	UnknownCostSells int // 取得費が分からないため売却件数・売却代金・取得費・実現損益に含めていない売りの件数
+}
+
+// AddTransaction 取引を記録し、売買・株式分割・併合を保有銘柄に反映
+// 買いは手数料込みの単価で平均取得単価を移動平均で再計算し、売りは平均取得単価を変えずに株数を減らす
+// 保有株数を超える売りはエラーとする
+func (r *PortfolioRepository) AddTransaction(t *PortfolioTransaction) error {
+	return r.addTransaction(t, true)
+}
+
+// ImportTransaction CSVから取り込んだ取引を記録し、保有銘柄に反映
+// 取り込み期間より前に買った銘柄の売りなど、保有銘柄にない売りは取引履歴にのみ記録する
+func (r *PortfolioRepository) ImportTransaction(t *PortfolioTransaction) error {
+	return r.addTransaction(t, false)
+}
+
+// HasImportKey 重複判定用キーが一致する取引が記録済みかを判定
+func (r *PortfolioRepository) HasImportKey(portfolioID int64, importKey string) (bool, error) {
+	var count int
+	err := r.conn.GetDB().QueryRow(
+		"SELECT COUNT(*) FROM portfolio_transactions WHERE portfolio_id = ? AND import_key = ?", portfolioID, importKey,
+	).Scan(&count)
+	if err != nil {
+		return false, fmt.Errorf("取引履歴確認エラー: %v", err)
+	}
+	return count > 0, nil
+}
+
+// TransactionImportKey 取引の内容から重複判定用キーを作成
+// occurrence: 同じファイル内で内容が同じ取引の出現順（同じ日に同じ単価で複数回約定した場合に区別する）
+func TransactionImportKey(t *PortfolioTransaction, occurrence int) string {
+	key := fmt.Sprintf("%s|%s|%s|%d|%s|%s|%s|%d",
+		t.Code, t.TransactionType, t.TradeDate.Format("2006-01-02"), t.Quantity,
+		formatKeyFloat(t.Price), formatKeyFloat(t.Amount), formatKeyFloat(t.SplitRatio), occurrence)
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// formatKeyFloat 重複判定用キーに含める数値を文字列に変換
+func formatKeyFloat(v *float64) string {
+	if v == nil {
+		return ""
+	}
+	return fmt.Sprintf("%.4f", *v)
+}
+
+// addTransaction 取引を記録して保有銘柄に反映（strict=falseの場合は保有銘柄にない売りを取引履歴にのみ記録）
+func (r *PortfolioRepository) addTransaction(t *PortfolioTransaction, strict bool) error {
+	if err := ValidateTransaction(t); err != nil {
+		return err
+	}
+	if t.Source == "" {
+		t.Source = TransactionSourceManual
+	}
+
+	// 受渡日の指定がない場合、売買は約定日から計算し、それ以外は約定日と同じとする
+	if t.SettlementDate.IsZero() {
+		t.SettlementDate = t.TradeDate
+		if t.TransactionType == TransactionBuy || t.TransactionType == TransactionSell {
+			settlementDate, err := r.settlementDate(t.TradeDate)
+			if err != nil {
+				return err
+			}
+			t.SettlementDate = settlementDate
+		}
+	}
+
+	tx, cleanup := BeginTransaction(r.conn.GetDB())
+	defer cleanup()
+
+	companyName, err := getListedCompanyName(tx, t.Code)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	t.CompanyName = companyName
+
+	var importKey interface{}
+	if t.ImportKey != "" {
+		importKey = t.ImportKey
+	}
+	result, err := tx.Exec(`
+		INSERT INTO portfolio_transactions (
+			portfolio_id, code, transaction_type, trade_date, settlement_date,
+			quantity, price, fee, amount, tax, split_ratio, note, source, import_key
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`,
+		t.PortfolioID, t.Code, t.TransactionType, t.TradeDate.Format("2006-01-02"), t.SettlementDate.Format("2006-01-02"),
+		t.Quantity, t.Price, t.Fee, t.Amount, t.Tax, t.SplitRatio, t.Note, t.Source, importKey,
+	)
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("取引履歴保存エラー: %v", err)
+	}
+	t.ID, _ = result.LastInsertId()
+
+	// 保有銘柄に反映
+	switch t.TransactionType {
+	case TransactionBuy:
+		_, err = addHolding(tx, t.PortfolioID, t.Code, t.Quantity, (*t.Price*float64(t.Quantity)+t.Fee)/float64(t.Quantity))
+	case TransactionSell:
+		var current *Holding
+		if current, err = getHoldingForUpdate(tx, t.PortfolioID, t.Code); err != nil {
+			break
+		}
+		if current == nil && !strict {
+			log.Printf("保有銘柄にない売りのため取引履歴にのみ記録しました（銘柄: %s, 約定日: %s）", t.Code, t.TradeDate.Format("2006-01-02"))
+			break
+		}
+		if current != nil && strict && t.Quantity > current.Quantity {
+			err = fmt.Errorf("保有株数を超える売りです（銘柄: %s, 保有: %d株, 売り: %d株）", t.Code, current.Quantity, t.Quantity)
+			break
+		}
+		_, err = removeHolding(tx, t.PortfolioID, t.Code, t.Quantity)
+	case TransactionSplit:
+		err = splitHolding(tx, t)
+	}
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("トランザクションコミットエラー: %v", err)
+	}
+
+	return nil
+}
+
+// ValidateTransaction 取引の種別ごとに必要な項目を検証
+func ValidateTransaction(t *PortfolioTransaction) error {
+	if t.Fee < 0 || t.Tax < 0 {
+		return fmt.Errorf("手数料・税額は0以上を指定してください")
+	}
+
+	switch t.TransactionType {
+	case TransactionBuy, TransactionSell:
+		if t.Quantity <= 0 {
+			return fmt.Errorf("株数は1以上を指定してください: %d", t.Quantity)
+		}
+		if t.Price == nil || *t.Price < 0 {
+			return fmt.Errorf("約定単価を0以上で指定してください")
+		}
+	case TransactionDividend:
+		if t.Amount == nil || *t.Amount < 0 {
+			return fmt.Errorf("配当金額を0以上で指定してください")
+		}
+	case TransactionSplit:
+		if t.SplitRatio == nil || *t.SplitRatio <= 0 || *t.SplitRatio == 1 {
+			return fmt.Errorf("分割・併合比率は0より大きく1以外の値を指定してください（例: 1株を2株に分割する場合は2）")
+		}
+	default:
+		return fmt.Errorf("サポートされていない取引の種別です（buy, sell, dividend, split）: '%s'", t.TransactionType)
+	}
+
+	return nil
+}
+
+// settlementDate 約定日から受渡日を計算（2019-07-16以降はT+2、それより前はT+3）
+func (r *PortfolioRepository) settlementDate(tradeDate time.Time) (time.Time, error) {
+	calendar, err := NewTradingCalendarRepository(r.conn).LoadBusinessCalendar(tradeDate, tradeDate.AddDate(0, 0, 14))
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	days := 2
+	if tradeDate.Before(settlementT2StartDate) {
+		days = 3
+	}
+	return calendar.BusinessDaysAfter(tradeDate, days), nil
+}
+
+// splitHolding 記録した株式分割・併合を保有銘柄に反映（取得金額は変えずに株数と平均取得単価を調整し、端数株は切り捨て）
+// 保有銘柄が取引履歴どおりの場合は、株式分割・併合を含めた取引履歴を移動平均法で計算し直した保有株数・平均取得単価にする
+// 取引履歴と一致しない場合（取引履歴なしで追加した保有銘柄など）は、権利落ち日の前日に保有していた株数にのみ比率を掛ける
+// （いずれも権利落ち日以降に売買した株式には比率を掛けない）
+func splitHolding(tx *sql.Tx, split *PortfolioTransaction) error {
+	current, err := getHoldingForUpdate(tx, split.PortfolioID, split.Code)
+	if err != nil || current == nil {
+		return err
+	}
+
+	transactions, err := getTransactions(tx, split.PortfolioID, split.Code)
+	if err != nil {
+		return err
+	}
+	var before []*PortfolioTransaction
+	for _, t := range transactions {
+		if t.ID != split.ID {
+			before = append(before, t)
+		}
+	}
+
+	// 記録済みの取引履歴のみで計算し（daily_quotesからは補わない）、保有銘柄と一致するか確認する
+	if len(before) > 0 {
+		recorded := calculateJournal(before, nil)
+		last := recorded[len(recorded)-1]
+		if last.Position == current.Quantity && math.Abs(last.AverageCost-current.AverageCost) < 0.01 {
+			journal := calculateJournal(transactions, nil)
+			last = journal[len(journal)-1]
+			current.Quantity, current.AverageCost = last.Position, last.AverageCost
+			return updateSplitHolding(tx, current)
+		}
+	}
+
+	// 権利落ち日以降の売買の株数を除いた株数を権利落ち日の前日の保有株数とする
+	held := current.Quantity
+	for _, t := range before {
+		if t.TradeDate.Before(split.TradeDate) {
+			continue
+		}
+		switch t.TransactionType {
+		case TransactionBuy:
+			held -= t.Quantity
+		case TransactionSell:
+			held += t.Quantity
+		}
+	}
+	if held < 0 {
+		held = 0
+	} else if held > current.Quantity {
+		held = current.Quantity
+	}
+
+	quantity := current.Quantity - held + splitQuantity(held, *split.SplitRatio)
+	if quantity > 0 {
+		current.AverageCost = current.AverageCost * float64(current.Quantity) / float64(quantity)
+	}
+	current.Quantity = quantity
+	return updateSplitHolding(tx, current)
+}
+
+// updateSplitHolding 株式分割・併合を反映した保有銘柄を保存（株数が0の場合は削除）
+func updateSplitHolding(tx *sql.Tx, holding *Holding) error {
+	if holding.Quantity <= 0 {
+		if _, err := tx.Exec("DELETE FROM holdings WHERE portfolio_id = ? AND code = ?", holding.PortfolioID, holding.Code); err != nil {
+			return fmt.Errorf("保有銘柄削除エラー: %v", err)
+		}
+		return nil
+	}
+	return saveHolding(tx, holding)
+}
+
+// splitQuantity 株式分割・併合後の株数（端数は切り捨て）
+func splitQuantity(quantity int64, ratio float64) int64 {
+	return int64(math.Floor(float64(quantity)*ratio + 1e-6))
+}
+
+// GetTransactions 取引履歴を約定日の古い順に取得
+// code: 銘柄コード（空の場合は全銘柄）
+func (r *PortfolioRepository) GetTransactions(portfolioID int64, code string) ([]*PortfolioTransaction, error) {
+	return getTransactions(r.conn.GetDB(), portfolioID, code)
+}
+
+// getTransactions 取引履歴を約定日の古い順に取得（トランザクション内の未コミットの取引も含めて取得できるようにする）
+func getTransactions(q sqlQueryer, portfolioID int64, code string) ([]*PortfolioTransaction, error) {
+	query := `
+		SELECT
+			t.id, t.portfolio_id, t.code, COALESCE(li.company_name, ''), t.transaction_type,
+			t.trade_date, t.settlement_date, t.quantity, t.price, t.fee, t.amount, t.tax,
+			t.split_ratio, COALESCE(t.note, ''), t.source, COALESCE(t.import_key, '')
+		FROM portfolio_transactions t
+		LEFT JOIN listed_info li ON li.code = t.code
+		WHERE t.portfolio_id = ?
+	`
+	args := []interface{}{portfolioID}
+	if code != "" {
+		query += " AND t.code = ?"
+		args = append(args, code)
+	}
+	query += " ORDER BY t.trade_date, t.id"
+
+	rows, err := q.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("取引履歴取得エラー: %v", err)
+	}
+	defer rows.Close()
+
+	var transactions []*PortfolioTransaction
+	for rows.Next() {
+		t := &PortfolioTransaction{}
+		err := rows.Scan(
+			&t.ID,
+			&t.PortfolioID,
+			&t.Code,
+			&t.CompanyName,
+			&t.TransactionType,
+			&t.TradeDate,
+			&t.SettlementDate,
+			&t.Quantity,
+			&t.Price,
+			&t.Fee,
+			&t.Amount,
+			&t.Tax,
+			&t.SplitRatio,
+			&t.Note,
+			&t.Source,
+			&t.ImportKey,
+		)
+		if err != nil {
+			log.Printf("取引履歴スキャンエラー: %v", err)
+			continue
+		}
+		transactions = append(transactions, t)
+	}
+
+	return transactions, nil
+}
+
+// BuildJournal 取引履歴を銘柄ごとに移動平均法で計算し、取引後の保有株数・平均取得単価と売りの実現損益を付けて約定日順に返す
+// 保有中に権利落ちした株式分割・併合が取引履歴にない場合は、daily_quotesの調整係数から補う（Auto=true）
+// code: 銘柄コード（空の場合は全銘柄）
+func (r *PortfolioRepository) BuildJournal(portfolioID int64, code string) ([]*JournalEntry, error) {
+	transactions, err := r.GetTransactions(portfolioID, code)
+	if err != nil {
+		return nil, err
+	}
+
+	byCode := make(map[string][]*PortfolioTransaction)
+	var codes []string
+	for _, t := range transactions {
+		if _, ok := byCode[t.Code]; !ok {
+			codes = append(codes, t.Code)
+		}
+		byCode[t.Code] = append(byCode[t.Code], t)
+	}
+
+	var journal []*JournalEntry
+	for _, c := range codes {
+		splits, err := getSplitEvents(r.conn.GetDB(), c)
+		if err != nil {
+			return nil, err
+		}
+		journal = append(journal, calculateJournal(byCode[c], splits)...)
+	}
+
+	sort.SliceStable(journal, func(i, j int) bool {
+		if !journal[i].TradeDate.Equal(journal[j].TradeDate) {
+			return journal[i].TradeDate.Before(journal[j].TradeDate)
+		}
+		return journal[i].Code < journal[j].Code
+	})
+
+	return journal, nil
+}
+
+// calculateJournal 1銘柄の取引履歴（約定日順）を移動平均法で計算
+// 同じ日の株式分割・併合は権利落ち日の寄り付き前に反映されるものとして売買より先に処理する
+func calculateJournal(transactions []*PortfolioTransaction, splits []splitEvent) []*JournalEntry {
+	entries := make([]*JournalEntry, 0, len(transactions))
+	recorded := make(map[string]bool)
+	for _, t := range transactions {
+		entries = append(entries, &JournalEntry{PortfolioTransaction: t})
+		if t.TransactionType == TransactionSplit {
+			recorded[t.TradeDate.Format("2006-01-02")] = true
+		}
+	}
+
+	// 取引履歴にない株式分割・併合を補う
+	first := transactions[0]
+	for _, split := range splits {
+		if split.tradeDate.Before(first.TradeDate) || recorded[split.tradeDate.Format("2006-01-02")] {
+			continue
+		}
+		ratio := math.Round(1/split.factor*1e6) / 1e6
+		entries = append(entries, &JournalEntry{
+			PortfolioTransaction: &PortfolioTransaction{
+				PortfolioID:     first.PortfolioID,
+				Code:            first.Code,
+				CompanyName:     first.CompanyName,
+				TransactionType: TransactionSplit,
+				TradeDate:       split.tradeDate,
+				SettlementDate:  split.tradeDate,
+				SplitRatio:      &ratio,
+				Note:            fmt.Sprintf("daily_quotesの調整係数 %g から補完", split.factor),
+			},
+			Auto: true,
+		})
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if !a.TradeDate.Equal(b.TradeDate) {
+			return a.TradeDate.Before(b.TradeDate)
+		}
+		return a.TransactionType == TransactionSplit && b.TransactionType != TransactionSplit
+	})
+
+	var position int64
+	var averageCost float64
+	journal := make([]*JournalEntry, 0, len(entries))
+	for _, e := range entries {
+		switch e.TransactionType {
+		case TransactionBuy:
+			cost := *e.Price*float64(e.Quantity) + e.Fee
+			averageCost = (averageCost*float64(position) + cost) / float64(position+e.Quantity)
+			position += e.Quantity
+		case TransactionSell:
+			quantity := e.Quantity
+			if quantity > position {
+				// 取得費を0とすると実現損益が過大になるため、取得費不明として集計から除く
+				log.Printf("保有株数を超える売りのため取得費不明とします（銘柄: %s, 約定日: %s, 売り: %d株, 保有: %d株）",
+					e.Code, e.TradeDate.Format("2006-01-02"), quantity, position)
+				e.UnknownCost = true
+			} else {
+				costBasis := averageCost * float64(quantity)
+				pnl := *e.Price*float64(quantity) - e.Fee - costBasis
+				e.CostBasis = &costBasis
+				e.RealizedPnL = &pnl
+			}
+			position -= quantity
+			if position <= 0 {
+				position, averageCost = 0, 0
+			}
+		case TransactionSplit:
+			// 保有していない期間の株式分割・併合は補わない
+			if e.Auto && position == 0 {
+				continue
+			}
+			quantity := splitQuantity(position, *e.SplitRatio)
+			if quantity > 0 {
+				averageCost = averageCost * float64(position) / float64(quantity)
+			} else {
+				averageCost = 0
+			}
+			position = quantity
+		}
+
+		e.Position = position
+		e.AverageCost = averageCost
+		journal = append(journal, e)
+	}
+
+	return journal
+}
+
+// PendingSplits 保有中に権利落ちしたが取引履歴に記録されていない株式分割・併合を取得
+func (r *PortfolioRepository) PendingSplits(portfolioID int64) ([]*JournalEntry, error) {
+	journal, err := r.BuildJournal(portfolioID, "")
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []*JournalEntry
+	for _, e := range journal {
+		if e.Auto {
+			pending = append(pending, e)
+		}
+	}
+	return pending, nil
+}
+
+// SummarizeByYear 取引履歴から年ごとの実現損益と配当を集計（年の古い順）
+// 売買は受渡日、配当は支払日の年で集計する（確定申告・年間取引報告書と同じ基準）
+// 取得費が分からない売りは件数のみ数え、売却代金・取得費・実現損益には含めない
+func SummarizeByYear(journal []*JournalEntry) []*YearlyPnL {
+	byYear := make(map[int]*YearlyPnL)
+	get := func(year int) *YearlyPnL {
+		if y, ok := byYear[year]; ok {
+			return y
+		}
+		y := &YearlyPnL{Year: year}
+		byYear[year] = y
+		return y
+	}
+
+	for _, e := range journal {
+		switch e.TransactionType {
+		case TransactionSell:
+			y := get(e.SettlementDate.Year())
+			y.Tax += e.Tax
+			if e.UnknownCost {
+				y.UnknownCostSells++
+				continue
+			}
+			y.Sells++
+			y.Proceeds += *e.Price*float64(e.Quantity) - e.Fee
+			y.CostBasis += *e.CostBasis
+			y.RealizedPnL += *e.RealizedPnL
+		case TransactionDividend:
+			y := get(e.TradeDate.Year())
+			y.Dividends += *e.Amount
+			y.Tax += e.Tax
+		}
+	}
+
+	years := make([]*YearlyPnL, 0, len(byYear))
+	for _, y := range byYear {
+		years = append(years, y)
+	}
+	sort.Slice(years, func(i, j int) bool { return years[i].Year < years[j].Year })
+
+	return years
+}
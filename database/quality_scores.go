@@ -0,0 +1,483 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"math"
+	"time"
+)
+
+// Z-scoreの判定区分
+const (
+	ZoneSafe     = "safe"
+	ZoneGrey     = "grey"
+	ZoneDistress = "distress"
+)
+
+// FScoreComponents Piotroski F-scoreの内訳（nilはデータ不足で判定不能）
+type FScoreComponents struct {
+	ROAPositive       *bool
+	CFOPositive       *bool
+	ROAImproved       *bool
+	Accrual           *bool
+	LeverageImproved  *bool
+	LiquidityImproved *bool
+	NoDilution        *bool
+	MarginImproved    *bool
+	TurnoverImproved  *bool
+}
+
+// ZScoreComponents Altman Z-score（変形版）の内訳
+type ZScoreComponents struct {
+	CashToAssets             *float64
+	EquityToAssets           *float64
+	OperatingProfitToAssets  *float64
+	MarketValueToLiabilities *float64
+	SalesToAssets            *float64
+}
+
+// QualityScore 財務品質スコアの構造体
+type QualityScore struct {
+	LocalCode           string
+	FiscalYearStartDate time.Time
+	FiscalYearEndDate   *time.Time
+	DisclosedDate       time.Time
+	F                   FScoreComponents
+	FScore              int
+	FScoreEvaluated     int
+	Z                   ZScoreComponents
+	MarketValueEquity   *int64
+	ZScore              *float64
+	ZZone               string // 算出できない場合は空文字列
+}
+
+// QualityScoreRepository 財務品質スコアのリポジトリ
+type QualityScoreRepository struct {
+	conn *Connection
+}
+
+// NewQualityScoreRepository 新しいリポジトリを作成
+func NewQualityScoreRepository(conn *Connection) *QualityScoreRepository {
+	return &QualityScoreRepository{conn: conn}
+}
+
+// annualFundamentals スコア算出に使う通期実績
+type annualFundamentals struct {
+	fiscalYearStart time.Time
+	fiscalYearEnd   *time.Time
+	disclosedDate   time.Time
+	netSales        *int64
+	operatingProfit *int64
+	profit          *int64
+	totalAssets     *int64
+	equity          *int64
+	cfOperating     *int64
+	cash            *int64
+	issuedShares    *int64
+	treasuryShares  *int64
+}
+
+// BuildScores 通期実績から財務品質スコアを算出して保存
+// localCodes: 対象銘柄コード（空の場合は全銘柄）
+func (r *QualityScoreRepository) BuildScores(localCodes []string) error {
+	tx, cleanup := BeginTransaction(r.conn.GetDB())
+	defer cleanup()
+
+	if len(localCodes) == 0 {
+		codes, err := selectDistinctLocalCodes(tx)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		localCodes = codes
+	}
+
+	processedCount := 0
+	for _, code := range localCodes {
+		if err := r.processLocalCode(tx, code); err != nil {
+			log.Printf("銘柄 %s の財務品質スコア算出でエラー: %v", code, err)
+			continue
+		}
+		processedCount++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("トランザクションコミットエラー: %v", err)
+	}
+
+	log.Printf("財務品質スコアの作成完了: %d銘柄処理", processedCount)
+	return nil
+}
+
+// processLocalCode 個別銘柄の財務品質スコアを算出して置き換え
+func (r *QualityScoreRepository) processLocalCode(tx *sql.Tx, localCode string) error {
+	years, err := getAnnualFundamentals(tx, localCode)
+	if err != nil {
+		return err
+	}
+
+	byStart := make(map[string]*annualFundamentals, len(years))
+	for _, year := range years {
+		byStart[year.fiscalYearStart.Format("2006-01-02")] = year
+	}
+
+	splits, err := getSplitEvents(tx, localCode)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec("DELETE FROM quality_scores WHERE local_code = ?", localCode); err != nil {
+		return fmt.Errorf("既存データ削除エラー: %v", err)
+	}
+
+	for _, year := range years {
+		prev := byStart[year.fiscalYearStart.AddDate(-1, 0, 0).Format("2006-01-02")]
+
+		marketValue, err := getMarketValueAt(tx, localCode, year)
+		if err != nil {
+			return err
+		}
+
+		score := calculateQualityScore(localCode, year, prev, marketValue, splits)
+		if err := r.insertScore(tx, score); err != nil {
+			return fmt.Errorf("財務品質スコア挿入エラー (年度: %s): %v", year.fiscalYearStart.Format("2006-01-02"), err)
+		}
+	}
+
+	return nil
+}
+
+// getAnnualFundamentals 会計年度ごとの最新の通期実績を会計年度の古い順に取得
+func getAnnualFundamentals(q sqlQueryer, localCode string) ([]*annualFundamentals, error) {
+	query := `
+		SELECT
+			current_fiscal_year_start_date,
+			current_fiscal_year_end_date,
+			disclosed_date,
+			net_sales,
+			operating_profit,
+			profit,
+			total_assets,
+			equity,
+			cf_operating,
+			cash_and_equivalents,
+			issued_shares_end_fy_incl_treasury,
+			treasury_shares_end_fy
+		FROM statements
+		WHERE local_code = ?
+			AND type_of_current_period = 'FY'
+			AND current_fiscal_year_start_date IS NOT NULL
+			AND (net_sales IS NOT NULL OR operating_profit IS NOT NULL OR profit IS NOT NULL)
+		ORDER BY current_fiscal_year_start_date, disclosed_date, disclosed_time
+	`
+
+	rows, err := q.Query(query, localCode)
+	if err != nil {
+		return nil, fmt.Errorf("通期実績データ取得エラー: %v", err)
+	}
+	defer rows.Close()
+
+	var years []*annualFundamentals
+	for rows.Next() {
+		year := &annualFundamentals{}
+		err := rows.Scan(
+			&year.fiscalYearStart,
+			&year.fiscalYearEnd,
+			&year.disclosedDate,
+			&year.netSales,
+			&year.operatingProfit,
+			&year.profit,
+			&year.totalAssets,
+			&year.equity,
+			&year.cfOperating,
+			&year.cash,
+			&year.issuedShares,
+			&year.treasuryShares,
+		)
+		if err != nil {
+			log.Printf("通期実績データスキャンエラー: %v", err)
+			continue
+		}
+
+		// 同一会計年度は後の開示で置き換える
+		if n := len(years); n > 0 && years[n-1].fiscalYearStart.Equal(year.fiscalYearStart) {
+			years[n-1] = year
+			continue
+		}
+		years = append(years, year)
+	}
+
+	return years, nil
+}
+
+// getMarketValueAt 期末日以前10日以内の直近終値と期末の株式数から時価総額を算出（算出できない場合はnil）
+func getMarketValueAt(q *sql.Tx, localCode string, year *annualFundamentals) (*int64, error) {
+	if year.fiscalYearEnd == nil || year.issuedShares == nil {
+		return nil, nil
+	}
+
+	var price sql.NullFloat64
+	err := q.QueryRow(`
+		SELECT close FROM daily_quotes
+		WHERE code = ? AND trade_date <= ? AND trade_date > ? AND close IS NOT NULL
+		ORDER BY trade_date DESC
+		LIMIT 1
+	`, localCode, *year.fiscalYearEnd, year.fiscalYearEnd.AddDate(0, 0, -10)).Scan(&price)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("期末株価取得エラー: %v", err)
+	}
+
+	shares := *year.issuedShares
+	if year.treasuryShares != nil {
+		shares -= *year.treasuryShares
+	}
+	if shares <= 0 {
+		return nil, nil
+	}
+
+	marketValue := int64(math.Round(price.Float64 * float64(shares)))
+	return &marketValue, nil
+}
+
+// splitFactorBetween fromより後、to以前に権利落ちした株式分割・併合の調整係数の積（株式数は積の逆数倍になる）
+func splitFactorBetween(splits []splitEvent, from, to time.Time) float64 {
+	factor := 1.0
+	for _, split := range splits {
+		if split.tradeDate.After(from) && !split.tradeDate.After(to) {
+			factor *= split.factor
+		}
+	}
+	return factor
+}
+
+// ratioOf 分子÷分母（分母が0以下またはNULLの場合はNULL）
+func ratioOf(numerator, denominator *int64) *float64 {
+	if numerator == nil || denominator == nil || *denominator <= 0 {
+		return nil
+	}
+	v := float64(*numerator) / float64(*denominator)
+	return &v
+}
+
+// compareGreater a > b の判定（どちらかがNULLの場合はNULL）
+func compareGreater(a, b *float64) *bool {
+	if a == nil || b == nil {
+		return nil
+	}
+	v := *a > *b
+	return &v
+}
+
+// boolPtr 判定結果のポインタを返す
+func boolPtr(v bool) *bool {
+	return &v
+}
+
+// calculateQualityScore 当期と前期の通期実績からF-scoreとZ-scoreを算出（prev・marketValueはnilの場合あり）
+// splits: 株式分割・併合の履歴（前期末の発行済株式数を当期末の株式数ベースに調整する）
+func calculateQualityScore(localCode string, year, prev *annualFundamentals, marketValue *int64, splits []splitEvent) *QualityScore {
+	score := &QualityScore{
+		LocalCode:           localCode,
+		FiscalYearStartDate: year.fiscalYearStart,
+		FiscalYearEndDate:   year.fiscalYearEnd,
+		DisclosedDate:       year.disclosedDate,
+		MarketValueEquity:   marketValue,
+	}
+
+	// F-score（当期のみで判定できる項目）
+	roa := ratioOf(year.profit, year.totalAssets)
+	if roa != nil {
+		score.F.ROAPositive = boolPtr(*roa > 0)
+	}
+	if year.cfOperating != nil {
+		score.F.CFOPositive = boolPtr(*year.cfOperating > 0)
+		if year.profit != nil {
+			score.F.Accrual = boolPtr(*year.cfOperating > *year.profit)
+		}
+	}
+
+	// F-score（前期との比較で判定する項目）
+	if prev != nil {
+		score.F.ROAImproved = compareGreater(roa, ratioOf(prev.profit, prev.totalAssets))
+		score.F.LeverageImproved = compareGreater(ratioOf(year.equity, year.totalAssets), ratioOf(prev.equity, prev.totalAssets))
+		score.F.LiquidityImproved = compareGreater(ratioOf(year.cash, year.totalAssets), ratioOf(prev.cash, prev.totalAssets))
+		if year.issuedShares != nil && prev.issuedShares != nil {
+			// 株式分割・併合による株式数の増減は希薄化としない（調整後の端数は四捨五入）
+			prevShares := float64(*prev.issuedShares)
+			if year.fiscalYearEnd != nil && prev.fiscalYearEnd != nil {
+				prevShares /= splitFactorBetween(splits, *prev.fiscalYearEnd, *year.fiscalYearEnd)
+			}
+			score.F.NoDilution = boolPtr(*year.issuedShares <= int64(math.Round(prevShares)))
+		}
+		score.F.MarginImproved = compareGreater(ratioOf(year.operatingProfit, year.netSales), ratioOf(prev.operatingProfit, prev.netSales))
+		score.F.TurnoverImproved = compareGreater(ratioOf(year.netSales, year.totalAssets), ratioOf(prev.netSales, prev.totalAssets))
+	}
+
+	for _, component := range []*bool{
+		score.F.ROAPositive, score.F.CFOPositive, score.F.ROAImproved,
+		score.F.Accrual, score.F.LeverageImproved, score.F.LiquidityImproved,
+		score.F.NoDilution, score.F.MarginImproved, score.F.TurnoverImproved,
+	} {
+		if component == nil {
+			continue
+		}
+		score.FScoreEvaluated++
+		if *component {
+			score.FScore++
+		}
+	}
+
+	// Z-score（変形版）
+	score.Z.CashToAssets = roundRatio(ratioOf(year.cash, year.totalAssets))
+	score.Z.EquityToAssets = roundRatio(ratioOf(year.equity, year.totalAssets))
+	score.Z.OperatingProfitToAssets = roundRatio(ratioOf(year.operatingProfit, year.totalAssets))
+	score.Z.SalesToAssets = roundRatio(ratioOf(year.netSales, year.totalAssets))
+	if year.totalAssets != nil && year.equity != nil {
+		liabilities := *year.totalAssets - *year.equity
+		score.Z.MarketValueToLiabilities = roundRatio(ratioOf(marketValue, &liabilities))
+	}
+
+	z := score.Z
+	if z.CashToAssets != nil && z.EquityToAssets != nil && z.OperatingProfitToAssets != nil &&
+		z.MarketValueToLiabilities != nil && z.SalesToAssets != nil {
+		v := 1.2**z.CashToAssets + 1.4**z.EquityToAssets + 3.3**z.OperatingProfitToAssets +
+			0.6**z.MarketValueToLiabilities + 1.0**z.SalesToAssets
+		v = math.Round(v*100) / 100
+		score.ZScore = &v
+
+		switch {
+		case v > 2.99:
+			score.ZZone = ZoneSafe
+		case v >= 1.81:
+			score.ZZone = ZoneGrey
+		default:
+			score.ZZone = ZoneDistress
+		}
+	}
+
+	return score
+}
+
+// roundRatio 比率を小数4桁に丸める
+func roundRatio(v *float64) *float64 {
+	if v == nil {
+		return nil
+	}
+	rounded := math.Round(*v*10000) / 10000
+	return &rounded
+}
+
+// insertScore 財務品質スコアデータを挿入
+func (r *QualityScoreRepository) insertScore(tx *sql.Tx, score *QualityScore) error {
+	query := `
+		INSERT INTO quality_scores (
+			local_code, fiscal_year_start_date, fiscal_year_end_date, disclosed_date,
+			f_roa_positive, f_cfo_positive, f_roa_improved, f_accrual, f_leverage_improved,
+			f_liquidity_improved, f_no_dilution, f_margin_improved, f_turnover_improved,
+			f_score, f_score_evaluated,
+			z_cash_to_assets, z_equity_to_assets, z_operating_profit_to_assets,
+			z_market_value_to_liabilities, z_sales_to_assets,
+			market_value_equity, z_score, z_zone
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	var zone interface{}
+	if score.ZZone != "" {
+		zone = score.ZZone
+	}
+
+	_, err := tx.Exec(query,
+		score.LocalCode,
+		score.FiscalYearStartDate,
+		score.FiscalYearEndDate,
+		score.DisclosedDate,
+		score.F.ROAPositive,
+		score.F.CFOPositive,
+		score.F.ROAImproved,
+		score.F.Accrual,
+		score.F.LeverageImproved,
+		score.F.LiquidityImproved,
+		score.F.NoDilution,
+		score.F.MarginImproved,
+		score.F.TurnoverImproved,
+		score.FScore,
+		score.FScoreEvaluated,
+		score.Z.CashToAssets,
+		score.Z.EquityToAssets,
+		score.Z.OperatingProfitToAssets,
+		score.Z.MarketValueToLiabilities,
+		score.Z.SalesToAssets,
+		score.MarketValueEquity,
+		score.ZScore,
+		zone,
+	)
+
+	return err
+}
+
+// GetScoresByCode 銘柄の財務品質スコアを会計年度の新しい順に取得
+func (r *QualityScoreRepository) GetScoresByCode(localCode string, limit int) ([]*QualityScore, error) {
+	query := `
+		SELECT
+			local_code, fiscal_year_start_date, fiscal_year_end_date, disclosed_date,
+			f_roa_positive, f_cfo_positive, f_roa_improved, f_accrual, f_leverage_improved,
+			f_liquidity_improved, f_no_dilution, f_margin_improved, f_turnover_improved,
+			f_score, f_score_evaluated,
+			z_cash_to_assets, z_equity_to_assets, z_operating_profit_to_assets,
+			z_market_value_to_liabilities, z_sales_to_assets,
+			market_value_equity, z_score, COALESCE(z_zone, '')
+		FROM quality_scores
+		WHERE local_code = ?
+		ORDER BY fiscal_year_start_date DESC
+	`
+	if limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", limit)
+	}
+
+	rows, err := r.conn.GetDB().Query(query, localCode)
+	if err != nil {
+		return nil, fmt.Errorf("財務品質スコアデータ取得エラー: %v", err)
+	}
+	defer rows.Close()
+
+	var scores []*QualityScore
+	for rows.Next() {
+		score := &QualityScore{}
+		err := rows.Scan(
+			&score.LocalCode,
+			&score.FiscalYearStartDate,
+			&score.FiscalYearEndDate,
+			&score.DisclosedDate,
+			&score.F.ROAPositive,
+			&score.F.CFOPositive,
+			&score.F.ROAImproved,
+			&score.F.Accrual,
+			&score.F.LeverageImproved,
+			&score.F.LiquidityImproved,
+			&score.F.NoDilution,
+			&score.F.MarginImproved,
+			&score.F.TurnoverImproved,
+			&score.FScore,
+			&score.FScoreEvaluated,
+			&score.Z.CashToAssets,
+			&score.Z.EquityToAssets,
+			&score.Z.OperatingProfitToAssets,
+			&score.Z.MarketValueToLiabilities,
+			&score.Z.SalesToAssets,
+			&score.MarketValueEquity,
+			&score.ZScore,
+			&score.ZZone,
+		)
+		if err != nil {
+			log.Printf("財務品質スコアデータスキャンエラー: %v", err)
+			continue
+		}
+		scores = append(scores, score)
+	}
+
+	return scores, nil
+}
@@ -0,0 +1,202 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"time"
+)
+
+// 権利確定の種別
+const (
+	RightsInterim = "interim"
+	RightsYearEnd = "year_end"
+)
+
+// RightsEntry 権利確定月の配当銘柄
+type RightsEntry struct {
+	Code              string
+	CompanyName       string
+	RightsType        string // RightsInterim または RightsYearEnd
+	RecordDate        time.Time
+	FiscalYearEndDate *time.Time
+	DisclosedDate     time.Time // 予想の開示日
+	ForecastDPS       float64   // 当該権利確定日の予想配当
+	AnnualForecastDPS *float64
+	TradeDate         *time.Time // 株価の取引日
+	AdjustmentClose   *float64
+	DividendYield     *float64 // 予想年間配当/調整後終値(%)
+}
+
+// RightsCalendarRepository 権利確定カレンダーのリポジトリ
+type RightsCalendarRepository struct {
+	conn *Connection
+}
+
+// NewRightsCalendarRepository 新しいリポジトリを作成
+func NewRightsCalendarRepository(conn *Connection) *RightsCalendarRepository {
+	return &RightsCalendarRepository{conn: conn}
+}
+
+// GetRightsEntries 指定年月の月末に中間・期末配当の権利が確定する上場中の銘柄を、予想配当利回りの高い順に取得
+// 権利確定日は期末日（期末配当）と期首から6か月後の月末（中間配当）とみなす
+// rightsType: RightsInterim, RightsYearEnd（空の場合は両方）
+func (r *RightsCalendarRepository) GetRightsEntries(year int, month time.Month, rightsType string) ([]*RightsEntry, error) {
+	recordDate := time.Date(year, month+1, 0, 0, 0, 0, 0, time.Local)
+
+	var entries []*RightsEntry
+	if rightsType == "" || rightsType == RightsYearEnd {
+		// 期末配当: 期末日が権利確定日の会計年度
+		yearEnd, err := r.getForecastDividends(RightsYearEnd, recordDate, `
+			SELECT local_code, disclosed_date, disclosed_time, current_fiscal_year_end_date,
+				fc_dps_fy, fc_dps_annual
+			FROM statements
+			WHERE current_fiscal_year_end_date = ? AND fc_dps_fy IS NOT NULL
+			UNION ALL
+			SELECT local_code, disclosed_date, disclosed_time, next_fiscal_year_end_date,
+				ny_fc_dps_fy, `+nextYearDividendAnnualExpr+`
+			FROM statements
+			WHERE next_fiscal_year_end_date = ? AND ny_fc_dps_fy IS NOT NULL
+		`, recordDate, recordDate)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, yearEnd...)
+	}
+	if rightsType == "" || rightsType == RightsInterim {
+		// 中間配当: 期首から6か月後の月末が権利確定日の会計年度
+		fiscalYearStart := time.Date(year, month-5, 1, 0, 0, 0, 0, time.Local)
+		interim, err := r.getForecastDividends(RightsInterim, recordDate, `
+			SELECT local_code, disclosed_date, disclosed_time, current_fiscal_year_end_date,
+				fc_dps_2q, fc_dps_annual
+			FROM statements
+			WHERE current_fiscal_year_start_date = ? AND fc_dps_2q IS NOT NULL
+			UNION ALL
+			SELECT local_code, disclosed_date, disclosed_time, next_fiscal_year_end_date,
+				ny_fc_dps_2q, `+nextYearDividendAnnualExpr+`
+			FROM statements
+			WHERE next_fiscal_year_start_date = ? AND ny_fc_dps_2q IS NOT NULL
+		`, fiscalYearStart, fiscalYearStart)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, interim...)
+	}
+
+	listed, err := r.attachPrices(entries)
+	if err != nil {
+		return nil, err
+	}
+
+	// 予想配当利回りの高い順に並べる（利回りを算出できない銘柄は末尾）
+	sort.SliceStable(listed, func(i, j int) bool {
+		a, b := listed[i].DividendYield, listed[j].DividendYield
+		if a == nil || b == nil {
+			return a != nil
+		}
+		return *a > *b
+	})
+
+	return listed, nil
+}
+
+// getForecastDividends 銘柄ごとに最新の開示の予想配当を取得（予想配当が0の銘柄は除く）
+func (r *RightsCalendarRepository) getForecastDividends(rightsType string, recordDate time.Time, union string, args ...interface{}) ([]*RightsEntry, error) {
+	query := "SELECT * FROM (" + union + ") forecasts ORDER BY local_code, disclosed_date, disclosed_time"
+
+	rows, err := r.conn.GetDB().Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("予想配当データ取得エラー: %v", err)
+	}
+	defer rows.Close()
+
+	var entries []*RightsEntry
+	for rows.Next() {
+		entry := &RightsEntry{RightsType: rightsType, RecordDate: recordDate}
+		var disclosedTime sql.NullString
+		err := rows.Scan(
+			&entry.Code,
+			&entry.DisclosedDate,
+			&disclosedTime,
+			&entry.FiscalYearEndDate,
+			&entry.ForecastDPS,
+			&entry.AnnualForecastDPS,
+		)
+		if err != nil {
+			log.Printf("予想配当データスキャンエラー: %v", err)
+			continue
+		}
+
+		// 同一銘柄は後の開示で置き換える
+		if n := len(entries); n > 0 && entries[n-1].Code == entry.Code {
+			entries[n-1] = entry
+			continue
+		}
+		entries = append(entries, entry)
+	}
+
+	var paying []*RightsEntry
+	for _, entry := range entries {
+		if entry.ForecastDPS > 0 {
+			paying = append(paying, entry)
+		}
+	}
+
+	return paying, nil
+}
+
+// attachPrices 上場中の銘柄に絞り込み、企業名とdaily_quotesの最新取引日の調整後終値・予想配当利回りを設定
+func (r *RightsCalendarRepository) attachPrices(entries []*RightsEntry) ([]*RightsEntry, error) {
+	if len(entries) == 0 {
+		return nil, nil
+	}
+
+	rows, err := r.conn.GetDB().Query(`
+		SELECT li.code, li.company_name, dq.trade_date, dq.adjustment_close
+		FROM listed_info li
+		LEFT JOIN daily_quotes dq
+			ON dq.code = li.code
+			AND dq.trade_date = (SELECT MAX(trade_date) FROM daily_quotes)
+		WHERE li.delisted_date IS NULL
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("株価データ取得エラー: %v", err)
+	}
+	defer rows.Close()
+
+	type listedPrice struct {
+		companyName string
+		tradeDate   *time.Time
+		close       *float64
+	}
+	prices := make(map[string]*listedPrice)
+	for rows.Next() {
+		var code string
+		price := &listedPrice{}
+		if err := rows.Scan(&code, &price.companyName, &price.tradeDate, &price.close); err != nil {
+			log.Printf("株価データスキャンエラー: %v", err)
+			continue
+		}
+		prices[code] = price
+	}
+
+	var listed []*RightsEntry
+	for _, entry := range entries {
+		price, ok := prices[entry.Code]
+		if !ok {
+			continue
+		}
+		entry.CompanyName = price.companyName
+		entry.TradeDate = price.tradeDate
+		entry.AdjustmentClose = price.close
+		if entry.AnnualForecastDPS != nil && price.close != nil && *price.close > 0 {
+			dividendYield := math.Round(*entry.AnnualForecastDPS / *price.close * 10000) / 100
+			entry.DividendYield = &dividendYield
+		}
+		listed = append(listed, entry)
+	}
+
+	return listed, nil
+}
@@ -0,0 +1,296 @@
+package database
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ScreenCriteria スクリーニング条件（nilの条件は適用しない）
+type ScreenCriteria struct {
+	MinFScore         *int
+	MinZScore         *float64
+	MaxPER            *float64 // 予想PER
+	MaxPBR            *float64
+	MinDividendYield  *float64 // 予想配当利回り(%)
+	MinMarketCap      *int64
+	MinIncreaseStreak *int                 // 連続増配年数（最新の実績年度）
+	Indicators        []IndicatorCondition // テクニカル指標の条件（最新取引日の値で判定）
+	Universe          UniverseFilter       // 市場区分・規模区分・業種（現在の上場銘柄情報で判定）・ウォッチリスト・タグの条件
+	SortBy            string               // ScreenSortKeysのキー（空の場合はコード順）
+	Limit             int
+}
+
+// IndicatorNames テクニカル指標の条件に使われる指標名（"close"を含む）を重複なく出現順に取得
+func (c ScreenCriteria) IndicatorNames() []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, condition := range c.Indicators {
+		for _, name := range []string{condition.Left, condition.Right} {
+			if name != "" && !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+// IndicatorCondition テクニカル指標の条件（例: rsi_14 < 30, close > sma_200）
+type IndicatorCondition struct {
+	Left     string   // 指標名または"close"（調整後終値）
+	Operator string   // <, <=, >, >=
+	Right    string   // 比較対象の指標名または"close"（Valueを指定した場合は空）
+	Value    *float64 // 比較対象の数値
+}
+
+// indicatorOperandClose 条件で調整後終値を表すオペランド
+const indicatorOperandClose = "close"
+
+var (
+	indicatorConditionPattern = regexp.MustCompile(`^\s*([a-z0-9_.]+)\s*(<=|>=|<|>)\s*([a-z0-9_.\-]+)\s*$`)
+	indicatorNamePattern      = regexp.MustCompile(`^[a-z][a-z0-9_.]*$`)
+)
+
+// ParseIndicatorCondition "rsi_14<30"や"close>sma_200"の形式のテクニカル指標の条件を解析
+func ParseIndicatorCondition(s string) (IndicatorCondition, error) {
+	m := indicatorConditionPattern.FindStringSubmatch(s)
+	if m == nil || !indicatorNamePattern.MatchString(m[1]) {
+		return IndicatorCondition{}, fmt.Errorf("テクニカル指標の条件の形式が正しくありません（例: rsi_14<30, close>sma_200）: '%s'", s)
+	}
+
+	condition := IndicatorCondition{Left: m[1], Operator: m[2]}
+	if v, err := strconv.ParseFloat(m[3], 64); err == nil {
+		condition.Value = &v
+	} else if indicatorNamePattern.MatchString(m[3]) {
+		condition.Right = m[3]
+	} else {
+		return IndicatorCondition{}, fmt.Errorf("テクニカル指標の条件の比較対象が正しくありません: '%s'", m[3])
+	}
+	return condition, nil
+}
+
+// String 条件を"rsi_14<30"の形式で表す
+func (c IndicatorCondition) String() string {
+	if c.Value != nil {
+		return c.Left + c.Operator + strconv.FormatFloat(*c.Value, 'f', -1, 64)
+	}
+	return c.Left + c.Operator + c.Right
+}
+
+// ScreenSortKeys 並べ替えキーとORDER BY句の対応
+var ScreenSortKeys = map[string]string{
+	"code":       "li.code",
+	"fscore":     "qs.f_score DESC, li.code",
+	"zscore":     "qs.z_score DESC, li.code",
+	"per":        "v.forecast_per IS NULL, v.forecast_per, li.code",
+	"pbr":        "v.pbr IS NULL, v.pbr, li.code",
+	"yield":      "v.forecast_dividend_yield DESC, li.code",
+	"market_cap": "v.market_cap DESC, li.code",
+	"streak":     "dh.increase_streak DESC, li.code",
+}
+
+// ScreenResult スクリーニング結果の1銘柄
+type ScreenResult struct {
+	Code              string
+	CompanyName       string
+	TradeDate         *time.Time
+	Close             *float64
+	MarketCap         *int64
+	ForecastPER       *float64
+	PBR               *float64
+	DividendYield     *float64
+	FiscalYearEndDate *time.Time // スコアの対象年度末
+	FScore            *int
+	FScoreEvaluated   *int
+	ZScore            *float64
+	ZZone             string
+	IncreaseStreak    *int
+	Indicators        map[string]*float64 // 条件に使ったテクニカル指標の値（"close"は調整後終値）
+}
+
+// ScreenerRepository スクリーニングのリポジトリ
+type ScreenerRepository struct {
+	conn *Connection
+}
+
+// NewScreenerRepository 新しいリポジトリを作成
+func NewScreenerRepository(conn *Connection) *ScreenerRepository {
+	return &ScreenerRepository{conn: conn}
+}
+
+// Screen 上場中の銘柄を、最新取引日の株価指標・テクニカル指標、最新年度の財務品質スコア・連続増配年数で絞り込む
+func (r *ScreenerRepository) Screen(criteria ScreenCriteria) ([]*ScreenResult, error) {
+	indicatorNames, indicatorJoins, joinArgs := buildIndicatorJoins(criteria)
+
+	indicatorColumns := ""
+	for _, name := range indicatorNames {
+		indicatorColumns += ", " + indicatorColumn(name, indicatorNames)
+	}
+
+	query := `
+		SELECT
+			li.code, li.company_name,
+			v.trade_date, v.close, v.market_cap, v.forecast_per, v.pbr, v.forecast_dividend_yield,
+			qs.fiscal_year_end_date, qs.f_score, qs.f_score_evaluated, qs.z_score, COALESCE(qs.z_zone, ''),
+			dh.increase_streak` + indicatorColumns + `
+		FROM listed_info li
+		LEFT JOIN valuations v
+			ON v.code = li.code
+			AND v.trade_date = (SELECT MAX(trade_date) FROM valuations)
+		LEFT JOIN quality_scores qs
+			ON qs.local_code = li.code
+			AND qs.fiscal_year_start_date = (
+				SELECT MAX(fiscal_year_start_date) FROM quality_scores WHERE local_code = li.code
+			)
+		LEFT JOIN dividend_history dh
+			ON dh.local_code = li.code
+			AND dh.fiscal_year_start_date = (
+				SELECT MAX(fiscal_year_start_date) FROM dividend_history WHERE local_code = li.code AND is_forecast = FALSE
+			)
+	` + indicatorJoins
+
+	conditions := []string{"li.delisted_date IS NULL"}
+	args := joinArgs
+
+	universeConditions, universeArgs := criteria.Universe.conditions("li")
+	conditions = append(conditions, universeConditions...)
+	args = append(args, universeArgs...)
+
+	if criteria.MinFScore != nil {
+		conditions = append(conditions, "qs.f_score >= ?")
+		args = append(args, *criteria.MinFScore)
+	}
+	if criteria.MinZScore != nil {
+		conditions = append(conditions, "qs.z_score >= ?")
+		args = append(args, *criteria.MinZScore)
+	}
+	if criteria.MaxPER != nil {
+		conditions = append(conditions, "v.forecast_per <= ?")
+		args = append(args, *criteria.MaxPER)
+	}
+	if criteria.MaxPBR != nil {
+		conditions = append(conditions, "v.pbr <= ?")
+		args = append(args, *criteria.MaxPBR)
+	}
+	if criteria.MinDividendYield != nil {
+		conditions = append(conditions, "v.forecast_dividend_yield >= ?")
+		args = append(args, *criteria.MinDividendYield)
+	}
+	if criteria.MinMarketCap != nil {
+		conditions = append(conditions, "v.market_cap >= ?")
+		args = append(args, *criteria.MinMarketCap)
+	}
+	if criteria.MinIncreaseStreak != nil {
+		conditions = append(conditions, "dh.increase_streak >= ?")
+		args = append(args, *criteria.MinIncreaseStreak)
+	}
+	for _, condition := range criteria.Indicators {
+		right := "?"
+		if condition.Value != nil {
+			args = append(args, *condition.Value)
+		} else {
+			right = indicatorColumn(condition.Right, indicatorNames)
+		}
+		conditions = append(conditions, fmt.Sprintf("%s %s %s", indicatorColumn(condition.Left, indicatorNames), condition.Operator, right))
+	}
+
+	query += " WHERE " + strings.Join(conditions, " AND ")
+
+	orderBy, ok := ScreenSortKeys[criteria.SortBy]
+	if !ok {
+		if criteria.SortBy != "" {
+			return nil, fmt.Errorf("サポートされていない並べ替えキーです: '%s'", criteria.SortBy)
+		}
+		orderBy = ScreenSortKeys["code"]
+	}
+	query += " ORDER BY " + orderBy
+
+	if criteria.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", criteria.Limit)
+	}
+
+	rows, err := r.conn.GetDB().Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("スクリーニングエラー: %v", err)
+	}
+	defer rows.Close()
+
+	var results []*ScreenResult
+	for rows.Next() {
+		result := &ScreenResult{Indicators: make(map[string]*float64, len(indicatorNames))}
+		indicatorValues := make([]*float64, len(indicatorNames))
+		dest := []interface{}{
+			&result.Code,
+			&result.CompanyName,
+			&result.TradeDate,
+			&result.Close,
+			&result.MarketCap,
+			&result.ForecastPER,
+			&result.PBR,
+			&result.DividendYield,
+			&result.FiscalYearEndDate,
+			&result.FScore,
+			&result.FScoreEvaluated,
+			&result.ZScore,
+			&result.ZZone,
+			&result.IncreaseStreak,
+		}
+		for i := range indicatorValues {
+			dest = append(dest, &indicatorValues[i])
+		}
+		if err := rows.Scan(dest...); err != nil {
+			log.Printf("スクリーニング結果スキャンエラー: %v", err)
+			continue
+		}
+		for i, name := range indicatorNames {
+			result.Indicators[name] = indicatorValues[i]
+		}
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// buildIndicatorJoins 条件に使うテクニカル指標ごとに最新取引日の値を結合するJOIN句を作成
+// 調整後終値（"close"）はテクニカル指標の最新取引日のdaily_quotesから取得する
+func buildIndicatorJoins(criteria ScreenCriteria) ([]string, string, []interface{}) {
+	names := criteria.IndicatorNames()
+
+	joins := ""
+	var args []interface{}
+	for i, name := range names {
+		if name == indicatorOperandClose {
+			joins += `
+			LEFT JOIN daily_quotes idq
+				ON idq.code = li.code
+				AND idq.trade_date = (SELECT MAX(trade_date) FROM indicators)`
+			continue
+		}
+		alias := fmt.Sprintf("ind%d", i)
+		joins += fmt.Sprintf(`
+			LEFT JOIN indicators %[1]s
+				ON %[1]s.code = li.code
+				AND %[1]s.name = ?
+				AND %[1]s.trade_date = (SELECT MAX(trade_date) FROM indicators)`, alias)
+		args = append(args, name)
+	}
+
+	return names, joins, args
+}
+
+// indicatorColumn 条件に使うテクニカル指標の値を参照する列
+func indicatorColumn(name string, names []string) string {
+	if name == indicatorOperandClose {
+		return "idq.adjustment_close"
+	}
+	for i, n := range names {
+		if n == name {
+			return fmt.Sprintf("ind%d.value", i)
+		}
+	}
+	return "NULL"
+}
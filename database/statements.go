@@ -85,6 +85,65 @@ func (r *StatementsRepository) SaveFinancialStatements(statements []schema.Finan
 	return nil
 }
 
+// FilterNewStatements まだ保存していない開示のみを返す（開示番号が保存済みの開示は除く。開示番号がない場合は開示日・銘柄コード・会計期間種別で判定）
+// 保存前に呼び出し、訂正開示など既存の行を上書きする開示も開示番号が異なれば新しい開示とする
+func (r *StatementsRepository) FilterNewStatements(statements []schema.FinancialStatement) ([]schema.FinancialStatement, error) {
+	existing := make(map[string]bool)
+
+	const batchSize = 500
+	for i := 0; i < len(statements); i += batchSize {
+		end := i + batchSize
+		if end > len(statements) {
+			end = len(statements)
+		}
+
+		var conditions []string
+		var args []interface{}
+		for _, stmt := range statements[i:end] {
+			if stmt.DisclosureNumber != "" {
+				conditions = append(conditions, "disclosure_number = ?")
+				args = append(args, stmt.DisclosureNumber)
+			} else {
+				conditions = append(conditions, "(disclosed_date = ? AND local_code = ? AND type_of_current_period = ?)")
+				args = append(args, stmt.DisclosedDate, stmt.LocalCode, stmt.TypeOfCurrentPeriod)
+			}
+		}
+
+		rows, err := r.conn.GetDB().Query(`
+			SELECT COALESCE(disclosure_number, ''), DATE_FORMAT(disclosed_date, '%Y-%m-%d'), local_code, type_of_current_period
+			FROM statements
+			WHERE `+strings.Join(conditions, " OR "), args...)
+		if err != nil {
+			return nil, fmt.Errorf("保存済み開示取得エラー: %v", err)
+		}
+		for rows.Next() {
+			var number, date, code, period string
+			if err := rows.Scan(&number, &date, &code, &period); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("保存済み開示スキャンエラー: %v", err)
+			}
+			if number != "" {
+				existing[number] = true
+			}
+			existing[date+"/"+code+"/"+period] = true
+		}
+		rows.Close()
+	}
+
+	var newStatements []schema.FinancialStatement
+	for _, stmt := range statements {
+		key := stmt.DisclosureNumber
+		if key == "" {
+			key = stmt.DisclosedDate + "/" + stmt.LocalCode + "/" + stmt.TypeOfCurrentPeriod
+		}
+		if !existing[key] {
+			newStatements = append(newStatements, stmt)
+		}
+	}
+
+	return newStatements, nil
+}
+
 // GetFinancialStatements 条件に基づいて財務情報を取得
 func (r *StatementsRepository) GetFinancialStatements(localCode, disclosedDate, typeOfCurrentPeriod string) ([]schema.FinancialStatement, error) {
 	var statements []schema.FinancialStatement
@@ -0,0 +1,311 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+)
+
+// PointInTimeStatement 指定時点で開示済みだった財務情報（実績または予想）
+type PointInTimeStatement struct {
+	LocalCode           string
+	DisclosedDate       time.Time
+	DisclosedTime       string // HH:MM:SS（不明な場合は空文字列）
+	TypeOfDocument      string
+	TypeOfCurrentPeriod string
+	FiscalYearStartDate *time.Time
+	FiscalYearEndDate   *time.Time
+	NetSales            *int64
+	OperatingProfit     *int64
+	OrdinaryProfit      *int64
+	Profit              *int64
+	EPS                 *float64
+	DividendPerShare    *float64
+	// 以下は実績のみ
+	TotalAssets        *int64
+	Equity             *int64
+	EquityToAssetRatio *float64
+	BVPS               *float64
+	IssuedShares       *int64 // 期末発行済株式数（自己株式を含む）
+	TreasuryShares     *int64 // 期末自己株式数
+	IsForecast         bool
+	DataType           string
+}
+
+// FundamentalsAsOf 指定時点で開示済みだった最新の実績・予想
+type FundamentalsAsOf struct {
+	LocalCode    string
+	AsOf         time.Time
+	LatestActual *PointInTimeStatement // 最新の実績（四半期累計を含む）
+	AnnualActual *PointInTimeStatement // 最新の通期実績
+	Forecast     *PointInTimeStatement // 最新の通期予想（当期または翌期）
+}
+
+// asOfCondition 開示日時が基準日時以前であることを表す条件（disclosed_timeがNULLの場合は0時扱い）
+const asOfCondition = `
+	(disclosed_date < ? OR (disclosed_date = ? AND COALESCE(disclosed_time, '00:00:00') <= ?))
+`
+
+// nextYearDividendAnnualExpr 翌期予想の年間配当（翌期予想は年間配当の列がないため各期の予想配当を合計）
+const nextYearDividendAnnualExpr = `
+	CASE
+		WHEN ny_fc_dps_1q IS NULL AND ny_fc_dps_2q IS NULL AND ny_fc_dps_3q IS NULL AND ny_fc_dps_fy IS NULL THEN NULL
+		ELSE COALESCE(ny_fc_dps_1q, 0) + COALESCE(ny_fc_dps_2q, 0) + COALESCE(ny_fc_dps_3q, 0) + COALESCE(ny_fc_dps_fy, 0)
+	END
+`
+
+// asOfArgs asOfConditionのプレースホルダー引数
+func asOfArgs(asOf time.Time) []interface{} {
+	date := asOf.Format("2006-01-02")
+	return []interface{}{date, date, asOf.Format("15:04:05")}
+}
+
+// GetFundamentalsAsOf 指定時点で開示済みだった最新の実績・予想を取得
+func (r *StatementsRepository) GetFundamentalsAsOf(localCode string, asOf time.Time) (*FundamentalsAsOf, error) {
+	latestActual, err := r.GetActualAsOf(localCode, asOf, false)
+	if err != nil {
+		return nil, err
+	}
+
+	annualActual, err := r.GetActualAsOf(localCode, asOf, true)
+	if err != nil {
+		return nil, err
+	}
+
+	forecast, err := r.GetForecastAsOf(localCode, asOf)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FundamentalsAsOf{
+		LocalCode:    localCode,
+		AsOf:         asOf,
+		LatestActual: latestActual,
+		AnnualActual: annualActual,
+		Forecast:     forecast,
+	}, nil
+}
+
+// actualSelect 実績の取得クエリ（local_codeのプレースホルダーを1つ含む）
+const actualSelect = `
+	SELECT
+		local_code,
+		disclosed_date,
+		disclosed_time,
+		type_of_document,
+		type_of_current_period,
+		current_fiscal_year_start_date,
+		current_fiscal_year_end_date,
+		net_sales,
+		operating_profit,
+		ordinary_profit,
+		profit,
+		eps,
+		result_dps_annual,
+		total_assets,
+		equity,
+		equity_to_asset_ratio,
+		bvps,
+		issued_shares_end_fy_incl_treasury,
+		treasury_shares_end_fy
+	FROM statements
+	WHERE local_code = ?
+		AND (net_sales IS NOT NULL OR operating_profit IS NOT NULL OR profit IS NOT NULL)
+`
+
+// forecastSelect 当期予想と翌期予想を同じ列構成で取得するクエリ（whereは各SELECTに付加する条件、local_codeのプレースホルダーを2つ含む）
+func forecastSelect(where string) string {
+	return `
+		SELECT * FROM (
+			SELECT
+				local_code,
+				disclosed_date,
+				disclosed_time,
+				type_of_document,
+				type_of_current_period,
+				current_fiscal_year_start_date AS fiscal_year_start_date,
+				current_fiscal_year_end_date AS fiscal_year_end_date,
+				fc_net_sales AS net_sales,
+				fc_operating_profit AS operating_profit,
+				fc_ordinary_profit AS ordinary_profit,
+				fc_profit AS profit,
+				fc_eps AS eps,
+				fc_dps_annual AS dividend_per_share,
+				'current_forecast' AS data_type
+			FROM statements
+			WHERE local_code = ?
+				AND (fc_net_sales IS NOT NULL OR fc_operating_profit IS NOT NULL OR fc_profit IS NOT NULL)
+				` + where + `
+			UNION ALL
+			SELECT
+				local_code,
+				disclosed_date,
+				disclosed_time,
+				type_of_document,
+				type_of_current_period,
+				next_fiscal_year_start_date AS fiscal_year_start_date,
+				next_fiscal_year_end_date AS fiscal_year_end_date,
+				ny_fc_net_sales AS net_sales,
+				ny_fc_operating_profit AS operating_profit,
+				ny_fc_ordinary_profit AS ordinary_profit,
+				ny_fc_profit AS profit,
+				ny_fc_eps AS eps,
+				` + nextYearDividendAnnualExpr + ` AS dividend_per_share,
+				'next_year_forecast' AS data_type
+			FROM statements
+			WHERE local_code = ?
+				AND next_fiscal_year_start_date IS NOT NULL
+				AND (ny_fc_net_sales IS NOT NULL OR ny_fc_operating_profit IS NOT NULL OR ny_fc_profit IS NOT NULL)
+				` + where + `
+		) forecasts
+	`
+}
+
+// rowScanner *sql.Rowと*sql.Rowsに共通のScanインターフェース
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanActual actualSelectの1行を読み込む
+func scanActual(row rowScanner) (*PointInTimeStatement, error) {
+	stmt := &PointInTimeStatement{DataType: "current_actual"}
+	var disclosedTime, typeOfDocument sql.NullString
+	err := row.Scan(
+		&stmt.LocalCode,
+		&stmt.DisclosedDate,
+		&disclosedTime,
+		&typeOfDocument,
+		&stmt.TypeOfCurrentPeriod,
+		&stmt.FiscalYearStartDate,
+		&stmt.FiscalYearEndDate,
+		&stmt.NetSales,
+		&stmt.OperatingProfit,
+		&stmt.OrdinaryProfit,
+		&stmt.Profit,
+		&stmt.EPS,
+		&stmt.DividendPerShare,
+		&stmt.TotalAssets,
+		&stmt.Equity,
+		&stmt.EquityToAssetRatio,
+		&stmt.BVPS,
+		&stmt.IssuedShares,
+		&stmt.TreasuryShares,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	stmt.DisclosedTime = disclosedTime.String
+	stmt.TypeOfDocument = typeOfDocument.String
+	return stmt, nil
+}
+
+// scanForecast forecastSelectの1行を読み込む
+func scanForecast(row rowScanner) (*PointInTimeStatement, error) {
+	stmt := &PointInTimeStatement{IsForecast: true}
+	var disclosedTime, typeOfDocument sql.NullString
+	err := row.Scan(
+		&stmt.LocalCode,
+		&stmt.DisclosedDate,
+		&disclosedTime,
+		&typeOfDocument,
+		&stmt.TypeOfCurrentPeriod,
+		&stmt.FiscalYearStartDate,
+		&stmt.FiscalYearEndDate,
+		&stmt.NetSales,
+		&stmt.OperatingProfit,
+		&stmt.OrdinaryProfit,
+		&stmt.Profit,
+		&stmt.EPS,
+		&stmt.DividendPerShare,
+		&stmt.DataType,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	stmt.DisclosedTime = disclosedTime.String
+	stmt.TypeOfDocument = typeOfDocument.String
+	return stmt, nil
+}
+
+// GetActualAsOf 指定時点で開示済みだった最新の実績を取得（該当がない場合はnil）
+// annualOnly: trueの場合は通期（FY）の実績のみを対象とする
+func (r *StatementsRepository) GetActualAsOf(localCode string, asOf time.Time, annualOnly bool) (*PointInTimeStatement, error) {
+	query := actualSelect + " AND " + asOfCondition
+
+	args := append([]interface{}{localCode}, asOfArgs(asOf)...)
+	if annualOnly {
+		query += " AND type_of_current_period = 'FY'"
+	}
+	query += " ORDER BY disclosed_date DESC, disclosed_time DESC LIMIT 1"
+
+	stmt, err := scanActual(r.conn.GetDB().QueryRow(query, args...))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("実績データ取得エラー: %v", err)
+	}
+
+	return stmt, nil
+}
+
+// GetForecastAsOf 指定時点で開示済みだった最新の通期予想を取得（該当がない場合はnil）
+// 通期決算の開示では当期予想が空になり翌期予想が記載されるため、両者のうち最新の開示を採用する
+func (r *StatementsRepository) GetForecastAsOf(localCode string, asOf time.Time) (*PointInTimeStatement, error) {
+	// 開示日時の新しい順・会計年度の新しい順に並べる
+	query := forecastSelect("AND "+asOfCondition) + `
+		ORDER BY disclosed_date DESC, disclosed_time DESC, fiscal_year_start_date DESC
+		LIMIT 1
+	`
+
+	args := append([]interface{}{localCode}, asOfArgs(asOf)...)
+	args = append(args, localCode)
+	args = append(args, asOfArgs(asOf)...)
+
+	stmt, err := scanForecast(r.conn.GetDB().QueryRow(query, args...))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("予想データ取得エラー: %v", err)
+	}
+
+	return stmt, nil
+}
+
+// getStatementHistory 実績・予想の全開示を開示日時の古い順に取得（予想は同一開示内で会計年度の古い順）
+func getStatementHistory(q sqlQueryer, localCode string) (actuals, forecasts []*PointInTimeStatement, err error) {
+	rows, err := q.Query(actualSelect+" ORDER BY disclosed_date, disclosed_time", localCode)
+	if err != nil {
+		return nil, nil, fmt.Errorf("実績データ取得エラー: %v", err)
+	}
+	for rows.Next() {
+		stmt, err := scanActual(rows)
+		if err != nil {
+			log.Printf("実績データスキャンエラー: %v", err)
+			continue
+		}
+		actuals = append(actuals, stmt)
+	}
+	rows.Close()
+
+	query := forecastSelect("") + " ORDER BY disclosed_date, disclosed_time, fiscal_year_start_date"
+	rows, err = q.Query(query, localCode, localCode)
+	if err != nil {
+		return nil, nil, fmt.Errorf("予想データ取得エラー: %v", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		stmt, err := scanForecast(rows)
+		if err != nil {
+			log.Printf("予想データスキャンエラー: %v", err)
+			continue
+		}
+		forecasts = append(forecasts, stmt)
+	}
+
+	return actuals, forecasts, nil
+}
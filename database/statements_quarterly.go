@@ -0,0 +1,422 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"time"
+)
+
+// QuarterlyFigures 売上高・利益系の数値
+type QuarterlyFigures struct {
+	NetSales        *int64
+	OperatingProfit *int64
+	OrdinaryProfit  *int64
+	Profit          *int64
+}
+
+// QuarterlyGrowth 売上高・利益系の増減率（%）
+type QuarterlyGrowth struct {
+	NetSales        *float64
+	OperatingProfit *float64
+	OrdinaryProfit  *float64
+	Profit          *float64
+}
+
+// StatementsQuarterly 四半期単独財務の構造体
+type StatementsQuarterly struct {
+	LocalCode           string
+	FiscalYearStartDate time.Time
+	FiscalYearEndDate   time.Time
+	FiscalQuarter       int // 1〜4（4は通期決算）
+	PeriodEndDate       *time.Time
+	DisclosedDate       time.Time
+	Cumulative          QuarterlyFigures // 期首からの累計値
+	Standalone          QuarterlyFigures // 四半期単独値
+	TTM                 QuarterlyFigures // 直近4四半期合計
+	YoY                 QuarterlyGrowth  // 四半期単独値の前年同期比
+}
+
+// StatementsQuarterlyRepository 四半期単独財務のリポジトリ
+type StatementsQuarterlyRepository struct {
+	conn *Connection
+}
+
+// NewStatementsQuarterlyRepository 新しいリポジトリを作成
+func NewStatementsQuarterlyRepository(conn *Connection) *StatementsQuarterlyRepository {
+	return &StatementsQuarterlyRepository{conn: conn}
+}
+
+// periodQuarters 会計期間種別と四半期の対応
+var periodQuarters = map[string]int{
+	"1Q": 1,
+	"2Q": 2,
+	"3Q": 3,
+	"FY": 4,
+}
+
+// quarterKey 会計年度と四半期のキー
+type quarterKey struct {
+	fiscalYearStart string
+	quarter         int
+}
+
+// BuildQuarterly 財務データから四半期単独値を算出して保存
+// localCodes: 対象銘柄コード（空の場合は全銘柄）
+func (r *StatementsQuarterlyRepository) BuildQuarterly(localCodes []string) error {
+	tx, cleanup := BeginTransaction(r.conn.GetDB())
+	defer cleanup()
+
+	if len(localCodes) == 0 {
+		codes, err := selectDistinctLocalCodes(tx)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		localCodes = codes
+	}
+
+	processedCount := 0
+	for _, code := range localCodes {
+		if err := r.processLocalCode(tx, code); err != nil {
+			log.Printf("銘柄 %s の四半期単独値算出でエラー: %v", code, err)
+			continue
+		}
+		processedCount++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("トランザクションコミットエラー: %v", err)
+	}
+
+	log.Printf("四半期単独財務の作成完了: %d銘柄処理", processedCount)
+	return nil
+}
+
+// selectDistinctLocalCodes 財務データが存在する銘柄コードを取得
+func selectDistinctLocalCodes(tx *sql.Tx) ([]string, error) {
+	rows, err := tx.Query("SELECT DISTINCT local_code FROM statements ORDER BY local_code")
+	if err != nil {
+		return nil, fmt.Errorf("銘柄コード取得エラー: %v", err)
+	}
+	defer rows.Close()
+
+	var codes []string
+	for rows.Next() {
+		var code string
+		if err := rows.Scan(&code); err != nil {
+			log.Printf("銘柄コードスキャンエラー: %v", err)
+			continue
+		}
+		codes = append(codes, code)
+	}
+
+	return codes, nil
+}
+
+// processLocalCode 個別銘柄の四半期単独値を算出して置き換え
+func (r *StatementsQuarterlyRepository) processLocalCode(tx *sql.Tx, localCode string) error {
+	quarters, err := r.getCumulativeQuarters(tx, localCode)
+	if err != nil {
+		return err
+	}
+
+	calculateQuarterly(quarters)
+
+	if _, err := tx.Exec("DELETE FROM statements_quarterly WHERE local_code = ?", localCode); err != nil {
+		return fmt.Errorf("既存データ削除エラー: %v", err)
+	}
+
+	for _, q := range quarters {
+		if err := r.insertQuarterly(tx, q); err != nil {
+			return fmt.Errorf("四半期データ挿入エラー (年度: %s, 四半期: %d): %v",
+				q.FiscalYearStartDate.Format("2006-01-02"), q.FiscalQuarter, err)
+		}
+	}
+
+	return nil
+}
+
+// getCumulativeQuarters 会計年度・四半期ごとの最新の累計実績を取得（古い順）
+func (r *StatementsQuarterlyRepository) getCumulativeQuarters(tx *sql.Tx, localCode string) ([]*StatementsQuarterly, error) {
+	query := `
+		SELECT
+			current_fiscal_year_start_date,
+			current_fiscal_year_end_date,
+			current_period_end_date,
+			type_of_current_period,
+			disclosed_date,
+			net_sales,
+			operating_profit,
+			ordinary_profit,
+			profit
+		FROM statements
+		WHERE local_code = ?
+			AND type_of_current_period IN ('1Q', '2Q', '3Q', 'FY')
+			AND current_fiscal_year_start_date IS NOT NULL
+			AND current_fiscal_year_end_date IS NOT NULL
+			AND (net_sales IS NOT NULL OR operating_profit IS NOT NULL OR profit IS NOT NULL)
+		ORDER BY disclosed_date, disclosed_time
+	`
+
+	rows, err := tx.Query(query, localCode)
+	if err != nil {
+		return nil, fmt.Errorf("累計実績取得エラー: %v", err)
+	}
+	defer rows.Close()
+
+	// 同じ四半期の訂正開示は後の開示で上書きする
+	quarterMap := make(map[quarterKey]*StatementsQuarterly)
+	for rows.Next() {
+		q := &StatementsQuarterly{LocalCode: localCode}
+		var periodType string
+
+		err := rows.Scan(
+			&q.FiscalYearStartDate,
+			&q.FiscalYearEndDate,
+			&q.PeriodEndDate,
+			&periodType,
+			&q.DisclosedDate,
+			&q.Cumulative.NetSales,
+			&q.Cumulative.OperatingProfit,
+			&q.Cumulative.OrdinaryProfit,
+			&q.Cumulative.Profit,
+		)
+		if err != nil {
+			log.Printf("累計実績スキャンエラー: %v", err)
+			continue
+		}
+
+		q.FiscalQuarter = periodQuarters[periodType]
+		quarterMap[quarterKey{q.FiscalYearStartDate.Format("2006-01-02"), q.FiscalQuarter}] = q
+	}
+
+	quarters := make([]*StatementsQuarterly, 0, len(quarterMap))
+	for _, q := range quarterMap {
+		quarters = append(quarters, q)
+	}
+	sort.Slice(quarters, func(i, j int) bool {
+		if !quarters[i].FiscalYearStartDate.Equal(quarters[j].FiscalYearStartDate) {
+			return quarters[i].FiscalYearStartDate.Before(quarters[j].FiscalYearStartDate)
+		}
+		return quarters[i].FiscalQuarter < quarters[j].FiscalQuarter
+	})
+
+	return quarters, nil
+}
+
+// calculateQuarterly 累計値から四半期単独値・TTM・前年同期比を算出（quartersは古い順）
+func calculateQuarterly(quarters []*StatementsQuarterly) {
+	quarterMap := make(map[quarterKey]*StatementsQuarterly, len(quarters))
+	// 会計年度末日 -> 会計年度開始日（前年度の特定に使用）
+	fiscalYearByEnd := make(map[string]string)
+	for _, q := range quarters {
+		start := q.FiscalYearStartDate.Format("2006-01-02")
+		quarterMap[quarterKey{start, q.FiscalQuarter}] = q
+		fiscalYearByEnd[q.FiscalYearEndDate.Format("2006-01-02")] = start
+	}
+
+	// 直前の四半期を取得（前年度は期末日が当年度開始日の前日であるものに限る）
+	previousQuarter := func(q *StatementsQuarterly) *StatementsQuarterly {
+		if q.FiscalQuarter > 1 {
+			return quarterMap[quarterKey{q.FiscalYearStartDate.Format("2006-01-02"), q.FiscalQuarter - 1}]
+		}
+		prevStart, ok := fiscalYearByEnd[q.FiscalYearStartDate.AddDate(0, 0, -1).Format("2006-01-02")]
+		if !ok {
+			return nil
+		}
+		return quarterMap[quarterKey{prevStart, 4}]
+	}
+
+	// 前年同期を取得
+	sameQuarterLastYear := func(q *StatementsQuarterly) *StatementsQuarterly {
+		prevStart, ok := fiscalYearByEnd[q.FiscalYearStartDate.AddDate(0, 0, -1).Format("2006-01-02")]
+		if !ok {
+			return nil
+		}
+		return quarterMap[quarterKey{prevStart, q.FiscalQuarter}]
+	}
+
+	// 四半期単独値
+	for _, q := range quarters {
+		if q.FiscalQuarter == 1 {
+			q.Standalone = q.Cumulative
+			continue
+		}
+		prev := quarterMap[quarterKey{q.FiscalYearStartDate.Format("2006-01-02"), q.FiscalQuarter - 1}]
+		if prev == nil {
+			continue
+		}
+		q.Standalone = QuarterlyFigures{
+			NetSales:        subInt64(q.Cumulative.NetSales, prev.Cumulative.NetSales),
+			OperatingProfit: subInt64(q.Cumulative.OperatingProfit, prev.Cumulative.OperatingProfit),
+			OrdinaryProfit:  subInt64(q.Cumulative.OrdinaryProfit, prev.Cumulative.OrdinaryProfit),
+			Profit:          subInt64(q.Cumulative.Profit, prev.Cumulative.Profit),
+		}
+	}
+
+	// TTMと前年同期比
+	for _, q := range quarters {
+		window := []*StatementsQuarterly{q}
+		for cur := q; len(window) < 4; {
+			cur = previousQuarter(cur)
+			if cur == nil {
+				break
+			}
+			window = append(window, cur)
+		}
+		if len(window) == 4 {
+			q.TTM = sumStandalone(window)
+		}
+
+		if last := sameQuarterLastYear(q); last != nil {
+			q.YoY = QuarterlyGrowth{
+				NetSales:        growthRate(q.Standalone.NetSales, last.Standalone.NetSales),
+				OperatingProfit: growthRate(q.Standalone.OperatingProfit, last.Standalone.OperatingProfit),
+				OrdinaryProfit:  growthRate(q.Standalone.OrdinaryProfit, last.Standalone.OrdinaryProfit),
+				Profit:          growthRate(q.Standalone.Profit, last.Standalone.Profit),
+			}
+		}
+	}
+}
+
+// sumStandalone 四半期単独値を項目ごとに合計（1つでも欠損があればNULL）
+func sumStandalone(quarters []*StatementsQuarterly) QuarterlyFigures {
+	sum := func(get func(f QuarterlyFigures) *int64) *int64 {
+		var total int64
+		for _, q := range quarters {
+			v := get(q.Standalone)
+			if v == nil {
+				return nil
+			}
+			total += *v
+		}
+		return &total
+	}
+
+	return QuarterlyFigures{
+		NetSales:        sum(func(f QuarterlyFigures) *int64 { return f.NetSales }),
+		OperatingProfit: sum(func(f QuarterlyFigures) *int64 { return f.OperatingProfit }),
+		OrdinaryProfit:  sum(func(f QuarterlyFigures) *int64 { return f.OrdinaryProfit }),
+		Profit:          sum(func(f QuarterlyFigures) *int64 { return f.Profit }),
+	}
+}
+
+// subInt64 差分を計算（どちらかがNULLの場合はNULL）
+func subInt64(a, b *int64) *int64 {
+	if a == nil || b == nil {
+		return nil
+	}
+	v := *a - *b
+	return &v
+}
+
+// growthRate 増減率（%）を計算（基準値が0またはNULLの場合はNULL、基準値が負の場合は絶対値で割る）
+func growthRate(current, base *int64) *float64 {
+	if current == nil || base == nil || *base == 0 {
+		return nil
+	}
+	v := math.Round(float64(*current-*base)/math.Abs(float64(*base))*10000) / 100
+	return &v
+}
+
+// insertQuarterly 四半期単独データを挿入
+func (r *StatementsQuarterlyRepository) insertQuarterly(tx *sql.Tx, q *StatementsQuarterly) error {
+	query := `
+		INSERT INTO statements_quarterly (
+			local_code, fiscal_year_start_date, fiscal_year_end_date, fiscal_quarter,
+			period_end_date, disclosed_date,
+			cum_net_sales, cum_operating_profit, cum_ordinary_profit, cum_profit,
+			net_sales, operating_profit, ordinary_profit, profit,
+			ttm_net_sales, ttm_operating_profit, ttm_ordinary_profit, ttm_profit,
+			net_sales_yoy, operating_profit_yoy, ordinary_profit_yoy, profit_yoy
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	_, err := tx.Exec(query,
+		q.LocalCode,
+		q.FiscalYearStartDate,
+		q.FiscalYearEndDate,
+		q.FiscalQuarter,
+		q.PeriodEndDate,
+		q.DisclosedDate,
+		q.Cumulative.NetSales,
+		q.Cumulative.OperatingProfit,
+		q.Cumulative.OrdinaryProfit,
+		q.Cumulative.Profit,
+		q.Standalone.NetSales,
+		q.Standalone.OperatingProfit,
+		q.Standalone.OrdinaryProfit,
+		q.Standalone.Profit,
+		q.TTM.NetSales,
+		q.TTM.OperatingProfit,
+		q.TTM.OrdinaryProfit,
+		q.TTM.Profit,
+		q.YoY.NetSales,
+		q.YoY.OperatingProfit,
+		q.YoY.OrdinaryProfit,
+		q.YoY.Profit,
+	)
+
+	return err
+}
+
+// GetQuarterlyByCode 銘柄コード別の四半期単独データを取得（新しい順）
+func (r *StatementsQuarterlyRepository) GetQuarterlyByCode(localCode string) ([]*StatementsQuarterly, error) {
+	query := `
+		SELECT
+			local_code, fiscal_year_start_date, fiscal_year_end_date, fiscal_quarter,
+			period_end_date, disclosed_date,
+			cum_net_sales, cum_operating_profit, cum_ordinary_profit, cum_profit,
+			net_sales, operating_profit, ordinary_profit, profit,
+			ttm_net_sales, ttm_operating_profit, ttm_ordinary_profit, ttm_profit,
+			net_sales_yoy, operating_profit_yoy, ordinary_profit_yoy, profit_yoy
+		FROM statements_quarterly
+		WHERE local_code = ?
+		ORDER BY fiscal_year_start_date DESC, fiscal_quarter DESC
+	`
+
+	rows, err := r.conn.GetDB().Query(query, localCode)
+	if err != nil {
+		return nil, fmt.Errorf("四半期データ取得エラー: %v", err)
+	}
+	defer rows.Close()
+
+	var quarters []*StatementsQuarterly
+	for rows.Next() {
+		q := &StatementsQuarterly{}
+		err := rows.Scan(
+			&q.LocalCode,
+			&q.FiscalYearStartDate,
+			&q.FiscalYearEndDate,
+			&q.FiscalQuarter,
+			&q.PeriodEndDate,
+			&q.DisclosedDate,
+			&q.Cumulative.NetSales,
+			&q.Cumulative.OperatingProfit,
+			&q.Cumulative.OrdinaryProfit,
+			&q.Cumulative.Profit,
+			&q.Standalone.NetSales,
+			&q.Standalone.OperatingProfit,
+			&q.Standalone.OrdinaryProfit,
+			&q.Standalone.Profit,
+			&q.TTM.NetSales,
+			&q.TTM.OperatingProfit,
+			&q.TTM.OrdinaryProfit,
+			&q.TTM.Profit,
+			&q.YoY.NetSales,
+			&q.YoY.OperatingProfit,
+			&q.YoY.OrdinaryProfit,
+			&q.YoY.Profit,
+		)
+		if err != nil {
+			log.Printf("四半期データスキャンエラー: %v", err)
+			continue
+		}
+		quarters = append(quarters, q)
+	}
+
+	return quarters, nil
+}
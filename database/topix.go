@@ -0,0 +1,92 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"log/slog"
+	"time"
+
+	"stock-automation/schema"
+)
+
+// TopixRepository TOPIXのリポジトリ
+type TopixRepository struct {
+	conn *Connection
+}
+
+// NewTopixRepository 新しいリポジトリを作成
+func NewTopixRepository(conn *Connection) *TopixRepository {
+	return &TopixRepository{conn: conn}
+}
+
+// SaveTopix TOPIX指数四本値を保存
+func (r *TopixRepository) SaveTopix(topix []schema.Topix) error {
+	if len(topix) == 0 {
+		return fmt.Errorf("保存するデータがありません")
+	}
+
+	// タイムスタンプを設定
+	records := make([]schema.Topix, len(topix))
+	now := time.Now()
+	for i, t := range topix {
+		records[i] = t
+		records[i].CreatedAt = now
+		records[i].UpdatedAt = now
+	}
+
+	// バッチサイズを制限（MySQLのプレースホルダー制限を回避）
+	const batchSize = 500
+	db := r.conn.GetGormDB()
+
+	for i := 0; i < len(records); i += batchSize {
+		end := i + batchSize
+		if end > len(records) {
+			end = len(records)
+		}
+
+		batch := records[i:end]
+		if result := db.Save(&batch); result.Error != nil {
+			return fmt.Errorf("データベース保存エラー (バッチ %d-%d): %v", i+1, end, result.Error)
+		}
+	}
+
+	slog.Debug("topix保存完了", "total_count", len(records))
+	return nil
+}
+
+// GetLatestTradeDate 保存済みの最終取引日を取得（データがない場合はnil）
+func (r *TopixRepository) GetLatestTradeDate() (*time.Time, error) {
+	var date sql.NullTime
+	if err := r.conn.GetDB().QueryRow("SELECT MAX(trade_date) FROM topix").Scan(&date); err != nil {
+		return nil, fmt.Errorf("TOPIX最終取引日取得エラー: %v", err)
+	}
+	if !date.Valid {
+		return nil, nil
+	}
+	return &date.Time, nil
+}
+
+// GetCloses 期間内のTOPIXの終値を古い順に取得
+func (r *TopixRepository) GetCloses(from, to time.Time) ([]*ClosePrice, error) {
+	rows, err := r.conn.GetDB().Query(
+		"SELECT trade_date, close FROM topix WHERE trade_date BETWEEN ? AND ? AND close > 0 ORDER BY trade_date",
+		from.Format("2006-01-02"), to.Format("2006-01-02"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("TOPIX取得エラー: %v", err)
+	}
+	defer rows.Close()
+
+	var prices []*ClosePrice
+	for rows.Next() {
+		price := &ClosePrice{}
+		if err := rows.Scan(&price.Date, &price.Close); err != nil {
+			log.Printf("TOPIXスキャンエラー: %v", err)
+			continue
+		}
+		prices = append(prices, price)
+	}
+
+	return prices, nil
+}
@@ -0,0 +1,143 @@
+package database
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"stock-automation/schema"
+)
+
+// 取引カレンダーの休日区分
+const (
+	HolidayDivisionNonBusiness = "0"
+	HolidayDivisionBusiness    = "1"
+	HolidayDivisionHalfDay     = "2"
+	HolidayDivisionHolidayOpen = "3"
+)
+
+// TradingCalendarRepository 取引カレンダーのリポジトリ
+type TradingCalendarRepository struct {
+	conn *Connection
+}
+
+// NewTradingCalendarRepository 新しいリポジトリを作成
+func NewTradingCalendarRepository(conn *Connection) *TradingCalendarRepository {
+	return &TradingCalendarRepository{conn: conn}
+}
+
+// SaveTradingCalendar 取引カレンダーを保存
+func (r *TradingCalendarRepository) SaveTradingCalendar(days []schema.TradingCalendar) error {
+	if len(days) == 0 {
+		return fmt.Errorf("保存するデータがありません")
+	}
+
+	// タイムスタンプを設定
+	records := make([]schema.TradingCalendar, len(days))
+	now := time.Now()
+	for i, day := range days {
+		records[i] = day
+		records[i].CreatedAt = now
+		records[i].UpdatedAt = now
+	}
+
+	// バッチサイズを制限（MySQLのプレースホルダー制限を回避）
+	const batchSize = 500
+	db := r.conn.GetGormDB()
+
+	for i := 0; i < len(records); i += batchSize {
+		end := i + batchSize
+		if end > len(records) {
+			end = len(records)
+		}
+
+		batch := records[i:end]
+		if result := db.Save(&batch); result.Error != nil {
+			return fmt.Errorf("データベース保存エラー (バッチ %d-%d): %v", i+1, end, result.Error)
+		}
+	}
+
+	slog.Debug("trading_calendar保存完了", "total_count", len(records))
+	return nil
+}
+
+// BusinessCalendar 営業日の判定（取引カレンダーにない日は土日・年末年始を休業日とみなす）
+type BusinessCalendar struct {
+	days map[string]bool // 日付 -> 営業日かどうか
+}
+
+// LoadBusinessCalendar 指定期間の取引カレンダーを読み込む
+func (r *TradingCalendarRepository) LoadBusinessCalendar(from, to time.Time) (*BusinessCalendar, error) {
+	rows, err := r.conn.GetDB().Query(
+		"SELECT calendar_date, holiday_division FROM trading_calendar WHERE calendar_date BETWEEN ? AND ?",
+		from.Format("2006-01-02"), to.Format("2006-01-02"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("取引カレンダー取得エラー: %v", err)
+	}
+	defer rows.Close()
+
+	calendar := &BusinessCalendar{days: make(map[string]bool)}
+	for rows.Next() {
+		var date time.Time
+		var division string
+		if err := rows.Scan(&date, &division); err != nil {
+			slog.Warn("取引カレンダースキャンエラー", "error", err)
+			continue
+		}
+		calendar.days[date.Format("2006-01-02")] = division == HolidayDivisionBusiness || division == HolidayDivisionHalfDay
+	}
+
+	return calendar, nil
+}
+
+// Covers 指定日が取引カレンダーに含まれるかを判定
+func (c *BusinessCalendar) Covers(date time.Time) bool {
+	_, ok := c.days[date.Format("2006-01-02")]
+	return ok
+}
+
+// IsBusinessDay 指定日が営業日かを判定
+func (c *BusinessCalendar) IsBusinessDay(date time.Time) bool {
+	if business, ok := c.days[date.Format("2006-01-02")]; ok {
+		return business
+	}
+
+	// 取引カレンダーにない日は土日と年末年始（12/31〜1/3）を休業日とみなす
+	switch date.Weekday() {
+	case time.Saturday, time.Sunday:
+		return false
+	}
+	if (date.Month() == time.December && date.Day() == 31) || (date.Month() == time.January && date.Day() <= 3) {
+		return false
+	}
+	return true
+}
+
+// BusinessDaysBefore 指定日以前の直近の営業日からn営業日さかのぼった日を取得（n=0の場合は指定日以前の直近の営業日）
+func (c *BusinessCalendar) BusinessDaysBefore(date time.Time, n int) time.Time {
+	for !c.IsBusinessDay(date) {
+		date = date.AddDate(0, 0, -1)
+	}
+	for i := 0; i < n; i++ {
+		date = date.AddDate(0, 0, -1)
+		for !c.IsBusinessDay(date) {
+			date = date.AddDate(0, 0, -1)
+		}
+	}
+	return date
+}
+
+// BusinessDaysAfter 指定日からn営業日後の日を取得（指定日が休業日の場合は翌営業日から数える）
+func (c *BusinessCalendar) BusinessDaysAfter(date time.Time, n int) time.Time {
+	for !c.IsBusinessDay(date) {
+		date = date.AddDate(0, 0, 1)
+	}
+	for i := 0; i < n; i++ {
+		date = date.AddDate(0, 0, 1)
+		for !c.IsBusinessDay(date) {
+			date = date.AddDate(0, 0, 1)
+		}
+	}
+	return date
+}
@@ -0,0 +1,251 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"log/slog"
+	"strings"
+	"time"
+
+	"stock-automation/schema"
+)
+
+// UniverseFilter ユニバースの絞り込み条件（空の条件は適用しない）
+type UniverseFilter struct {
+	MarketCodes     []string // 市場区分コード（例: 0111）
+	ScaleCategories []string // 規模区分（例: TOPIX Core30）
+	Sector17Codes   []string
+	Sector33Codes   []string
+	Codes           CodeFilter // ウォッチリスト・タグ
+}
+
+// IsEmpty 条件が指定されていないかを判定
+func (f UniverseFilter) IsEmpty() bool {
+	return len(f.MarketCodes) == 0 && len(f.ScaleCategories) == 0 && len(f.Sector17Codes) == 0 && len(f.Sector33Codes) == 0 && f.Codes.IsEmpty()
+}
+
+// conditions 指定したテーブル別名の列に対するWHERE条件を作成
+func (f UniverseFilter) conditions(alias string) ([]string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+
+	for _, c := range []struct {
+		column string
+		values []string
+	}{
+		{"market_code", f.MarketCodes},
+		{"scale_category", f.ScaleCategories},
+		{"sector17_code", f.Sector17Codes},
+		{"sector33_code", f.Sector33Codes},
+	} {
+		if len(c.values) == 0 {
+			continue
+		}
+		conditions = append(conditions, fmt.Sprintf("%s.%s IN (?%s)", alias, c.column, strings.Repeat(", ?", len(c.values)-1)))
+		for _, v := range c.values {
+			args = append(args, v)
+		}
+	}
+
+	codeConditions, codeArgs := f.Codes.conditions(alias + ".code")
+	conditions = append(conditions, codeConditions...)
+	args = append(args, codeArgs...)
+
+	return conditions, args
+}
+
+// UniverseMember 指定日時点の上場銘柄
+type UniverseMember struct {
+	Code          string
+	CompanyName   string
+	MarketCode    string
+	Sector17Code  string
+	Sector33Code  string
+	ScaleCategory string
+	SnapshotDate  time.Time // 判定に使った上場銘柄一覧の適用日
+}
+
+// UniverseRepository ユニバースのリポジトリ
+type UniverseRepository struct {
+	conn *Connection
+}
+
+// NewUniverseRepository 新しいリポジトリを作成
+func NewUniverseRepository(conn *Connection) *UniverseRepository {
+	return &UniverseRepository{conn: conn}
+}
+
+// Universe 指定日時点で上場していた銘柄を、その時点の市場区分・規模区分・業種で絞り込んで取得（コード順）
+// 指定日以前で直近の上場銘柄一覧スナップショットを使い、スナップショットがない場合は上場銘柄情報の履歴で代用する
+// （履歴は取得開始以前に上場廃止した銘柄を含まないため、生存バイアスが残る）
+func (r *UniverseRepository) Universe(asOf time.Time, filter UniverseFilter) ([]*UniverseMember, error) {
+	date := asOf.Format("2006-01-02")
+
+	var snapshotDate sql.NullTime
+	err := r.conn.GetDB().QueryRow(
+		"SELECT MAX(snapshot_date) FROM listed_info_snapshots WHERE snapshot_date <= ?", date,
+	).Scan(&snapshotDate)
+	if err != nil {
+		return nil, fmt.Errorf("スナップショット取得エラー: %v", err)
+	}
+
+	var query string
+	var args []interface{}
+	var conditions []string
+	if snapshotDate.Valid {
+		query = `
+			SELECT s.code, s.company_name, COALESCE(s.market_code, ''), COALESCE(s.sector17_code, ''),
+				COALESCE(s.sector33_code, ''), COALESCE(s.scale_category, ''), s.snapshot_date
+			FROM listed_info_snapshots s
+		`
+		conditions = append(conditions, "s.snapshot_date = ?")
+		args = append(args, snapshotDate.Time)
+	} else {
+		slog.Debug("指定日以前の上場銘柄一覧スナップショットがないため上場銘柄情報の履歴で代用", "date", date)
+		query = `
+			SELECT s.code, s.company_name, COALESCE(s.market_code, ''), COALESCE(s.sector17_code, ''),
+				COALESCE(s.sector33_code, ''), COALESCE(s.scale_category, ''), s.effective_from
+			FROM listed_info_history s
+			JOIN listed_info li ON li.code = s.code
+		`
+		conditions = append(conditions,
+			"s.effective_from <= ?",
+			"(s.effective_to IS NULL OR s.effective_to >= ?)",
+			"(li.delisted_date IS NULL OR li.delisted_date > ?)",
+		)
+		args = append(args, date, date, date)
+	}
+
+	filterConditions, filterArgs := filter.conditions("s")
+	conditions = append(conditions, filterConditions...)
+	args = append(args, filterArgs...)
+
+	query += " WHERE " + strings.Join(conditions, " AND ") + " ORDER BY s.code"
+
+	rows, err := r.conn.GetDB().Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("ユニバース取得エラー: %v", err)
+	}
+	defer rows.Close()
+
+	var members []*UniverseMember
+	for rows.Next() {
+		m := &UniverseMember{}
+		err := rows.Scan(
+			&m.Code,
+			&m.CompanyName,
+			&m.MarketCode,
+			&m.Sector17Code,
+			&m.Sector33Code,
+			&m.ScaleCategory,
+			&m.SnapshotDate,
+		)
+		if err != nil {
+			log.Printf("ユニバーススキャンエラー: %v", err)
+			continue
+		}
+		members = append(members, m)
+	}
+
+	return members, nil
+}
+
+// GetFirstSnapshotDate 最も古いスナップショットの適用日を取得（スナップショットがない場合はnil）
+func (r *UniverseRepository) GetFirstSnapshotDate() (*time.Time, error) {
+	var date sql.NullTime
+	if err := r.conn.GetDB().QueryRow("SELECT MIN(snapshot_date) FROM listed_info_snapshots").Scan(&date); err != nil {
+		return nil, fmt.Errorf("スナップショット取得エラー: %v", err)
+	}
+	if !date.Valid {
+		return nil, nil
+	}
+	return &date.Time, nil
+}
+
+// SaveSnapshots 上場銘柄一覧を適用日ごとのスナップショットとして保存（同じ適用日のスナップショットは置き換える）
+func (r *ListedInfoRepository) SaveSnapshots(listedInfos []schema.ListedInfo) error {
+	if len(listedInfos) == 0 {
+		return fmt.Errorf("保存するデータがありません")
+	}
+
+	byDate := make(map[string][]*schema.ListedInfo)
+	var dates []string
+	for i := range listedInfos {
+		info := &listedInfos[i]
+		if _, ok := byDate[info.Date]; !ok {
+			dates = append(dates, info.Date)
+		}
+		byDate[info.Date] = append(byDate[info.Date], info)
+	}
+
+	tx, cleanup := BeginTransaction(r.conn.GetDB())
+	defer cleanup()
+
+	for _, date := range dates {
+		if _, err := tx.Exec("DELETE FROM listed_info_snapshots WHERE snapshot_date = ?", date); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("既存スナップショット削除エラー (適用日: %s): %v", date, err)
+		}
+
+		// バッチサイズを制限（MySQLのプレースホルダー制限を回避）
+		const batchSize = 500
+		infos := byDate[date]
+		for i := 0; i < len(infos); i += batchSize {
+			end := i + batchSize
+			if end > len(infos) {
+				end = len(infos)
+			}
+			if err := insertSnapshots(tx, infos[i:end]); err != nil {
+				tx.Rollback()
+				return fmt.Errorf("スナップショット挿入エラー (適用日: %s, バッチ %d-%d): %v", date, i+1, end, err)
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("トランザクションコミットエラー: %v", err)
+	}
+
+	slog.Debug("listed_info_snapshots保存完了", "dates", dates, "total_count", len(listedInfos))
+	return nil
+}
+
+// insertSnapshots スナップショットをまとめて挿入
+func insertSnapshots(tx *sql.Tx, infos []*schema.ListedInfo) error {
+	placeholders := make([]string, len(infos))
+	args := make([]interface{}, 0, len(infos)*9)
+	for i, info := range infos {
+		placeholders[i] = "(?, ?, ?, ?, ?, ?, ?, ?, ?)"
+		args = append(args,
+			info.Date,
+			info.Code,
+			info.CompanyName,
+			info.CompanyNameEnglish,
+			info.MarketCode,
+			info.Sector17Code,
+			info.Sector33Code,
+			info.ScaleCategory,
+			info.MarginCode,
+		)
+	}
+
+	query := `
+		INSERT INTO listed_info_snapshots (
+			snapshot_date, code, company_name, company_name_english, market_code,
+			sector17_code, sector33_code, scale_category, margin_code
+		) VALUES ` + strings.Join(placeholders, ", ")
+
+	_, err := tx.Exec(query, args...)
+	return err
+}
+
+// HasSnapshot 指定した適用日のスナップショットが保存済みかを判定
+func (r *ListedInfoRepository) HasSnapshot(date string) (bool, error) {
+	var count int
+	err := r.conn.GetDB().QueryRow("SELECT COUNT(*) FROM listed_info_snapshots WHERE snapshot_date = ?", date).Scan(&count)
+	if err != nil {
+		return false, fmt.Errorf("スナップショット確認エラー: %v", err)
+	}
+	return count > 0, nil
+}
@@ -0,0 +1,408 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"time"
+)
+
+// valuationCutoffTime この時刻より前に開示された財務情報を当日の終値に反映する（以降の開示は翌取引日から反映）
+const valuationCutoffTime = "15:00:00"
+
+// valuationWarmupDays 差分作成時に、開始日時点で有効な財務情報と株式分割を把握するためにさかのぼる日数
+const valuationWarmupDays = 400
+
+// Valuation 株価指標の構造体
+type Valuation struct {
+	Code                  string
+	CompanyName           string // 取得時のみ（listed_infoから結合）
+	TradeDate             time.Time
+	Close                 float64
+	SharesOutstanding     *int64
+	MarketCap             *int64
+	ActualEPS             *float64
+	ActualPER             *float64
+	ActualDisclosedDate   *time.Time
+	ForecastEPS           *float64
+	ForecastPER           *float64
+	ForecastDPS           *float64
+	ForecastDividendYield *float64
+	ForecastDisclosedDate *time.Time
+	BVPS                  *float64
+	PBR                   *float64
+}
+
+// ValuationRepository 株価指標のリポジトリ
+type ValuationRepository struct {
+	conn *Connection
+}
+
+// NewValuationRepository 新しいリポジトリを作成
+func NewValuationRepository(conn *Connection) *ValuationRepository {
+	return &ValuationRepository{conn: conn}
+}
+
+// valuationQuote 株価指標の算出に使う日次株価
+type valuationQuote struct {
+	tradeDate        time.Time
+	close            *float64
+	adjustmentFactor *float64
+}
+
+// effectiveStatement 有効になった財務情報と、有効になった時点の株式分割累積係数
+type effectiveStatement struct {
+	stmt *PointInTimeStatement
+	base float64
+}
+
+// BuildValuations 日次株価と開示済みの財務情報から株価指標を算出して保存
+// localCodes: 対象銘柄コード（空の場合は財務データのある全銘柄）
+// fromDate: 作成開始日（YYYY-MM-DD形式、空の場合は全期間を再作成）
+func (r *ValuationRepository) BuildValuations(localCodes []string, fromDate string) error {
+	var from *time.Time
+	if fromDate != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", fromDate, time.Local)
+		if err != nil {
+			return fmt.Errorf("日付の形式が正しくありません（YYYY-MM-DD形式で指定してください）: %v", err)
+		}
+		from = &parsed
+	}
+
+	tx, cleanup := BeginTransaction(r.conn.GetDB())
+	defer cleanup()
+
+	if len(localCodes) == 0 {
+		codes, err := selectDistinctLocalCodes(tx)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		localCodes = codes
+	}
+
+	processedCount := 0
+	for _, code := range localCodes {
+		if err := r.processLocalCode(tx, code, from); err != nil {
+			log.Printf("銘柄 %s の株価指標算出でエラー: %v", code, err)
+			continue
+		}
+		processedCount++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("トランザクションコミットエラー: %v", err)
+	}
+
+	log.Printf("株価指標の作成完了: %d銘柄処理", processedCount)
+	return nil
+}
+
+// processLocalCode 個別銘柄の株価指標を算出して置き換え（fromがnilの場合は全期間）
+func (r *ValuationRepository) processLocalCode(tx *sql.Tx, code string, from *time.Time) error {
+	actuals, forecasts, err := getStatementHistory(tx, code)
+	if err != nil {
+		return err
+	}
+
+	quotes, err := getValuationQuotes(tx, code, from)
+	if err != nil {
+		return err
+	}
+
+	valuations := calculateValuations(code, quotes, actuals, forecasts, from)
+
+	deleteQuery := "DELETE FROM valuations WHERE code = ?"
+	deleteArgs := []interface{}{code}
+	if from != nil {
+		deleteQuery += " AND trade_date >= ?"
+		deleteArgs = append(deleteArgs, *from)
+	}
+	if _, err := tx.Exec(deleteQuery, deleteArgs...); err != nil {
+		return fmt.Errorf("既存データ削除エラー: %v", err)
+	}
+
+	// バッチサイズを制限（MySQLのプレースホルダー制限を回避）
+	const batchSize = 500
+	for i := 0; i < len(valuations); i += batchSize {
+		end := i + batchSize
+		if end > len(valuations) {
+			end = len(valuations)
+		}
+		if err := r.insertValuations(tx, valuations[i:end]); err != nil {
+			return fmt.Errorf("株価指標挿入エラー (バッチ %d-%d): %v", i+1, end, err)
+		}
+	}
+
+	return nil
+}
+
+// getValuationQuotes 日次株価を取引日の古い順に取得（fromが指定された場合は助走期間を含めて取得）
+func getValuationQuotes(q sqlQueryer, code string, from *time.Time) ([]valuationQuote, error) {
+	query := "SELECT trade_date, close, adjustment_factor FROM daily_quotes WHERE code = ?"
+	args := []interface{}{code}
+	if from != nil {
+		query += " AND trade_date >= ?"
+		args = append(args, from.AddDate(0, 0, -valuationWarmupDays))
+	}
+	query += " ORDER BY trade_date"
+
+	rows, err := q.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("株価データ取得エラー: %v", err)
+	}
+	defer rows.Close()
+
+	var quotes []valuationQuote
+	for rows.Next() {
+		var quote valuationQuote
+		if err := rows.Scan(&quote.tradeDate, &quote.close, &quote.adjustmentFactor); err != nil {
+			log.Printf("株価データスキャンエラー: %v", err)
+			continue
+		}
+		quotes = append(quotes, quote)
+	}
+
+	return quotes, nil
+}
+
+// effectiveOn 財務情報が取引日の終値に反映されるかを判定
+func effectiveOn(stmt *PointInTimeStatement, tradeDate time.Time) bool {
+	if !stmt.DisclosedDate.Equal(tradeDate) {
+		return stmt.DisclosedDate.Before(tradeDate)
+	}
+	disclosedTime := stmt.DisclosedTime
+	if disclosedTime == "" {
+		disclosedTime = "00:00:00"
+	}
+	return disclosedTime < valuationCutoffTime
+}
+
+// calculateValuations 取引日ごとに開示済みの財務情報を反映しながら株価指標を算出
+// 開示後に株式分割・併合があった場合は、daily_quotesの調整係数で1株当たりの値と株式数を調整する
+func calculateValuations(code string, quotes []valuationQuote, actuals, forecasts []*PointInTimeStatement, from *time.Time) []*Valuation {
+	var valuations []*Valuation
+	var sharesSource, annualActual, bvpsSource, forecast *effectiveStatement
+
+	cumulativeFactor := 1.0
+	ai, fi := 0, 0
+	for _, quote := range quotes {
+		// 当日の終値までに開示された財務情報を反映（当日の株式分割は開示後の調整対象とする）
+		for ai < len(actuals) && effectiveOn(actuals[ai], quote.tradeDate) {
+			e := &effectiveStatement{stmt: actuals[ai], base: cumulativeFactor}
+			if e.stmt.IssuedShares != nil {
+				sharesSource = e
+			}
+			if e.stmt.TypeOfCurrentPeriod == "FY" {
+				annualActual = e
+			}
+			if e.stmt.BVPS != nil {
+				bvpsSource = e
+			}
+			ai++
+		}
+		for fi < len(forecasts) && effectiveOn(forecasts[fi], quote.tradeDate) {
+			forecast = &effectiveStatement{stmt: forecasts[fi], base: cumulativeFactor}
+			fi++
+		}
+
+		if quote.adjustmentFactor != nil && *quote.adjustmentFactor > 0 {
+			cumulativeFactor *= *quote.adjustmentFactor
+		}
+
+		if quote.close == nil || *quote.close <= 0 {
+			continue
+		}
+		if from != nil && quote.tradeDate.Before(*from) {
+			continue
+		}
+
+		price := *quote.close
+		v := &Valuation{
+			Code:      code,
+			TradeDate: quote.tradeDate,
+			Close:     price,
+		}
+
+		if sharesSource != nil {
+			ratio := cumulativeFactor / sharesSource.base
+			shares := *sharesSource.stmt.IssuedShares
+			if sharesSource.stmt.TreasuryShares != nil {
+				shares -= *sharesSource.stmt.TreasuryShares
+			}
+			if shares > 0 {
+				adjusted := int64(math.Round(float64(shares) / ratio))
+				marketCap := int64(math.Round(price * float64(adjusted)))
+				v.SharesOutstanding = &adjusted
+				v.MarketCap = &marketCap
+			}
+		}
+
+		if annualActual != nil {
+			v.ActualDisclosedDate = &annualActual.stmt.DisclosedDate
+			v.ActualEPS = adjustPerShare(annualActual.stmt.EPS, cumulativeFactor/annualActual.base)
+			v.ActualPER = priceRatio(price, v.ActualEPS)
+		}
+
+		if forecast != nil {
+			ratio := cumulativeFactor / forecast.base
+			v.ForecastDisclosedDate = &forecast.stmt.DisclosedDate
+			v.ForecastEPS = adjustPerShare(forecast.stmt.EPS, ratio)
+			v.ForecastPER = priceRatio(price, v.ForecastEPS)
+			v.ForecastDPS = adjustPerShare(forecast.stmt.DividendPerShare, ratio)
+			if v.ForecastDPS != nil {
+				dividendYield := math.Round(*v.ForecastDPS/price*10000) / 100
+				v.ForecastDividendYield = &dividendYield
+			}
+		}
+
+		if bvpsSource != nil {
+			v.BVPS = adjustPerShare(bvpsSource.stmt.BVPS, cumulativeFactor/bvpsSource.base)
+			v.PBR = priceRatio(price, v.BVPS)
+		}
+
+		valuations = append(valuations, v)
+	}
+
+	return valuations
+}
+
+// adjustPerShare 1株当たりの値を株式分割・併合の係数で調整
+func adjustPerShare(v *float64, ratio float64) *float64 {
+	if v == nil {
+		return nil
+	}
+	adjusted := math.Round(*v*ratio*100) / 100
+	return &adjusted
+}
+
+// priceRatio 株価を1株当たりの値で割った倍率（1株当たりの値が0以下の場合はnil）
+func priceRatio(price float64, perShare *float64) *float64 {
+	if perShare == nil || *perShare <= 0 {
+		return nil
+	}
+	ratio := math.Round(price / *perShare * 100) / 100
+	return &ratio
+}
+
+// insertValuations 株価指標データをまとめて挿入
+func (r *ValuationRepository) insertValuations(tx *sql.Tx, valuations []*Valuation) error {
+	if len(valuations) == 0 {
+		return nil
+	}
+
+	const columns = 15
+	placeholders := make([]string, len(valuations))
+	args := make([]interface{}, 0, len(valuations)*columns)
+	for i, v := range valuations {
+		placeholders[i] = "(?" + strings.Repeat(", ?", columns-1) + ")"
+		args = append(args,
+			v.Code,
+			v.TradeDate,
+			v.Close,
+			v.SharesOutstanding,
+			v.MarketCap,
+			v.ActualEPS,
+			v.ActualPER,
+			v.ActualDisclosedDate,
+			v.ForecastEPS,
+			v.ForecastPER,
+			v.ForecastDPS,
+			v.ForecastDividendYield,
+			v.ForecastDisclosedDate,
+			v.BVPS,
+			v.PBR,
+		)
+	}
+
+	query := `
+		INSERT INTO valuations (
+			code, trade_date, close, shares_outstanding, market_cap,
+			actual_eps, actual_per, actual_disclosed_date,
+			forecast_eps, forecast_per, forecast_dps, forecast_dividend_yield, forecast_disclosed_date,
+			bvps, pbr
+		) VALUES ` + strings.Join(placeholders, ", ")
+
+	_, err := tx.Exec(query, args...)
+	return err
+}
+
+// GetLatestTradeDate daily_quotesの最新取引日を取得（データがない場合は空文字列）
+func (r *ValuationRepository) GetLatestTradeDate() (string, error) {
+	var latest sql.NullTime
+	if err := r.conn.GetDB().QueryRow("SELECT MAX(trade_date) FROM daily_quotes").Scan(&latest); err != nil {
+		return "", fmt.Errorf("最新取引日取得エラー: %v", err)
+	}
+	if !latest.Valid {
+		return "", nil
+	}
+	return latest.Time.Format("2006-01-02"), nil
+}
+
+// GetValuations 株価指標を取得
+// code: 銘柄コード（指定した場合は取引日の新しい順、空の場合は最新取引日の全銘柄をコード順）
+func (r *ValuationRepository) GetValuations(code string, filter CodeFilter, limit int) ([]*Valuation, error) {
+	query := `
+		SELECT
+			v.code, COALESCE(li.company_name, ''), v.trade_date, v.close, v.shares_outstanding, v.market_cap,
+			v.actual_eps, v.actual_per, v.actual_disclosed_date,
+			v.forecast_eps, v.forecast_per, v.forecast_dps, v.forecast_dividend_yield, v.forecast_disclosed_date,
+			v.bvps, v.pbr
+		FROM valuations v
+		LEFT JOIN listed_info li ON li.code = v.code
+	`
+	conditions, args := filter.conditions("v.code")
+	if code != "" {
+		conditions = append(conditions, "v.code = ?")
+		args = append(args, code)
+	} else {
+		conditions = append(conditions, "v.trade_date = (SELECT MAX(trade_date) FROM valuations)")
+	}
+	query += " WHERE " + strings.Join(conditions, " AND ")
+	if code != "" {
+		query += " ORDER BY v.trade_date DESC"
+	} else {
+		query += " ORDER BY v.code"
+	}
+	if limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", limit)
+	}
+
+	rows, err := r.conn.GetDB().Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("株価指標データ取得エラー: %v", err)
+	}
+	defer rows.Close()
+
+	var valuations []*Valuation
+	for rows.Next() {
+		v := &Valuation{}
+		err := rows.Scan(
+			&v.Code,
+			&v.CompanyName,
+			&v.TradeDate,
+			&v.Close,
+			&v.SharesOutstanding,
+			&v.MarketCap,
+			&v.ActualEPS,
+			&v.ActualPER,
+			&v.ActualDisclosedDate,
+			&v.ForecastEPS,
+			&v.ForecastPER,
+			&v.ForecastDPS,
+			&v.ForecastDividendYield,
+			&v.ForecastDisclosedDate,
+			&v.BVPS,
+			&v.PBR,
+		)
+		if err != nil {
+			log.Printf("株価指標データスキャンエラー: %v", err)
+			continue
+		}
+		valuations = append(valuations, v)
+	}
+
+	return valuations, nil
+}
@@ -0,0 +1,365 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DefaultWatchlistName ウォッチリスト名を指定しない場合のウォッチリスト
+const DefaultWatchlistName = "default"
+
+// CodeFilter ウォッチリスト・タグによる銘柄の絞り込み条件（空の条件は適用しない）
+type CodeFilter struct {
+	Watchlist string // ウォッチリスト名
+	Tag       string
+}
+
+// IsEmpty 条件が指定されていないかを判定
+func (f CodeFilter) IsEmpty() bool {
+	return f.Watchlist == "" && f.Tag == ""
+}
+
+// conditions 指定した銘柄コードの列に対するWHERE条件を作成
+func (f CodeFilter) conditions(column string) ([]string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+
+	if f.Watchlist != "" {
+		conditions = append(conditions, column+` IN (
+			SELECT wi.code FROM watchlist_items wi JOIN watchlists w ON w.id = wi.watchlist_id WHERE w.name = ?
+		)`)
+		args = append(args, f.Watchlist)
+	}
+	if f.Tag != "" {
+		conditions = append(conditions, column+" IN (SELECT code FROM code_tags WHERE tag = ?)")
+		args = append(args, f.Tag)
+	}
+
+	return conditions, args
+}
+
+// Scope GORMのクエリに指定した銘柄コードの列に対する条件を追加するスコープ
+func (f CodeFilter) Scope(column string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		conditions, args := f.conditions(column)
+		for i, condition := range conditions {
+			db = db.Where(condition, args[i])
+		}
+		return db
+	}
+}
+
+// Watchlist ウォッチリスト
+type Watchlist struct {
+	ID          int64
+	Name        string
+	Description string
+	Items       int // 銘柄数
+	CreatedAt   time.Time
+}
+
+// WatchlistItem ウォッチリストの銘柄と直近の値動き（株価がない場合はnil）
+type WatchlistItem struct {
+	WatchlistID   int64
+	Code          string
+	CompanyName   string
+	AddedAt       time.Time
+	Tags          []string
+	LastTradeDate *time.Time
+	LastClose     *float64 // 最新の調整後終値
+	DayChangePct  *float64 // 前日比(%)
+	AddedClose    *float64 // 追加日以前の直近の調整後終値
+	SinceAddedPct *float64 // 追加日からの騰落率(%)
+}
+
+// WatchlistRepository ウォッチリストのリポジトリ
+type WatchlistRepository struct {
+	conn *Connection
+}
+
+// NewWatchlistRepository 新しいリポジトリを作成
+func NewWatchlistRepository(conn *Connection) *WatchlistRepository {
+	return &WatchlistRepository{conn: conn}
+}
+
+// GetWatchlist 名前でウォッチリストを取得（存在しない場合はnil）
+func (r *WatchlistRepository) GetWatchlist(name string) (*Watchlist, error) {
+	w := &Watchlist{}
+	var description sql.NullString
+	err := r.conn.GetDB().QueryRow(`
+		SELECT w.id, w.name, w.description, w.created_at, (SELECT COUNT(*) FROM watchlist_items WHERE watchlist_id = w.id)
+		FROM watchlists w
+		WHERE w.name = ?
+	`, name).Scan(&w.ID, &w.Name, &description, &w.CreatedAt, &w.Items)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ウォッチリスト取得エラー: %v", err)
+	}
+	w.Description = description.String
+	return w, nil
+}
+
+// GetOrCreateWatchlist 名前でウォッチリストを取得し、存在しない場合は作成
+func (r *WatchlistRepository) GetOrCreateWatchlist(name string) (*Watchlist, error) {
+	w, err := r.GetWatchlist(name)
+	if err != nil || w != nil {
+		return w, err
+	}
+
+	if _, err := r.conn.GetDB().Exec("INSERT IGNORE INTO watchlists (name) VALUES (?)", name); err != nil {
+		return nil, fmt.Errorf("ウォッチリスト作成エラー: %v", err)
+	}
+	log.Printf("ウォッチリストを作成しました: %s", name)
+
+	return r.GetWatchlist(name)
+}
+
+// GetWatchlists 全ウォッチリストを名前順に取得
+func (r *WatchlistRepository) GetWatchlists() ([]*Watchlist, error) {
+	rows, err := r.conn.GetDB().Query(`
+		SELECT w.id, w.name, w.description, w.created_at, COUNT(wi.code)
+		FROM watchlists w
+		LEFT JOIN watchlist_items wi ON wi.watchlist_id = w.id
+		GROUP BY w.id, w.name, w.description, w.created_at
+		ORDER BY w.name
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("ウォッチリスト取得エラー: %v", err)
+	}
+	defer rows.Close()
+
+	var watchlists []*Watchlist
+	for rows.Next() {
+		w := &Watchlist{}
+		var description sql.NullString
+		if err := rows.Scan(&w.ID, &w.Name, &description, &w.CreatedAt, &w.Items); err != nil {
+			log.Printf("ウォッチリストスキャンエラー: %v", err)
+			continue
+		}
+		w.Description = description.String
+		watchlists = append(watchlists, w)
+	}
+
+	return watchlists, nil
+}
+
+// DeleteWatchlist ウォッチリストを銘柄ごと削除
+func (r *WatchlistRepository) DeleteWatchlist(watchlistID int64) error {
+	if _, err := r.conn.GetDB().Exec("DELETE FROM watchlists WHERE id = ?", watchlistID); err != nil {
+		return fmt.Errorf("ウォッチリスト削除エラー: %v", err)
+	}
+	return nil
+}
+
+// AddItems ウォッチリストに銘柄を追加し、追加した銘柄数を返す（追加済みの銘柄は読み飛ばす）
+func (r *WatchlistRepository) AddItems(watchlistID int64, codes []string) (int, error) {
+	tx, cleanup := BeginTransaction(r.conn.GetDB())
+	defer cleanup()
+
+	added := 0
+	for _, code := range codes {
+		if _, err := getListedCompanyName(tx, code); err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+		result, err := tx.Exec("INSERT IGNORE INTO watchlist_items (watchlist_id, code) VALUES (?, ?)", watchlistID, code)
+		if err != nil {
+			tx.Rollback()
+			return 0, fmt.Errorf("ウォッチリスト銘柄保存エラー: %v", err)
+		}
+		if n, _ := result.RowsAffected(); n > 0 {
+			added++
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("トランザクションコミットエラー: %v", err)
+	}
+
+	return added, nil
+}
+
+// RemoveItems ウォッチリストから銘柄を削除し、削除した銘柄数を返す
+func (r *WatchlistRepository) RemoveItems(watchlistID int64, codes []string) (int, error) {
+	if len(codes) == 0 {
+		return 0, nil
+	}
+
+	args := []interface{}{watchlistID}
+	for _, code := range codes {
+		args = append(args, code)
+	}
+	result, err := r.conn.GetDB().Exec(
+		"DELETE FROM watchlist_items WHERE watchlist_id = ? AND code IN (?"+strings.Repeat(", ?", len(codes)-1)+")", args...,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("ウォッチリスト銘柄削除エラー: %v", err)
+	}
+	n, _ := result.RowsAffected()
+	return int(n), nil
+}
+
+// GetItems ウォッチリストの銘柄を最新の調整後終値・前日比・追加日からの騰落率・タグとともにコード順に取得
+func (r *WatchlistRepository) GetItems(watchlistID int64) ([]*WatchlistItem, error) {
+	return r.GetItemsAsOf(watchlistID, "")
+}
+
+// GetItemsAsOf ウォッチリストの銘柄を指定日以前の直近の調整後終値・前日比・追加日からの騰落率・タグとともにコード順に取得
+// date: YYYY-MM-DD形式（空の場合は最新）
+func (r *WatchlistRepository) GetItemsAsOf(watchlistID int64, date string) ([]*WatchlistItem, error) {
+	dateCondition := ""
+	args := []interface{}{}
+	if date != "" {
+		dateCondition = " AND trade_date <= ?"
+		args = append(args, date)
+	}
+	args = append(args, watchlistID)
+
+	rows, err := r.conn.GetDB().Query(`
+		SELECT
+			wi.watchlist_id, wi.code, COALESCE(li.company_name, ''), wi.created_at,
+			COALESCE((SELECT GROUP_CONCAT(ct.tag ORDER BY ct.tag SEPARATOR ',') FROM code_tags ct WHERE ct.code = wi.code), ''),
+			dq.trade_date, dq.adjustment_close,
+			(
+				SELECT prev.adjustment_close FROM daily_quotes prev
+				WHERE prev.code = wi.code AND prev.trade_date < dq.trade_date AND prev.adjustment_close > 0
+				ORDER BY prev.trade_date DESC
+				LIMIT 1
+			) AS prev_close,
+			(
+				SELECT added.adjustment_close FROM daily_quotes added
+				WHERE added.code = wi.code AND added.trade_date <= DATE(wi.created_at) AND added.adjustment_close > 0
+				ORDER BY added.trade_date DESC
+				LIMIT 1
+			) AS added_close
+		FROM watchlist_items wi
+		LEFT JOIN listed_info li ON li.code = wi.code
+		LEFT JOIN daily_quotes dq
+			ON dq.code = wi.code
+			AND dq.trade_date = (
+				SELECT MAX(trade_date) FROM daily_quotes WHERE code = wi.code AND adjustment_close > 0`+dateCondition+`
+			)
+		WHERE wi.watchlist_id = ?
+		ORDER BY wi.code
+	`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("ウォッチリスト銘柄取得エラー: %v", err)
+	}
+	defer rows.Close()
+
+	var items []*WatchlistItem
+	for rows.Next() {
+		item := &WatchlistItem{}
+		var tags string
+		var tradeDate sql.NullTime
+		var lastClose, prevClose, addedClose sql.NullFloat64
+		err := rows.Scan(
+			&item.WatchlistID,
+			&item.Code,
+			&item.CompanyName,
+			&item.AddedAt,
+			&tags,
+			&tradeDate,
+			&lastClose,
+			&prevClose,
+			&addedClose,
+		)
+		if err != nil {
+			log.Printf("ウォッチリスト銘柄スキャンエラー: %v", err)
+			continue
+		}
+
+		if tags != "" {
+			item.Tags = strings.Split(tags, ",")
+		}
+		if tradeDate.Valid {
+			item.LastTradeDate = &tradeDate.Time
+		}
+		if lastClose.Valid {
+			item.LastClose = &lastClose.Float64
+			if prevClose.Valid {
+				item.DayChangePct = deviationPct(lastClose.Float64, prevClose.Float64)
+			}
+		}
+		if addedClose.Valid {
+			item.AddedClose = &addedClose.Float64
+			if lastClose.Valid {
+				item.SinceAddedPct = deviationPct(lastClose.Float64, addedClose.Float64)
+			}
+		}
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+// GetCodes ウォッチリスト・タグの条件に一致する銘柄コードをコード順に取得
+func (r *WatchlistRepository) GetCodes(filter CodeFilter) ([]string, error) {
+	conditions, args := filter.conditions("li.code")
+	query := "SELECT li.code FROM listed_info li"
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	rows, err := r.conn.GetDB().Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("銘柄コード取得エラー: %v", err)
+	}
+	defer rows.Close()
+
+	var codes []string
+	for rows.Next() {
+		var code string
+		if err := rows.Scan(&code); err != nil {
+			log.Printf("銘柄コードスキャンエラー: %v", err)
+			continue
+		}
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	return codes, nil
+}
+
+// GetWatchlistNames 銘柄コードごとに登録しているウォッチリスト名を名前順に取得（どのウォッチリストにもない銘柄は含まない）
+func (r *WatchlistRepository) GetWatchlistNames(codes []string) (map[string][]string, error) {
+	names := make(map[string][]string)
+	if len(codes) == 0 {
+		return names, nil
+	}
+
+	args := make([]interface{}, len(codes))
+	for i, code := range codes {
+		args[i] = code
+	}
+	rows, err := r.conn.GetDB().Query(`
+		SELECT wi.code, w.name
+		FROM watchlist_items wi
+		JOIN watchlists w ON w.id = wi.watchlist_id
+		WHERE wi.code IN (?`+strings.Repeat(", ?", len(codes)-1)+`)
+		ORDER BY wi.code, w.name
+	`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("ウォッチリスト取得エラー: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var code, name string
+		if err := rows.Scan(&code, &name); err != nil {
+			log.Printf("ウォッチリストスキャンエラー: %v", err)
+			continue
+		}
+		names[code] = append(names[code], name)
+	}
+
+	return names, nil
+}
@@ -0,0 +1,29 @@
+package helper
+
+import "math"
+
+// TradingDaysPerYear 年率換算に使う年間の取引日数
+const TradingDaysPerYear = 245
+
+// MeanStdDev 平均と標本標準偏差（2件未満の場合の標準偏差は0）
+func MeanStdDev(values []float64) (float64, float64) {
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(len(values))
+	if len(values) < 2 {
+		return mean, 0
+	}
+
+	variance := 0.0
+	for _, v := range values {
+		variance += (v - mean) * (v - mean)
+	}
+	return mean, math.Sqrt(variance / float64(len(values)-1))
+}
+
+// Round2 小数点以下2桁に四捨五入
+func Round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -17,3 +17,11 @@ func SubDate(date string, days int) string {
 
 	return start.AddDate(0, 0, -days).Format("2006-01-02")
 }
+
+// NormalizeCode 4桁の銘柄コードをJ-Quantsの5桁形式に変換（例: 7203 -> 72030）
+func NormalizeCode(code string) string {
+	if len(code) == 4 {
+		return code + "0"
+	}
+	return code
+}
@@ -11,6 +11,8 @@ type Client struct {
 	ListedClient      *ListedClient
 	DailyQuotesClient *DailyQuotesClient
 	StatementsClient  *StatementsClient
+	MarketsClient     *MarketsClient
+	IndicesClient     *IndicesClient
 }
 
 // NewClient 新しいクライアントを作成
@@ -18,8 +20,10 @@ func NewClient() *Client {
 	httpClient := &http.Client{}
 	return &Client{
 		AuthClient:        NewAuthClient(baseURL, httpClient),
-		ListedClient:      NewListedClient(baseURL, httpClient),
+		ListedClient:      NewListedClient(baseURL, interval, httpClient),
 		DailyQuotesClient: NewDailyQuotesClient(baseURL, interval, httpClient),
 		StatementsClient:  NewStatementsClient(baseURL, interval, httpClient),
+		MarketsClient:     NewMarketsClient(baseURL, httpClient),
+		IndicesClient:     NewIndicesClient(baseURL, interval, httpClient),
 	}
 }
@@ -0,0 +1,99 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"time"
+
+	"stock-automation/schema"
+)
+
+// IndicesClient 指数関連のAPIクライアント
+type IndicesClient struct {
+	baseURL    string
+	interval   int
+	httpClient *http.Client
+}
+
+// NewIndicesClient 新しい指数クライアントを作成
+func NewIndicesClient(baseURL string, interval int, httpClient *http.Client) *IndicesClient {
+	return &IndicesClient{
+		baseURL:    baseURL,
+		interval:   interval,
+		httpClient: httpClient,
+	}
+}
+
+// GetTopix TOPIX指数四本値を取得
+// from, to: 期間（YYYY-MM-DD形式、空の場合はAPIで取得可能な全期間）
+func (c *IndicesClient) GetTopix(idToken, from, to string) ([]schema.Topix, error) {
+	// パラメータ組み立て
+	params := url.Values{}
+	if from != "" {
+		params.Add("from", from)
+	}
+	if to != "" {
+		params.Add("to", to)
+	}
+
+	var result []schema.Topix
+	for {
+		resp, err := c.requestTopix(idToken, params)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, resp.Topix...)
+
+		if resp.PaginationKey == "" {
+			break
+		}
+
+		params.Set("pagination_key", resp.PaginationKey)
+
+		// PaginationKeyによる繰り返し時にintervalのインターバル
+		if c.interval > 0 {
+			time.Sleep(time.Duration(c.interval) * time.Second)
+		}
+	}
+
+	return result, nil
+}
+
+func (c *IndicesClient) requestTopix(idToken string, params url.Values) (*schema.TopixResponse, error) {
+	// URLの構築
+	requestURL := fmt.Sprintf("%s/indices/topix", c.baseURL)
+	if len(params) > 0 {
+		requestURL += "?" + params.Encode()
+	}
+
+	req, err := http.NewRequest("GET", requestURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+idToken)
+
+	slog.Debug("Topixリクエスト開始", "requestURL", requestURL)
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("ステータスコードエラー: %d, レスポンス: %s", resp.StatusCode, string(body))
+	}
+
+	var result schema.TopixResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+
+	slog.Debug("Topixリクエスト完了", "count", len(result.Topix), "pagination_key", result.PaginationKey)
+	return &result, nil
+}
@@ -7,6 +7,7 @@ import (
 	"log/slog"
 	"net/http"
 	"net/url"
+	"time"
 
 	"stock-automation/schema"
 )
@@ -14,31 +15,52 @@ import (
 // ListedClient 上場銘柄関連のAPIクライアント
 type ListedClient struct {
 	baseURL    string
+	interval   int
 	httpClient *http.Client
 }
 
 // NewListedClient 新しい上場銘柄クライアントを作成
-func NewListedClient(baseURL string, httpClient *http.Client) *ListedClient {
+func NewListedClient(baseURL string, interval int, httpClient *http.Client) *ListedClient {
 	return &ListedClient{
 		baseURL:    baseURL,
+		interval:   interval,
 		httpClient: httpClient,
 	}
 }
 
 // GetListedInfo 上場銘柄一覧を取得
-func (c *ListedClient) GetListedInfo(idToken, date string) ([]schema.ListedInfo, error) {
+func (c *ListedClient) GetListedInfo(idToken, code, date string) ([]schema.ListedInfo, error) {
 	// パラメータ組み立て
 	params := url.Values{}
+	if code != "" {
+		params.Add("code", code)
+	}
 	if date != "" {
 		params.Add("date", date)
 	}
 
-	resp, err := c.requestListedInfo(idToken, params)
-	if err != nil {
-		return nil, err
+	var result []schema.ListedInfo
+	for {
+		resp, err := c.requestListedInfo(idToken, params)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, resp.Info...)
+
+		if resp.PaginationKey == "" {
+			break
+		}
+
+		params.Set("pagination_key", resp.PaginationKey)
+
+		// PaginationKeyによる繰り返し時にintervalのインターバル
+		if c.interval > 0 {
+			time.Sleep(time.Duration(c.interval) * time.Second)
+		}
 	}
 
-	return resp.Info, nil
+	return result, nil
 }
 
 func (c *ListedClient) requestListedInfo(idToken string, params url.Values) (*schema.ListedInfoResponse, error) {
@@ -71,6 +93,6 @@ func (c *ListedClient) requestListedInfo(idToken string, params url.Values) (*sc
 		return nil, err
 	}
 
-	slog.Debug("ListedInfoリクエスト完了", "count", len(result.Info))
+	slog.Debug("ListedInfoリクエスト完了", "count", len(result.Info), "pagination_key", result.PaginationKey)
 	return &result, nil
 }
@@ -0,0 +1,71 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+
+	"stock-automation/schema"
+)
+
+// MarketsClient 市場情報関連のAPIクライアント
+type MarketsClient struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewMarketsClient 新しい市場情報クライアントを作成
+func NewMarketsClient(baseURL string, httpClient *http.Client) *MarketsClient {
+	return &MarketsClient{
+		baseURL:    baseURL,
+		httpClient: httpClient,
+	}
+}
+
+// GetTradingCalendar 取引カレンダーを取得
+// from, to: 期間（YYYY-MM-DD形式、空の場合はAPIの既定の期間）
+func (c *MarketsClient) GetTradingCalendar(idToken, from, to string) ([]schema.TradingCalendar, error) {
+	// パラメータ組み立て
+	params := url.Values{}
+	if from != "" {
+		params.Add("from", from)
+	}
+	if to != "" {
+		params.Add("to", to)
+	}
+
+	// URLの構築
+	requestURL := fmt.Sprintf("%s/markets/trading_calendar", c.baseURL)
+	if len(params) > 0 {
+		requestURL += "?" + params.Encode()
+	}
+
+	req, err := http.NewRequest("GET", requestURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+idToken)
+
+	slog.Debug("TradingCalendarリクエスト開始", "requestURL", requestURL)
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("ステータスコードエラー: %d, レスポンス: %s", resp.StatusCode, string(body))
+	}
+
+	var result schema.TradingCalendarResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+
+	slog.Debug("TradingCalendarリクエスト完了", "count", len(result.TradingCalendar))
+	return result.TradingCalendar, nil
+}
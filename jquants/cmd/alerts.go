@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"stock-automation/jquants/service"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	alertsDate string
+)
+
+var AlertsCmd = &cobra.Command{
+	Use:   "alerts",
+	Short: "アラート評価・通知",
+	Long:  "sa alertで登録した有効なルールを評価し、条件に一致した銘柄をALERT_NOTIFIERSの送信先へ通知します（同じルール・銘柄・取引日の通知は1回のみ）",
+	RunE:  evaluateAlerts,
+}
+
+func init() {
+	// フラグを追加
+	AlertsCmd.Flags().StringVarP(&alertsDate, "date", "d", "", "評価する取引日（YYYY-MM-DD形式、指定しない場合はdaily_quotesの最新取引日）")
+}
+
+func evaluateAlerts(cmd *cobra.Command, args []string) error {
+	// グローバルフラグからverboseの値を取得
+	verbose, _ := cmd.Root().PersistentFlags().GetBool("verbose")
+
+	service, err := service.NewAlertService(verbose)
+	if err != nil {
+		return fmt.Errorf("アラート評価サービス初期化エラー: %v", err)
+	}
+	defer service.Close()
+
+	slog.Info("アラート評価開始", "date", alertsDate)
+	err = service.EvaluateAlerts(alertsDate)
+	if err != nil {
+		slog.Error("アラート評価エラー", "error", err)
+		return fmt.Errorf("アラート評価エラー: %v", err)
+	}
+	slog.Info("アラート評価完了")
+
+	return nil
+}
@@ -18,7 +18,7 @@ var (
 var DailyCmd = &cobra.Command{
 	Use:   "daily",
 	Short: "日次データ一括更新",
-	Long:  "上場銘柄一覧→日次株価四本値→財務情報の順で一括更新します",
+	Long:  "上場銘柄一覧→日次株価四本値→財務情報→株価指標→テクニカル指標→期間別評価指標→TOPIXの順で一括更新し、最後にアラートを評価・通知します",
 	RunE:  updateDaily,
 }
 
@@ -43,7 +43,7 @@ func updateDaily(cmd *cobra.Command, args []string) error {
 	}
 	defer listedInfoService.Close()
 
-	err = listedInfoService.UpdateListedInfo(dailyDate)
+	err = listedInfoService.UpdateListedInfo("", dailyDate)
 	if err != nil {
 		slog.Error("上場銘柄情報データ更新エラー", "error", err)
 		return fmt.Errorf("上場銘柄情報データ更新エラー: %v", err)
@@ -80,6 +80,16 @@ func updateDaily(cmd *cobra.Command, args []string) error {
 	}
 	defer statementsService.Close()
 
+	// ウォッチリストの銘柄の新しい開示を通知（通知先の設定に誤りがあっても財務情報の更新は続行）
+	// 複数日の一括取得で大量に通知しないよう、1日分の更新の場合のみ通知する
+	if dailyCount <= 1 {
+		if err := statementsService.EnableDisclosureNotifications(); err != nil {
+			slog.Warn("新規開示通知設定エラー（通知なしで続行）", "error", err)
+		}
+	} else {
+		slog.Info("複数日の取得のため新規開示の通知をスキップ", "count", dailyCount)
+	}
+
 	err = statementsService.UpdateStatementsWithCount("", dailyDate, dailyCount)
 	if err != nil {
 		slog.Error("財務情報データ更新エラー", "error", err)
@@ -87,6 +97,80 @@ func updateDaily(cmd *cobra.Command, args []string) error {
 	}
 	slog.Info("財務情報更新完了")
 
+	// 4. 株価指標の更新（取得した期間の株価と開示済みの財務情報から算出）
+	slog.Info("4. 株価指標更新開始")
+	valuationService, err := service.NewValuationService(verbose)
+	if err != nil {
+		return fmt.Errorf("株価指標サービス初期化エラー: %v", err)
+	}
+	defer valuationService.Close()
+
+	err = valuationService.UpdateValuations(dailyDate, dailyCount)
+	if err != nil {
+		slog.Error("株価指標データ更新エラー", "error", err)
+		return fmt.Errorf("株価指標データ更新エラー: %v", err)
+	}
+	slog.Info("株価指標更新完了")
+
+	// 5. テクニカル指標の更新（保存済みの最終取引日より後の日のみ追加）
+	slog.Info("5. テクニカル指標更新開始")
+	indicatorService, err := service.NewIndicatorService(verbose)
+	if err != nil {
+		return fmt.Errorf("テクニカル指標サービス初期化エラー: %v", err)
+	}
+	defer indicatorService.Close()
+
+	err = indicatorService.UpdateIndicators()
+	if err != nil {
+		slog.Error("テクニカル指標データ更新エラー", "error", err)
+		return fmt.Errorf("テクニカル指標データ更新エラー: %v", err)
+	}
+	slog.Info("テクニカル指標更新完了")
+
+	// 6. 期間別評価指標の更新（最終取引日時点の最高・最低調整終値と乖離率）
+	slog.Info("6. 期間別評価指標更新開始")
+	assessmentService, err := service.NewAssessmentService(verbose)
+	if err != nil {
+		return fmt.Errorf("銘柄評価サービス初期化エラー: %v", err)
+	}
+	defer assessmentService.Close()
+
+	err = assessmentService.UpdateWindowMetrics()
+	if err != nil {
+		slog.Error("期間別評価指標データ更新エラー", "error", err)
+		return fmt.Errorf("期間別評価指標データ更新エラー: %v", err)
+	}
+	slog.Info("期間別評価指標更新完了")
+
+	// 7. TOPIXの更新（保存済みの最終取引日以降。指数データが提供されないプランの場合もあるため、失敗しても続行）
+	slog.Info("7. TOPIX更新開始")
+	topixService, err := service.NewTopixService(verbose)
+	if err != nil {
+		return fmt.Errorf("TOPIXサービス初期化エラー: %v", err)
+	}
+	defer topixService.Close()
+
+	if err := topixService.UpdateTopixFromLatest(); err != nil {
+		slog.Warn("TOPIXデータ更新エラー（スキップ）", "error", err)
+	} else {
+		slog.Info("TOPIX更新完了")
+	}
+
+	// 8. アラートの評価・通知（更新したデータに対して評価。通知先の障害でデータ更新を失敗扱いにしないため、失敗しても続行）
+	slog.Info("8. アラート評価開始")
+	alertService, err := service.NewAlertService(verbose)
+	if err != nil {
+		slog.Warn("アラート評価サービス初期化エラー（スキップ）", "error", err)
+	} else {
+		defer alertService.Close()
+
+		if err := alertService.EvaluateAlerts(dailyDate); err != nil {
+			slog.Warn("アラート評価エラー（スキップ）", "error", err)
+		} else {
+			slog.Info("アラート評価完了")
+		}
+	}
+
 	slog.Info("日次データ一括更新完了")
 	return nil
 }
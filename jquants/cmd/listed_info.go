@@ -9,6 +9,7 @@ import (
 )
 
 var (
+	listedInfoCode string
 	listedInfoDate string
 )
 
@@ -21,6 +22,7 @@ var ListedInfoCmd = &cobra.Command{
 
 func init() {
 	// フラグを追加
+	ListedInfoCmd.Flags().StringVar(&listedInfoCode, "code", "", "銘柄コード（指定しない場合は全銘柄）")
 	ListedInfoCmd.Flags().StringVarP(&listedInfoDate, "date", "d", "", "日付（YYYY-MM-DD形式、指定しない場合はAPIの最新日付）")
 }
 
@@ -34,9 +36,9 @@ func updateListedInfo(cmd *cobra.Command, args []string) error {
 	}
 	defer service.Close()
 
-	// 対象日付の全銘柄データを更新
-	slog.Info("上場銘柄情報更新開始", "date", listedInfoDate)
-	err = service.UpdateListedInfo(listedInfoDate)
+	// 対象日付の銘柄データを更新（code未指定の場合は全銘柄）
+	slog.Info("上場銘柄情報更新開始", "code", listedInfoCode, "date", listedInfoDate)
+	err = service.UpdateListedInfo(listedInfoCode, listedInfoDate)
 	if err != nil {
 		slog.Error("上場銘柄情報データ更新エラー", "error", err)
 		return fmt.Errorf("上場銘柄情報データ更新エラー: %v", err)
@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"stock-automation/helper"
+	"stock-automation/jquants/service"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	snapshotsFrom     string
+	snapshotsTo       string
+	snapshotsStep     int
+	snapshotsForce    bool
+	snapshotsInterval int
+)
+
+var ListedInfoSnapshotsCmd = &cobra.Command{
+	Use:   "listed_info_snapshots",
+	Short: "過去の上場銘柄一覧スナップショット取得",
+	Long:  "期間内の日付ごとにJ-Quantsの上場銘柄一覧を取得して、適用日ごとのスナップショットとしてDBへ保存する機能を提供します（バックテストのユニバースに使用）",
+	RunE:  updateListedInfoSnapshots,
+}
+
+func init() {
+	// フラグを追加
+	ListedInfoSnapshotsCmd.Flags().StringVar(&snapshotsFrom, "from", "", "開始日（YYYY-MM-DD形式、必須）")
+	ListedInfoSnapshotsCmd.Flags().StringVar(&snapshotsTo, "to", "", "終了日（YYYY-MM-DD形式、指定しない場合は当日）")
+	ListedInfoSnapshotsCmd.Flags().IntVar(&snapshotsStep, "step", 7, "取得間隔（日数、デフォルト: 7）")
+	ListedInfoSnapshotsCmd.Flags().BoolVar(&snapshotsForce, "force", false, "保存済みの日付も再取得する")
+	ListedInfoSnapshotsCmd.Flags().IntVar(&snapshotsInterval, "interval", 5, "インターバル（秒、デフォルト: 5）")
+	ListedInfoSnapshotsCmd.MarkFlagRequired("from")
+}
+
+func updateListedInfoSnapshots(cmd *cobra.Command, args []string) error {
+	// グローバルフラグからverboseの値を取得
+	verbose, _ := cmd.Root().PersistentFlags().GetBool("verbose")
+
+	service, err := service.NewListedInfoService(verbose)
+	if err != nil {
+		return fmt.Errorf("上場銘柄情報サービス初期化エラー: %v", err)
+	}
+	defer service.Close()
+
+	to := snapshotsTo
+	if to == "" {
+		to = helper.GetTodayDate()
+	}
+
+	slog.Info("上場銘柄一覧スナップショット更新開始", "from", snapshotsFrom, "to", to, "step", snapshotsStep)
+	err = service.UpdateListedInfoSnapshots(snapshotsFrom, to, snapshotsStep, snapshotsForce, snapshotsInterval)
+	if err != nil {
+		slog.Error("上場銘柄一覧スナップショット更新エラー", "error", err)
+		return fmt.Errorf("上場銘柄一覧スナップショット更新エラー: %v", err)
+	}
+
+	return nil
+}
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"fmt"
+	"log/slog"
 	"stock-automation/jquants/service"
 
 	"github.com/spf13/cobra"
@@ -12,6 +13,7 @@ var (
 	statementsDate     string
 	statementsCount    int
 	statementsInterval int
+	statementsNotify   bool
 )
 
 var StatementsCmd = &cobra.Command{
@@ -27,6 +29,7 @@ func init() {
 	StatementsCmd.Flags().StringVar(&statementsDate, "date", "", "日付（YYYY-MM-DD形式、codeともに指定しない場合は当日）")
 	StatementsCmd.Flags().IntVar(&statementsCount, "count", 1, "取得する日数（指定した日付からさかのぼる日数、デフォルト: 1）")
 	StatementsCmd.Flags().IntVar(&statementsInterval, "interval", 5, "インターバル（秒、デフォルト: 5）")
+	StatementsCmd.Flags().BoolVar(&statementsNotify, "notify", false, "ウォッチリストの銘柄の新しい開示を通知（過去分の一括取得で大量に通知しないよう、指定した場合のみ）")
 }
 
 func updateStatements(cmd *cobra.Command, args []string) error {
@@ -39,6 +42,13 @@ func updateStatements(cmd *cobra.Command, args []string) error {
 	}
 	defer service.Close()
 
+	if statementsNotify {
+		if err := service.EnableDisclosureNotifications(); err != nil {
+			return fmt.Errorf("新規開示通知設定エラー: %v", err)
+		}
+		slog.Debug("新規開示通知を有効化")
+	}
+
 	err = service.UpdateStatementsWithCount(statementsCode, statementsDate, statementsCount)
 	if err != nil {
 		return fmt.Errorf("財務情報データ更新エラー: %v", err)
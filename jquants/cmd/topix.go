@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"stock-automation/jquants/service"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	topixFrom string
+	topixTo   string
+)
+
+var TopixCmd = &cobra.Command{
+	Use:   "topix",
+	Short: "TOPIX取得",
+	Long:  "J-QuantsのTOPIX指数四本値を取得して、DBへ保存する機能を提供します（ご利用のプランで指数データが提供されている場合のみ）",
+	RunE:  updateTopix,
+}
+
+func init() {
+	// フラグを追加
+	TopixCmd.Flags().StringVar(&topixFrom, "from", "", "開始日（YYYY-MM-DD形式、指定しない場合はAPIで取得可能な全期間）")
+	TopixCmd.Flags().StringVar(&topixTo, "to", "", "終了日（YYYY-MM-DD形式、指定しない場合はAPIで取得可能な全期間）")
+}
+
+func updateTopix(cmd *cobra.Command, args []string) error {
+	// グローバルフラグからverboseの値を取得
+	verbose, _ := cmd.Root().PersistentFlags().GetBool("verbose")
+
+	service, err := service.NewTopixService(verbose)
+	if err != nil {
+		return fmt.Errorf("TOPIXサービス初期化エラー: %v", err)
+	}
+	defer service.Close()
+
+	slog.Info("TOPIX更新開始", "from", topixFrom, "to", topixTo)
+	err = service.UpdateTopix(topixFrom, topixTo)
+	if err != nil {
+		slog.Error("TOPIXデータ更新エラー", "error", err)
+		return fmt.Errorf("TOPIXデータ更新エラー: %v", err)
+	}
+	slog.Info("TOPIXデータ更新完了")
+
+	return nil
+}
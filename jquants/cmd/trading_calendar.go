@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"stock-automation/jquants/service"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	tradingCalendarFrom string
+	tradingCalendarTo   string
+)
+
+var TradingCalendarCmd = &cobra.Command{
+	Use:   "trading_calendar",
+	Short: "取引カレンダー取得",
+	Long:  "J-Quantsの取引カレンダー（東証の営業日・休業日）を取得して、DBへ保存する機能を提供します",
+	RunE:  updateTradingCalendar,
+}
+
+func init() {
+	// フラグを追加
+	TradingCalendarCmd.Flags().StringVar(&tradingCalendarFrom, "from", "", "開始日（YYYY-MM-DD形式、指定しない場合はAPIで取得可能な全期間）")
+	TradingCalendarCmd.Flags().StringVar(&tradingCalendarTo, "to", "", "終了日（YYYY-MM-DD形式、指定しない場合はAPIで取得可能な全期間）")
+}
+
+func updateTradingCalendar(cmd *cobra.Command, args []string) error {
+	// グローバルフラグからverboseの値を取得
+	verbose, _ := cmd.Root().PersistentFlags().GetBool("verbose")
+
+	service, err := service.NewTradingCalendarService(verbose)
+	if err != nil {
+		return fmt.Errorf("取引カレンダーサービス初期化エラー: %v", err)
+	}
+	defer service.Close()
+
+	slog.Info("取引カレンダー更新開始", "from", tradingCalendarFrom, "to", tradingCalendarTo)
+	err = service.UpdateTradingCalendar(tradingCalendarFrom, tradingCalendarTo)
+	if err != nil {
+		slog.Error("取引カレンダーデータ更新エラー", "error", err)
+		return fmt.Errorf("取引カレンダーデータ更新エラー: %v", err)
+	}
+	slog.Info("取引カレンダーデータ更新完了")
+
+	return nil
+}
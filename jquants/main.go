@@ -47,4 +47,8 @@ func init() {
 	rootCmd.AddCommand(cmd.DailyQuotesCmd)
 	rootCmd.AddCommand(cmd.StatementsCmd)
 	rootCmd.AddCommand(cmd.ListedInfoCmd)
+	rootCmd.AddCommand(cmd.ListedInfoSnapshotsCmd)
+	rootCmd.AddCommand(cmd.TradingCalendarCmd)
+	rootCmd.AddCommand(cmd.TopixCmd)
+	rootCmd.AddCommand(cmd.AlertsCmd)
 }
@@ -0,0 +1,67 @@
+package notify
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// defaultAlertFile ALERT_FILEが未設定の場合の出力先
+const defaultAlertFile = "alerts.log"
+
+// WriterNotifier io.Writerに通知を書き出す
+type WriterNotifier struct {
+	name string
+	w    io.Writer
+}
+
+// NewWriterNotifier 指定したio.Writerに書き出す送信先を作成
+func NewWriterNotifier(name string, w io.Writer) *WriterNotifier {
+	return &WriterNotifier{name: name, w: w}
+}
+
+// NewStdoutNotifier 標準出力に書き出す送信先を作成
+func NewStdoutNotifier() *WriterNotifier {
+	return NewWriterNotifier("stdout", os.Stdout)
+}
+
+// Name 送信先の名前
+func (n *WriterNotifier) Name() string {
+	return n.name
+}
+
+// Notify 件名と本文を書き出す
+func (n *WriterNotifier) Notify(msg *Message) error {
+	_, err := fmt.Fprintf(n.w, "%s\n%s\n", msg.Subject, msg.Body)
+	return err
+}
+
+// FileNotifier ファイルに通知を追記する
+type FileNotifier struct {
+	path string
+}
+
+// NewFileNotifier 指定したファイルに追記する送信先を作成
+func NewFileNotifier(path string) *FileNotifier {
+	return &FileNotifier{path: path}
+}
+
+// Name 送信先の名前
+func (n *FileNotifier) Name() string {
+	return "file"
+}
+
+// Notify 送信日時・件名・本文をファイルに追記
+func (n *FileNotifier) Notify(msg *Message) error {
+	f, err := os.OpenFile(n.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("ファイルオープンエラー: %v", err)
+	}
+	defer f.Close()
+
+	if _, err := fmt.Fprintf(f, "%s %s\n%s\n\n", time.Now().Format("2006-01-02 15:04:05"), msg.Subject, msg.Body); err != nil {
+		return fmt.Errorf("ファイル書き込みエラー: %v", err)
+	}
+	return nil
+}
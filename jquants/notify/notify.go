@@ -0,0 +1,127 @@
+package notify
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Message 通知内容
+type Message struct {
+	Subject string
+	Body    string
+}
+
+// Notifier 通知の送信先
+type Notifier interface {
+	// Name 送信先の名前（ログ出力用）
+	Name() string
+	// Notify 通知を送信
+	Notify(msg *Message) error
+}
+
+// MultiNotifier 複数の送信先にまとめて通知する
+type MultiNotifier []Notifier
+
+// Name 送信先の名前をカンマ区切りで返す
+func (m MultiNotifier) Name() string {
+	names := make([]string, len(m))
+	for i, n := range m {
+		names[i] = n.Name()
+	}
+	return strings.Join(names, ",")
+}
+
+// Notify 全ての送信先に通知を送信（失敗した送信先があっても残りの送信先には送信し、全て失敗した場合のみエラーを返す）
+func (m MultiNotifier) Notify(msg *Message) error {
+	var errs []error
+	for _, n := range m {
+		if err := n.Notify(msg); err != nil {
+			slog.Error("通知送信エラー", "notifier", n.Name(), "error", err)
+			errs = append(errs, fmt.Errorf("%s: %v", n.Name(), err))
+		}
+	}
+	if len(m) > 0 && len(errs) == len(m) {
+		return errors.Join(errs...)
+	}
+	return nil
+}
+
+// defaultNotifiers 送信先の設定がない場合の標準の送信先
+const defaultNotifiers = "stdout"
+
+// NewNotifierFromEnv 環境変数ALERT_NOTIFIERS（カンマ区切り: stdout, file, webhook, smtp）から送信先を作成（未設定の場合はstdout）
+func NewNotifierFromEnv() (MultiNotifier, error) {
+	value := os.Getenv("ALERT_NOTIFIERS")
+	if value == "" {
+		value = defaultNotifiers
+	}
+
+	var notifiers MultiNotifier
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(value, ",") {
+		name := strings.ToLower(strings.TrimSpace(part))
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		var notifier Notifier
+		switch name {
+		case "stdout":
+			notifier = NewStdoutNotifier()
+		case "file":
+			path := os.Getenv("ALERT_FILE")
+			if path == "" {
+				path = defaultAlertFile
+			}
+			notifier = NewFileNotifier(path)
+		case "webhook":
+			url := os.Getenv("ALERT_WEBHOOK_URL")
+			if url == "" {
+				return nil, fmt.Errorf("ALERT_WEBHOOK_URLが設定されていません")
+			}
+			notifier = NewWebhookNotifier(url)
+		case "smtp":
+			smtpNotifier, err := newSMTPNotifierFromEnv()
+			if err != nil {
+				return nil, err
+			}
+			notifier = smtpNotifier
+		default:
+			return nil, fmt.Errorf("ALERT_NOTIFIERSの値が無効です（stdout, file, webhook, smtp）: '%s'", part)
+		}
+		notifiers = append(notifiers, notifier)
+	}
+
+	return notifiers, nil
+}
+
+// newSMTPNotifierFromEnv 環境変数ALERT_SMTP_*からSMTPの送信先を作成
+func newSMTPNotifierFromEnv() (*SMTPNotifier, error) {
+	host := os.Getenv("ALERT_SMTP_HOST")
+	from := os.Getenv("ALERT_SMTP_FROM")
+	var to []string
+	for _, addr := range strings.Split(os.Getenv("ALERT_SMTP_TO"), ",") {
+		if addr = strings.TrimSpace(addr); addr != "" {
+			to = append(to, addr)
+		}
+	}
+	if host == "" || from == "" || len(to) == 0 {
+		return nil, fmt.Errorf("ALERT_SMTP_HOST, ALERT_SMTP_FROM, ALERT_SMTP_TOを設定してください")
+	}
+
+	port := defaultSMTPPort
+	if value := os.Getenv("ALERT_SMTP_PORT"); value != "" {
+		p, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("ALERT_SMTP_PORTの値が無効です: '%s'", value)
+		}
+		port = p
+	}
+
+	return NewSMTPNotifier(host, port, os.Getenv("ALERT_SMTP_USER"), os.Getenv("ALERT_SMTP_PASSWORD"), from, to), nil
+}
@@ -0,0 +1,79 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultSMTPPort ALERT_SMTP_PORTが未設定の場合のポート（STARTTLSのサブミッションポート）
+const defaultSMTPPort = 587
+
+// SMTPNotifier メールで通知する
+type SMTPNotifier struct {
+	addr     string
+	host     string
+	username string
+	password string
+	from     string
+	to       []string
+}
+
+// NewSMTPNotifier 指定したSMTPサーバーから送信する送信先を作成（usernameが空の場合は認証しない）
+func NewSMTPNotifier(host string, port int, username, password, from string, to []string) *SMTPNotifier {
+	return &SMTPNotifier{
+		addr:     net.JoinHostPort(host, strconv.Itoa(port)),
+		host:     host,
+		username: username,
+		password: password,
+		from:     from,
+		to:       to,
+	}
+}
+
+// Name 送信先の名前
+func (n *SMTPNotifier) Name() string {
+	return "smtp"
+}
+
+// Notify 件名・本文をUTF-8のテキストメールで送信（サーバーが対応している場合はSTARTTLSを使用）
+func (n *SMTPNotifier) Notify(msg *Message) error {
+	var auth smtp.Auth
+	if n.username != "" {
+		auth = smtp.PlainAuth("", n.username, n.password, n.host)
+	}
+
+	if err := smtp.SendMail(n.addr, auth, n.from, n.to, n.buildMail(msg)); err != nil {
+		return fmt.Errorf("メール送信エラー: %v", err)
+	}
+	return nil
+}
+
+// buildMail メールのヘッダーと本文を作成（件名はMIMEエンコード、本文はBase64）
+func (n *SMTPNotifier) buildMail(msg *Message) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\n", n.from)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(n.to, ", "))
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.BEncoding.Encode("UTF-8", msg.Subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: base64\r\n")
+	b.WriteString("\r\n")
+
+	// 1行76文字で折り返す
+	encoded := base64.StdEncoding.EncodeToString([]byte(msg.Body))
+	for len(encoded) > 76 {
+		b.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+	b.WriteString(encoded + "\r\n")
+
+	return b.Bytes()
+}
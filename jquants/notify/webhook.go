@@ -0,0 +1,53 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// WebhookNotifier 指定したURLに通知をJSONでPOSTする
+type WebhookNotifier struct {
+	url        string
+	httpClient *http.Client
+}
+
+// webhookPayload POSTするJSON（textはSlack・Discordなどの受信Webhookでそのまま表示できる形式）
+type webhookPayload struct {
+	Subject string `json:"subject"`
+	Body    string `json:"body"`
+	Text    string `json:"text"`
+}
+
+// NewWebhookNotifier 指定したURLにPOSTする送信先を作成
+func NewWebhookNotifier(url string) *WebhookNotifier {
+	return &WebhookNotifier{url: url, httpClient: &http.Client{Timeout: 30 * time.Second}}
+}
+
+// Name 送信先の名前
+func (n *WebhookNotifier) Name() string {
+	return "webhook"
+}
+
+// Notify 件名・本文をJSONでPOST（2xx以外のステータスはエラー）
+func (n *WebhookNotifier) Notify(msg *Message) error {
+	payload, err := json.Marshal(webhookPayload{Subject: msg.Subject, Body: msg.Body, Text: msg.Subject + "\n" + msg.Body})
+	if err != nil {
+		return fmt.Errorf("JSON変換エラー: %v", err)
+	}
+
+	resp, err := n.httpClient.Post(n.url, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("Webhook送信エラー: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("Webhook送信エラー: ステータス %d: %s", resp.StatusCode, string(body))
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package service
+
+import (
+	"fmt"
+	"log/slog"
+	"stock-automation/database"
+	"stock-automation/jquants/notify"
+	"strings"
+	"time"
+)
+
+// AlertService アラート評価サービスクラス
+type AlertService struct {
+	dbConn     *database.Connection
+	repository *database.AlertRepository
+	notifier   notify.Notifier
+}
+
+// NewAlertService 新しいアラート評価サービスを作成（通知先は環境変数ALERT_NOTIFIERSから作成）
+func NewAlertService(verbose bool) (*AlertService, error) {
+	notifier, err := notify.NewNotifierFromEnv()
+	if err != nil {
+		return nil, fmt.Errorf("通知先設定エラー: %v", err)
+	}
+
+	// データベース接続を作成
+	dbConn, err := database.NewConnectionFromEnv(verbose)
+	if err != nil {
+		return nil, fmt.Errorf("データベース接続エラー: %v", err)
+	}
+
+	return &AlertService{
+		dbConn:     dbConn,
+		repository: database.NewAlertRepository(dbConn),
+		notifier:   notifier,
+	}, nil
+}
+
+// EvaluateAlerts 有効なルールを評価して発生履歴に保存し、未通知の発生履歴を通知
+// date: 評価する取引日（YYYY-MM-DD形式、空の場合はdaily_quotesの最新取引日）
+func (s *AlertService) EvaluateAlerts(date string) error {
+	tradeDate, err := s.resolveTradeDate(date)
+	if err != nil {
+		return err
+	}
+	if tradeDate == nil {
+		slog.Info("株価データがないため、アラートを評価しません")
+		return nil
+	}
+
+	rules, err := s.repository.GetRules(true)
+	if err != nil {
+		return err
+	}
+
+	for _, rule := range rules {
+		events, err := s.repository.Evaluate(rule, *tradeDate)
+		if err != nil {
+			// 1件のルールの誤りで他のルールの評価を止めない
+			slog.Error("アラート評価エラー", "rule", rule.Name, "error", err)
+			continue
+		}
+		added, err := s.repository.SaveEvents(events)
+		if err != nil {
+			return err
+		}
+		slog.Debug("アラート評価完了", "rule", rule.Name, "matched", len(events), "new", added)
+	}
+
+	// 前回の通知に失敗した発生履歴も含めて通知する
+	pending, err := s.repository.GetPendingEvents()
+	if err != nil {
+		return err
+	}
+	if len(pending) == 0 {
+		slog.Info("新たなアラートはありません", "date", tradeDate.Format("2006-01-02"), "rules", len(rules))
+		return nil
+	}
+
+	if err := s.notifier.Notify(buildAlertMessage(pending)); err != nil {
+		return fmt.Errorf("アラート通知エラー: %v", err)
+	}
+	if err := s.repository.MarkNotified(pending); err != nil {
+		return err
+	}
+	slog.Info("アラート通知完了", "date", tradeDate.Format("2006-01-02"), "count", len(pending), "notifier", s.notifier.Name())
+
+	return nil
+}
+
+// resolveTradeDate 評価する取引日を決定
+func (s *AlertService) resolveTradeDate(date string) (*time.Time, error) {
+	if date == "" {
+		return s.repository.GetLatestTradeDate()
+	}
+	t, err := time.ParseInLocation("2006-01-02", date, time.Local)
+	if err != nil {
+		return nil, fmt.Errorf("日付の形式が正しくありません（YYYY-MM-DD）: %s", date)
+	}
+	return &t, nil
+}
+
+// buildAlertMessage 発生履歴を取引日ごとにまとめた通知内容を作成
+func buildAlertMessage(events []*database.AlertEvent) *notify.Message {
+	var b strings.Builder
+	var current string
+	for _, e := range events {
+		date := e.TradeDate.Format("2006-01-02")
+		if date != current {
+			if current != "" {
+				b.WriteString("\n")
+			}
+			fmt.Fprintf(&b, "%s\n", date)
+			current = date
+		}
+		fmt.Fprintf(&b, "  %s\n", e.Message())
+	}
+
+	return &notify.Message{
+		Subject: fmt.Sprintf("株価アラート %d件（%s）", len(events), current),
+		Body:    strings.TrimRight(b.String(), "\n"),
+	}
+}
+
+// Close データベース接続を閉じる
+func (s *AlertService) Close() error {
+	if s.dbConn != nil {
+		return s.dbConn.Close()
+	}
+	return nil
+}
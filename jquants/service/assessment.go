@@ -0,0 +1,58 @@
+package service
+
+import (
+	"fmt"
+	"log/slog"
+	"stock-automation/database"
+)
+
+// AssessmentService 銘柄評価サービスクラス
+type AssessmentService struct {
+	dbConn     *database.Connection
+	repository *database.AssessmentWindowRepository
+	windows    []database.AssessmentWindow
+}
+
+// NewAssessmentService 新しい銘柄評価サービスを作成
+func NewAssessmentService(verbose bool) (*AssessmentService, error) {
+	// 期間を環境変数から読み込む
+	windows, err := database.NewAssessmentWindowsFromEnv()
+	if err != nil {
+		return nil, fmt.Errorf("銘柄評価設定エラー: %v", err)
+	}
+
+	// データベース接続を作成
+	dbConn, err := database.NewConnectionFromEnv(verbose)
+	if err != nil {
+		return nil, fmt.Errorf("データベース接続エラー: %v", err)
+	}
+
+	return &AssessmentService{
+		dbConn:     dbConn,
+		repository: database.NewAssessmentWindowRepository(dbConn),
+		windows:    windows,
+	}, nil
+}
+
+// UpdateWindowMetrics 全銘柄の期間別評価指標を最終取引日時点で作成
+func (s *AssessmentService) UpdateWindowMetrics() error {
+	if err := s.repository.BuildWindowMetrics(nil, s.windows); err != nil {
+		return fmt.Errorf("期間別評価指標作成エラー: %v", err)
+	}
+
+	labels := make([]string, len(s.windows))
+	for i, window := range s.windows {
+		labels[i] = window.Label
+	}
+	slog.Info("期間別評価指標作成完了", "windows", labels)
+
+	return nil
+}
+
+// Close データベース接続を閉じる
+func (s *AssessmentService) Close() error {
+	if s.dbConn != nil {
+		return s.dbConn.Close()
+	}
+	return nil
+}
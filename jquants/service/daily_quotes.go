@@ -6,15 +6,18 @@ import (
 	"stock-automation/database"
 	"stock-automation/helper"
 	"stock-automation/jquants/api"
+	"stock-automation/schema"
 	"time"
 )
 
 // DailyQuotesService 日次株価四本値サービスクラス
 type DailyQuotesService struct {
-	client     *api.Client
-	dbConn     *database.Connection
-	repository *database.DailyQuotesRepository
-	interval   int // インターバル（秒）
+	client                    *api.Client
+	dbConn                    *database.Connection
+	repository                *database.DailyQuotesRepository
+	corporateActionRepository *database.CorporateActionRepository
+	dividendRepository        *database.DividendHistoryRepository
+	interval                  int // インターバル（秒）
 }
 
 // NewDailyQuotesService 新しい日次株価四本値サービスを作成
@@ -29,10 +32,12 @@ func NewDailyQuotesService(interval int, verbose bool) (*DailyQuotesService, err
 	repository := database.NewDailyQuotesRepository(dbConn)
 
 	return &DailyQuotesService{
-		client:     api.NewClient(),
-		dbConn:     dbConn,
-		repository: repository,
-		interval:   interval,
+		client:                    api.NewClient(),
+		dbConn:                    dbConn,
+		repository:                repository,
+		corporateActionRepository: database.NewCorporateActionRepository(dbConn),
+		dividendRepository:        database.NewDividendHistoryRepository(dbConn),
+		interval:                  interval,
 	}, nil
 }
 
@@ -61,6 +66,10 @@ func (s *DailyQuotesService) UpdateDailyQuotes(code, date string) error {
 			return fmt.Errorf("データベース保存エラー: %v", err)
 		}
 		slog.Info("株価データ保存完了", "code", code, "date", date, "count", len(quotes))
+
+		if err := s.updateCorporateActions(quotes); err != nil {
+			return fmt.Errorf("コーポレートアクション更新エラー: %v", err)
+		}
 	} else {
 		slog.Info("取得したデータがありません", "code", code, "date", date)
 	}
@@ -68,6 +77,32 @@ func (s *DailyQuotesService) UpdateDailyQuotes(code, date string) error {
 	return nil
 }
 
+// updateCorporateActions 保存した四本値から株式分割・併合を記録し、新たに検出した銘柄の過去の調整後株価・配当履歴を再計算
+// 保存済みの調整後株価・分割調整後の配当は取得時点の調整係数に基づくため、新しい分割・併合が反映されていない
+func (s *DailyQuotesService) updateCorporateActions(quotes []schema.DailyQuote) error {
+	codes, err := s.corporateActionRepository.RecordFromQuotes(quotes)
+	if err != nil {
+		return err
+	}
+	if len(codes) == 0 {
+		return nil
+	}
+
+	for _, code := range codes {
+		if err := s.corporateActionRepository.ReadjustPrices(code); err != nil {
+			return fmt.Errorf("調整後株価再計算エラー (銘柄: %s): %v", code, err)
+		}
+	}
+
+	// 配当履歴は財務情報の取得時にしか作成されないため、次の開示まで分割前の株数基準のまま残らないよう作り直す
+	if err := s.dividendRepository.BuildDividendHistory(codes); err != nil {
+		return fmt.Errorf("配当履歴再作成エラー: %v", err)
+	}
+
+	slog.Info("株式分割・併合を検出し調整後株価・配当履歴を再計算しました", "codes", codes)
+	return nil
+}
+
 // UpdateDailyQuotesMultipleDates 複数日付の株価データを取得し、DBに保存（間隔制御付き）
 // date: 開始日付
 // count: 取得する日数
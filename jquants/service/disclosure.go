@@ -0,0 +1,143 @@
+package service
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"stock-automation/database"
+	"stock-automation/jquants/notify"
+	"stock-automation/schema"
+	"strings"
+)
+
+// DisclosureHook ウォッチリストの銘柄の新しい開示を保存した後に呼ばれるフック
+type DisclosureHook func(digests []*database.DisclosureDigest) error
+
+// AddDisclosureHook 新しい開示を保存した後に呼ばれるフックを追加
+func (s *StatementsService) AddDisclosureHook(hook DisclosureHook) {
+	s.disclosureHooks = append(s.disclosureHooks, hook)
+}
+
+// EnableDisclosureNotifications ウォッチリストの銘柄の新しい開示をALERT_NOTIFIERSの送信先へ通知するフックを追加
+// 要約の言語は環境変数DISCLOSURE_NOTIFY_LANG（ja, en, ja,en。未設定の場合はja）
+func (s *StatementsService) EnableDisclosureNotifications() error {
+	notifier, err := notify.NewNotifierFromEnv()
+	if err != nil {
+		return fmt.Errorf("通知先設定エラー: %v", err)
+	}
+	langs, err := disclosureLangsFromEnv()
+	if err != nil {
+		return err
+	}
+
+	s.AddDisclosureHook(NewDisclosureNotifyHook(notifier, langs))
+	return nil
+}
+
+// findNewWatchedDisclosures ウォッチリストの銘柄の財務情報のうち、まだ保存していない開示を抽出（フックがない場合は何もしない）
+func (s *StatementsService) findNewWatchedDisclosures(statements []schema.FinancialStatement) ([]schema.FinancialStatement, error) {
+	if len(s.disclosureHooks) == 0 {
+		return nil, nil
+	}
+
+	var codes []string
+	seen := make(map[string]bool)
+	for _, stmt := range statements {
+		if !seen[stmt.LocalCode] {
+			seen[stmt.LocalCode] = true
+			codes = append(codes, stmt.LocalCode)
+		}
+	}
+
+	watched, err := s.watchlistRepository.GetWatchlistNames(codes)
+	if err != nil {
+		return nil, err
+	}
+
+	var candidates []schema.FinancialStatement
+	for _, stmt := range statements {
+		if len(watched[stmt.LocalCode]) > 0 {
+			candidates = append(candidates, stmt)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+
+	return s.repository.FilterNewStatements(candidates)
+}
+
+// emitNewDisclosures 新しい開示の要約を作成してフックを呼び出す（フックのエラーは財務情報の更新を止めない）
+func (s *StatementsService) emitNewDisclosures(statements []schema.FinancialStatement) {
+	if len(statements) == 0 || len(s.disclosureHooks) == 0 {
+		return
+	}
+
+	digests, err := database.NewDisclosureRepository(s.dbConn).BuildDigests(statements)
+	if err != nil {
+		slog.Warn("開示要約作成エラー（通知をスキップ）", "error", err)
+		return
+	}
+	slog.Info("ウォッチリスト銘柄の新規開示", "count", len(digests))
+
+	for _, hook := range s.disclosureHooks {
+		if err := hook(digests); err != nil {
+			slog.Warn("新規開示フックエラー", "error", err)
+		}
+	}
+}
+
+// NewDisclosureNotifyHook 新しい開示の要約を通知するフックを作成（複数の言語を指定した場合は言語ごとに続けて記載）
+func NewDisclosureNotifyHook(notifier notify.Notifier, langs []string) DisclosureHook {
+	return func(digests []*database.DisclosureDigest) error {
+		if len(digests) == 0 {
+			return nil
+		}
+		if err := notifier.Notify(buildDisclosureMessage(digests, langs)); err != nil {
+			return fmt.Errorf("新規開示通知エラー: %v", err)
+		}
+		slog.Info("新規開示通知完了", "count", len(digests), "notifier", notifier.Name())
+		return nil
+	}
+}
+
+// buildDisclosureMessage 開示の要約から通知内容を作成
+func buildDisclosureMessage(digests []*database.DisclosureDigest, langs []string) *notify.Message {
+	var subjects, sections []string
+	for _, lang := range langs {
+		summaries := make([]string, len(digests))
+		for i, d := range digests {
+			summaries[i] = d.Summary(lang)
+		}
+		sections = append(sections, strings.Join(summaries, "\n\n"))
+
+		if lang == database.LangEnglish {
+			subjects = append(subjects, fmt.Sprintf("%d new disclosure(s) on watchlist", len(digests)))
+		} else {
+			subjects = append(subjects, fmt.Sprintf("ウォッチリスト銘柄の新規開示 %d件", len(digests)))
+		}
+	}
+
+	return &notify.Message{
+		Subject: strings.Join(subjects, " / "),
+		Body:    strings.Join(sections, "\n\n----\n\n"),
+	}
+}
+
+// disclosureLangsFromEnv 環境変数DISCLOSURE_NOTIFY_LANGから要約の言語を読み込む
+func disclosureLangsFromEnv() ([]string, error) {
+	value := os.Getenv("DISCLOSURE_NOTIFY_LANG")
+	if value == "" {
+		return []string{database.LangJapanese}, nil
+	}
+
+	var langs []string
+	for _, part := range strings.Split(value, ",") {
+		lang := strings.ToLower(strings.TrimSpace(part))
+		if lang != database.LangJapanese && lang != database.LangEnglish {
+			return nil, fmt.Errorf("DISCLOSURE_NOTIFY_LANGの値が無効です（ja, en）: '%s'", part)
+		}
+		langs = append(langs, lang)
+	}
+	return langs, nil
+}
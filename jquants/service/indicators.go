@@ -0,0 +1,53 @@
+package service
+
+import (
+	"fmt"
+	"log/slog"
+	"stock-automation/database"
+)
+
+// IndicatorService テクニカル指標サービスクラス
+type IndicatorService struct {
+	dbConn     *database.Connection
+	repository *database.IndicatorRepository
+	config     database.IndicatorConfig
+}
+
+// NewIndicatorService 新しいテクニカル指標サービスを作成
+func NewIndicatorService(verbose bool) (*IndicatorService, error) {
+	// 算出設定を環境変数から読み込む
+	config, err := database.NewIndicatorConfigFromEnv()
+	if err != nil {
+		return nil, fmt.Errorf("テクニカル指標設定エラー: %v", err)
+	}
+
+	// データベース接続を作成
+	dbConn, err := database.NewConnectionFromEnv(verbose)
+	if err != nil {
+		return nil, fmt.Errorf("データベース接続エラー: %v", err)
+	}
+
+	return &IndicatorService{
+		dbConn:     dbConn,
+		repository: database.NewIndicatorRepository(dbConn),
+		config:     config,
+	}, nil
+}
+
+// UpdateIndicators 全銘柄について保存済みの最終取引日より後のテクニカル指標を追加
+func (s *IndicatorService) UpdateIndicators() error {
+	if err := s.repository.BuildIndicators(nil, s.config, false); err != nil {
+		return fmt.Errorf("テクニカル指標作成エラー: %v", err)
+	}
+	slog.Info("テクニカル指標作成完了", "indicators", s.config.Names())
+
+	return nil
+}
+
+// Close データベース接続を閉じる
+func (s *IndicatorService) Close() error {
+	if s.dbConn != nil {
+		return s.dbConn.Close()
+	}
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 	"log/slog"
 	"stock-automation/database"
 	"stock-automation/jquants/api"
+	"time"
 )
 
 // ListedInfoService 上場銘柄情報サービスクラス
@@ -41,33 +42,133 @@ func (s *ListedInfoService) Close() error {
 }
 
 // UpdateListedInfo 上場銘柄情報を取得し、DBに保存
+// code: 銘柄コード（空の場合は全銘柄）
 // date: 日付（空の場合はAPIの最新日付）
-func (s *ListedInfoService) UpdateListedInfo(date string) error {
+func (s *ListedInfoService) UpdateListedInfo(code, date string) error {
 	idToken, err := s.client.AuthClient.GetIdToken()
 	if err != nil {
 		return fmt.Errorf("IDトークン取得エラー: %v", err)
 	}
 
-	slog.Debug("上場銘柄情報取得開始", "date", date)
+	slog.Debug("上場銘柄情報取得開始", "code", code, "date", date)
 
 	// 上場銘柄情報を取得（pagination_key対応で全データ取得）
-	listedInfo, err := s.client.ListedClient.GetListedInfo(idToken, date)
+	listedInfo, err := s.client.ListedClient.GetListedInfo(idToken, code, date)
 	if err != nil {
 		return fmt.Errorf("上場銘柄情報取得エラー: %v", err)
 	}
 
 	if len(listedInfo) == 0 {
-		slog.Warn("取得したデータがありません", "date", date)
+		slog.Warn("取得したデータがありません", "code", code, "date", date)
 		return nil
 	}
 
 	slog.Debug("上場銘柄情報取得完了", "count", len(listedInfo))
 
+	// 全銘柄取得時のみ、保存済みの銘柄と比較して新規上場・上場廃止を検出
+	var events []database.ListingEvent
+	if code == "" {
+		events, err = s.repository.DetectListingEvents(listedInfo)
+		if err != nil {
+			return fmt.Errorf("上場イベント検出エラー: %v", err)
+		}
+	}
+
 	// データベースに保存
 	if err := s.repository.SaveListedInfo(listedInfo); err != nil {
 		return fmt.Errorf("データベース保存エラー: %v", err)
 	}
 
-	slog.Info("上場銘柄情報更新完了", "date", date, "count", len(listedInfo))
+	// 属性の変更を履歴テーブルに反映
+	if err := s.repository.SaveListedInfoHistory(listedInfo); err != nil {
+		return fmt.Errorf("履歴保存エラー: %v", err)
+	}
+
+	// 上場イベントを保存し、上場廃止日を更新
+	if err := s.repository.SaveListingEvents(events); err != nil {
+		return fmt.Errorf("上場イベント保存エラー: %v", err)
+	}
+
+	// 全銘柄取得時は適用日時点のユニバースとしてスナップショットも保存
+	if code == "" {
+		if err := s.repository.SaveSnapshots(listedInfo); err != nil {
+			return fmt.Errorf("スナップショット保存エラー: %v", err)
+		}
+	}
+
+	slog.Info("上場銘柄情報更新完了", "code", code, "date", date, "count", len(listedInfo))
+	return nil
+}
+
+// UpdateListedInfoSnapshots 期間内の日付ごとに全銘柄の上場銘柄一覧を取得し、適用日ごとのスナップショットとして保存
+// from, to: 期間（YYYY-MM-DD形式）
+// step: 取得間隔（日数）。土日に当たる場合は翌月曜日に取得する
+// force: 保存済みの日付も再取得する
+// interval: 取得ごとのインターバル（秒）
+func (s *ListedInfoService) UpdateListedInfoSnapshots(from, to string, step int, force bool, interval int) error {
+	fromDate, err := time.ParseInLocation("2006-01-02", from, time.Local)
+	if err != nil {
+		return fmt.Errorf("開始日の形式が不正です（YYYY-MM-DD）: %v", err)
+	}
+	toDate, err := time.ParseInLocation("2006-01-02", to, time.Local)
+	if err != nil {
+		return fmt.Errorf("終了日の形式が不正です（YYYY-MM-DD）: %v", err)
+	}
+	if toDate.Before(fromDate) {
+		return fmt.Errorf("終了日は開始日以降を指定してください: %s〜%s", from, to)
+	}
+	if step < 1 {
+		return fmt.Errorf("取得間隔は1日以上を指定してください: %d", step)
+	}
+
+	idToken, err := s.client.AuthClient.GetIdToken()
+	if err != nil {
+		return fmt.Errorf("IDトークン取得エラー: %v", err)
+	}
+
+	saved := 0
+	for date := fromDate; !date.After(toDate); date = date.AddDate(0, 0, step) {
+		target := date
+		for target.Weekday() == time.Saturday || target.Weekday() == time.Sunday {
+			target = target.AddDate(0, 0, 1)
+		}
+		if target.After(toDate) {
+			break
+		}
+		dateStr := target.Format("2006-01-02")
+
+		if !force {
+			exists, err := s.repository.HasSnapshot(dateStr)
+			if err != nil {
+				return err
+			}
+			if exists {
+				slog.Debug("保存済みのためスキップ", "date", dateStr)
+				continue
+			}
+		}
+
+		if saved > 0 && interval > 0 {
+			time.Sleep(time.Duration(interval) * time.Second)
+		}
+
+		listedInfo, err := s.client.ListedClient.GetListedInfo(idToken, "", dateStr)
+		if err != nil {
+			slog.Error("上場銘柄一覧取得エラー", "date", dateStr, "error", err)
+			continue
+		}
+		if len(listedInfo) == 0 {
+			slog.Warn("取得したデータがありません", "date", dateStr)
+			continue
+		}
+
+		if err := s.repository.SaveSnapshots(listedInfo); err != nil {
+			return fmt.Errorf("スナップショット保存エラー (日付: %s): %v", dateStr, err)
+		}
+		saved++
+		slog.Info("スナップショット保存完了", "date", dateStr, "count", len(listedInfo))
+	}
+
+	slog.Info("上場銘柄一覧スナップショット更新完了", "from", from, "to", to, "saved", saved)
 	return nil
 }
@@ -6,15 +6,24 @@ import (
 	"stock-automation/database"
 	"stock-automation/helper"
 	"stock-automation/jquants/api"
+	"stock-automation/schema"
 	"time"
 )
 
 // StatementsService 財務情報サービスクラス
 type StatementsService struct {
-	client     *api.Client
-	dbConn     *database.Connection
-	repository *database.StatementsRepository
-	interval   int // インターバル（秒）
+	client              *api.Client
+	dbConn              *database.Connection
+	repository          *database.StatementsRepository
+	quarterlyRepository *database.StatementsQuarterlyRepository
+	revisionRepository  *database.ForecastRevisionRepository
+	surpriseRepository  *database.EarningsSurpriseRepository
+	ratioRepository     *database.FinancialRatioRepository
+	scoreRepository     *database.QualityScoreRepository
+	dividendRepository  *database.DividendHistoryRepository
+	watchlistRepository *database.WatchlistRepository
+	disclosureHooks     []DisclosureHook
+	interval            int // インターバル（秒）
 }
 
 // NewStatementsService 新しい財務情報サービスを作成
@@ -29,10 +38,17 @@ func NewStatementsService(interval int, verbose bool) (*StatementsService, error
 	repository := database.NewStatementsRepository(dbConn)
 
 	return &StatementsService{
-		client:     api.NewClient(),
-		dbConn:     dbConn,
-		repository: repository,
-		interval:   interval,
+		client:              api.NewClient(),
+		dbConn:              dbConn,
+		repository:          repository,
+		quarterlyRepository: database.NewStatementsQuarterlyRepository(dbConn),
+		revisionRepository:  database.NewForecastRevisionRepository(dbConn),
+		surpriseRepository:  database.NewEarningsSurpriseRepository(dbConn),
+		ratioRepository:     database.NewFinancialRatioRepository(dbConn),
+		scoreRepository:     database.NewQualityScoreRepository(dbConn),
+		dividendRepository:  database.NewDividendHistoryRepository(dbConn),
+		watchlistRepository: database.NewWatchlistRepository(dbConn),
+		interval:            interval,
 	}, nil
 }
 
@@ -57,10 +73,25 @@ func (s *StatementsService) UpdateStatements(code, date string) error {
 
 	// データベースに保存
 	if len(statements) > 0 {
+		// 保存すると新しい開示か判定できなくなるため、保存前にウォッチリストの銘柄の新しい開示を抽出
+		newDisclosures, err := s.findNewWatchedDisclosures(statements)
+		if err != nil {
+			slog.Warn("新規開示の判定エラー（通知をスキップ）", "error", err)
+		}
+
 		if err := s.repository.SaveFinancialStatements(statements); err != nil {
 			return fmt.Errorf("データベース保存エラー: %v", err)
 		}
 		slog.Info("財務情報保存完了", "code", code, "date", date, "count", len(statements))
+
+		// 前年同期・前回予想との比較に保存後の財務情報を使うため、保存後にフックを呼び出す
+		// 派生データの更新に失敗しても次回は新しい開示と判定されないため、派生データの更新より先に通知する
+		s.emitNewDisclosures(newDisclosures)
+
+		// 保存した銘柄の派生データを更新
+		if err := s.updateDerived(statements); err != nil {
+			return fmt.Errorf("派生データ更新エラー: %v", err)
+		}
 	} else {
 		slog.Info("取得したデータがありません", "code", code, "date", date)
 	}
@@ -68,6 +99,44 @@ func (s *StatementsService) UpdateStatements(code, date string) error {
 	return nil
 }
 
+// updateDerived 保存した財務情報の銘柄について派生データ（四半期単独値・業績予想修正・決算サプライズ・財務比率・財務品質スコア・配当履歴）を再作成
+func (s *StatementsService) updateDerived(statements []schema.FinancialStatement) error {
+	seen := make(map[string]bool)
+	var codes []string
+	for _, stmt := range statements {
+		if !seen[stmt.LocalCode] {
+			seen[stmt.LocalCode] = true
+			codes = append(codes, stmt.LocalCode)
+		}
+	}
+
+	if err := s.quarterlyRepository.BuildQuarterly(codes); err != nil {
+		return fmt.Errorf("四半期単独値作成エラー: %v", err)
+	}
+
+	if err := s.revisionRepository.BuildRevisions(codes); err != nil {
+		return fmt.Errorf("業績予想修正作成エラー: %v", err)
+	}
+
+	if err := s.surpriseRepository.BuildSurprises(codes); err != nil {
+		return fmt.Errorf("決算サプライズ作成エラー: %v", err)
+	}
+
+	if err := s.ratioRepository.BuildRatios(codes); err != nil {
+		return fmt.Errorf("財務比率作成エラー: %v", err)
+	}
+
+	if err := s.scoreRepository.BuildScores(codes); err != nil {
+		return fmt.Errorf("財務品質スコア作成エラー: %v", err)
+	}
+
+	if err := s.dividendRepository.BuildDividendHistory(codes); err != nil {
+		return fmt.Errorf("配当履歴作成エラー: %v", err)
+	}
+
+	return nil
+}
+
 // UpdateStatementsMultipleDates 複数日付の財務情報を取得し、DBに保存（間隔制御付き）
 // date: 開始日付
 // count: 取得する日数
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"fmt"
+	"log/slog"
+	"stock-automation/database"
+	"stock-automation/jquants/api"
+)
+
+// TopixService TOPIXサービスクラス
+type TopixService struct {
+	client     *api.Client
+	dbConn     *database.Connection
+	repository *database.TopixRepository
+}
+
+// NewTopixService 新しいTOPIXサービスを作成
+func NewTopixService(verbose bool) (*TopixService, error) {
+	// データベース接続を作成
+	dbConn, err := database.NewConnectionFromEnv(verbose)
+	if err != nil {
+		return nil, fmt.Errorf("データベース接続エラー: %v", err)
+	}
+
+	return &TopixService{
+		client:     api.NewClient(),
+		dbConn:     dbConn,
+		repository: database.NewTopixRepository(dbConn),
+	}, nil
+}
+
+// UpdateTopix TOPIX指数四本値を取得し、DBに保存
+// from, to: 期間（空の場合はAPIで取得可能な全期間）
+func (s *TopixService) UpdateTopix(from, to string) error {
+	idToken, err := s.client.AuthClient.GetIdToken()
+	if err != nil {
+		return fmt.Errorf("IDトークン取得エラー: %v", err)
+	}
+
+	topix, err := s.client.IndicesClient.GetTopix(idToken, from, to)
+	if err != nil {
+		return fmt.Errorf("TOPIX取得エラー: %v", err)
+	}
+
+	if len(topix) == 0 {
+		slog.Info("取得したデータがありません", "from", from, "to", to)
+		return nil
+	}
+
+	if err := s.repository.SaveTopix(topix); err != nil {
+		return fmt.Errorf("データベース保存エラー: %v", err)
+	}
+	slog.Info("TOPIX保存完了", "from", topix[0].Date, "to", topix[len(topix)-1].Date, "count", len(topix))
+
+	return nil
+}
+
+// UpdateTopixFromLatest 保存済みの最終取引日以降のTOPIX指数四本値を取得（データがない場合は全期間）
+func (s *TopixService) UpdateTopixFromLatest() error {
+	latest, err := s.repository.GetLatestTradeDate()
+	if err != nil {
+		return err
+	}
+
+	from := ""
+	if latest != nil {
+		from = latest.Format("2006-01-02")
+	}
+	return s.UpdateTopix(from, "")
+}
+
+// Close データベース接続を閉じる
+func (s *TopixService) Close() error {
+	if s.dbConn != nil {
+		return s.dbConn.Close()
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"fmt"
+	"log/slog"
+	"stock-automation/database"
+	"stock-automation/jquants/api"
+)
+
+// TradingCalendarService 取引カレンダーサービスクラス
+type TradingCalendarService struct {
+	client     *api.Client
+	dbConn     *database.Connection
+	repository *database.TradingCalendarRepository
+}
+
+// NewTradingCalendarService 新しい取引カレンダーサービスを作成
+func NewTradingCalendarService(verbose bool) (*TradingCalendarService, error) {
+	// データベース接続を作成
+	dbConn, err := database.NewConnectionFromEnv(verbose)
+	if err != nil {
+		return nil, fmt.Errorf("データベース接続エラー: %v", err)
+	}
+
+	return &TradingCalendarService{
+		client:     api.NewClient(),
+		dbConn:     dbConn,
+		repository: database.NewTradingCalendarRepository(dbConn),
+	}, nil
+}
+
+// UpdateTradingCalendar 取引カレンダーを取得し、DBに保存
+// from, to: 期間（空の場合はAPIで取得可能な全期間）
+func (s *TradingCalendarService) UpdateTradingCalendar(from, to string) error {
+	idToken, err := s.client.AuthClient.GetIdToken()
+	if err != nil {
+		return fmt.Errorf("IDトークン取得エラー: %v", err)
+	}
+
+	days, err := s.client.MarketsClient.GetTradingCalendar(idToken, from, to)
+	if err != nil {
+		return fmt.Errorf("取引カレンダー取得エラー: %v", err)
+	}
+
+	if len(days) == 0 {
+		slog.Info("取得したデータがありません", "from", from, "to", to)
+		return nil
+	}
+
+	if err := s.repository.SaveTradingCalendar(days); err != nil {
+		return fmt.Errorf("データベース保存エラー: %v", err)
+	}
+	slog.Info("取引カレンダー保存完了", "from", days[0].Date, "to", days[len(days)-1].Date, "count", len(days))
+
+	return nil
+}
+
+// Close データベース接続を閉じる
+func (s *TradingCalendarService) Close() error {
+	if s.dbConn != nil {
+		return s.dbConn.Close()
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"fmt"
+	"log/slog"
+	"stock-automation/database"
+	"stock-automation/helper"
+)
+
+// ValuationService 株価指標サービスクラス
+type ValuationService struct {
+	dbConn     *database.Connection
+	repository *database.ValuationRepository
+}
+
+// NewValuationService 新しい株価指標サービスを作成
+func NewValuationService(verbose bool) (*ValuationService, error) {
+	// データベース接続を作成
+	dbConn, err := database.NewConnectionFromEnv(verbose)
+	if err != nil {
+		return nil, fmt.Errorf("データベース接続エラー: %v", err)
+	}
+
+	return &ValuationService{
+		dbConn:     dbConn,
+		repository: database.NewValuationRepository(dbConn),
+	}, nil
+}
+
+// UpdateValuations 指定日からcount日分さかのぼった期間の株価指標を全銘柄分作成
+// date: 日付（空の場合はdaily_quotesの最新取引日）
+func (s *ValuationService) UpdateValuations(date string, count int) error {
+	if date == "" {
+		latest, err := s.repository.GetLatestTradeDate()
+		if err != nil {
+			return err
+		}
+		if latest == "" {
+			slog.Info("株価データがないため株価指標の作成をスキップします")
+			return nil
+		}
+		date = latest
+	}
+
+	fromDate := date
+	if count > 1 {
+		fromDate = helper.SubDate(date, count-1)
+	}
+
+	if err := s.repository.BuildValuations(nil, fromDate); err != nil {
+		return fmt.Errorf("株価指標作成エラー: %v", err)
+	}
+	slog.Info("株価指標作成完了", "from", fromDate, "to", date)
+
+	return nil
+}
+
+// Close データベース接続を閉じる
+func (s *ValuationService) Close() error {
+	if s.dbConn != nil {
+		return s.dbConn.Close()
+	}
+	return nil
+}
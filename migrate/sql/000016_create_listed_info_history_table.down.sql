@@ -0,0 +1,2 @@
+-- 上場銘柄情報履歴テーブルを削除
+DROP TABLE IF EXISTS listed_info_history;
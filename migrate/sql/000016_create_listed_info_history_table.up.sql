@@ -0,0 +1,36 @@
+-- 上場銘柄情報履歴テーブルを作成
+-- 市場区分・業種・貸借区分などの変更を適用期間（effective_from〜effective_to）付きで管理
+CREATE TABLE IF NOT EXISTS listed_info_history (
+    code VARCHAR(10) NOT NULL,
+    effective_from DATE NOT NULL,
+    effective_to DATE NULL COMMENT '適用終了日（この日を含む）。NULLは現在有効',
+    company_name VARCHAR(255) NOT NULL,
+    company_name_english VARCHAR(255),
+    market_code VARCHAR(10),
+    sector17_code VARCHAR(10),
+    sector33_code VARCHAR(10),
+    scale_category VARCHAR(100),
+    margin_code VARCHAR(10),
+    margin_code_name VARCHAR(100),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+    -- プライマリキー
+    PRIMARY KEY (code, effective_from),
+
+    -- 外部キー制約
+    CONSTRAINT fk_listed_info_history_code FOREIGN KEY (code) REFERENCES listed_info(code),
+
+    -- インデックス
+    INDEX idx_listed_info_history_effective_to (code, effective_to)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- 既存の上場銘柄情報を初期履歴として登録
+INSERT INTO listed_info_history (
+    code, effective_from, effective_to, company_name, company_name_english,
+    market_code, sector17_code, sector33_code, scale_category, margin_code, margin_code_name
+)
+SELECT
+    code, effective_date, NULL, company_name, company_name_english,
+    market_code, sector17_code, sector33_code, scale_category, margin_code, margin_code_name
+FROM listed_info;
@@ -0,0 +1,6 @@
+-- 新規上場・上場廃止イベントテーブルを削除
+DROP TABLE IF EXISTS listing_events;
+
+-- 上場銘柄情報から上場廃止日カラムを削除
+ALTER TABLE listed_info DROP INDEX idx_listed_info_delisted_date;
+ALTER TABLE listed_info DROP COLUMN delisted_date;
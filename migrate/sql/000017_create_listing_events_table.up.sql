@@ -0,0 +1,25 @@
+-- 上場銘柄情報に上場廃止日カラムを追加
+ALTER TABLE listed_info ADD COLUMN delisted_date DATE NULL COMMENT '上場廃止を検知した日（NULLは上場中）';
+ALTER TABLE listed_info ADD INDEX idx_listed_info_delisted_date (delisted_date);
+
+-- 新規上場・上場廃止イベントテーブルを作成
+-- 上場銘柄一覧の全件更新時に前回との差分から検知したイベントを管理
+CREATE TABLE IF NOT EXISTS listing_events (
+    code VARCHAR(10) NOT NULL,
+    event_date DATE NOT NULL,
+    event_type ENUM('listing', 'delisting') NOT NULL,
+    company_name VARCHAR(255),
+    market_code VARCHAR(10),
+
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+    -- プライマリキー
+    PRIMARY KEY (code, event_date, event_type),
+
+    -- 外部キー制約
+    CONSTRAINT fk_listing_events_code FOREIGN KEY (code) REFERENCES listed_info(code),
+
+    -- インデックス
+    INDEX idx_listing_events_event_date (event_date)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
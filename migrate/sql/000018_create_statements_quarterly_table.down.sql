@@ -0,0 +1,2 @@
+-- 四半期単独財務テーブルを削除
+DROP TABLE IF EXISTS statements_quarterly;
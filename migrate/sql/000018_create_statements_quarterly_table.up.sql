@@ -0,0 +1,47 @@
+-- 四半期単独財務テーブルを作成
+-- statementsの累計値（1Q/2Q/3Q/FY）から四半期単独値・直近12か月（TTM）・前年同期比を算出して管理
+CREATE TABLE IF NOT EXISTS statements_quarterly (
+    local_code VARCHAR(10) NOT NULL,
+    fiscal_year_start_date DATE NOT NULL,
+    fiscal_year_end_date DATE NOT NULL,
+    fiscal_quarter TINYINT NOT NULL COMMENT '四半期（1〜4、4は通期決算）',
+    period_end_date DATE,
+    disclosed_date DATE NOT NULL,
+
+    -- 期首からの累計値
+    cum_net_sales BIGINT,
+    cum_operating_profit BIGINT,
+    cum_ordinary_profit BIGINT,
+    cum_profit BIGINT,
+
+    -- 四半期単独値
+    net_sales BIGINT,
+    operating_profit BIGINT,
+    ordinary_profit BIGINT,
+    profit BIGINT,
+
+    -- 直近4四半期合計（TTM）
+    ttm_net_sales BIGINT,
+    ttm_operating_profit BIGINT,
+    ttm_ordinary_profit BIGINT,
+    ttm_profit BIGINT,
+
+    -- 四半期単独値の前年同期比（%）
+    net_sales_yoy DECIMAL(10,2),
+    operating_profit_yoy DECIMAL(10,2),
+    ordinary_profit_yoy DECIMAL(10,2),
+    profit_yoy DECIMAL(10,2),
+
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+    -- プライマリキー
+    PRIMARY KEY (local_code, fiscal_year_start_date, fiscal_quarter),
+
+    -- 外部キー制約
+    CONSTRAINT fk_statements_quarterly_local_code FOREIGN KEY (local_code) REFERENCES listed_info(code),
+
+    -- インデックス
+    INDEX idx_statements_quarterly_period_end (period_end_date),
+    INDEX idx_statements_quarterly_disclosed_date (disclosed_date)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
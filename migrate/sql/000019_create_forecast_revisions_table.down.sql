@@ -0,0 +1,2 @@
+-- 業績予想修正テーブルを削除
+DROP TABLE IF EXISTS forecast_revisions;
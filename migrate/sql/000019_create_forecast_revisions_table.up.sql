@@ -0,0 +1,27 @@
+-- 業績予想修正テーブルを作成
+-- 同一会計年度の予想値（当期予想・翌期予想）を開示順に比較し、変化があったものを管理
+CREATE TABLE IF NOT EXISTS forecast_revisions (
+    local_code VARCHAR(10) NOT NULL,
+    fiscal_year_start_date DATE NOT NULL,
+    fiscal_year_end_date DATE,
+    metric VARCHAR(30) NOT NULL COMMENT '指標（net_sales, operating_profit, ordinary_profit, profit, eps, dps_annual）',
+    disclosed_date DATE NOT NULL COMMENT '修正後の予想の開示日',
+    disclosed_time TIME,
+    prev_disclosed_date DATE NOT NULL COMMENT '修正前の予想の開示日',
+    old_value DECIMAL(22,2) NOT NULL,
+    new_value DECIMAL(22,2) NOT NULL,
+    change_pct DECIMAL(10,2) COMMENT '変化率(%)。修正前が0の場合はNULL',
+    direction ENUM('up', 'down') NOT NULL,
+
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+    -- プライマリキー
+    PRIMARY KEY (local_code, fiscal_year_start_date, metric, disclosed_date),
+
+    -- 外部キー制約
+    CONSTRAINT fk_forecast_revisions_local_code FOREIGN KEY (local_code) REFERENCES listed_info(code),
+
+    -- インデックス
+    INDEX idx_forecast_revisions_disclosed_date (disclosed_date, direction)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,2 @@
+-- 決算サプライズテーブルを削除
+DROP TABLE IF EXISTS earnings_surprises;
@@ -0,0 +1,34 @@
+-- 決算サプライズテーブルを作成
+-- 実績の開示ごとに、開示前に公表されていた同一会計年度の最新予想と比較した結果を管理
+CREATE TABLE IF NOT EXISTS earnings_surprises (
+    local_code VARCHAR(10) NOT NULL,
+    fiscal_year_start_date DATE NOT NULL,
+    fiscal_year_end_date DATE,
+    type_of_current_period VARCHAR(10) NOT NULL COMMENT '実績の期間（1Q, 2Q, 3Q, FY）',
+    metric VARCHAR(30) NOT NULL COMMENT '指標（net_sales, operating_profit, ordinary_profit, profit, eps）',
+    disclosed_date DATE NOT NULL COMMENT '実績の開示日',
+    disclosed_time TIME,
+    actual_value DECIMAL(22,2) NOT NULL,
+
+    -- 比較対象の予想（FYは通期予想、2Qは第2四半期累計予想。1Q・3QはNULL）
+    forecast_value DECIMAL(22,2),
+    forecast_disclosed_date DATE,
+    surprise_pct DECIMAL(10,2) COMMENT '予想比(%)。予想が0またはない場合はNULL',
+    result ENUM('beat', 'miss', 'inline'),
+
+    -- 通期予想に対する進捗率
+    full_year_forecast_value DECIMAL(22,2),
+    progress_pct DECIMAL(10,2) COMMENT '通期予想に対する進捗率(%)',
+
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+    -- プライマリキー
+    PRIMARY KEY (local_code, fiscal_year_start_date, type_of_current_period, metric),
+
+    -- 外部キー制約
+    CONSTRAINT fk_earnings_surprises_local_code FOREIGN KEY (local_code) REFERENCES listed_info(code),
+
+    -- インデックス
+    INDEX idx_earnings_surprises_disclosed_date (disclosed_date, metric)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
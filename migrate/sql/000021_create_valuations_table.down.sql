@@ -0,0 +1,2 @@
+-- 株価指標テーブルを削除
+DROP TABLE IF EXISTS valuations;
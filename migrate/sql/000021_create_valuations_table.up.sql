@@ -0,0 +1,39 @@
+-- 株価指標テーブルを作成
+-- 各取引日の終値と、その時点で開示済みだった財務情報から算出した時価総額・PER・PBR・配当利回りを管理
+CREATE TABLE IF NOT EXISTS valuations (
+    code VARCHAR(10) NOT NULL,
+    trade_date DATE NOT NULL,
+    close DECIMAL(10,2) NOT NULL COMMENT '終値（調整前）',
+
+    -- 時価総額（発行済株式数から自己株式数を除いた株式数で算出）
+    shares_outstanding BIGINT COMMENT '自己株式を除く発行済株式数（株式分割調整後）',
+    market_cap BIGINT,
+
+    -- 実績ベース（直近の通期実績）
+    actual_eps DECIMAL(10,2) COMMENT '実績EPS（株式分割調整後）',
+    actual_per DECIMAL(10,2),
+    actual_disclosed_date DATE COMMENT '実績の開示日',
+
+    -- 予想ベース（直近の通期予想）
+    forecast_eps DECIMAL(10,2) COMMENT '予想EPS（株式分割調整後）',
+    forecast_per DECIMAL(10,2),
+    forecast_dps DECIMAL(10,2) COMMENT '予想年間配当（株式分割調整後）',
+    forecast_dividend_yield DECIMAL(10,2) COMMENT '予想配当利回り(%)',
+    forecast_disclosed_date DATE COMMENT '予想の開示日',
+
+    -- 純資産ベース
+    bvps DECIMAL(10,2) COMMENT '1株当たり純資産（株式分割調整後）',
+    pbr DECIMAL(10,2),
+
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+    -- プライマリキー
+    PRIMARY KEY (code, trade_date),
+
+    -- 外部キー制約
+    CONSTRAINT fk_valuations_code FOREIGN KEY (code) REFERENCES listed_info(code),
+
+    -- インデックス
+    INDEX idx_valuations_trade_date (trade_date)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,2 @@
+-- 財務比率テーブルを削除
+DROP TABLE IF EXISTS financial_ratios;
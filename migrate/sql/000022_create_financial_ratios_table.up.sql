@@ -0,0 +1,53 @@
+-- 財務比率テーブルを作成
+-- 通期実績（会計年度ごとの最新の開示）から算出した収益性・安全性・成長性の指標を管理
+CREATE TABLE IF NOT EXISTS financial_ratios (
+    local_code VARCHAR(10) NOT NULL,
+    fiscal_year_start_date DATE NOT NULL,
+    fiscal_year_end_date DATE,
+    disclosed_date DATE NOT NULL,
+
+    -- 算出元の実績値
+    net_sales BIGINT,
+    operating_profit BIGINT,
+    profit BIGINT,
+    total_assets BIGINT,
+    equity BIGINT,
+    eps DECIMAL(10,2),
+    dividend_per_share DECIMAL(10,2),
+    cf_operating BIGINT,
+    cf_investing BIGINT,
+
+    -- 収益性（%）。ROE・ROAは期首・期末の平均（前期がない場合は期末）で算出
+    roe DECIMAL(10,2),
+    roa DECIMAL(10,2),
+    operating_margin DECIMAL(10,2),
+    net_margin DECIMAL(10,2),
+
+    -- 安全性（%）
+    equity_ratio DECIMAL(10,2) COMMENT '自己資本比率(%)',
+    equity_ratio_change DECIMAL(10,2) COMMENT '自己資本比率の前期差（ポイント）',
+
+    -- 還元（%）
+    payout_ratio DECIMAL(10,2) COMMENT '配当性向(%)',
+
+    -- 成長性（年率%）。基準年度・当年度とも正の場合のみ算出
+    net_sales_cagr_3y DECIMAL(10,2),
+    net_sales_cagr_5y DECIMAL(10,2),
+    profit_cagr_3y DECIMAL(10,2),
+    profit_cagr_5y DECIMAL(10,2),
+
+    -- フリーキャッシュフロー（営業CF + 投資CF）
+    free_cash_flow BIGINT,
+
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+    -- プライマリキー
+    PRIMARY KEY (local_code, fiscal_year_start_date),
+
+    -- 外部キー制約
+    CONSTRAINT fk_financial_ratios_local_code FOREIGN KEY (local_code) REFERENCES listed_info(code),
+
+    -- インデックス
+    INDEX idx_financial_ratios_fiscal_year_end (fiscal_year_end_date)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,2 @@
+-- 財務品質スコアテーブルを削除
+DROP TABLE IF EXISTS quality_scores;
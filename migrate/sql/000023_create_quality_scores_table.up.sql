@@ -0,0 +1,44 @@
+-- 財務品質スコアテーブルを作成
+-- 連続する会計年度の通期実績から算出したPiotroski F-scoreとAltman Z-score（変形版）を内訳とともに管理
+CREATE TABLE IF NOT EXISTS quality_scores (
+    local_code VARCHAR(10) NOT NULL,
+    fiscal_year_start_date DATE NOT NULL,
+    fiscal_year_end_date DATE,
+    disclosed_date DATE NOT NULL,
+
+    -- F-scoreの内訳（1: 該当, 0: 非該当, NULL: データ不足で判定不能）
+    f_roa_positive TINYINT(1) COMMENT 'ROA（当期純利益/期末総資産）が正',
+    f_cfo_positive TINYINT(1) COMMENT '営業CFが正',
+    f_roa_improved TINYINT(1) COMMENT 'ROAが前期より改善',
+    f_accrual TINYINT(1) COMMENT '営業CFが当期純利益を上回る',
+    f_leverage_improved TINYINT(1) COMMENT '自己資本比率が前期より上昇（長期負債比率低下の代替）',
+    f_liquidity_improved TINYINT(1) COMMENT '現金同等物/総資産が前期より上昇（流動比率改善の代替）',
+    f_no_dilution TINYINT(1) COMMENT '発行済株式数が前期から増加していない',
+    f_margin_improved TINYINT(1) COMMENT '営業利益率が前期より改善（粗利益率改善の代替）',
+    f_turnover_improved TINYINT(1) COMMENT '総資産回転率が前期より改善',
+    f_score TINYINT NOT NULL COMMENT '該当した項目数（0〜9）',
+    f_score_evaluated TINYINT NOT NULL COMMENT '判定できた項目数（0〜9）',
+
+    -- Z-scoreの内訳（運転資本・利益剰余金は取得できないため現金同等物・純資産で代替）
+    z_cash_to_assets DECIMAL(10,4) COMMENT 'X1: 現金同等物/総資産',
+    z_equity_to_assets DECIMAL(10,4) COMMENT 'X2: 純資産/総資産',
+    z_operating_profit_to_assets DECIMAL(10,4) COMMENT 'X3: 営業利益/総資産',
+    z_market_value_to_liabilities DECIMAL(10,4) COMMENT 'X4: 期末時価総額/負債（総資産-純資産）',
+    z_sales_to_assets DECIMAL(10,4) COMMENT 'X5: 売上高/総資産',
+    market_value_equity BIGINT COMMENT '期末日以前の直近終値×自己株式を除く期末発行済株式数',
+    z_score DECIMAL(10,2) COMMENT '1.2*X1 + 1.4*X2 + 3.3*X3 + 0.6*X4 + 1.0*X5',
+    z_zone ENUM('safe', 'grey', 'distress'),
+
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+    -- プライマリキー
+    PRIMARY KEY (local_code, fiscal_year_start_date),
+
+    -- 外部キー制約
+    CONSTRAINT fk_quality_scores_local_code FOREIGN KEY (local_code) REFERENCES listed_info(code),
+
+    -- インデックス
+    INDEX idx_quality_scores_f_score (f_score),
+    INDEX idx_quality_scores_z_score (z_score)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
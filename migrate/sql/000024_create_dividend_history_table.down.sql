@@ -0,0 +1,2 @@
+-- 配当履歴テーブルを削除
+DROP TABLE IF EXISTS dividend_history;
@@ -0,0 +1,32 @@
+-- 配当履歴テーブルを作成
+-- 会計年度ごとの年間配当（実績、実績がない年度は最新の予想）を株式分割調整後の値とともに管理し、増配・非減配の連続年数を記録
+CREATE TABLE IF NOT EXISTS dividend_history (
+    local_code VARCHAR(10) NOT NULL,
+    fiscal_year_start_date DATE NOT NULL,
+    fiscal_year_end_date DATE,
+    disclosed_date DATE NOT NULL,
+    is_forecast BOOLEAN NOT NULL DEFAULT FALSE,
+
+    -- 年間配当
+    dividend_per_share DECIMAL(10,2) NOT NULL COMMENT '開示された年間配当',
+    adjustment_ratio DECIMAL(16,8) NOT NULL DEFAULT 1 COMMENT '期末日より後の株式分割・併合の累積調整係数',
+    adjusted_dividend_per_share DECIMAL(12,4) NOT NULL COMMENT '現在の株式数ベースに調整した年間配当',
+    change_pct DECIMAL(10,2) COMMENT '前期比(%)（調整後で比較）',
+
+    -- 連続年数（前期が存在しない年度で途切れる）
+    increase_streak INT NOT NULL DEFAULT 0 COMMENT '連続増配年数',
+    no_cut_streak INT NOT NULL DEFAULT 0 COMMENT '連続非減配年数（無配の年度は含めない）',
+    dps_cagr_5y DECIMAL(10,2) COMMENT '5年前の年度からの年平均成長率(%)',
+
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+    -- プライマリキー
+    PRIMARY KEY (local_code, fiscal_year_start_date),
+
+    -- 外部キー制約
+    CONSTRAINT fk_dividend_history_local_code FOREIGN KEY (local_code) REFERENCES listed_info(code),
+
+    -- インデックス
+    INDEX idx_dividend_history_increase_streak (increase_streak)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
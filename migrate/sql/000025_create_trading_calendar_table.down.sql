@@ -0,0 +1,2 @@
+-- 取引カレンダーテーブルを削除
+DROP TABLE IF EXISTS trading_calendar;
@@ -0,0 +1,12 @@
+-- 取引カレンダーテーブルを作成
+-- J-Quantsの取引カレンダー（東証の営業日・休業日）を管理
+CREATE TABLE IF NOT EXISTS trading_calendar (
+    calendar_date DATE NOT NULL,
+    holiday_division VARCHAR(1) NOT NULL COMMENT '0: 非営業日, 1: 営業日, 2: 東証半日立会日, 3: 非営業日(祝日取引あり)',
+
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+    -- プライマリキー
+    PRIMARY KEY (calendar_date)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
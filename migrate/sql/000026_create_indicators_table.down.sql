@@ -0,0 +1,2 @@
+-- テクニカル指標テーブルを削除
+DROP TABLE IF EXISTS indicators;
@@ -0,0 +1,19 @@
+-- テクニカル指標テーブルを作成
+-- daily_quotesの調整後終値・調整後出来高から算出した移動平均・RSI・MACD・ボリンジャーバンドを、指標名ごとの行として管理
+-- 指標名は期間などのパラメータを含む（例: sma_25, rsi_14, macd_12_26_9, bb_upper_20_2）
+CREATE TABLE IF NOT EXISTS indicators (
+    code VARCHAR(10) NOT NULL,
+    trade_date DATE NOT NULL,
+    name VARCHAR(32) NOT NULL COMMENT '指標名',
+    value DECIMAL(20,4) NOT NULL,
+
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+    -- プライマリキー
+    PRIMARY KEY (code, trade_date, name),
+
+    -- インデックス
+    INDEX idx_indicators_name_trade_date (name, trade_date),
+    INDEX idx_indicators_trade_date (trade_date)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,2 @@
+-- 銘柄評価の期間別指標テーブルを削除
+DROP TABLE IF EXISTS assessment_window_metrics;
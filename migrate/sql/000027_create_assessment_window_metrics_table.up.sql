@@ -0,0 +1,25 @@
+-- 銘柄評価の期間別指標テーブルを作成
+-- assessmentの3か月固定のカラムに代わり、設定した任意の期間（例: 1M, 3M, 52W, 3Y）ごとの最高・最低調整終値と乖離率を行として管理
+CREATE TABLE IF NOT EXISTS assessment_window_metrics (
+    code VARCHAR(10) NOT NULL,
+    window_label VARCHAR(10) NOT NULL COMMENT '期間（例: 3M, 52W）',
+    window_start_date DATE NOT NULL COMMENT '期間の開始日',
+    last_trade_date DATE NOT NULL COMMENT '期間の終了日（最終取引日）',
+    last_adjustment_close DECIMAL(10,2) NOT NULL,
+    max_close DECIMAL(10,2) NOT NULL COMMENT '期間中の最高調整終値',
+    max_close_date DATE NOT NULL,
+    min_close DECIMAL(10,2) NOT NULL COMMENT '期間中の最低調整終値',
+    min_close_date DATE NOT NULL,
+    deviation_from_max DECIMAL(7,2) COMMENT '最高値からの乖離率(%)',
+    deviation_from_min DECIMAL(7,2) COMMENT '最低値からの乖離率(%)',
+    trading_days INT NOT NULL COMMENT '期間中の取引日数',
+
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+    -- プライマリキー
+    PRIMARY KEY (code, window_label),
+
+    -- インデックス
+    INDEX idx_assessment_window_metrics_window (window_label, deviation_from_max)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,2 @@
+-- コーポレートアクションテーブルを削除
+DROP TABLE IF EXISTS corporate_actions;
@@ -0,0 +1,19 @@
+-- コーポレートアクションテーブルを作成
+-- daily_quotesの調整係数（adjustment_factor）が1以外の日を株式分割・併合として記録し、過去の調整後株価の再計算状況を管理
+CREATE TABLE IF NOT EXISTS corporate_actions (
+    code VARCHAR(10) NOT NULL,
+    ex_date DATE NOT NULL COMMENT '権利落ち日（調整係数が設定された取引日）',
+    action_type VARCHAR(20) NOT NULL COMMENT 'split: 株式分割, reverse_split: 株式併合',
+    adjustment_factor DECIMAL(10,6) NOT NULL COMMENT '調整係数（例: 1:2の分割は0.5）',
+    split_ratio DECIMAL(12,6) NOT NULL COMMENT '1株あたりの分割・併合後の株数（調整係数の逆数）',
+    readjusted_at TIMESTAMP NULL COMMENT '過去の調整後株価を再計算した日時',
+
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+    -- プライマリキー
+    PRIMARY KEY (code, ex_date),
+
+    -- インデックス
+    INDEX idx_corporate_actions_ex_date (ex_date)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
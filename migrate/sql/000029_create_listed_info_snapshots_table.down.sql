@@ -0,0 +1,2 @@
+-- 上場銘柄一覧スナップショットテーブルを削除
+DROP TABLE IF EXISTS listed_info_snapshots;
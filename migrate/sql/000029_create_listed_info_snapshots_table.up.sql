@@ -0,0 +1,23 @@
+-- 上場銘柄一覧スナップショットテーブルを作成
+-- 過去日付の上場銘柄一覧（/listed/info?date=）を適用日ごとに全銘柄分保存し、指定日時点の上場銘柄（ユニバース）の判定に使用
+-- 上場廃止済みの銘柄も含むため、listed_infoへの外部キー制約は設けない
+CREATE TABLE IF NOT EXISTS listed_info_snapshots (
+    snapshot_date DATE NOT NULL COMMENT '適用日',
+    code VARCHAR(10) NOT NULL,
+    company_name VARCHAR(255) NOT NULL,
+    company_name_english VARCHAR(255),
+    market_code VARCHAR(10),
+    sector17_code VARCHAR(10),
+    sector33_code VARCHAR(10),
+    scale_category VARCHAR(100),
+    margin_code VARCHAR(10),
+
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+    -- プライマリキー
+    PRIMARY KEY (snapshot_date, code),
+
+    -- インデックス
+    INDEX idx_listed_info_snapshots_code (code, snapshot_date)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,2 @@
+-- ポートフォリオテーブルを削除
+DROP TABLE IF EXISTS portfolio;
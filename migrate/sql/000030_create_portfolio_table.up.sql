@@ -0,0 +1,16 @@
+-- ポートフォリオテーブルを作成
+-- 実際の保有銘柄を管理する単位（口座・戦略など）
+CREATE TABLE IF NOT EXISTS portfolio (
+    id BIGINT NOT NULL AUTO_INCREMENT,
+    name VARCHAR(64) NOT NULL COMMENT 'ポートフォリオ名',
+    description VARCHAR(255),
+
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+    -- プライマリキー
+    PRIMARY KEY (id),
+
+    -- ユニークキー
+    UNIQUE KEY uk_portfolio_name (name)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,2 @@
+-- 保有銘柄テーブルを削除
+DROP TABLE IF EXISTS holdings;
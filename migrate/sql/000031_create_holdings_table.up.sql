@@ -0,0 +1,21 @@
+-- 保有銘柄テーブルを作成
+-- ポートフォリオごとの銘柄の保有株数と平均取得単価（移動平均）を管理
+CREATE TABLE IF NOT EXISTS holdings (
+    portfolio_id BIGINT NOT NULL,
+    code VARCHAR(10) NOT NULL,
+    quantity BIGINT NOT NULL COMMENT '保有株数',
+    average_cost DECIMAL(14,4) NOT NULL COMMENT '平均取得単価（円）',
+
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+    -- プライマリキー
+    PRIMARY KEY (portfolio_id, code),
+
+    -- 外部キー制約
+    CONSTRAINT fk_holdings_portfolio_id FOREIGN KEY (portfolio_id) REFERENCES portfolio(id) ON DELETE CASCADE,
+    CONSTRAINT fk_holdings_code FOREIGN KEY (code) REFERENCES listed_info(code),
+
+    -- インデックス
+    INDEX idx_holdings_code (code)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
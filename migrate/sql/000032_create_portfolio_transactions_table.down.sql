@@ -0,0 +1,2 @@
+-- 取引履歴テーブルを削除
+DROP TABLE IF EXISTS portfolio_transactions;
@@ -0,0 +1,31 @@
+-- 取引履歴テーブルを作成
+-- ポートフォリオごとの買い・売り・配当・株式分割を記録し、移動平均法による実現損益の計算に使用
+CREATE TABLE IF NOT EXISTS portfolio_transactions (
+    id BIGINT NOT NULL AUTO_INCREMENT,
+    portfolio_id BIGINT NOT NULL,
+    code VARCHAR(10) NOT NULL,
+    transaction_type VARCHAR(10) NOT NULL COMMENT 'buy: 買い, sell: 売り, dividend: 配当, split: 株式分割・併合',
+    trade_date DATE NOT NULL COMMENT '約定日（配当は支払日、株式分割・併合は権利落ち日）',
+    settlement_date DATE NOT NULL COMMENT '受渡日（売買は約定日からT+2、それ以外は約定日と同じ）',
+    quantity BIGINT NOT NULL DEFAULT 0 COMMENT '株数（配当は対象株数、株式分割・併合は0）',
+    price DECIMAL(14,4) COMMENT '約定単価（円）',
+    fee DECIMAL(14,2) NOT NULL DEFAULT 0 COMMENT '手数料（消費税込み）',
+    amount DECIMAL(16,2) COMMENT '配当金額（税引前）',
+    tax DECIMAL(14,2) NOT NULL DEFAULT 0 COMMENT '源泉徴収税額',
+    split_ratio DECIMAL(12,6) COMMENT '1株あたりの分割・併合後の株数',
+    note VARCHAR(255),
+
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+    -- プライマリキー
+    PRIMARY KEY (id),
+
+    -- 外部キー制約
+    CONSTRAINT fk_portfolio_transactions_portfolio_id FOREIGN KEY (portfolio_id) REFERENCES portfolio(id) ON DELETE CASCADE,
+    CONSTRAINT fk_portfolio_transactions_code FOREIGN KEY (code) REFERENCES listed_info(code),
+
+    -- インデックス
+    INDEX idx_portfolio_transactions_code_date (portfolio_id, code, trade_date),
+    INDEX idx_portfolio_transactions_settlement_date (portfolio_id, settlement_date)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,5 @@
+-- portfolio_transactionsテーブルから取り込み元と重複判定用のキーを削除
+ALTER TABLE portfolio_transactions
+    DROP INDEX uk_portfolio_transactions_import_key,
+    DROP COLUMN import_key,
+    DROP COLUMN source;
@@ -0,0 +1,5 @@
+-- portfolio_transactionsテーブルに取り込み元と重複判定用のキーを追加
+ALTER TABLE portfolio_transactions
+    ADD COLUMN source VARCHAR(20) NOT NULL DEFAULT 'manual' COMMENT '取り込み元（manual: 手入力, sbi, rakuten, generic: CSV取り込み）' AFTER note,
+    ADD COLUMN import_key CHAR(64) COMMENT 'CSV取り込み時の重複判定用キー（約定内容のSHA-256）' AFTER source,
+    ADD UNIQUE KEY uk_portfolio_transactions_import_key (portfolio_id, import_key);
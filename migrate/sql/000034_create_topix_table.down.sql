@@ -0,0 +1,2 @@
+-- TOPIXテーブルを削除
+DROP TABLE IF EXISTS topix;
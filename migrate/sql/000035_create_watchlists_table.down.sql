@@ -0,0 +1,2 @@
+-- ウォッチリストテーブルを削除
+DROP TABLE IF EXISTS watchlists;
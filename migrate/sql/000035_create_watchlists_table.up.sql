@@ -0,0 +1,16 @@
+-- ウォッチリストテーブルを作成
+-- 監視対象の銘柄をまとめる単位（テーマ・検討中など）
+CREATE TABLE IF NOT EXISTS watchlists (
+    id BIGINT NOT NULL AUTO_INCREMENT,
+    name VARCHAR(64) NOT NULL COMMENT 'ウォッチリスト名',
+    description VARCHAR(255),
+
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+    -- プライマリキー
+    PRIMARY KEY (id),
+
+    -- ユニークキー
+    UNIQUE KEY uk_watchlists_name (name)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
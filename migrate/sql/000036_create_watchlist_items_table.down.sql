@@ -0,0 +1,2 @@
+-- ウォッチリスト銘柄テーブルを削除
+DROP TABLE IF EXISTS watchlist_items;
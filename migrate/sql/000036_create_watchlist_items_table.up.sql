@@ -0,0 +1,19 @@
+-- ウォッチリスト銘柄テーブルを作成
+-- ウォッチリストごとの監視対象の銘柄を管理（created_atを追加日とする）
+CREATE TABLE IF NOT EXISTS watchlist_items (
+    watchlist_id BIGINT NOT NULL,
+    code VARCHAR(10) NOT NULL,
+
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+    -- プライマリキー
+    PRIMARY KEY (watchlist_id, code),
+
+    -- 外部キー制約
+    CONSTRAINT fk_watchlist_items_watchlist_id FOREIGN KEY (watchlist_id) REFERENCES watchlists(id) ON DELETE CASCADE,
+    CONSTRAINT fk_watchlist_items_code FOREIGN KEY (code) REFERENCES listed_info(code),
+
+    -- インデックス
+    INDEX idx_watchlist_items_code (code)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
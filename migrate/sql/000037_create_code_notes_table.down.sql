@@ -0,0 +1,2 @@
+-- 銘柄メモテーブルを削除
+DROP TABLE IF EXISTS code_notes;
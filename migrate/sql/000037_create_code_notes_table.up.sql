@@ -0,0 +1,19 @@
+-- 銘柄メモテーブルを作成
+-- 銘柄ごとの調査メモを時系列で管理
+CREATE TABLE IF NOT EXISTS code_notes (
+    id BIGINT NOT NULL AUTO_INCREMENT,
+    code VARCHAR(10) NOT NULL,
+    note TEXT NOT NULL,
+
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+    -- プライマリキー
+    PRIMARY KEY (id),
+
+    -- 外部キー制約
+    CONSTRAINT fk_code_notes_code FOREIGN KEY (code) REFERENCES listed_info(code),
+
+    -- インデックス
+    INDEX idx_code_notes_code_created_at (code, created_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,2 @@
+-- 銘柄タグテーブルを削除
+DROP TABLE IF EXISTS code_tags;
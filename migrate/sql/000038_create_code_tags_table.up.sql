@@ -0,0 +1,17 @@
+-- 銘柄タグテーブルを作成
+-- 銘柄に付けた任意のタグ（テーマ・分類など）を管理
+CREATE TABLE IF NOT EXISTS code_tags (
+    code VARCHAR(10) NOT NULL,
+    tag VARCHAR(64) NOT NULL COMMENT 'タグ',
+
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    -- プライマリキー
+    PRIMARY KEY (code, tag),
+
+    -- 外部キー制約
+    CONSTRAINT fk_code_tags_code FOREIGN KEY (code) REFERENCES listed_info(code),
+
+    -- インデックス
+    INDEX idx_code_tags_tag (tag)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
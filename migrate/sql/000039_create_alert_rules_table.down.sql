@@ -0,0 +1,2 @@
+-- アラートルールテーブルを削除
+DROP TABLE IF EXISTS alert_rules;
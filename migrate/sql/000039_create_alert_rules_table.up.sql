@@ -0,0 +1,23 @@
+-- アラートルールテーブルを作成
+-- 指標（乖離率・配当利回り・株価など）としきい値の条件と、対象の銘柄（銘柄コード・ウォッチリスト・タグ、未指定の場合は全銘柄）を管理
+CREATE TABLE IF NOT EXISTS alert_rules (
+    id BIGINT NOT NULL AUTO_INCREMENT,
+    name VARCHAR(64) NOT NULL COMMENT 'ルール名',
+    metric VARCHAR(32) NOT NULL COMMENT '指標（例: deviation_from_min, forecast_dividend_yield）',
+    param VARCHAR(32) NULL COMMENT '指標のパラメータ（期間別評価指標の期間、テクニカル指標の指標名）',
+    operator VARCHAR(2) NOT NULL COMMENT '比較演算子（<, <=, >, >=）',
+    threshold DECIMAL(20,4) NOT NULL COMMENT 'しきい値',
+    code VARCHAR(10) NULL COMMENT '対象の銘柄コード',
+    watchlist VARCHAR(64) NULL COMMENT '対象のウォッチリスト名',
+    tag VARCHAR(64) NULL COMMENT '対象のタグ',
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+    -- プライマリキー
+    PRIMARY KEY (id),
+
+    -- ユニークキー
+    UNIQUE KEY uk_alert_rules_name (name)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,2 @@
+-- アラート発生履歴テーブルを削除
+DROP TABLE IF EXISTS alert_events;
@@ -0,0 +1,23 @@
+-- アラート発生履歴テーブルを作成
+-- ルール・銘柄・取引日ごとに1件とし、同じ日の同じ条件の通知が重複しないようにする（notified_atがNULLのものは未通知）
+CREATE TABLE IF NOT EXISTS alert_events (
+    rule_id BIGINT NOT NULL,
+    code VARCHAR(10) NOT NULL,
+    trade_date DATE NOT NULL,
+    value DECIMAL(20,4) NOT NULL COMMENT '評価時の指標の値',
+    threshold DECIMAL(20,4) NOT NULL COMMENT '評価時のしきい値',
+    notified_at TIMESTAMP NULL COMMENT '通知日時',
+
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+    -- プライマリキー
+    PRIMARY KEY (rule_id, code, trade_date),
+
+    -- 外部キー制約
+    CONSTRAINT fk_alert_events_rule_id FOREIGN KEY (rule_id) REFERENCES alert_rules(id) ON DELETE CASCADE,
+
+    -- インデックス
+    INDEX idx_alert_events_trade_date (trade_date),
+    INDEX idx_alert_events_notified_at (notified_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,250 @@
+package alert
+
+import (
+	"fmt"
+	"os"
+	"stock-automation/database"
+	"stock-automation/helper"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+var AlertCmd = &cobra.Command{
+	Use:   "alert",
+	Short: "アラートルール管理",
+	Long: `指標としきい値によるアラートルールを登録・表示する機能を提供します。
+登録した有効なルールはjquants daily（またはjquants alerts）の実行後に評価され、条件に一致した銘柄がALERT_NOTIFIERSの送信先へ通知されます。
+同じルール・銘柄・取引日の通知は1回のみです。`,
+}
+
+var addCmd = &cobra.Command{
+	Use:   "add",
+	Short: "アラートルールを追加",
+	Long: `アラートルールを追加します。対象は--code・--watchlist・--tagで指定し、指定しない場合は全銘柄を対象とします。
+
+指標:
+  deviation_from_max       最高値からの乖離率(%)（--paramは期間、省略時は3M）
+  deviation_from_min       最低値からの乖離率(%)（--paramは期間、省略時は3M）
+  forecast_dividend_yield  予想配当利回り(%)
+  forecast_per             予想PER
+  actual_per               実績PER
+  pbr                      PBR
+  market_cap               時価総額
+  close                    終値
+  volume                   出来高
+  change_pct               前日比(%)
+  indicator                テクニカル指標（--paramは指標名、例: rsi_14）
+
+例:
+  sa alert add --name near-low --metric deviation_from_min --param 52W --op "<" --threshold 3 --watchlist default
+  sa alert add --name high-yield --metric forecast_dividend_yield --op ">" --threshold 5 --tag 高配当`,
+	RunE: addRule,
+}
+
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "アラートルールを表示",
+	Long:  "登録したアラートルールを名前順に表示します",
+	RunE:  listRules,
+}
+
+var removeCmd = &cobra.Command{
+	Use:   "remove",
+	Short: "アラートルールを削除",
+	Long:  "アラートルールを発生履歴ごと削除します",
+	RunE:  removeRule,
+}
+
+var enableCmd = &cobra.Command{
+	Use:   "enable",
+	Short: "アラートルールを有効にする",
+	Long:  "無効にしたアラートルールを再び評価の対象にします",
+	RunE:  func(cmd *cobra.Command, args []string) error { return setRuleEnabled(cmd, true) },
+}
+
+var disableCmd = &cobra.Command{
+	Use:   "disable",
+	Short: "アラートルールを無効にする",
+	Long:  "アラートルールを削除せずに評価の対象外にします",
+	RunE:  func(cmd *cobra.Command, args []string) error { return setRuleEnabled(cmd, false) },
+}
+
+func init() {
+	// フラグを追加
+	addCmd.Flags().String("name", "", "ルール名（必須）")
+	addCmd.Flags().String("metric", "", "指標（必須）")
+	addCmd.Flags().String("param", "", "指標のパラメータ（期間、テクニカル指標の指標名）")
+	addCmd.Flags().String("op", "", "比較演算子（<, <=, >, >=、必須）")
+	addCmd.Flags().Float64("threshold", 0, "しきい値（必須）")
+	addCmd.Flags().String("code", "", "対象の銘柄コード")
+	addCmd.Flags().String("watchlist", "", "対象のウォッチリスト")
+	addCmd.Flags().String("tag", "", "対象のタグ")
+	addCmd.Flags().Bool("disabled", false, "無効な状態で追加")
+	addCmd.MarkFlagRequired("name")
+	addCmd.MarkFlagRequired("metric")
+	addCmd.MarkFlagRequired("op")
+	addCmd.MarkFlagRequired("threshold")
+
+	for _, cmd := range []*cobra.Command{removeCmd, enableCmd, disableCmd} {
+		cmd.Flags().String("name", "", "ルール名（必須）")
+		cmd.MarkFlagRequired("name")
+	}
+
+	AlertCmd.AddCommand(addCmd)
+	AlertCmd.AddCommand(listCmd)
+	AlertCmd.AddCommand(removeCmd)
+	AlertCmd.AddCommand(enableCmd)
+	AlertCmd.AddCommand(disableCmd)
+	AlertCmd.AddCommand(testCmd)
+	AlertCmd.AddCommand(historyCmd)
+}
+
+func addRule(cmd *cobra.Command, args []string) error {
+	rule := &database.AlertRule{Enabled: true}
+	rule.Name, _ = cmd.Flags().GetString("name")
+	rule.Metric, _ = cmd.Flags().GetString("metric")
+	rule.Param, _ = cmd.Flags().GetString("param")
+	rule.Operator, _ = cmd.Flags().GetString("op")
+	rule.Threshold, _ = cmd.Flags().GetFloat64("threshold")
+	rule.Code, _ = cmd.Flags().GetString("code")
+	rule.Filter.Watchlist, _ = cmd.Flags().GetString("watchlist")
+	rule.Filter.Tag, _ = cmd.Flags().GetString("tag")
+	disabled, _ := cmd.Flags().GetBool("disabled")
+
+	rule.Name = strings.TrimSpace(rule.Name)
+	rule.Metric = strings.ToLower(strings.TrimSpace(rule.Metric))
+	rule.Param = strings.TrimSpace(rule.Param)
+	rule.Operator = strings.TrimSpace(rule.Operator)
+	if rule.Code != "" {
+		rule.Code = helper.NormalizeCode(rule.Code)
+	}
+	rule.Enabled = !disabled
+
+	// データベース接続
+	conn, err := database.NewConnectionFromEnv(false)
+	if err != nil {
+		return fmt.Errorf("データベース接続エラー: %v", err)
+	}
+	defer conn.Close()
+
+	if rule.Filter.Watchlist != "" {
+		watchlist, err := database.NewWatchlistRepository(conn).GetWatchlist(rule.Filter.Watchlist)
+		if err != nil {
+			return err
+		}
+		if watchlist == nil {
+			return fmt.Errorf("ウォッチリストが見つかりません: %s", rule.Filter.Watchlist)
+		}
+	}
+
+	if err := database.NewAlertRepository(conn).AddRule(rule); err != nil {
+		return fmt.Errorf("アラートルール追加エラー: %v", err)
+	}
+
+	fmt.Printf("アラートルールを追加しました: %s（%s、対象: %s）\n", rule.Name, rule.Condition(), rule.Target())
+	return nil
+}
+
+func listRules(cmd *cobra.Command, args []string) error {
+	// データベース接続
+	conn, err := database.NewConnectionFromEnv(false)
+	if err != nil {
+		return fmt.Errorf("データベース接続エラー: %v", err)
+	}
+	defer conn.Close()
+
+	rules, err := database.NewAlertRepository(conn).GetRules(false)
+	if err != nil {
+		return fmt.Errorf("データ取得エラー: %v", err)
+	}
+
+	fmt.Printf("\n=== アラートルール ===\n\n")
+
+	// ヘッダーを表示
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ルール名\t条件\t対象\t状態\t作成日")
+	fmt.Fprintln(w, "----\t----\t----\t----\t----")
+
+	for _, rule := range rules {
+		status := "有効"
+		if !rule.Enabled {
+			status = "無効"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+			rule.Name, rule.Condition(), rule.Target(), status, rule.CreatedAt.Format("2006-01-02"))
+	}
+
+	w.Flush()
+
+	if len(rules) == 0 {
+		fmt.Println("データが見つかりませんでした")
+	} else {
+		fmt.Printf("\nルール数: %d\n", len(rules))
+	}
+
+	return nil
+}
+
+func removeRule(cmd *cobra.Command, args []string) error {
+	// データベース接続
+	conn, err := database.NewConnectionFromEnv(false)
+	if err != nil {
+		return fmt.Errorf("データベース接続エラー: %v", err)
+	}
+	defer conn.Close()
+
+	repository := database.NewAlertRepository(conn)
+	rule, err := getRule(cmd, repository)
+	if err != nil {
+		return err
+	}
+
+	if err := repository.DeleteRule(rule.ID); err != nil {
+		return err
+	}
+
+	fmt.Printf("アラートルールを削除しました: %s\n", rule.Name)
+	return nil
+}
+
+func setRuleEnabled(cmd *cobra.Command, enabled bool) error {
+	// データベース接続
+	conn, err := database.NewConnectionFromEnv(false)
+	if err != nil {
+		return fmt.Errorf("データベース接続エラー: %v", err)
+	}
+	defer conn.Close()
+
+	repository := database.NewAlertRepository(conn)
+	rule, err := getRule(cmd, repository)
+	if err != nil {
+		return err
+	}
+
+	if err := repository.SetRuleEnabled(rule.ID, enabled); err != nil {
+		return err
+	}
+
+	if enabled {
+		fmt.Printf("アラートルールを有効にしました: %s\n", rule.Name)
+	} else {
+		fmt.Printf("アラートルールを無効にしました: %s\n", rule.Name)
+	}
+	return nil
+}
+
+// getRule フラグで指定したルールを取得
+func getRule(cmd *cobra.Command, repository *database.AlertRepository) (*database.AlertRule, error) {
+	name, _ := cmd.Flags().GetString("name")
+
+	rule, err := repository.GetRule(name)
+	if err != nil {
+		return nil, err
+	}
+	if rule == nil {
+		return nil, fmt.Errorf("アラートルールが見つかりません: %s", name)
+	}
+	return rule, nil
+}
@@ -0,0 +1,154 @@
+package alert
+
+import (
+	"fmt"
+	"os"
+	"stock-automation/database"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var testCmd = &cobra.Command{
+	Use:   "test",
+	Short: "アラートルールを試し評価",
+	Long:  "アラートルールを評価し、条件に一致する銘柄を表示します（発生履歴への保存・通知は行いません。無効なルールも評価できます）",
+	RunE:  testRule,
+}
+
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "アラートの発生履歴を表示",
+	Long:  "条件に一致した銘柄の発生履歴を取引日の新しい順に表示します",
+	RunE:  showHistory,
+}
+
+func init() {
+	// フラグを追加
+	testCmd.Flags().String("name", "", "ルール名（必須）")
+	testCmd.Flags().StringP("date", "d", "", "評価する取引日（YYYY-MM-DD形式、指定しない場合はdaily_quotesの最新取引日）")
+	testCmd.MarkFlagRequired("name")
+
+	historyCmd.Flags().String("name", "", "ルール名（指定しない場合は全ルール）")
+	historyCmd.Flags().String("from", "", "取引日の開始日（YYYY-MM-DD形式）")
+	historyCmd.Flags().String("watchlist", "", "ウォッチリストの銘柄に絞り込む")
+	historyCmd.Flags().String("tag", "", "タグを付けた銘柄に絞り込む")
+	historyCmd.Flags().IntP("limit", "l", 50, "表示する行数の上限")
+	historyCmd.Flags().BoolP("all", "a", false, "全ての行を表示")
+}
+
+func testRule(cmd *cobra.Command, args []string) error {
+	date, _ := cmd.Flags().GetString("date")
+
+	// データベース接続
+	conn, err := database.NewConnectionFromEnv(false)
+	if err != nil {
+		return fmt.Errorf("データベース接続エラー: %v", err)
+	}
+	defer conn.Close()
+
+	repository := database.NewAlertRepository(conn)
+	rule, err := getRule(cmd, repository)
+	if err != nil {
+		return err
+	}
+
+	var tradeDate time.Time
+	if date != "" {
+		tradeDate, err = time.ParseInLocation("2006-01-02", date, time.Local)
+		if err != nil {
+			return fmt.Errorf("日付の形式が正しくありません（YYYY-MM-DD）: %s", date)
+		}
+	} else {
+		latest, err := repository.GetLatestTradeDate()
+		if err != nil {
+			return err
+		}
+		if latest == nil {
+			return fmt.Errorf("株価データがありません")
+		}
+		tradeDate = *latest
+	}
+
+	events, err := repository.Evaluate(rule, tradeDate)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("\n=== アラート試し評価（%s、%s） ===\n\n", rule.Name, tradeDate.Format("2006-01-02"))
+	fmt.Printf("条件: %s\n対象: %s\n\n", rule.Condition(), rule.Target())
+
+	// ヘッダーを表示
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "コード\t企業名\t値")
+	fmt.Fprintln(w, "----\t----\t----")
+
+	for _, e := range events {
+		fmt.Fprintf(w, "%s\t%s\t%g\n", e.Code, e.CompanyName, e.Value)
+	}
+
+	w.Flush()
+
+	if len(events) == 0 {
+		fmt.Println("条件に一致する銘柄はありません")
+	} else {
+		fmt.Printf("\n該当銘柄数: %d\n", len(events))
+	}
+
+	return nil
+}
+
+func showHistory(cmd *cobra.Command, args []string) error {
+	name, _ := cmd.Flags().GetString("name")
+	from, _ := cmd.Flags().GetString("from")
+	limit, _ := cmd.Flags().GetInt("limit")
+	showAll, _ := cmd.Flags().GetBool("all")
+
+	var filter database.CodeFilter
+	filter.Watchlist, _ = cmd.Flags().GetString("watchlist")
+	filter.Tag, _ = cmd.Flags().GetString("tag")
+
+	if showAll {
+		limit = 0
+	}
+
+	// データベース接続
+	conn, err := database.NewConnectionFromEnv(false)
+	if err != nil {
+		return fmt.Errorf("データベース接続エラー: %v", err)
+	}
+	defer conn.Close()
+
+	events, err := database.NewAlertRepository(conn).GetEvents(name, from, filter, limit)
+	if err != nil {
+		return fmt.Errorf("データ取得エラー: %v", err)
+	}
+
+	fmt.Printf("\n=== アラート発生履歴 ===\n\n")
+
+	// ヘッダーを表示
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "取引日\tルール名\tコード\t企業名\t指標\t値\tしきい値\t通知日時")
+	fmt.Fprintln(w, "----\t----\t----\t----\t----\t----\t----\t----")
+
+	for _, e := range events {
+		notifiedAt := "未通知"
+		if e.NotifiedAt != nil {
+			notifiedAt = e.NotifiedAt.Format("2006-01-02 15:04")
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%g\t%s %g\t%s\n",
+			e.TradeDate.Format("2006-01-02"), e.Rule.Name, e.Code, e.CompanyName,
+			e.Rule.MetricLabel(), e.Value, e.Rule.Operator, e.Threshold, notifiedAt)
+	}
+
+	w.Flush()
+
+	if len(events) == 0 {
+		fmt.Println("データが見つかりませんでした")
+	} else {
+		fmt.Printf("\n表示行数: %d\n", len(events))
+	}
+
+	return nil
+}
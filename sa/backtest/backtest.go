@@ -0,0 +1,334 @@
+package backtest
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"stock-automation/database"
+	"stock-automation/helper"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var BacktestCmd = &cobra.Command{
+	Use:   "backtest",
+	Short: "バックテスト",
+	Long: "daily_quotesの日足と各取引日時点の株価指標（valuations）を使って売買戦略を検証します\n" +
+		"注文は翌取引日の始値で約定し、東証の呼値・売買単位と手数料を考慮します（配当は考慮しません）\n\n" +
+		"利用可能な戦略:\n" +
+		"  - value: 株価指標の条件に合う銘柄のうち上位銘柄に定期的に等金額で入れ替え\n" +
+		"  - sma_cross: 短期移動平均が長期移動平均を上抜けたら買い、下抜けたら売り（--codes必須）",
+	RunE: runBacktest,
+}
+
+func init() {
+	// フラグを追加
+	BacktestCmd.Flags().String("strategy", "value", "戦略（value, sma_cross）")
+	BacktestCmd.Flags().String("from", "", "開始日（YYYY-MM-DD形式、必須）")
+	BacktestCmd.Flags().String("to", "", "終了日（YYYY-MM-DD形式、指定しない場合は当日）")
+	BacktestCmd.Flags().Float64("cash", 10000000, "初期資金（円）")
+	BacktestCmd.Flags().String("commission", "tiered", "手数料モデル（zero, tiered, rate:料率%[:最低手数料]）")
+	BacktestCmd.Flags().Int("slippage-ticks", 0, "約定価格を始値から不利な方向にずらす呼値の数")
+	BacktestCmd.Flags().Int("lot", DefaultLotSize, "売買単位（株）")
+	BacktestCmd.Flags().String("codes", "", "対象銘柄コード（カンマ区切り、指定しない場合は全銘柄）")
+	BacktestCmd.Flags().StringSlice("market", nil, "取引日時点の市場区分コードでユニバースを絞り込む（カンマ区切りで複数指定可）")
+	BacktestCmd.Flags().StringSlice("scale", nil, "取引日時点の規模区分でユニバースを絞り込む（カンマ区切りで複数指定可）")
+	BacktestCmd.Flags().StringSlice("sector17", nil, "取引日時点の17業種コードでユニバースを絞り込む（カンマ区切りで複数指定可）")
+	BacktestCmd.Flags().StringSlice("sector33", nil, "取引日時点の33業種コードでユニバースを絞り込む（カンマ区切りで複数指定可）")
+
+	// value戦略
+	BacktestCmd.Flags().Float64("max-per", 0, "[value] 予想PERの上限")
+	BacktestCmd.Flags().Float64("max-pbr", 0, "[value] PBRの上限")
+	BacktestCmd.Flags().Float64("min-yield", 0, "[value] 予想配当利回り(%)の下限")
+	BacktestCmd.Flags().Int64("min-market-cap", 0, "[value] 時価総額（円）の下限")
+	BacktestCmd.Flags().String("sort", "yield", "[value] 並べ替えキー（yield, per, pbr, market_cap）")
+	BacktestCmd.Flags().Int("holdings", 10, "[value] 保有銘柄数")
+	BacktestCmd.Flags().Int("rebalance", 1, "[value] 入れ替え間隔（月）")
+
+	// sma_cross戦略
+	BacktestCmd.Flags().Int("fast", 25, "[sma_cross] 短期移動平均の期間")
+	BacktestCmd.Flags().Int("slow", 75, "[sma_cross] 長期移動平均の期間")
+
+	// 出力
+	BacktestCmd.Flags().Bool("trades", false, "約定一覧を表示")
+	BacktestCmd.Flags().String("output", "", "資産推移（equity.csv）と約定一覧（trades.csv）を出力するディレクトリ")
+
+	BacktestCmd.MarkFlagRequired("from")
+}
+
+func runBacktest(cmd *cobra.Command, args []string) error {
+	config, err := parseConfig(cmd)
+	if err != nil {
+		return err
+	}
+
+	strategy, err := newStrategy(cmd, config.Codes)
+	if err != nil {
+		return err
+	}
+
+	// データベース接続
+	conn, err := database.NewConnectionFromEnv(false)
+	if err != nil {
+		return fmt.Errorf("データベース接続エラー: %v", err)
+	}
+	defer conn.Close()
+
+	engine := NewEngine(database.NewBacktestRepository(conn), database.NewUniverseRepository(conn), config)
+	result, err := engine.Run(strategy)
+	if err != nil {
+		return fmt.Errorf("バックテストエラー: %v", err)
+	}
+
+	printSummary(result)
+
+	if showTrades, _ := cmd.Flags().GetBool("trades"); showTrades {
+		printTrades(result.Trades)
+	}
+
+	if output, _ := cmd.Flags().GetString("output"); output != "" {
+		if err := writeCSV(output, result); err != nil {
+			return fmt.Errorf("CSV出力エラー: %v", err)
+		}
+		fmt.Printf("\n資産推移と約定一覧を出力しました: %s\n", output)
+	}
+
+	return nil
+}
+
+// parseConfig フラグからバックテストの設定を作成
+func parseConfig(cmd *cobra.Command) (Config, error) {
+	fromFlag, _ := cmd.Flags().GetString("from")
+	toFlag, _ := cmd.Flags().GetString("to")
+	cash, _ := cmd.Flags().GetFloat64("cash")
+	commissionFlag, _ := cmd.Flags().GetString("commission")
+	slippageTicks, _ := cmd.Flags().GetInt("slippage-ticks")
+	lot, _ := cmd.Flags().GetInt("lot")
+	codesFlag, _ := cmd.Flags().GetString("codes")
+
+	config := Config{InitialCash: cash, LotSize: lot, SlippageTicks: slippageTicks}
+
+	from, err := time.ParseInLocation("2006-01-02", fromFlag, time.Local)
+	if err != nil {
+		return config, fmt.Errorf("開始日の形式が正しくありません（YYYY-MM-DD形式で指定してください）: %v", err)
+	}
+	config.From = from
+
+	config.To = time.Now()
+	if toFlag != "" {
+		to, err := time.ParseInLocation("2006-01-02", toFlag, time.Local)
+		if err != nil {
+			return config, fmt.Errorf("終了日の形式が正しくありません（YYYY-MM-DD形式で指定してください）: %v", err)
+		}
+		config.To = to
+	}
+	if config.To.Before(config.From) {
+		return config, fmt.Errorf("終了日は開始日以降を指定してください")
+	}
+
+	if cash <= 0 {
+		return config, fmt.Errorf("初期資金は0より大きい値を指定してください: %g", cash)
+	}
+	if slippageTicks < 0 {
+		return config, fmt.Errorf("スリッページは0以上を指定してください: %d", slippageTicks)
+	}
+
+	config.Commission, err = ParseCommissionModel(commissionFlag)
+	if err != nil {
+		return config, err
+	}
+
+	for _, code := range strings.Split(codesFlag, ",") {
+		if code = strings.TrimSpace(code); code != "" {
+			config.Codes = append(config.Codes, helper.NormalizeCode(code))
+		}
+	}
+
+	var universe database.UniverseFilter
+	universe.MarketCodes, _ = cmd.Flags().GetStringSlice("market")
+	universe.ScaleCategories, _ = cmd.Flags().GetStringSlice("scale")
+	universe.Sector17Codes, _ = cmd.Flags().GetStringSlice("sector17")
+	universe.Sector33Codes, _ = cmd.Flags().GetStringSlice("sector33")
+	if !universe.IsEmpty() {
+		config.Universe = &universe
+	}
+
+	return config, nil
+}
+
+// newStrategy フラグから戦略を作成
+func newStrategy(cmd *cobra.Command, codes []string) (Strategy, error) {
+	name, _ := cmd.Flags().GetString("strategy")
+
+	switch name {
+	case "value":
+		sortBy, _ := cmd.Flags().GetString("sort")
+		holdings, _ := cmd.Flags().GetInt("holdings")
+		rebalance, _ := cmd.Flags().GetInt("rebalance")
+		strategy, err := NewValueStrategy(sortBy, holdings, rebalance)
+		if err != nil {
+			return nil, err
+		}
+
+		// 指定されたフラグのみ条件として適用
+		if cmd.Flags().Changed("max-per") {
+			v, _ := cmd.Flags().GetFloat64("max-per")
+			strategy.MaxPER = &v
+		}
+		if cmd.Flags().Changed("max-pbr") {
+			v, _ := cmd.Flags().GetFloat64("max-pbr")
+			strategy.MaxPBR = &v
+		}
+		if cmd.Flags().Changed("min-yield") {
+			v, _ := cmd.Flags().GetFloat64("min-yield")
+			strategy.MinYield = &v
+		}
+		if cmd.Flags().Changed("min-market-cap") {
+			v, _ := cmd.Flags().GetInt64("min-market-cap")
+			strategy.MinMarketCap = &v
+		}
+		return strategy, nil
+
+	case "sma_cross":
+		fast, _ := cmd.Flags().GetInt("fast")
+		slow, _ := cmd.Flags().GetInt("slow")
+		return NewSMACrossStrategy(fast, slow, codes)
+	}
+
+	return nil, fmt.Errorf("サポートされていない戦略です（value, sma_cross）: '%s'", name)
+}
+
+// printSummary 集計を表示
+func printSummary(result *Result) {
+	s := result.Summary
+
+	fmt.Printf("\n=== バックテスト結果 ===\n\n")
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "戦略\t%s\n", result.Strategy)
+	fmt.Fprintf(w, "期間\t%s〜%s（%d取引日）\n", s.StartDate.Format("2006-01-02"), s.EndDate.Format("2006-01-02"), len(result.Equity))
+	fmt.Fprintf(w, "手数料モデル\t%s\n", result.Config.Commission)
+	if u := result.Config.Universe; u != nil {
+		fmt.Fprintf(w, "ユニバース\t%s\n", formatUniverseFilter(*u))
+	}
+	fmt.Fprintf(w, "初期資金\t%.0f\n", s.InitialEquity)
+	fmt.Fprintf(w, "最終評価額\t%.0f\n", s.FinalEquity)
+	fmt.Fprintf(w, "累積リターン(%%)\t%.2f\n", s.TotalReturn)
+	fmt.Fprintf(w, "CAGR(%%)\t%s\n", formatFloat64Ptr(s.CAGR))
+	fmt.Fprintf(w, "ボラティリティ(%%)\t%s\n", formatFloat64Ptr(s.Volatility))
+	fmt.Fprintf(w, "シャープレシオ\t%s\n", formatFloat64Ptr(s.Sharpe))
+	maxDrawdownDate := "-"
+	if !s.MaxDrawdownDate.IsZero() {
+		maxDrawdownDate = s.MaxDrawdownDate.Format("2006-01-02")
+	}
+	fmt.Fprintf(w, "最大ドローダウン(%%)\t%.2f（%s）\n", s.MaxDrawdown, maxDrawdownDate)
+	fmt.Fprintf(w, "約定数\t%d（うち売り %d）\n", s.Trades, s.ClosedTrades)
+	fmt.Fprintf(w, "勝率(%%)\t%s\n", formatFloat64Ptr(s.WinRate))
+	fmt.Fprintf(w, "手数料合計\t%.0f\n", s.TotalCommission)
+	w.Flush()
+}
+
+// formatUniverseFilter ユニバースの条件を表示用の文字列に変換
+func formatUniverseFilter(f database.UniverseFilter) string {
+	var parts []string
+	for _, c := range []struct {
+		label  string
+		values []string
+	}{
+		{"市場区分", f.MarketCodes},
+		{"規模区分", f.ScaleCategories},
+		{"17業種", f.Sector17Codes},
+		{"33業種", f.Sector33Codes},
+	} {
+		if len(c.values) > 0 {
+			parts = append(parts, fmt.Sprintf("%s=%s", c.label, strings.Join(c.values, ",")))
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// printTrades 約定一覧を表示
+func printTrades(trades []Trade) {
+	fmt.Printf("\n=== 約定一覧 ===\n\n")
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "約定日\tコード\t売買\t株数\t約定価格\t手数料\t実現損益")
+	fmt.Fprintln(w, "----\t----\t----\t----\t----\t----\t----")
+
+	for _, t := range trades {
+		side, quantity := "買", t.Quantity
+		if quantity < 0 {
+			side, quantity = "売", -quantity
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%.1f\t%.0f\t%s\n",
+			t.Date.Format("2006-01-02"), t.Code, side, quantity, t.Price, t.Commission, formatFloat64Ptr(t.RealizedPnL))
+	}
+
+	w.Flush()
+	fmt.Printf("\n約定数: %d\n", len(trades))
+}
+
+// writeCSV 資産推移と約定一覧をCSVで出力
+func writeCSV(dir string, result *Result) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	equity := [][]string{{"date", "cash", "holdings", "equity", "positions"}}
+	for _, p := range result.Equity {
+		equity = append(equity, []string{
+			p.Date.Format("2006-01-02"),
+			strconv.FormatFloat(p.Cash, 'f', 0, 64),
+			strconv.FormatFloat(p.Holdings, 'f', 0, 64),
+			strconv.FormatFloat(p.Equity, 'f', 0, 64),
+			strconv.Itoa(p.Positions),
+		})
+	}
+	if err := writeCSVFile(filepath.Join(dir, "equity.csv"), equity); err != nil {
+		return err
+	}
+
+	trades := [][]string{{"date", "code", "quantity", "price", "commission", "realized_pnl"}}
+	for _, t := range result.Trades {
+		pnl := ""
+		if t.RealizedPnL != nil {
+			pnl = strconv.FormatFloat(*t.RealizedPnL, 'f', 0, 64)
+		}
+		trades = append(trades, []string{
+			t.Date.Format("2006-01-02"),
+			t.Code,
+			strconv.Itoa(t.Quantity),
+			strconv.FormatFloat(t.Price, 'f', -1, 64),
+			strconv.FormatFloat(t.Commission, 'f', 0, 64),
+			pnl,
+		})
+	}
+	return writeCSVFile(filepath.Join(dir, "trades.csv"), trades)
+}
+
+func writeCSVFile(path string, records [][]string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	if err := writer.WriteAll(records); err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+// formatFloat64Ptr 小数値を表示用に整形（NULLは"-"）
+func formatFloat64Ptr(v *float64) string {
+	if v == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%.2f", *v)
+}
@@ -0,0 +1,162 @@
+package backtest
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// DefaultLotSize 東証の売買単位（株）
+const DefaultLotSize = 100
+
+// tickSizeTable 東証の呼値の単位（TOPIX500構成銘柄以外）: 株価の上限と呼値
+var tickSizeTable = []struct {
+	upper float64
+	tick  float64
+}{
+	{3000, 1},
+	{5000, 5},
+	{30000, 10},
+	{50000, 50},
+	{300000, 100},
+	{500000, 500},
+	{3000000, 1000},
+	{5000000, 5000},
+	{30000000, 10000},
+	{50000000, 50000},
+}
+
+// TickSize 株価に対応する呼値の単位
+func TickSize(price float64) float64 {
+	for _, row := range tickSizeTable {
+		if price <= row.upper {
+			return row.tick
+		}
+	}
+	return 100000
+}
+
+// fillPrice 始値に呼値単位のスリッページを加えた約定価格（買いは上方向、売りは下方向）
+// 呼値の境界をまたぐ場合は、移動後の価格帯の呼値の刻みにそろえる
+func fillPrice(open float64, buy bool, slippageTicks int) float64 {
+	price := open
+	for i := 0; i < slippageTicks; i++ {
+		if buy {
+			next := price + TickSize(price)
+			tick := TickSize(next)
+			price = math.Ceil(next/tick) * tick
+		} else {
+			next := price - TickSize(price-1e-9)
+			if next <= 0 {
+				break
+			}
+			tick := TickSize(next)
+			price = math.Floor(next/tick) * tick
+		}
+	}
+	return price
+}
+
+// roundLot 株数を売買単位の倍数に切り捨て
+func roundLot(shares, lotSize int) int {
+	if lotSize <= 1 {
+		return shares
+	}
+	return shares / lotSize * lotSize
+}
+
+// CommissionModel 売買手数料の計算方法
+type CommissionModel interface {
+	// Commission 約定代金に対する手数料（円）
+	Commission(value float64) float64
+	String() string
+}
+
+// ZeroCommission 手数料なし
+type ZeroCommission struct{}
+
+func (ZeroCommission) Commission(value float64) float64 { return 0 }
+func (ZeroCommission) String() string                   { return "なし" }
+
+// RateCommission 約定代金に対する料率（最低手数料あり）
+type RateCommission struct {
+	Rate    float64 // 料率（0.001 = 0.1%）
+	Minimum float64 // 最低手数料（円）
+}
+
+func (c RateCommission) Commission(value float64) float64 {
+	return math.Max(math.Round(value*c.Rate), c.Minimum)
+}
+
+func (c RateCommission) String() string {
+	return fmt.Sprintf("料率 %g%%（最低 %g円）", c.Rate*100, c.Minimum)
+}
+
+// TieredCommission 約定代金の階段ごとの定額手数料
+type TieredCommission struct {
+	Tiers []CommissionTier // 約定代金の上限の昇順
+	Above float64          // 最上位の階段を超える場合の手数料
+}
+
+// CommissionTier 約定代金の上限と手数料
+type CommissionTier struct {
+	Upper float64
+	Fee   float64
+}
+
+// DefaultTieredCommission 1注文ごとの定額手数料の例（税込）
+var DefaultTieredCommission = TieredCommission{
+	Tiers: []CommissionTier{
+		{50000, 55},
+		{100000, 99},
+		{200000, 115},
+		{500000, 275},
+		{1000000, 535},
+		{1500000, 640},
+		{30000000, 1013},
+	},
+	Above: 1070,
+}
+
+func (c TieredCommission) Commission(value float64) float64 {
+	for _, tier := range c.Tiers {
+		if value <= tier.Upper {
+			return tier.Fee
+		}
+	}
+	return c.Above
+}
+
+func (c TieredCommission) String() string {
+	return "約定代金ごとの定額"
+}
+
+// ParseCommissionModel 手数料モデルを解析（zero, rate:料率%[:最低手数料], tiered）
+func ParseCommissionModel(s string) (CommissionModel, error) {
+	parts := strings.Split(s, ":")
+	switch parts[0] {
+	case "", "zero":
+		return ZeroCommission{}, nil
+	case "tiered":
+		return DefaultTieredCommission, nil
+	case "rate":
+		if len(parts) < 2 || len(parts) > 3 {
+			return nil, fmt.Errorf("料率の手数料はrate:料率%%[:最低手数料]の形式で指定してください（例: rate:0.1:100）: '%s'", s)
+		}
+		rate, err := strconv.ParseFloat(parts[1], 64)
+		if err != nil || rate < 0 {
+			return nil, fmt.Errorf("料率が正しくありません: '%s'", parts[1])
+		}
+		model := RateCommission{Rate: rate / 100}
+		if len(parts) == 3 {
+			minimum, err := strconv.ParseFloat(parts[2], 64)
+			if err != nil || minimum < 0 {
+				return nil, fmt.Errorf("最低手数料が正しくありません: '%s'", parts[2])
+			}
+			model.Minimum = minimum
+		}
+		return model, nil
+	}
+	return nil, fmt.Errorf("サポートされていない手数料モデルです（zero, rate:料率%%[:最低手数料], tiered）: '%s'", s)
+}
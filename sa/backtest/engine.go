@@ -0,0 +1,287 @@
+package backtest
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"stock-automation/database"
+	"time"
+)
+
+// Config バックテストの設定
+type Config struct {
+	From          time.Time
+	To            time.Time
+	InitialCash   float64
+	LotSize       int
+	SlippageTicks int // 約定価格を始値から不利な方向にずらす呼値の数
+	Commission    CommissionModel
+	Codes         []string // 対象銘柄コード（空の場合は全銘柄）
+
+	// 取引日時点のユニバースの条件（nilの場合は絞り込まない）
+	// 指定した場合、戦略にはその取引日に上場していて条件に合う銘柄の日足だけを渡す
+	Universe *database.UniverseFilter
+}
+
+// Trade 約定
+type Trade struct {
+	Date        time.Time
+	Code        string
+	Quantity    int // 正の場合は買い、負の場合は売り
+	Price       float64
+	Commission  float64
+	RealizedPnL *float64 // 売りの場合の実現損益（手数料控除後）
+}
+
+// EquityPoint 取引日ごとの資産推移
+type EquityPoint struct {
+	Date      time.Time
+	Cash      float64
+	Holdings  float64
+	Equity    float64
+	Positions int
+}
+
+// Result バックテストの結果
+type Result struct {
+	Strategy string
+	Config   Config
+	Equity   []EquityPoint
+	Trades   []Trade
+	Summary  Summary
+}
+
+// position 保有銘柄（平均取得単価は手数料を含む）
+type position struct {
+	shares  int
+	avgCost float64
+}
+
+// Engine バックテストの実行エンジン
+type Engine struct {
+	repository *database.BacktestRepository
+	universe   *database.UniverseRepository
+	config     Config
+}
+
+// NewEngine 新しいエンジンを作成
+// universe: Config.Universeを指定する場合のユニバースのリポジトリ
+func NewEngine(repository *database.BacktestRepository, universe *database.UniverseRepository, config Config) *Engine {
+	if config.LotSize <= 0 {
+		config.LotSize = DefaultLotSize
+	}
+	if config.Commission == nil {
+		config.Commission = ZeroCommission{}
+	}
+	return &Engine{repository: repository, universe: universe, config: config}
+}
+
+// Run 期間内の取引日ごとに、株式分割・併合の反映→前日の注文の始値での約定→終値での評価→戦略の呼び出しを繰り返す
+func (e *Engine) Run(strategy Strategy) (*Result, error) {
+	dates, err := e.repository.GetTradeDates(e.config.From, e.config.To)
+	if err != nil {
+		return nil, err
+	}
+	if len(dates) == 0 {
+		return nil, fmt.Errorf("期間内に株価データがありません: %s〜%s", e.config.From.Format("2006-01-02"), e.config.To.Format("2006-01-02"))
+	}
+
+	if e.config.Universe != nil {
+		if e.universe == nil {
+			return nil, fmt.Errorf("ユニバースの条件を指定する場合はユニバースのリポジトリが必要です")
+		}
+		first, err := e.universe.GetFirstSnapshotDate()
+		if err != nil {
+			return nil, err
+		}
+		if first == nil || first.After(dates[0]) {
+			log.Printf("上場銘柄一覧スナップショットがない期間は上場銘柄情報の履歴で代用するため、生存バイアスが残ります")
+		}
+	}
+
+	result := &Result{Strategy: strategy.Name(), Config: e.config}
+	cash := e.config.InitialCash
+	positions := make(map[string]*position)
+	history := make(map[string][]*database.DailyBar)
+	lastClose := make(map[string]float64)
+	lookback := strategy.Lookback()
+	if lookback < 1 {
+		lookback = 1
+	}
+
+	var pending []Order
+	for _, date := range dates {
+		bars, err := e.repository.GetDailyBars(date, e.config.Codes)
+		if err != nil {
+			return nil, err
+		}
+		barMap := make(map[string]*database.DailyBar, len(bars))
+		for _, bar := range bars {
+			barMap[bar.Code] = bar
+		}
+
+		// 1. 権利落ち日の株式分割・併合を保有株数と平均取得単価に反映
+		for code, pos := range positions {
+			bar := barMap[code]
+			if bar == nil || bar.AdjustmentFactor == 1 || bar.AdjustmentFactor <= 0 {
+				continue
+			}
+			pos.shares = int(math.Round(float64(pos.shares) / bar.AdjustmentFactor))
+			pos.avgCost *= bar.AdjustmentFactor
+			if price, ok := lastClose[code]; ok {
+				lastClose[code] = price * bar.AdjustmentFactor
+			}
+		}
+
+		// 2. 前日の注文を始値で約定（売りを先に執行して資金を確保）
+		sort.SliceStable(pending, func(i, j int) bool {
+			return pending[i].Quantity < 0 && pending[j].Quantity > 0
+		})
+		for _, order := range pending {
+			trade, ok := e.fill(order, barMap[order.Code], date, &cash, positions)
+			if ok {
+				result.Trades = append(result.Trades, trade)
+			}
+		}
+		pending = nil
+
+		// 3. 終値で評価
+		for _, bar := range bars {
+			if bar.Close > 0 {
+				lastClose[bar.Code] = bar.Close
+			}
+			h := append(history[bar.Code], bar)
+			if len(h) > lookback {
+				h = h[len(h)-lookback:]
+			}
+			history[bar.Code] = h
+		}
+
+		holdings := 0.0
+		current := make(map[string]int, len(positions))
+		for code, pos := range positions {
+			holdings += float64(pos.shares) * lastClose[code]
+			current[code] = pos.shares
+		}
+		equity := cash + holdings
+		result.Equity = append(result.Equity, EquityPoint{
+			Date:      date,
+			Cash:      cash,
+			Holdings:  holdings,
+			Equity:    equity,
+			Positions: len(positions),
+		})
+
+		// 4. 戦略を呼び出して翌取引日の注文を受け付ける
+		strategyBars := barMap
+		if e.config.Universe != nil {
+			strategyBars, err = e.universeBars(date, barMap)
+			if err != nil {
+				return nil, err
+			}
+		}
+		ctx := &Context{
+			Date:      date,
+			Bars:      strategyBars,
+			Cash:      cash,
+			Equity:    equity,
+			Positions: current,
+			LotSize:   e.config.LotSize,
+			history:   history,
+			lastClose: lastClose,
+		}
+		pending = strategy.OnBar(ctx)
+	}
+
+	if len(pending) > 0 {
+		log.Printf("最終取引日の注文 %d件は約定せずに終了しました", len(pending))
+	}
+
+	result.Summary = summarize(result, e.config.InitialCash)
+	return result, nil
+}
+
+// universeBars 取引日時点のユニバースに含まれる銘柄の日足に絞り込む
+func (e *Engine) universeBars(date time.Time, barMap map[string]*database.DailyBar) (map[string]*database.DailyBar, error) {
+	members, err := e.universe.Universe(date, *e.config.Universe)
+	if err != nil {
+		return nil, err
+	}
+
+	bars := make(map[string]*database.DailyBar, len(members))
+	for _, member := range members {
+		if bar, ok := barMap[member.Code]; ok {
+			bars[member.Code] = bar
+		}
+	}
+	return bars, nil
+}
+
+// fill 注文を始値（スリッページ考慮）で約定
+// 買いは売買単位に切り捨て、資金が足りない場合は買える単位まで減らす。売りは保有株数を上限とし、全株売却の場合は単元未満株も売却する
+func (e *Engine) fill(order Order, bar *database.DailyBar, date time.Time, cash *float64, positions map[string]*position) (Trade, bool) {
+	if order.Quantity == 0 {
+		return Trade{}, false
+	}
+	if bar == nil || bar.Open <= 0 {
+		log.Printf("%s 銘柄 %s は始値がないため注文を取り消しました", date.Format("2006-01-02"), order.Code)
+		return Trade{}, false
+	}
+
+	buy := order.Quantity > 0
+	price := fillPrice(bar.Open, buy, e.config.SlippageTicks)
+	pos := positions[order.Code]
+
+	if buy {
+		quantity := roundLot(order.Quantity, e.config.LotSize)
+		for quantity > 0 {
+			value := price * float64(quantity)
+			if value+e.config.Commission.Commission(value) <= *cash {
+				break
+			}
+			quantity -= e.config.LotSize
+		}
+		if quantity <= 0 {
+			return Trade{}, false
+		}
+
+		value := price * float64(quantity)
+		commission := e.config.Commission.Commission(value)
+		*cash -= value + commission
+
+		if pos == nil {
+			pos = &position{}
+			positions[order.Code] = pos
+		}
+		pos.avgCost = (pos.avgCost*float64(pos.shares) + value + commission) / float64(pos.shares+quantity)
+		pos.shares += quantity
+
+		return Trade{Date: date, Code: order.Code, Quantity: quantity, Price: price, Commission: commission}, true
+	}
+
+	if pos == nil || pos.shares == 0 {
+		return Trade{}, false
+	}
+	quantity := -order.Quantity
+	if quantity >= pos.shares {
+		quantity = pos.shares
+	} else {
+		quantity = roundLot(quantity, e.config.LotSize)
+	}
+	if quantity <= 0 {
+		return Trade{}, false
+	}
+
+	value := price * float64(quantity)
+	commission := e.config.Commission.Commission(value)
+	*cash += value - commission
+	pnl := value - commission - pos.avgCost*float64(quantity)
+
+	pos.shares -= quantity
+	if pos.shares == 0 {
+		delete(positions, order.Code)
+	}
+
+	return Trade{Date: date, Code: order.Code, Quantity: -quantity, Price: price, Commission: commission, RealizedPnL: &pnl}, true
+}
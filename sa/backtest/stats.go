@@ -0,0 +1,101 @@
+package backtest
+
+import (
+	"math"
+	"stock-automation/helper"
+	"time"
+)
+
+// Summary バックテストの集計
+type Summary struct {
+	StartDate       time.Time
+	EndDate         time.Time
+	InitialEquity   float64
+	FinalEquity     float64
+	TotalReturn     float64  // 累積リターン(%)
+	CAGR            *float64 // 年率リターン(%)
+	Volatility      *float64 // 年率ボラティリティ(%)
+	Sharpe          *float64 // シャープレシオ（無リスク金利0）
+	MaxDrawdown     float64  // 最大ドローダウン(%)
+	MaxDrawdownDate time.Time
+	Trades          int
+	ClosedTrades    int      // 売りの約定数
+	WinRate         *float64 // 売りの約定のうち実現損益がプラスの割合(%)
+	TotalCommission float64
+}
+
+// summarize 資産推移と約定から集計
+func summarize(result *Result, initialCash float64) Summary {
+	summary := Summary{
+		InitialEquity: initialCash,
+		Trades:        len(result.Trades),
+	}
+	if len(result.Equity) == 0 {
+		return summary
+	}
+
+	first, last := result.Equity[0], result.Equity[len(result.Equity)-1]
+	summary.StartDate = first.Date
+	summary.EndDate = last.Date
+	summary.FinalEquity = last.Equity
+	if initialCash > 0 {
+		summary.TotalReturn = helper.Round2((last.Equity/initialCash - 1) * 100)
+
+		years := last.Date.Sub(first.Date).Hours() / 24 / 365.25
+		if years > 0 && last.Equity > 0 {
+			v := helper.Round2((math.Pow(last.Equity/initialCash, 1/years) - 1) * 100)
+			summary.CAGR = &v
+		}
+	}
+
+	// 日次リターンからボラティリティ・シャープレシオを算出
+	prev := initialCash
+	var returns []float64
+	for _, point := range result.Equity {
+		if prev > 0 {
+			returns = append(returns, point.Equity/prev-1)
+		}
+		prev = point.Equity
+	}
+	if len(returns) > 1 {
+		mean, sd := helper.MeanStdDev(returns)
+		volatility := helper.Round2(sd * math.Sqrt(helper.TradingDaysPerYear) * 100)
+		summary.Volatility = &volatility
+		if sd > 0 {
+			sharpe := helper.Round2(mean / sd * math.Sqrt(helper.TradingDaysPerYear))
+			summary.Sharpe = &sharpe
+		}
+	}
+
+	// 最大ドローダウン
+	peak := initialCash
+	for _, point := range result.Equity {
+		if point.Equity > peak {
+			peak = point.Equity
+		}
+		if peak > 0 {
+			if drawdown := (point.Equity/peak - 1) * 100; drawdown < summary.MaxDrawdown {
+				summary.MaxDrawdown = drawdown
+				summary.MaxDrawdownDate = point.Date
+			}
+		}
+	}
+	summary.MaxDrawdown = helper.Round2(summary.MaxDrawdown)
+
+	wins := 0
+	for _, trade := range result.Trades {
+		summary.TotalCommission += trade.Commission
+		if trade.RealizedPnL != nil {
+			summary.ClosedTrades++
+			if *trade.RealizedPnL > 0 {
+				wins++
+			}
+		}
+	}
+	if summary.ClosedTrades > 0 {
+		v := helper.Round2(float64(wins) / float64(summary.ClosedTrades) * 100)
+		summary.WinRate = &v
+	}
+
+	return summary
+}
@@ -0,0 +1,187 @@
+package backtest
+
+import (
+	"fmt"
+	"sort"
+	"stock-automation/database"
+)
+
+// ValueStrategy 定期的に株価指標の条件に合う銘柄を抽出し、上位銘柄に等金額で入れ替える戦略
+type ValueStrategy struct {
+	MaxPER          *float64 // 予想PERの上限
+	MaxPBR          *float64
+	MinYield        *float64 // 予想配当利回り(%)の下限
+	MinMarketCap    *int64
+	SortBy          string // yield, per, pbr, market_cap
+	Holdings        int    // 保有銘柄数
+	RebalanceMonths int    // 入れ替え間隔（月）
+
+	lastRebalance int // 最後に入れ替えた年月（年×12+月）
+}
+
+// valueSortKeys ValueStrategyの並べ替えキー
+var valueSortKeys = map[string]bool{"yield": true, "per": true, "pbr": true, "market_cap": true}
+
+// NewValueStrategy 新しい戦略を作成
+func NewValueStrategy(sortBy string, holdings, rebalanceMonths int) (*ValueStrategy, error) {
+	if !valueSortKeys[sortBy] {
+		return nil, fmt.Errorf("サポートされていない並べ替えキーです（yield, per, pbr, market_cap）: '%s'", sortBy)
+	}
+	if holdings < 1 {
+		return nil, fmt.Errorf("保有銘柄数は1以上を指定してください: %d", holdings)
+	}
+	if rebalanceMonths < 1 {
+		return nil, fmt.Errorf("入れ替え間隔は1か月以上を指定してください: %d", rebalanceMonths)
+	}
+	return &ValueStrategy{SortBy: sortBy, Holdings: holdings, RebalanceMonths: rebalanceMonths}, nil
+}
+
+func (s *ValueStrategy) Name() string {
+	return fmt.Sprintf("value（%s上位%d銘柄、%dか月ごとに入れ替え）", s.SortBy, s.Holdings, s.RebalanceMonths)
+}
+
+func (s *ValueStrategy) Lookback() int { return 1 }
+
+func (s *ValueStrategy) OnBar(ctx *Context) []Order {
+	month := ctx.Date.Year()*12 + int(ctx.Date.Month())
+	if s.lastRebalance != 0 && month-s.lastRebalance < s.RebalanceMonths {
+		return nil
+	}
+	s.lastRebalance = month
+
+	var candidates []*database.DailyBar
+	for _, bar := range ctx.Bars {
+		if bar.Close <= 0 || !s.matches(bar) {
+			continue
+		}
+		candidates = append(candidates, bar)
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if less, ok := s.compare(candidates[i], candidates[j]); ok {
+			return less
+		}
+		return candidates[i].Code < candidates[j].Code
+	})
+	if len(candidates) > s.Holdings {
+		candidates = candidates[:s.Holdings]
+	}
+
+	weights := make(map[string]float64, len(candidates))
+	for _, bar := range candidates {
+		weights[bar.Code] = 1 / float64(s.Holdings)
+	}
+	return ctx.RebalanceOrders(weights)
+}
+
+// matches 取引日時点の株価指標が条件に合うかを判定（並べ替えに使う指標がない銘柄は除く）
+func (s *ValueStrategy) matches(bar *database.DailyBar) bool {
+	if s.MaxPER != nil && (bar.ForecastPER == nil || *bar.ForecastPER > *s.MaxPER) {
+		return false
+	}
+	if s.MaxPBR != nil && (bar.PBR == nil || *bar.PBR > *s.MaxPBR) {
+		return false
+	}
+	if s.MinYield != nil && (bar.ForecastDividendYield == nil || *bar.ForecastDividendYield < *s.MinYield) {
+		return false
+	}
+	if s.MinMarketCap != nil && (bar.MarketCap == nil || *bar.MarketCap < *s.MinMarketCap) {
+		return false
+	}
+
+	switch s.SortBy {
+	case "yield":
+		return bar.ForecastDividendYield != nil
+	case "per":
+		return bar.ForecastPER != nil
+	case "pbr":
+		return bar.PBR != nil
+	default:
+		return bar.MarketCap != nil
+	}
+}
+
+// compare 並べ替えキーで比較（同値の場合はok=false）
+func (s *ValueStrategy) compare(a, b *database.DailyBar) (less bool, ok bool) {
+	var x, y float64
+	descending := false
+	switch s.SortBy {
+	case "yield":
+		x, y, descending = *a.ForecastDividendYield, *b.ForecastDividendYield, true
+	case "per":
+		x, y = *a.ForecastPER, *b.ForecastPER
+	case "pbr":
+		x, y = *a.PBR, *b.PBR
+	default:
+		x, y, descending = float64(*a.MarketCap), float64(*b.MarketCap), true
+	}
+	if x == y {
+		return false, false
+	}
+	if descending {
+		return x > y, true
+	}
+	return x < y, true
+}
+
+// SMACrossStrategy 調整後終値の短期移動平均が長期移動平均を上抜けたら買い、下抜けたら売る戦略
+// 買う金額は評価額を対象銘柄数で等分した金額
+type SMACrossStrategy struct {
+	Fast  int
+	Slow  int
+	Codes []string
+}
+
+// NewSMACrossStrategy 新しい戦略を作成
+func NewSMACrossStrategy(fast, slow int, codes []string) (*SMACrossStrategy, error) {
+	if fast < 1 || slow <= fast {
+		return nil, fmt.Errorf("移動平均の期間は1以上かつ短期<長期で指定してください: %d, %d", fast, slow)
+	}
+	if len(codes) == 0 {
+		return nil, fmt.Errorf("sma_cross戦略では--codesで対象銘柄を指定してください")
+	}
+	return &SMACrossStrategy{Fast: fast, Slow: slow, Codes: codes}, nil
+}
+
+func (s *SMACrossStrategy) Name() string {
+	return fmt.Sprintf("sma_cross（%d日/%d日）", s.Fast, s.Slow)
+}
+
+func (s *SMACrossStrategy) Lookback() int { return s.Slow + 1 }
+
+func (s *SMACrossStrategy) OnBar(ctx *Context) []Order {
+	var orders []Order
+	for _, code := range s.Codes {
+		history := ctx.History(code)
+		if len(history) < s.Slow+1 || ctx.Bars[code] == nil {
+			continue
+		}
+
+		fastNow, slowNow := averageClose(history, s.Fast, 0), averageClose(history, s.Slow, 0)
+		fastPrev, slowPrev := averageClose(history, s.Fast, 1), averageClose(history, s.Slow, 1)
+
+		position := ctx.Position(code)
+		switch {
+		case position == 0 && fastPrev <= slowPrev && fastNow > slowNow:
+			price := ctx.LastClose(code)
+			if price > 0 {
+				shares := roundLot(int(ctx.Equity/float64(len(s.Codes))/price), ctx.LotSize)
+				if shares > 0 {
+					orders = append(orders, Order{Code: code, Quantity: shares})
+				}
+			}
+		case position > 0 && fastPrev >= slowPrev && fastNow < slowNow:
+			orders = append(orders, Order{Code: code, Quantity: -position})
+		}
+	}
+	return orders
+}
+
+// averageClose 末尾からskip本前を最終日とするperiod本の調整後終値の平均
+func averageClose(history []*database.DailyBar, period, skip int) float64 {
+	end := len(history) - skip
+	sum := 0.0
+	for _, bar := range history[end-period : end] {
+		sum += bar.AdjustmentClose
+	}
+	return sum / float64(period)
+}
@@ -0,0 +1,93 @@
+package backtest
+
+import (
+	"math"
+	"sort"
+	"stock-automation/database"
+	"time"
+)
+
+// Strategy バックテストの売買戦略
+type Strategy interface {
+	// Name 戦略名
+	Name() string
+	// Lookback Contextの履歴として保持する日足の本数
+	Lookback() int
+	// OnBar 取引日の終値確定後に呼ばれ、翌取引日の始値で執行する注文を返す
+	OnBar(ctx *Context) []Order
+}
+
+// Order 成行注文（Quantityが正の場合は買い、負の場合は売り。売買単位に満たない端数は切り捨て）
+type Order struct {
+	Code     string
+	Quantity int
+}
+
+// Context 戦略に渡す取引日時点の情報
+type Context struct {
+	Date      time.Time
+	Bars      map[string]*database.DailyBar // 当日の日足（株価のある銘柄のみ）
+	Cash      float64
+	Equity    float64        // 当日終値での評価額と現金の合計
+	Positions map[string]int // 保有株数
+	LotSize   int
+
+	history   map[string][]*database.DailyBar
+	lastClose map[string]float64
+}
+
+// History 銘柄の直近の日足を古い順に取得（当日を含み最大Lookback本）
+func (c *Context) History(code string) []*database.DailyBar {
+	return c.history[code]
+}
+
+// Position 銘柄の保有株数
+func (c *Context) Position(code string) int {
+	return c.Positions[code]
+}
+
+// LastClose 銘柄の直近の終値（株価がない場合は0）
+func (c *Context) LastClose(code string) float64 {
+	return c.lastClose[code]
+}
+
+// RebalanceOrders 目標の保有比率（評価額に対する割合）に近づける注文を作成
+// 目標にない保有銘柄は全て売却し、株数は当日終値で売買単位に切り捨てて計算する
+func (c *Context) RebalanceOrders(weights map[string]float64) []Order {
+	var orders []Order
+
+	codes := make([]string, 0, len(c.Positions)+len(weights))
+	seen := make(map[string]bool)
+	for code := range c.Positions {
+		codes = append(codes, code)
+		seen[code] = true
+	}
+	for code := range weights {
+		if !seen[code] {
+			codes = append(codes, code)
+		}
+	}
+	sort.Strings(codes)
+
+	for _, code := range codes {
+		current := c.Positions[code]
+		weight, ok := weights[code]
+		if !ok {
+			if current > 0 {
+				orders = append(orders, Order{Code: code, Quantity: -current})
+			}
+			continue
+		}
+
+		price := c.lastClose[code]
+		if price <= 0 {
+			continue
+		}
+		target := roundLot(int(math.Floor(c.Equity*weight/price)), c.LotSize)
+		if diff := target - current; diff != 0 {
+			orders = append(orders, Order{Code: code, Quantity: diff})
+		}
+	}
+
+	return orders
+}
@@ -0,0 +1,15 @@
+package calendar
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var CalendarCmd = &cobra.Command{
+	Use:   "calendar",
+	Short: "カレンダー表示",
+	Long:  "権利確定日などの投資カレンダーを表示する機能を提供します",
+}
+
+func init() {
+	CalendarCmd.AddCommand(rightsCmd)
+}
@@ -0,0 +1,130 @@
+package calendar
+
+import (
+	"fmt"
+	"os"
+	"stock-automation/database"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// settlementDays 受渡しまでの営業日数（T+2）
+const settlementDays = 2
+
+var rightsCmd = &cobra.Command{
+	Use:   "rights",
+	Short: "権利確定月の配当銘柄を表示",
+	Long:  "指定した月の月末に中間・期末配当の権利が確定する銘柄を、予想配当・予想配当利回り・権利付最終日とともに表示します",
+	RunE:  showRights,
+}
+
+func init() {
+	// フラグを追加
+	rightsCmd.Flags().Int("month", 0, "権利確定月（1〜12）")
+	rightsCmd.Flags().Int("year", 0, "権利確定年（指定しない場合は当日以降で直近の年）")
+	rightsCmd.Flags().String("type", "", "配当の種別（interim, year_end。指定しない場合は両方）")
+	rightsCmd.Flags().IntP("limit", "l", 50, "表示する行数の上限")
+	rightsCmd.Flags().BoolP("all", "a", false, "全ての行を表示")
+	rightsCmd.MarkFlagRequired("month")
+}
+
+func showRights(cmd *cobra.Command, args []string) error {
+	month, _ := cmd.Flags().GetInt("month")
+	year, _ := cmd.Flags().GetInt("year")
+	rightsType, _ := cmd.Flags().GetString("type")
+	limit, _ := cmd.Flags().GetInt("limit")
+	showAll, _ := cmd.Flags().GetBool("all")
+
+	if month < 1 || month > 12 {
+		return fmt.Errorf("monthは1〜12を指定してください: %d", month)
+	}
+	if rightsType != "" && rightsType != database.RightsInterim && rightsType != database.RightsYearEnd {
+		return fmt.Errorf("typeはinterimまたはyear_endを指定してください: '%s'", rightsType)
+	}
+
+	// 年の指定がない場合は当日以降で直近の権利確定日（月末）の年
+	if year == 0 {
+		now := time.Now()
+		today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+		year = now.Year()
+		if time.Date(year, time.Month(month)+1, 0, 0, 0, 0, 0, time.Local).Before(today) {
+			year++
+		}
+	}
+
+	// データベース接続
+	conn, err := database.NewConnectionFromEnv(false)
+	if err != nil {
+		return fmt.Errorf("データベース接続エラー: %v", err)
+	}
+	defer conn.Close()
+
+	repository := database.NewRightsCalendarRepository(conn)
+	entries, err := repository.GetRightsEntries(year, time.Month(month), rightsType)
+	if err != nil {
+		return fmt.Errorf("データ取得エラー: %v", err)
+	}
+
+	// 権利確定日と権利付最終日（権利確定日以前の直近の営業日からT+2さかのぼった営業日）、権利落ち日（権利付最終日の翌営業日）
+	recordDate := time.Date(year, time.Month(month)+1, 0, 0, 0, 0, 0, time.Local)
+	calendarRepository := database.NewTradingCalendarRepository(conn)
+	businessCalendar, err := calendarRepository.LoadBusinessCalendar(recordDate.AddDate(0, 0, -31), recordDate)
+	if err != nil {
+		return fmt.Errorf("取引カレンダー取得エラー: %v", err)
+	}
+	effectiveRecordDate := businessCalendar.BusinessDaysBefore(recordDate, 0)
+	lastBuyDate := businessCalendar.BusinessDaysBefore(recordDate, settlementDays)
+	exRightsDate := businessCalendar.BusinessDaysAfter(lastBuyDate, 1)
+
+	fmt.Printf("\n=== %d年%d月 権利確定銘柄 ===\n\n", year, month)
+	fmt.Printf("権利確定日: %s\n", effectiveRecordDate.Format("2006-01-02"))
+	fmt.Printf("権利付最終日: %s（権利落ち日: %s）\n", lastBuyDate.Format("2006-01-02"), exRightsDate.Format("2006-01-02"))
+	if !businessCalendar.Covers(recordDate) {
+		fmt.Println("※ 取引カレンダーが未取得のため、土日・年末年始のみを休業日として計算しています（jquants trading_calendarで取得できます）")
+	}
+	fmt.Println()
+
+	typeNames := map[string]string{
+		database.RightsInterim: "中間",
+		database.RightsYearEnd: "期末",
+	}
+
+	if showAll {
+		limit = 0
+	}
+	if limit > 0 && len(entries) > limit {
+		entries = entries[:limit]
+	}
+
+	// ヘッダーを表示
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "コード\t企業名\t種別\t予想配当\t予想年間配当\t調整後終値\t予想配当利回り(%)\t予想開示日")
+	fmt.Fprintln(w, "----\t----\t----\t----\t----\t----\t----\t----")
+
+	for _, entry := range entries {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%.2f\t%s\t%s\t%s\t%s\n",
+			entry.Code, entry.CompanyName, typeNames[entry.RightsType], entry.ForecastDPS,
+			formatFloat64Ptr(entry.AnnualForecastDPS), formatFloat64Ptr(entry.AdjustmentClose),
+			formatFloat64Ptr(entry.DividendYield), entry.DisclosedDate.Format("2006-01-02"))
+	}
+
+	w.Flush()
+
+	if len(entries) == 0 {
+		fmt.Println("データが見つかりませんでした")
+	} else {
+		fmt.Printf("\n表示行数: %d\n", len(entries))
+	}
+
+	return nil
+}
+
+// formatFloat64Ptr 小数値を表示用に整形（NULLは"-"）
+func formatFloat64Ptr(v *float64) string {
+	if v == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%.2f", *v)
+}
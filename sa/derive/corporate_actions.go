@@ -0,0 +1,69 @@
+package derive
+
+import (
+	"fmt"
+	"log"
+	"stock-automation/database"
+	"stock-automation/helper"
+
+	"github.com/spf13/cobra"
+)
+
+var corporateActionsCmd = &cobra.Command{
+	Use:   "corporate_actions",
+	Short: "株式分割・併合を記録し調整後株価・配当履歴を再計算",
+	Long:  "daily_quotesの調整係数から未記録の株式分割・併合をcorporate_actionsに記録し、新たに検出した銘柄の過去の調整後四本値・出来高と配当履歴を再計算します（jquants daily_quotesでは取得時に自動で実行されます）",
+	RunE:  buildCorporateActions,
+}
+
+func init() {
+	// フラグを追加
+	corporateActionsCmd.Flags().String("code", "", "銘柄コード（指定しない場合は全銘柄）")
+	corporateActionsCmd.Flags().Bool("readjust", false, "新たな検出がない銘柄も調整後株価を再計算（--code指定時のみ）")
+}
+
+func buildCorporateActions(cmd *cobra.Command, args []string) error {
+	code, _ := cmd.Flags().GetString("code")
+	readjust, _ := cmd.Flags().GetBool("readjust")
+
+	if readjust && code == "" {
+		return fmt.Errorf("--readjustは--codeと合わせて指定してください")
+	}
+
+	// データベース接続
+	conn, err := database.NewConnectionFromEnv(false)
+	if err != nil {
+		return fmt.Errorf("データベース接続エラー: %v", err)
+	}
+	defer conn.Close()
+
+	var codes []string
+	if code != "" {
+		codes = append(codes, helper.NormalizeCode(code))
+	}
+
+	repository := database.NewCorporateActionRepository(conn)
+	detected, err := repository.DetectCorporateActions(codes)
+	if err != nil {
+		return fmt.Errorf("株式分割・併合検出エラー: %v", err)
+	}
+	if readjust && len(detected) == 0 {
+		detected = codes
+	}
+
+	for _, c := range detected {
+		if err := repository.ReadjustPrices(c); err != nil {
+			log.Printf("銘柄 %s の調整後株価再計算でエラー: %v", c, err)
+		}
+	}
+
+	// 分割調整後の配当・増配年数も新しい株数基準で作り直す
+	if len(detected) > 0 {
+		if err := database.NewDividendHistoryRepository(conn).BuildDividendHistory(detected); err != nil {
+			return fmt.Errorf("配当履歴再作成エラー: %v", err)
+		}
+	}
+
+	log.Printf("株式分割・併合の記録完了: %d銘柄で新たに検出", len(detected))
+	return nil
+}
@@ -0,0 +1,24 @@
+package derive
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var DeriveCmd = &cobra.Command{
+	Use:   "derive",
+	Short: "派生データ作成",
+	Long:  "財務情報・株価データから派生テーブルを作成（再作成）する機能を提供します",
+}
+
+func init() {
+	DeriveCmd.AddCommand(quarterlyCmd)
+	DeriveCmd.AddCommand(revisionsCmd)
+	DeriveCmd.AddCommand(surprisesCmd)
+	DeriveCmd.AddCommand(ratiosCmd)
+	DeriveCmd.AddCommand(scoresCmd)
+	DeriveCmd.AddCommand(dividendsCmd)
+	DeriveCmd.AddCommand(valuationsCmd)
+	DeriveCmd.AddCommand(indicatorsCmd)
+	DeriveCmd.AddCommand(windowsCmd)
+	DeriveCmd.AddCommand(corporateActionsCmd)
+}
@@ -0,0 +1,44 @@
+package derive
+
+import (
+	"fmt"
+	"stock-automation/database"
+	"stock-automation/helper"
+
+	"github.com/spf13/cobra"
+)
+
+var dividendsCmd = &cobra.Command{
+	Use:   "dividends",
+	Short: "配当履歴を作成",
+	Long:  "statementsの年間配当実績・予想とdaily_quotesの調整係数から連続増配年数などを算出し、dividend_historyを再作成します",
+	RunE:  buildDividends,
+}
+
+func init() {
+	// フラグを追加
+	dividendsCmd.Flags().String("code", "", "銘柄コード（指定しない場合は全銘柄）")
+}
+
+func buildDividends(cmd *cobra.Command, args []string) error {
+	code, _ := cmd.Flags().GetString("code")
+
+	// データベース接続
+	conn, err := database.NewConnectionFromEnv(false)
+	if err != nil {
+		return fmt.Errorf("データベース接続エラー: %v", err)
+	}
+	defer conn.Close()
+
+	var codes []string
+	if code != "" {
+		codes = append(codes, helper.NormalizeCode(code))
+	}
+
+	repository := database.NewDividendHistoryRepository(conn)
+	if err := repository.BuildDividendHistory(codes); err != nil {
+		return fmt.Errorf("配当履歴作成エラー: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,51 @@
+package derive
+
+import (
+	"fmt"
+	"stock-automation/database"
+	"stock-automation/helper"
+
+	"github.com/spf13/cobra"
+)
+
+var indicatorsCmd = &cobra.Command{
+	Use:   "indicators",
+	Short: "テクニカル指標を作成",
+	Long:  "daily_quotesの調整後終値・調整後出来高から移動平均・RSI・MACD・ボリンジャーバンドを算出し、indicatorsを再作成します（期間はINDICATOR_*環境変数で設定）",
+	RunE:  buildIndicators,
+}
+
+func init() {
+	// フラグを追加
+	indicatorsCmd.Flags().String("code", "", "銘柄コード（指定しない場合は全銘柄）")
+	indicatorsCmd.Flags().Bool("incremental", false, "保存済みの最終取引日より後の日のみ追加（指定しない場合は全期間を再作成）")
+}
+
+func buildIndicators(cmd *cobra.Command, args []string) error {
+	code, _ := cmd.Flags().GetString("code")
+	incremental, _ := cmd.Flags().GetBool("incremental")
+
+	config, err := database.NewIndicatorConfigFromEnv()
+	if err != nil {
+		return fmt.Errorf("テクニカル指標設定エラー: %v", err)
+	}
+
+	// データベース接続
+	conn, err := database.NewConnectionFromEnv(false)
+	if err != nil {
+		return fmt.Errorf("データベース接続エラー: %v", err)
+	}
+	defer conn.Close()
+
+	var codes []string
+	if code != "" {
+		codes = append(codes, helper.NormalizeCode(code))
+	}
+
+	repository := database.NewIndicatorRepository(conn)
+	if err := repository.BuildIndicators(codes, config, !incremental); err != nil {
+		return fmt.Errorf("テクニカル指標作成エラー: %v", err)
+	}
+
+	return nil
+}
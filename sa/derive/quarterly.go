@@ -0,0 +1,44 @@
+package derive
+
+import (
+	"fmt"
+	"stock-automation/database"
+	"stock-automation/helper"
+
+	"github.com/spf13/cobra"
+)
+
+var quarterlyCmd = &cobra.Command{
+	Use:   "quarterly",
+	Short: "四半期単独財務を作成",
+	Long:  "statementsの累計値から四半期単独値・TTM・前年同期比を算出し、statements_quarterlyを再作成します",
+	RunE:  buildQuarterly,
+}
+
+func init() {
+	// フラグを追加
+	quarterlyCmd.Flags().String("code", "", "銘柄コード（指定しない場合は全銘柄）")
+}
+
+func buildQuarterly(cmd *cobra.Command, args []string) error {
+	code, _ := cmd.Flags().GetString("code")
+
+	// データベース接続
+	conn, err := database.NewConnectionFromEnv(false)
+	if err != nil {
+		return fmt.Errorf("データベース接続エラー: %v", err)
+	}
+	defer conn.Close()
+
+	var codes []string
+	if code != "" {
+		codes = append(codes, helper.NormalizeCode(code))
+	}
+
+	repository := database.NewStatementsQuarterlyRepository(conn)
+	if err := repository.BuildQuarterly(codes); err != nil {
+		return fmt.Errorf("四半期単独財務作成エラー: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,44 @@
+package derive
+
+import (
+	"fmt"
+	"stock-automation/database"
+	"stock-automation/helper"
+
+	"github.com/spf13/cobra"
+)
+
+var ratiosCmd = &cobra.Command{
+	Use:   "ratios",
+	Short: "財務比率を作成",
+	Long:  "statementsの通期実績からROE・ROA・利益率・CAGRなどを算出し、financial_ratiosを再作成します",
+	RunE:  buildRatios,
+}
+
+func init() {
+	// フラグを追加
+	ratiosCmd.Flags().String("code", "", "銘柄コード（指定しない場合は全銘柄）")
+}
+
+func buildRatios(cmd *cobra.Command, args []string) error {
+	code, _ := cmd.Flags().GetString("code")
+
+	// データベース接続
+	conn, err := database.NewConnectionFromEnv(false)
+	if err != nil {
+		return fmt.Errorf("データベース接続エラー: %v", err)
+	}
+	defer conn.Close()
+
+	var codes []string
+	if code != "" {
+		codes = append(codes, helper.NormalizeCode(code))
+	}
+
+	repository := database.NewFinancialRatioRepository(conn)
+	if err := repository.BuildRatios(codes); err != nil {
+		return fmt.Errorf("財務比率作成エラー: %v", err)
+	}
+
+	return nil
+}
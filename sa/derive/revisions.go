@@ -0,0 +1,44 @@
+package derive
+
+import (
+	"fmt"
+	"stock-automation/database"
+	"stock-automation/helper"
+
+	"github.com/spf13/cobra"
+)
+
+var revisionsCmd = &cobra.Command{
+	Use:   "revisions",
+	Short: "業績予想修正を作成",
+	Long:  "statementsの通期予想を開示順に比較して修正を検出し、forecast_revisionsを再作成します",
+	RunE:  buildRevisions,
+}
+
+func init() {
+	// フラグを追加
+	revisionsCmd.Flags().String("code", "", "銘柄コード（指定しない場合は全銘柄）")
+}
+
+func buildRevisions(cmd *cobra.Command, args []string) error {
+	code, _ := cmd.Flags().GetString("code")
+
+	// データベース接続
+	conn, err := database.NewConnectionFromEnv(false)
+	if err != nil {
+		return fmt.Errorf("データベース接続エラー: %v", err)
+	}
+	defer conn.Close()
+
+	var codes []string
+	if code != "" {
+		codes = append(codes, helper.NormalizeCode(code))
+	}
+
+	repository := database.NewForecastRevisionRepository(conn)
+	if err := repository.BuildRevisions(codes); err != nil {
+		return fmt.Errorf("業績予想修正作成エラー: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,44 @@
+package derive
+
+import (
+	"fmt"
+	"stock-automation/database"
+	"stock-automation/helper"
+
+	"github.com/spf13/cobra"
+)
+
+var scoresCmd = &cobra.Command{
+	Use:   "scores",
+	Short: "財務品質スコアを作成",
+	Long:  "連続する会計年度の通期実績からF-scoreとZ-score（変形版）を算出し、quality_scoresを再作成します",
+	RunE:  buildScores,
+}
+
+func init() {
+	// フラグを追加
+	scoresCmd.Flags().String("code", "", "銘柄コード（指定しない場合は全銘柄）")
+}
+
+func buildScores(cmd *cobra.Command, args []string) error {
+	code, _ := cmd.Flags().GetString("code")
+
+	// データベース接続
+	conn, err := database.NewConnectionFromEnv(false)
+	if err != nil {
+		return fmt.Errorf("データベース接続エラー: %v", err)
+	}
+	defer conn.Close()
+
+	var codes []string
+	if code != "" {
+		codes = append(codes, helper.NormalizeCode(code))
+	}
+
+	repository := database.NewQualityScoreRepository(conn)
+	if err := repository.BuildScores(codes); err != nil {
+		return fmt.Errorf("財務品質スコア作成エラー: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,44 @@
+package derive
+
+import (
+	"fmt"
+	"stock-automation/database"
+	"stock-automation/helper"
+
+	"github.com/spf13/cobra"
+)
+
+var surprisesCmd = &cobra.Command{
+	Use:   "surprises",
+	Short: "決算サプライズを作成",
+	Long:  "statementsの実績を開示前の最新予想と比較し、earnings_surprisesを再作成します",
+	RunE:  buildSurprises,
+}
+
+func init() {
+	// フラグを追加
+	surprisesCmd.Flags().String("code", "", "銘柄コード（指定しない場合は全銘柄）")
+}
+
+func buildSurprises(cmd *cobra.Command, args []string) error {
+	code, _ := cmd.Flags().GetString("code")
+
+	// データベース接続
+	conn, err := database.NewConnectionFromEnv(false)
+	if err != nil {
+		return fmt.Errorf("データベース接続エラー: %v", err)
+	}
+	defer conn.Close()
+
+	var codes []string
+	if code != "" {
+		codes = append(codes, helper.NormalizeCode(code))
+	}
+
+	repository := database.NewEarningsSurpriseRepository(conn)
+	if err := repository.BuildSurprises(codes); err != nil {
+		return fmt.Errorf("決算サプライズ作成エラー: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,46 @@
+package derive
+
+import (
+	"fmt"
+	"stock-automation/database"
+	"stock-automation/helper"
+
+	"github.com/spf13/cobra"
+)
+
+var valuationsCmd = &cobra.Command{
+	Use:   "valuations",
+	Short: "株価指標を作成",
+	Long:  "daily_quotesの終値と各取引日時点で開示済みの財務情報から時価総額・PER・PBR・配当利回りを算出し、valuationsを再作成します",
+	RunE:  buildValuations,
+}
+
+func init() {
+	// フラグを追加
+	valuationsCmd.Flags().String("code", "", "銘柄コード（指定しない場合は全銘柄）")
+	valuationsCmd.Flags().String("from", "", "作成開始日（YYYY-MM-DD形式、指定しない場合は全期間）")
+}
+
+func buildValuations(cmd *cobra.Command, args []string) error {
+	code, _ := cmd.Flags().GetString("code")
+	from, _ := cmd.Flags().GetString("from")
+
+	// データベース接続
+	conn, err := database.NewConnectionFromEnv(false)
+	if err != nil {
+		return fmt.Errorf("データベース接続エラー: %v", err)
+	}
+	defer conn.Close()
+
+	var codes []string
+	if code != "" {
+		codes = append(codes, helper.NormalizeCode(code))
+	}
+
+	repository := database.NewValuationRepository(conn)
+	if err := repository.BuildValuations(codes, from); err != nil {
+		return fmt.Errorf("株価指標作成エラー: %v", err)
+	}
+
+	return nil
+}
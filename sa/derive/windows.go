@@ -0,0 +1,57 @@
+package derive
+
+import (
+	"fmt"
+	"stock-automation/database"
+	"stock-automation/helper"
+
+	"github.com/spf13/cobra"
+)
+
+var windowsCmd = &cobra.Command{
+	Use:   "windows",
+	Short: "期間別評価指標を作成",
+	Long:  "銘柄ごとの最終取引日時点で、期間（ASSESSMENT_WINDOWS環境変数または--windowsで指定）ごとの最高・最低調整終値と乖離率を算出し、assessment_window_metricsを再作成します",
+	RunE:  buildWindows,
+}
+
+func init() {
+	// フラグを追加
+	windowsCmd.Flags().String("code", "", "銘柄コード（指定しない場合は全銘柄）")
+	windowsCmd.Flags().String("windows", "", "期間（カンマ区切り、例: 1M,3M,52W,3Y。指定しない場合はASSESSMENT_WINDOWS環境変数）")
+}
+
+func buildWindows(cmd *cobra.Command, args []string) error {
+	code, _ := cmd.Flags().GetString("code")
+	windowsFlag, _ := cmd.Flags().GetString("windows")
+
+	var windows []database.AssessmentWindow
+	var err error
+	if windowsFlag != "" {
+		windows, err = database.ParseAssessmentWindows(windowsFlag)
+	} else {
+		windows, err = database.NewAssessmentWindowsFromEnv()
+	}
+	if err != nil {
+		return err
+	}
+
+	// データベース接続
+	conn, err := database.NewConnectionFromEnv(false)
+	if err != nil {
+		return fmt.Errorf("データベース接続エラー: %v", err)
+	}
+	defer conn.Close()
+
+	var codes []string
+	if code != "" {
+		codes = append(codes, helper.NormalizeCode(code))
+	}
+
+	repository := database.NewAssessmentWindowRepository(conn)
+	if err := repository.BuildWindowMetrics(codes, windows); err != nil {
+		return fmt.Errorf("期間別評価指標作成エラー: %v", err)
+	}
+
+	return nil
+}
@@ -5,7 +5,14 @@ import (
 	"os"
 	"stock-automation/helper"
 
+	"sa/alert"
+	"sa/backtest"
+	"sa/calendar"
+	"sa/derive"
+	"sa/portfolio"
 	"sa/query"
+	"sa/report"
+	"sa/watch"
 
 	"github.com/spf13/cobra"
 )
@@ -39,4 +46,13 @@ func init() {
 	rootCmd.PersistentFlags().StringVar(&logLevel, "log", "", "ログレベル (debug, info, warn, error)")
 
 	rootCmd.AddCommand(query.QueryCmd)
+	rootCmd.AddCommand(derive.DeriveCmd)
+	rootCmd.AddCommand(calendar.CalendarCmd)
+	rootCmd.AddCommand(backtest.BacktestCmd)
+	rootCmd.AddCommand(portfolio.PortfolioCmd)
+	rootCmd.AddCommand(watch.WatchCmd)
+	rootCmd.AddCommand(watch.NoteCmd)
+	rootCmd.AddCommand(watch.TagCmd)
+	rootCmd.AddCommand(alert.AlertCmd)
+	rootCmd.AddCommand(report.ReportCmd)
 }
@@ -0,0 +1,54 @@
+package portfolio
+
+import (
+	"fmt"
+	"stock-automation/database"
+	"stock-automation/helper"
+
+	"github.com/spf13/cobra"
+)
+
+var addCmd = &cobra.Command{
+	Use:   "add",
+	Short: "保有銘柄を追加",
+	Long:  "保有銘柄を追加します。保有済みの銘柄は株数を加算し、平均取得単価を移動平均で再計算します",
+	RunE:  addHolding,
+}
+
+func init() {
+	// フラグを追加
+	addCmd.Flags().String("code", "", "銘柄コード（必須）")
+	addCmd.Flags().Int64P("quantity", "q", 0, "株数（必須）")
+	addCmd.Flags().Float64("price", 0, "取得単価（円、必須）")
+	addCmd.MarkFlagRequired("code")
+	addCmd.MarkFlagRequired("quantity")
+	addCmd.MarkFlagRequired("price")
+}
+
+func addHolding(cmd *cobra.Command, args []string) error {
+	code, _ := cmd.Flags().GetString("code")
+	quantity, _ := cmd.Flags().GetInt64("quantity")
+	price, _ := cmd.Flags().GetFloat64("price")
+
+	// データベース接続
+	conn, err := database.NewConnectionFromEnv(false)
+	if err != nil {
+		return fmt.Errorf("データベース接続エラー: %v", err)
+	}
+	defer conn.Close()
+
+	repository := database.NewPortfolioRepository(conn)
+	portfolio, err := getPortfolio(cmd, repository, true)
+	if err != nil {
+		return err
+	}
+
+	holding, err := repository.AddHolding(portfolio.ID, helper.NormalizeCode(code), quantity, price)
+	if err != nil {
+		return fmt.Errorf("保有銘柄追加エラー: %v", err)
+	}
+
+	fmt.Printf("%s: %s %s を%d株追加しました（保有株数: %d、平均取得単価: %.2f）\n",
+		portfolio.Name, holding.Code, holding.CompanyName, quantity, holding.Quantity, holding.AverageCost)
+	return nil
+}
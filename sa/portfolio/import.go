@@ -0,0 +1,257 @@
+package portfolio
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"stock-automation/database"
+	"stock-automation/helper"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/transform"
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import <file.csv>",
+	Short: "証券会社のCSVから取引を取り込む",
+	Long: `証券会社からダウンロードした取引履歴CSVを取引履歴に取り込み、保有銘柄に反映します。
+銘柄コードは上場銘柄情報（listed_info）の銘柄コードに対応付け、取り込み済みの取引は重複として読み飛ばします。
+取り込めなかった行は理由とともに表示します。
+
+形式:
+  sbi      SBI証券の約定履歴（現物の買い・売り）
+  rakuten  楽天証券の国内株式取引履歴（現物の買付・売付）または配当金・分配金
+  generic  汎用CSV（列: date, code, type, quantity, price, fee, amount, tax, ratio, settlement_date, name, note）`,
+	Args: cobra.ExactArgs(1),
+	RunE: importTransactions,
+}
+
+func init() {
+	// フラグを追加
+	importCmd.Flags().String("format", "", "CSVの形式（sbi, rakuten, generic、必須）")
+	importCmd.Flags().String("encoding", "", "文字コード（sjis, utf8。指定しない場合はsbi・rakutenはsjis、genericはutf8）")
+	importCmd.Flags().Bool("dry-run", false, "取り込まずに結果のみ表示")
+	importCmd.MarkFlagRequired("format")
+}
+
+func importTransactions(cmd *cobra.Command, args []string) error {
+	formatName, _ := cmd.Flags().GetString("format")
+	encoding, _ := cmd.Flags().GetString("encoding")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+	format, ok := importFormats[formatName]
+	if !ok {
+		return fmt.Errorf("サポートされていない形式です（sbi, rakuten, generic）: '%s'", formatName)
+	}
+	if encoding == "" {
+		encoding = format.encoding
+	}
+
+	records, err := readCSV(args[0], encoding)
+	if err != nil {
+		return err
+	}
+
+	rows, unmatched, err := format.parse(records)
+	if err != nil {
+		return fmt.Errorf("CSV解析エラー: %v", err)
+	}
+
+	// データベース接続
+	conn, err := database.NewConnectionFromEnv(false)
+	if err != nil {
+		return fmt.Errorf("データベース接続エラー: %v", err)
+	}
+	defer conn.Close()
+
+	companyNames, err := database.NewListedInfoRepository(conn).GetCompanyNames()
+	if err != nil {
+		return fmt.Errorf("上場銘柄情報取得エラー: %v", err)
+	}
+
+	// dry-runの場合はポートフォリオを作成しない（存在しない場合は全行を取り込み対象とする）
+	repository := database.NewPortfolioRepository(conn)
+	var portfolio *database.Portfolio
+	if dryRun {
+		name, _ := cmd.Flags().GetString("portfolio")
+		portfolio, err = repository.GetPortfolio(name)
+	} else {
+		portfolio, err = getPortfolio(cmd, repository, true)
+	}
+	if err != nil {
+		return err
+	}
+
+	// 銘柄コードを上場銘柄情報に対応付け
+	var matched []*importedRow
+	codeMatcher := newCodeMatcher(companyNames)
+	for _, row := range rows {
+		code, ok := codeMatcher.match(row.code, row.name)
+		if !ok {
+			unmatched = append(unmatched, &unmatchedRow{
+				line:   row.line,
+				reason: fmt.Sprintf("上場銘柄情報にない銘柄です（コード: '%s', 銘柄名: '%s'）", row.code, row.name),
+			})
+			continue
+		}
+		if err := database.ValidateTransaction(row.transaction); err != nil {
+			unmatched = append(unmatched, &unmatchedRow{line: row.line, reason: err.Error()})
+			continue
+		}
+		row.transaction.Code = code
+		row.transaction.Source = formatName
+		if portfolio != nil {
+			row.transaction.PortfolioID = portfolio.ID
+		}
+		matched = append(matched, row)
+	}
+
+	// 買いを売りより先に反映するため約定日の古い順に取り込む
+	// 同じ約定日の取引は実際の約定順にする（証券会社のCSVは新しい順のため行番号の大きい順、それ以外はファイルの順）
+	sort.SliceStable(matched, func(i, j int) bool {
+		a, b := matched[i], matched[j]
+		if !a.transaction.TradeDate.Equal(b.transaction.TradeDate) {
+			return a.transaction.TradeDate.Before(b.transaction.TradeDate)
+		}
+		if format.newestFirst {
+			return a.line > b.line
+		}
+		return a.line < b.line
+	})
+
+	imported, duplicates := 0, 0
+	occurrences := make(map[string]int)
+	for _, row := range matched {
+		t := row.transaction
+		base := database.TransactionImportKey(t, 0)
+		t.ImportKey = database.TransactionImportKey(t, occurrences[base])
+		occurrences[base]++
+
+		if dryRun && portfolio == nil {
+			imported++
+			continue
+		}
+		exists, err := repository.HasImportKey(portfolio.ID, t.ImportKey)
+		if err != nil {
+			return err
+		}
+		if exists {
+			duplicates++
+			continue
+		}
+		if dryRun {
+			imported++
+			continue
+		}
+		if err := repository.ImportTransaction(t); err != nil {
+			unmatched = append(unmatched, &unmatchedRow{line: row.line, reason: err.Error()})
+			continue
+		}
+		imported++
+	}
+
+	sort.SliceStable(unmatched, func(i, j int) bool { return unmatched[i].line < unmatched[j].line })
+	printImportResult(args[0], portfolio, dryRun, imported, duplicates, unmatched, records)
+
+	return nil
+}
+
+// readCSV CSVファイルを指定した文字コードで読み込む
+func readCSV(path, encoding string) ([][]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("ファイル読み込みエラー: %v", err)
+	}
+
+	var reader io.Reader
+	switch encoding {
+	case "sjis":
+		reader = transform.NewReader(bytes.NewReader(data), japanese.ShiftJIS.NewDecoder())
+	case "utf8":
+		reader = bytes.NewReader(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")))
+	default:
+		return nil, fmt.Errorf("サポートされていない文字コードです（sjis, utf8）: '%s'", encoding)
+	}
+
+	r := csv.NewReader(reader)
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("CSV読み込みエラー: %v", err)
+	}
+	return records, nil
+}
+
+// codeMatcher CSVの銘柄コード・銘柄名を上場銘柄情報の銘柄コードに対応付ける
+type codeMatcher struct {
+	companyNames map[string]string // 銘柄コード -> 企業名
+	codesByName  map[string][]string
+}
+
+func newCodeMatcher(companyNames map[string]string) *codeMatcher {
+	m := &codeMatcher{companyNames: companyNames, codesByName: make(map[string][]string)}
+	for code, name := range companyNames {
+		m.codesByName[name] = append(m.codesByName[name], code)
+	}
+	return m
+}
+
+// match 4桁（英字を含む新コードを含む）の銘柄コードは末尾に0を付けて対応付け、コードがない場合は企業名が一致する銘柄（1件のみ）に対応付ける
+func (m *codeMatcher) match(code, name string) (string, bool) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if code != "" {
+		code = helper.NormalizeCode(code)
+		_, ok := m.companyNames[code]
+		return code, ok
+	}
+
+	if codes := m.codesByName[strings.TrimSpace(name)]; len(codes) == 1 {
+		return codes[0], true
+	}
+	return "", false
+}
+
+// printImportResult 取り込み結果と取り込めなかった行を表示
+func printImportResult(path string, portfolio *database.Portfolio, dryRun bool, imported, duplicates int, unmatched []*unmatchedRow, records [][]string) {
+	name := "-"
+	if portfolio != nil {
+		name = portfolio.Name
+	}
+
+	fmt.Printf("\n=== CSV取り込み結果（%s） ===\n\n", name)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "ファイル\t%s\n", path)
+	if dryRun {
+		fmt.Fprintf(w, "取り込み対象\t%d件（dry-run）\n", imported)
+	} else {
+		fmt.Fprintf(w, "取り込み\t%d件\n", imported)
+	}
+	fmt.Fprintf(w, "取り込み済み（重複）\t%d件\n", duplicates)
+	fmt.Fprintf(w, "取り込めなかった行\t%d件\n", len(unmatched))
+	w.Flush()
+
+	if len(unmatched) == 0 {
+		return
+	}
+
+	fmt.Printf("\n--- 取り込めなかった行 ---\n\n")
+	w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "行\t理由\t内容")
+	fmt.Fprintln(w, "----\t----\t----")
+	for _, u := range unmatched {
+		text := u.text
+		if text == "" && u.line-1 < len(records) {
+			text = strings.Join(records[u.line-1], ",")
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\n", u.line, u.reason, text)
+	}
+	w.Flush()
+}
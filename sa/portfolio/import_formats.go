@@ -0,0 +1,329 @@
+package portfolio
+
+import (
+	"fmt"
+	"regexp"
+	"stock-automation/database"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// importedRow CSVの1行から読み取った取引
+type importedRow struct {
+	line        int    // ファイル内の行番号（1始まり）
+	code        string // CSVの銘柄コード
+	name        string // CSVの銘柄名
+	transaction *database.PortfolioTransaction
+}
+
+// unmatchedRow 取り込めなかった行
+type unmatchedRow struct {
+	line   int
+	reason string
+	text   string
+}
+
+// importFormat 証券会社ごとのCSV形式
+type importFormat struct {
+	encoding    string // 既定の文字コード（sjis, utf8）
+	newestFirst bool   // 約定日の新しい順に出力されるCSVか（同じ約定日の取引を行番号の大きい順に取り込む）
+	parse       func(records [][]string) ([]*importedRow, []*unmatchedRow, error)
+}
+
+// importFormats サポートするCSV形式
+var importFormats = map[string]importFormat{
+	"sbi":     {encoding: "sjis", newestFirst: true, parse: parseSBI},
+	"rakuten": {encoding: "sjis", newestFirst: true, parse: parseRakuten},
+	"generic": {encoding: "utf8", parse: parseGeneric},
+}
+
+// csvHeader 見出し行の列名と列番号の対応
+type csvHeader struct {
+	line    int // 見出し行の行番号（1始まり）
+	columns map[string]int
+}
+
+// headerSuffixPattern 列名末尾の単位などの括弧書き（例: 数量［株］、配当・分配金合計（税引前）[円/現地通貨]）
+var headerSuffixPattern = regexp.MustCompile(`(\s*[\[［(（〔][^\]］)）〕]*[\]］)）〕])+$`)
+
+// normalizeHeader 列名から空白と末尾の括弧書きを除く
+func normalizeHeader(s string) string {
+	s = strings.TrimSpace(strings.TrimPrefix(s, "\ufeff"))
+	return headerSuffixPattern.ReplaceAllString(s, "")
+}
+
+// findHeader 必要な列名を全て含む最初の行を見出し行として探す（証券会社のCSVは見出し行の前に口座情報などの行がある）
+func findHeader(records [][]string, required ...string) (*csvHeader, error) {
+	for i, record := range records {
+		columns := make(map[string]int, len(record))
+		for j, cell := range record {
+			name := normalizeHeader(cell)
+			if _, ok := columns[name]; !ok {
+				columns[name] = j
+			}
+		}
+
+		found := true
+		for _, name := range required {
+			if _, ok := columns[name]; !ok {
+				found = false
+				break
+			}
+		}
+		if found {
+			return &csvHeader{line: i + 1, columns: columns}, nil
+		}
+	}
+	return nil, fmt.Errorf("見出し行が見つかりません（必要な列: %s）", strings.Join(required, ", "))
+}
+
+// has 列があるかを判定
+func (h *csvHeader) has(name string) bool {
+	_, ok := h.columns[name]
+	return ok
+}
+
+// value 列の値を取得（列がない場合や行が短い場合は空文字）
+func (h *csvHeader) value(record []string, name string) string {
+	i, ok := h.columns[name]
+	if !ok || i >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[i])
+}
+
+// parseCSVDate 日付を解析（2006/01/02, 2006-01-02, 2006年01月02日など）
+func parseCSVDate(s string) (time.Time, error) {
+	for _, layout := range []string{"2006/1/2", "2006-1-2", "2006年1月2日", "20060102"} {
+		if date, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return date, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("日付の形式が正しくありません: '%s'", s)
+}
+
+// parseCSVNumber 数値を解析（桁区切りのカンマや単位を除く。空や"-"の場合はnil）
+func parseCSVNumber(s string) (*float64, error) {
+	s = strings.NewReplacer(",", "", "円", "", "株", "", " ", "", "　", "").Replace(s)
+	if s == "" || s == "-" || s == "--" {
+		return nil, nil
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return nil, fmt.Errorf("数値の形式が正しくありません: '%s'", s)
+	}
+	return &v, nil
+}
+
+// rowParser 1行の解析中に最初に発生したエラーを保持する
+type rowParser struct {
+	header *csvHeader
+	record []string
+	err    error
+}
+
+func (p *rowParser) date(name string) time.Time {
+	if p.err != nil {
+		return time.Time{}
+	}
+	date, err := parseCSVDate(p.header.value(p.record, name))
+	if err != nil {
+		p.err = fmt.Errorf("%s: %v", name, err)
+	}
+	return date
+}
+
+func (p *rowParser) number(name string) *float64 {
+	if p.err != nil {
+		return nil
+	}
+	v, err := parseCSVNumber(p.header.value(p.record, name))
+	if err != nil {
+		p.err = fmt.Errorf("%s: %v", name, err)
+	}
+	return v
+}
+
+// amount 数値を解析（空の場合は0）
+func (p *rowParser) amount(name string) float64 {
+	if v := p.number(name); v != nil {
+		return *v
+	}
+	return 0
+}
+
+// quantity 株数を解析（空の場合は0）
+func (p *rowParser) quantity(name string) int64 {
+	return int64(p.amount(name))
+}
+
+// parseRecords 見出し行より後の行を1行ずつ解析（空行は読み飛ばす）
+// parseRow: 取引を返すか、取り込まない理由を返す
+func parseRecords(records [][]string, header *csvHeader, parseRow func(p *rowParser) (*importedRow, string)) ([]*importedRow, []*unmatchedRow) {
+	var rows []*importedRow
+	var unmatched []*unmatchedRow
+	for i := header.line; i < len(records); i++ {
+		record := records[i]
+		if strings.TrimSpace(strings.Join(record, "")) == "" {
+			continue
+		}
+
+		p := &rowParser{header: header, record: record}
+		row, reason := parseRow(p)
+		if p.err != nil {
+			reason = p.err.Error()
+		}
+		if reason != "" {
+			unmatched = append(unmatched, &unmatchedRow{line: i + 1, reason: reason, text: strings.Join(record, ",")})
+			continue
+		}
+		row.line = i + 1
+		rows = append(rows, row)
+	}
+	return rows, unmatched
+}
+
+// parseSBI SBI証券の約定履歴CSVを解析（現物の買い・売りのみ）
+func parseSBI(records [][]string) ([]*importedRow, []*unmatchedRow, error) {
+	header, err := findHeader(records, "約定日", "銘柄", "銘柄コード", "取引", "約定数量", "約定単価")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rows, unmatched := parseRecords(records, header, func(p *rowParser) (*importedRow, string) {
+		var transactionType string
+		switch trade := p.header.value(p.record, "取引"); {
+		case strings.Contains(trade, "現物買"):
+			transactionType = database.TransactionBuy
+		case strings.Contains(trade, "現物売"):
+			transactionType = database.TransactionSell
+		default:
+			return nil, fmt.Sprintf("サポートされていない取引です: '%s'", trade)
+		}
+
+		t := &database.PortfolioTransaction{
+			TransactionType: transactionType,
+			TradeDate:       p.date("約定日"),
+			Quantity:        p.quantity("約定数量"),
+			Price:           p.number("約定単価"),
+			Fee:             p.amount("手数料/諸経費等"),
+			Tax:             p.amount("税額"),
+		}
+		if p.header.has("受渡日") {
+			t.SettlementDate = p.date("受渡日")
+		}
+		return &importedRow{
+			code:        p.header.value(p.record, "銘柄コード"),
+			name:        p.header.value(p.record, "銘柄"),
+			transaction: t,
+		}, ""
+	})
+
+	return rows, unmatched, nil
+}
+
+// parseRakuten 楽天証券の国内株式取引履歴CSV（現物の買付・売付）または配当金・分配金CSVを解析
+func parseRakuten(records [][]string) ([]*importedRow, []*unmatchedRow, error) {
+	if header, err := findHeader(records, "入金日", "銘柄コード", "配当・分配金合計"); err == nil {
+		return parseRakutenDividends(records, header)
+	}
+
+	header, err := findHeader(records, "約定日", "銘柄コード", "銘柄名", "取引区分", "売買区分", "数量", "単価")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rows, unmatched := parseRecords(records, header, func(p *rowParser) (*importedRow, string) {
+		if category := p.header.value(p.record, "取引区分"); category != "現物" {
+			return nil, fmt.Sprintf("サポートされていない取引区分です: '%s'", category)
+		}
+
+		var transactionType string
+		switch side := p.header.value(p.record, "売買区分"); side {
+		case "買付":
+			transactionType = database.TransactionBuy
+		case "売付":
+			transactionType = database.TransactionSell
+		default:
+			return nil, fmt.Sprintf("サポートされていない売買区分です: '%s'", side)
+		}
+
+		// 手数料と諸費用を手数料、税金等（源泉徴収税額）を税額とする
+		t := &database.PortfolioTransaction{
+			TransactionType: transactionType,
+			TradeDate:       p.date("約定日"),
+			Quantity:        p.quantity("数量"),
+			Price:           p.number("単価"),
+			Fee:             p.amount("手数料") + p.amount("諸費用"),
+			Tax:             p.amount("税金等"),
+		}
+		if p.header.has("受渡日") {
+			t.SettlementDate = p.date("受渡日")
+		}
+		return &importedRow{
+			code:        p.header.value(p.record, "銘柄コード"),
+			name:        p.header.value(p.record, "銘柄名"),
+			transaction: t,
+		}, ""
+	})
+
+	return rows, unmatched, nil
+}
+
+// parseRakutenDividends 楽天証券の配当金・分配金CSVを解析（国内株式のみ）
+func parseRakutenDividends(records [][]string, header *csvHeader) ([]*importedRow, []*unmatchedRow, error) {
+	rows, unmatched := parseRecords(records, header, func(p *rowParser) (*importedRow, string) {
+		if product := p.header.value(p.record, "商品"); product != "" && product != "国内株式" {
+			return nil, fmt.Sprintf("サポートされていない商品です: '%s'", product)
+		}
+
+		t := &database.PortfolioTransaction{
+			TransactionType: database.TransactionDividend,
+			TradeDate:       p.date("入金日"),
+			Quantity:        p.quantity("数量"),
+			Amount:          p.number("配当・分配金合計"),
+			Tax:             p.amount("税額合計"),
+		}
+		return &importedRow{
+			code:        p.header.value(p.record, "銘柄コード"),
+			name:        p.header.value(p.record, "銘柄"),
+			transaction: t,
+		}, ""
+	})
+
+	return rows, unmatched, nil
+}
+
+// parseGeneric 汎用CSVを解析
+// 列: date, code, type（buy, sell, dividend, split）は必須。quantity, price, fee, amount, tax, ratio, settlement_date, name, noteは任意
+func parseGeneric(records [][]string) ([]*importedRow, []*unmatchedRow, error) {
+	header, err := findHeader(records, "date", "code", "type")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rows, unmatched := parseRecords(records, header, func(p *rowParser) (*importedRow, string) {
+		t := &database.PortfolioTransaction{
+			TransactionType: strings.ToLower(p.header.value(p.record, "type")),
+			TradeDate:       p.date("date"),
+			Quantity:        p.quantity("quantity"),
+			Price:           p.number("price"),
+			Fee:             p.amount("fee"),
+			Amount:          p.number("amount"),
+			Tax:             p.amount("tax"),
+			SplitRatio:      p.number("ratio"),
+			Note:            p.header.value(p.record, "note"),
+		}
+		if p.header.value(p.record, "settlement_date") != "" {
+			t.SettlementDate = p.date("settlement_date")
+		}
+		return &importedRow{
+			code:        p.header.value(p.record, "code"),
+			name:        p.header.value(p.record, "name"),
+			transaction: t,
+		}, ""
+	})
+
+	return rows, unmatched, nil
+}
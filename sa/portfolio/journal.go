@@ -0,0 +1,101 @@
+package portfolio
+
+import (
+	"fmt"
+	"os"
+	"stock-automation/database"
+	"stock-automation/helper"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+var journalCmd = &cobra.Command{
+	Use:   "journal",
+	Short: "取引履歴を表示",
+	Long: `取引履歴を約定日順に、移動平均法で計算した取引後の保有株数・平均取得単価と売りの実現損益とともに表示します。
+保有中に権利落ちした株式分割・併合が記録されていない場合は、daily_quotesの調整係数から補って計算します（種別に*を表示）`,
+	RunE: showJournal,
+}
+
+func init() {
+	// フラグを追加
+	journalCmd.Flags().String("code", "", "銘柄コード（指定しない場合は全銘柄）")
+	journalCmd.Flags().Int("year", 0, "表示する年（約定日の年、指定しない場合は全期間）")
+}
+
+func showJournal(cmd *cobra.Command, args []string) error {
+	code, _ := cmd.Flags().GetString("code")
+	year, _ := cmd.Flags().GetInt("year")
+	if code != "" {
+		code = helper.NormalizeCode(code)
+	}
+
+	// データベース接続
+	conn, err := database.NewConnectionFromEnv(false)
+	if err != nil {
+		return fmt.Errorf("データベース接続エラー: %v", err)
+	}
+	defer conn.Close()
+
+	repository := database.NewPortfolioRepository(conn)
+	portfolio, err := getPortfolio(cmd, repository, false)
+	if err != nil {
+		return err
+	}
+
+	journal, err := repository.BuildJournal(portfolio.ID, code)
+	if err != nil {
+		return fmt.Errorf("データ取得エラー: %v", err)
+	}
+
+	fmt.Printf("\n=== 取引履歴（%s） ===\n\n", portfolio.Name)
+
+	// ヘッダーを表示
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\t約定日\t受渡日\tコード\t企業名\t種別\t株数\t単価\t手数料\t配当金額\t税額\t分割比率\t保有株数\t平均取得単価\t実現損益\tメモ")
+	fmt.Fprintln(w, "----\t----\t----\t----\t----\t----\t----\t----\t----\t----\t----\t----\t----\t----\t----\t----")
+
+	shown := 0
+	autoSplits, unknownCosts := 0, 0
+	for _, e := range journal {
+		if year != 0 && e.TradeDate.Year() != year {
+			continue
+		}
+		id, typeName := fmt.Sprintf("%d", e.ID), transactionTypeNames[e.TransactionType]
+		if e.Auto {
+			id, typeName = "-", typeName+"*"
+			autoSplits++
+		}
+		pnl := formatAmountPtr(e.RealizedPnL)
+		if e.UnknownCost {
+			pnl = "取得費不明"
+			unknownCosts++
+		}
+		quantity := "-"
+		if e.Quantity != 0 {
+			quantity = fmt.Sprintf("%d", e.Quantity)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%.0f\t%s\t%.0f\t%s\t%d\t%.2f\t%s\t%s\n",
+			id, e.TradeDate.Format("2006-01-02"), e.SettlementDate.Format("2006-01-02"), e.Code, e.CompanyName, typeName,
+			quantity, formatFloat64Ptr(e.Price), e.Fee, formatAmountPtr(e.Amount), e.Tax, formatFloat64Ptr(e.SplitRatio),
+			e.Position, e.AverageCost, pnl, e.Note)
+		shown++
+	}
+
+	w.Flush()
+
+	if shown == 0 {
+		fmt.Println("データが見つかりませんでした")
+		return nil
+	}
+	fmt.Printf("\n表示行数: %d\n", shown)
+	if unknownCosts > 0 {
+		fmt.Printf("※ 取得費不明は保有株数を超える売り（取り込み期間より前に買った株式など）で、実現損益の集計に含めていません\n")
+	}
+	if autoSplits > 0 {
+		fmt.Printf("※ *は取引履歴にない株式分割・併合をdaily_quotesの調整係数から補ったものです（sa portfolio splits --applyで記録できます）\n")
+	}
+
+	return nil
+}
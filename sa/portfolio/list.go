@@ -0,0 +1,91 @@
+package portfolio
+
+import (
+	"fmt"
+	"os"
+	"stock-automation/database"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "保有銘柄を表示",
+	Long:  "ポートフォリオの保有銘柄を株数・平均取得単価・取得金額とともに表示します。--portfoliosを指定した場合はポートフォリオの一覧を表示します",
+	RunE:  listHoldings,
+}
+
+func init() {
+	// フラグを追加
+	listCmd.Flags().Bool("portfolios", false, "ポートフォリオの一覧を表示")
+}
+
+func listHoldings(cmd *cobra.Command, args []string) error {
+	showPortfolios, _ := cmd.Flags().GetBool("portfolios")
+
+	// データベース接続
+	conn, err := database.NewConnectionFromEnv(false)
+	if err != nil {
+		return fmt.Errorf("データベース接続エラー: %v", err)
+	}
+	defer conn.Close()
+
+	repository := database.NewPortfolioRepository(conn)
+
+	if showPortfolios {
+		portfolios, err := repository.GetPortfolios()
+		if err != nil {
+			return fmt.Errorf("データ取得エラー: %v", err)
+		}
+
+		fmt.Printf("\n=== ポートフォリオ一覧 ===\n\n")
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "名前\t説明\t作成日時")
+		fmt.Fprintln(w, "----\t----\t----")
+		for _, p := range portfolios {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", p.Name, p.Description, p.CreatedAt.Format("2006-01-02 15:04:05"))
+		}
+		w.Flush()
+
+		if len(portfolios) == 0 {
+			fmt.Println("データが見つかりませんでした")
+		}
+		return nil
+	}
+
+	portfolio, err := getPortfolio(cmd, repository, false)
+	if err != nil {
+		return err
+	}
+
+	holdings, err := repository.GetHoldings(portfolio.ID)
+	if err != nil {
+		return fmt.Errorf("データ取得エラー: %v", err)
+	}
+
+	fmt.Printf("\n=== 保有銘柄（%s） ===\n\n", portfolio.Name)
+
+	// ヘッダーを表示
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "コード\t企業名\t株数\t平均取得単価\t取得金額\t更新日時")
+	fmt.Fprintln(w, "----\t----\t----\t----\t----\t----")
+
+	total := 0.0
+	for _, h := range holdings {
+		cost := h.AverageCost * float64(h.Quantity)
+		total += cost
+		fmt.Fprintf(w, "%s\t%s\t%d\t%.2f\t%.0f\t%s\n",
+			h.Code, h.CompanyName, h.Quantity, h.AverageCost, cost, h.UpdatedAt.Format("2006-01-02 15:04:05"))
+	}
+
+	w.Flush()
+
+	if len(holdings) == 0 {
+		fmt.Println("データが見つかりませんでした")
+	} else {
+		fmt.Printf("\n銘柄数: %d / 取得金額合計: %.0f\n", len(holdings), total)
+	}
+
+	return nil
+}
@@ -0,0 +1,96 @@
+package portfolio
+
+import (
+	"fmt"
+	"os"
+	"stock-automation/database"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+var pnlCmd = &cobra.Command{
+	Use:   "pnl",
+	Short: "年ごとの実現損益を表示",
+	Long: `取引履歴から移動平均法で計算した実現損益と配当を年ごとに集計して表示します（確定申告用）。
+売買は受渡日、配当は支払日の年で集計します。--yearを指定した場合はその年の売りの明細も表示します`,
+	RunE: showPnL,
+}
+
+func init() {
+	// フラグを追加
+	pnlCmd.Flags().Int("year", 0, "明細を表示する年（受渡日の年）")
+}
+
+func showPnL(cmd *cobra.Command, args []string) error {
+	year, _ := cmd.Flags().GetInt("year")
+
+	// データベース接続
+	conn, err := database.NewConnectionFromEnv(false)
+	if err != nil {
+		return fmt.Errorf("データベース接続エラー: %v", err)
+	}
+	defer conn.Close()
+
+	repository := database.NewPortfolioRepository(conn)
+	portfolio, err := getPortfolio(cmd, repository, false)
+	if err != nil {
+		return err
+	}
+
+	journal, err := repository.BuildJournal(portfolio.ID, "")
+	if err != nil {
+		return fmt.Errorf("データ取得エラー: %v", err)
+	}
+
+	years := database.SummarizeByYear(journal)
+
+	fmt.Printf("\n=== 年別実現損益（%s） ===\n\n", portfolio.Name)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "年\t売却件数\t売却代金\t取得費\t実現損益\t配当金額\t源泉徴収税額\t取得費不明の売り")
+	fmt.Fprintln(w, "----\t----\t----\t----\t----\t----\t----\t----")
+	unknownCostSells := 0
+	for _, y := range years {
+		if year != 0 && y.Year != year {
+			continue
+		}
+		fmt.Fprintf(w, "%d\t%d\t%.0f\t%.0f\t%.0f\t%.0f\t%.0f\t%d\n",
+			y.Year, y.Sells, y.Proceeds, y.CostBasis, y.RealizedPnL, y.Dividends, y.Tax, y.UnknownCostSells)
+		unknownCostSells += y.UnknownCostSells
+	}
+	w.Flush()
+
+	if unknownCostSells > 0 {
+		fmt.Printf("\n※ 保有株数を超える売り（取り込み期間より前に買った株式など）は取得費が分からないため、売却件数・売却代金・取得費・実現損益に含めていません（%d件）\n", unknownCostSells)
+	}
+
+	if len(years) == 0 {
+		fmt.Println("データが見つかりませんでした")
+		return nil
+	}
+
+	if year == 0 {
+		return nil
+	}
+
+	fmt.Printf("\n--- %d年の売却明細 ---\n\n", year)
+	w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "約定日\t受渡日\tコード\t企業名\t株数\t単価\t手数料\t取得費\t実現損益")
+	fmt.Fprintln(w, "----\t----\t----\t----\t----\t----\t----\t----\t----")
+	for _, e := range journal {
+		if e.TransactionType != database.TransactionSell || e.SettlementDate.Year() != year {
+			continue
+		}
+		costBasis, pnl := formatAmountPtr(e.CostBasis), formatAmountPtr(e.RealizedPnL)
+		if e.UnknownCost {
+			costBasis, pnl = "取得費不明", "取得費不明"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%s\t%.0f\t%s\t%s\n",
+			e.TradeDate.Format("2006-01-02"), e.SettlementDate.Format("2006-01-02"), e.Code, e.CompanyName,
+			e.Quantity, formatFloat64Ptr(e.Price), e.Fee, costBasis, pnl)
+	}
+	w.Flush()
+
+	return nil
+}
@@ -0,0 +1,75 @@
+package portfolio
+
+import (
+	"fmt"
+	"stock-automation/database"
+
+	"github.com/spf13/cobra"
+)
+
+var PortfolioCmd = &cobra.Command{
+	Use:   "portfolio",
+	Short: "保有銘柄管理",
+	Long:  "実際の保有銘柄をポートフォリオごとに登録し、最新の終値で評価する機能を提供します",
+}
+
+func init() {
+	// 全サブコマンド共通のフラグを追加
+	PortfolioCmd.PersistentFlags().StringP("portfolio", "p", database.DefaultPortfolioName, "ポートフォリオ名")
+
+	PortfolioCmd.AddCommand(addCmd)
+	PortfolioCmd.AddCommand(removeCmd)
+	PortfolioCmd.AddCommand(listCmd)
+	PortfolioCmd.AddCommand(reportCmd)
+	PortfolioCmd.AddCommand(tradeCmd)
+	PortfolioCmd.AddCommand(journalCmd)
+	PortfolioCmd.AddCommand(pnlCmd)
+	PortfolioCmd.AddCommand(splitsCmd)
+	PortfolioCmd.AddCommand(importCmd)
+	PortfolioCmd.AddCommand(riskCmd)
+}
+
+// transactionTypeNames 取引の種別の表示名
+var transactionTypeNames = map[string]string{
+	database.TransactionBuy:      "買い",
+	database.TransactionSell:     "売り",
+	database.TransactionDividend: "配当",
+	database.TransactionSplit:    "分割・併合",
+}
+
+// getPortfolio フラグで指定したポートフォリオを取得（create=trueの場合は存在しなければ作成）
+func getPortfolio(cmd *cobra.Command, repository *database.PortfolioRepository, create bool) (*database.Portfolio, error) {
+	name, _ := cmd.Flags().GetString("portfolio")
+	if name == "" {
+		return nil, fmt.Errorf("ポートフォリオ名を指定してください")
+	}
+
+	if create {
+		return repository.GetOrCreatePortfolio(name)
+	}
+
+	portfolio, err := repository.GetPortfolio(name)
+	if err != nil {
+		return nil, err
+	}
+	if portfolio == nil {
+		return nil, fmt.Errorf("ポートフォリオが見つかりません: %s", name)
+	}
+	return portfolio, nil
+}
+
+// formatFloat64Ptr float64ポインタを文字列に変換
+func formatFloat64Ptr(v *float64) string {
+	if v == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%.2f", *v)
+}
+
+// formatAmountPtr 金額（円）のポインタを整数の文字列に変換
+func formatAmountPtr(v *float64) string {
+	if v == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%.0f", *v)
+}
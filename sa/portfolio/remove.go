@@ -0,0 +1,54 @@
+package portfolio
+
+import (
+	"fmt"
+	"stock-automation/database"
+	"stock-automation/helper"
+
+	"github.com/spf13/cobra"
+)
+
+var removeCmd = &cobra.Command{
+	Use:   "remove",
+	Short: "保有銘柄を削除",
+	Long:  "保有銘柄の株数を減らします。株数を指定しない場合や保有株数以上を指定した場合は銘柄を削除します（平均取得単価は変わりません）",
+	RunE:  removeHolding,
+}
+
+func init() {
+	// フラグを追加
+	removeCmd.Flags().String("code", "", "銘柄コード（必須）")
+	removeCmd.Flags().Int64P("quantity", "q", 0, "減らす株数（指定しない場合は全株）")
+	removeCmd.MarkFlagRequired("code")
+}
+
+func removeHolding(cmd *cobra.Command, args []string) error {
+	code, _ := cmd.Flags().GetString("code")
+	quantity, _ := cmd.Flags().GetInt64("quantity")
+	code = helper.NormalizeCode(code)
+
+	// データベース接続
+	conn, err := database.NewConnectionFromEnv(false)
+	if err != nil {
+		return fmt.Errorf("データベース接続エラー: %v", err)
+	}
+	defer conn.Close()
+
+	repository := database.NewPortfolioRepository(conn)
+	portfolio, err := getPortfolio(cmd, repository, false)
+	if err != nil {
+		return err
+	}
+
+	remaining, err := repository.RemoveHolding(portfolio.ID, code, quantity)
+	if err != nil {
+		return fmt.Errorf("保有銘柄削除エラー: %v", err)
+	}
+
+	if remaining == nil {
+		fmt.Printf("%s: %s を削除しました\n", portfolio.Name, code)
+	} else {
+		fmt.Printf("%s: %s を%d株減らしました（保有株数: %d）\n", portfolio.Name, code, quantity, remaining.Quantity)
+	}
+	return nil
+}
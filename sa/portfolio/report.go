@@ -0,0 +1,88 @@
+package portfolio
+
+import (
+	"fmt"
+	"os"
+	"stock-automation/database"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+var reportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "保有銘柄の評価レポートを表示",
+	Long:  "保有銘柄をdaily_quotesの最新の終値で評価し、含み損益・33業種別の構成比・予想年間配当金（statements_summaryの今期予想）を表示します",
+	RunE:  showReport,
+}
+
+func showReport(cmd *cobra.Command, args []string) error {
+	// データベース接続
+	conn, err := database.NewConnectionFromEnv(false)
+	if err != nil {
+		return fmt.Errorf("データベース接続エラー: %v", err)
+	}
+	defer conn.Close()
+
+	repository := database.NewPortfolioRepository(conn)
+	portfolio, err := getPortfolio(cmd, repository, false)
+	if err != nil {
+		return err
+	}
+
+	valuation, err := repository.GetValuation(portfolio)
+	if err != nil {
+		return fmt.Errorf("データ取得エラー: %v", err)
+	}
+
+	if len(valuation.Holdings) == 0 {
+		fmt.Printf("%s: 保有銘柄がありません\n", portfolio.Name)
+		return nil
+	}
+
+	fmt.Printf("\n=== 評価レポート（%s） ===\n\n", portfolio.Name)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "コード\t企業名\t33業種\t株数\t平均取得単価\t終値\t終値日\t評価額\t含み損益\t損益率(%)\t構成比(%)\t予想配当\t予想年間配当金")
+	fmt.Fprintln(w, "----\t----\t----\t----\t----\t----\t----\t----\t----\t----\t----\t----\t----")
+	for _, v := range valuation.Holdings {
+		priceDate := "-"
+		if v.PriceDate != nil {
+			priceDate = v.PriceDate.Format("2006-01-02")
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%.2f\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			v.Code, v.CompanyName, v.Sector33Name, v.Quantity, v.AverageCost,
+			formatFloat64Ptr(v.Close), priceDate, formatAmountPtr(v.MarketValue), formatAmountPtr(v.UnrealizedPnL),
+			formatFloat64Ptr(v.UnrealizedPnLPct), formatFloat64Ptr(v.Weight),
+			formatFloat64Ptr(v.ForecastDPS), formatAmountPtr(v.ForecastAnnualIncome))
+	}
+	w.Flush()
+
+	fmt.Printf("\n--- 33業種別構成比 ---\n\n")
+	w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "33業種\t銘柄数\t評価額\t構成比(%)")
+	fmt.Fprintln(w, "----\t----\t----\t----")
+	for _, s := range valuation.Sectors {
+		name := s.Sector33Name
+		if name == "" {
+			name = "不明"
+		}
+		fmt.Fprintf(w, "%s\t%d\t%.0f\t%.2f\n", name, s.Holdings, s.MarketValue, s.Weight)
+	}
+	w.Flush()
+
+	fmt.Printf("\n--- 合計 ---\n\n")
+	w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "取得金額\t%.0f\n", valuation.CostValue)
+	fmt.Fprintf(w, "評価額\t%.0f\n", valuation.MarketValue)
+	fmt.Fprintf(w, "含み損益\t%.0f（%s%%）\n", valuation.UnrealizedPnL, formatFloat64Ptr(valuation.UnrealizedPnLPct))
+	fmt.Fprintf(w, "予想年間配当金\t%.0f\n", valuation.ForecastAnnualIncome)
+	fmt.Fprintf(w, "取得金額に対する予想配当利回り(%%)\t%s\n", formatFloat64Ptr(valuation.ForecastYieldOnCost))
+	w.Flush()
+
+	if valuation.UnpricedHoldings > 0 {
+		fmt.Printf("\n※ 株価がない%d銘柄は評価額・含み損益の合計から除いています\n", valuation.UnpricedHoldings)
+	}
+
+	return nil
+}
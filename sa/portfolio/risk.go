@@ -0,0 +1,181 @@
+package portfolio
+
+import (
+	"fmt"
+	"os"
+	"stock-automation/database"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var riskCmd = &cobra.Command{
+	Use:   "risk",
+	Short: "保有銘柄のリスクレポートを表示",
+	Long: `保有銘柄をdaily_quotesの調整後終値で評価し、期間ごとに銘柄別・ポートフォリオ全体のリスク指標を表示します。
+ポートフォリオ全体は最新の評価額の構成比で保有し続けた場合の日次リターンから算出します。
+
+指標:
+  ボラティリティ  日次リターンの標準偏差の年率換算
+  ベータ・相関    TOPIXの日次リターンに対する値（jquants topixでTOPIXを取得している場合のみ）
+  VaR・CVaR       日次リターンによるヒストリカル法（1日の損失率）
+  最大ドローダウン  期間内の高値からの最大下落率
+  相関行列        銘柄間の日次リターンの相関係数`,
+	RunE: showRisk,
+}
+
+func init() {
+	// フラグを追加
+	riskCmd.Flags().String("windows", "3M,1Y", "期間（カンマ区切り、例: 3M,1Y,3Y）")
+	riskCmd.Flags().Float64("confidence", 95, "VaR・CVaRの信頼水準(%)")
+}
+
+func showRisk(cmd *cobra.Command, args []string) error {
+	windowsValue, _ := cmd.Flags().GetString("windows")
+	confidence, _ := cmd.Flags().GetFloat64("confidence")
+
+	windows, err := database.ParseAssessmentWindows(windowsValue)
+	if err != nil {
+		return err
+	}
+	if confidence <= 50 || confidence >= 100 {
+		return fmt.Errorf("信頼水準は50より大きく100未満で指定してください: %g", confidence)
+	}
+
+	// データベース接続
+	conn, err := database.NewConnectionFromEnv(false)
+	if err != nil {
+		return fmt.Errorf("データベース接続エラー: %v", err)
+	}
+	defer conn.Close()
+
+	repository := database.NewPortfolioRepository(conn)
+	portfolio, err := getPortfolio(cmd, repository, false)
+	if err != nil {
+		return err
+	}
+
+	valuation, err := repository.GetValuation(portfolio)
+	if err != nil {
+		return fmt.Errorf("データ取得エラー: %v", err)
+	}
+
+	// 株価のある保有銘柄の評価額を構成比とし、最新の終値日を基準日とする
+	var holdings []*database.HoldingValuation
+	var codes []string
+	weights := make(map[string]float64)
+	var end time.Time
+	for _, v := range valuation.Holdings {
+		if v.MarketValue == nil || v.PriceDate == nil {
+			continue
+		}
+		holdings = append(holdings, v)
+		codes = append(codes, v.Code)
+		weights[v.Code] = *v.MarketValue
+		if v.PriceDate.After(end) {
+			end = *v.PriceDate
+		}
+	}
+	if len(holdings) == 0 {
+		fmt.Printf("%s: 株価のある保有銘柄がありません\n", portfolio.Name)
+		return nil
+	}
+
+	// 最も長い期間の開始日の前日の終値から読み込む（休場日を考慮して余裕を持たせる）
+	from := end
+	for _, window := range windows {
+		if start := window.StartDate(end); start.Before(from) {
+			from = start
+		}
+	}
+	loadFrom := from.AddDate(0, 0, -14)
+
+	closes, err := database.NewDailyQuotesRepository(conn).GetAdjustedCloses(codes, loadFrom, end)
+	if err != nil {
+		return fmt.Errorf("データ取得エラー: %v", err)
+	}
+	topix, err := database.NewTopixRepository(conn).GetCloses(loadFrom, end)
+	if err != nil {
+		return fmt.Errorf("データ取得エラー: %v", err)
+	}
+
+	fmt.Printf("\n=== リスクレポート（%s、基準日: %s） ===\n", portfolio.Name, end.Format("2006-01-02"))
+
+	level := fmt.Sprintf("%g%%", confidence)
+	for _, window := range windows {
+		start := window.StartDate(end)
+
+		returns := make(map[string]dailyReturns, len(codes))
+		for _, code := range codes {
+			returns[code] = toDailyReturns(closes[code], start)
+		}
+		var market dailyReturns
+		if len(topix) > 0 {
+			market = toDailyReturns(topix, start)
+		}
+
+		fmt.Printf("\n--- %s（%s〜%s） ---\n\n", window.Label, start.Format("2006-01-02"), end.Format("2006-01-02"))
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(w, "コード\t企業名\t構成比(%%)\t日数\tリターン(%%)\tボラティリティ(%%)\tベータ\tTOPIX相関\tVaR %s(%%)\tCVaR %s(%%)\t最大DD(%%)\n", level, level)
+		fmt.Fprintln(w, "----\t----\t----\t----\t----\t----\t----\t----\t----\t----\t----")
+		for _, v := range holdings {
+			printRiskRow(w, v.Code, v.CompanyName, formatFloat64Ptr(v.Weight), calculateRisk(returns[v.Code], market, confidence/100))
+		}
+		total := calculateRisk(portfolioReturns(returns, weights), market, confidence/100)
+		printRiskRow(w, "-", "ポートフォリオ", "100.00", total)
+		if market != nil {
+			printRiskRow(w, "-", "TOPIX", "-", calculateRisk(market, market, confidence/100))
+		}
+		w.Flush()
+
+		if total.VaR != nil {
+			fmt.Printf("\n評価額%.0f円に対する1日の損失額: VaR %.0f円 / CVaR %.0f円（信頼水準%s）\n",
+				valuation.MarketValue, valuation.MarketValue**total.VaR/100, valuation.MarketValue**total.CVaR/100, level)
+		}
+
+		if len(codes) > 1 {
+			printCorrelationMatrix(codes, returns)
+		}
+	}
+
+	fmt.Println()
+	if len(topix) == 0 {
+		fmt.Println("※ TOPIXのデータがないため、ベータ・TOPIX相関は算出していません（jquants topixで取得できます）")
+	}
+	fmt.Printf("※ 日次リターンが%d日未満の場合は指標を算出していません\n", minRiskObservations)
+	if valuation.UnpricedHoldings > 0 {
+		fmt.Printf("※ 株価がない%d銘柄は除いています\n", valuation.UnpricedHoldings)
+	}
+
+	return nil
+}
+
+// printRiskRow リスク指標の1行を表示
+func printRiskRow(w *tabwriter.Writer, code, name, weight string, m riskMetrics) {
+	fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+		code, name, weight, m.Observations, formatFloat64Ptr(m.Return), formatFloat64Ptr(m.Volatility),
+		formatFloat64Ptr(m.Beta), formatFloat64Ptr(m.Correlation),
+		formatFloat64Ptr(m.VaR), formatFloat64Ptr(m.CVaR), formatFloat64Ptr(m.MaxDrawdown))
+}
+
+// printCorrelationMatrix 銘柄間の日次リターンの相関行列を表示
+func printCorrelationMatrix(codes []string, returns map[string]dailyReturns) {
+	fmt.Printf("\n相関行列\n\n")
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(w, "\t%s\t\n", strings.Join(codes, "\t"))
+	for _, a := range codes {
+		cells := make([]string, len(codes))
+		for j, b := range codes {
+			if a == b {
+				cells[j] = "1.00"
+				continue
+			}
+			cells[j] = formatFloat64Ptr(correlation(returns[a], returns[b]))
+		}
+		fmt.Fprintf(w, "%s\t%s\t\n", a, strings.Join(cells, "\t"))
+	}
+	w.Flush()
+}
@@ -0,0 +1,205 @@
+package portfolio
+
+import (
+	"math"
+	"sort"
+	"stock-automation/database"
+	"stock-automation/helper"
+	"time"
+)
+
+// minRiskObservations リスク指標を算出するのに必要な日次リターンの最小件数
+const minRiskObservations = 20
+
+// dailyReturns 日付（YYYY-MM-DD）ごとの日次リターン
+type dailyReturns map[string]float64
+
+// riskMetrics 日次リターンから算出したリスク指標（件数が足りない場合はnil）
+type riskMetrics struct {
+	Observations int
+	Return       *float64 // 期間リターン(%)
+	Volatility   *float64 // 年率ボラティリティ(%)
+	Beta         *float64 // TOPIXに対するベータ
+	Correlation  *float64 // TOPIXとの相関係数
+	VaR          *float64 // 日次のヒストリカルVaR(%、損失を正の値で表す)
+	CVaR         *float64 // 日次のヒストリカルCVaR(%、VaRを超える損失の平均)
+	MaxDrawdown  *float64 // 最大ドローダウン(%)
+}
+
+// toDailyReturns 終値から日次リターンを算出（from以降の取引日のみ。前日の終値はfromより前でもよい）
+func toDailyReturns(prices []*database.ClosePrice, from time.Time) dailyReturns {
+	returns := make(dailyReturns)
+	for i := 1; i < len(prices); i++ {
+		if prices[i].Date.Before(from) || prices[i-1].Close <= 0 {
+			continue
+		}
+		returns[prices[i].Date.Format("2006-01-02")] = prices[i].Close/prices[i-1].Close - 1
+	}
+	return returns
+}
+
+// sortedDates 日付の古い順
+func (r dailyReturns) sortedDates() []string {
+	dates := make([]string, 0, len(r))
+	for date := range r {
+		dates = append(dates, date)
+	}
+	sort.Strings(dates)
+	return dates
+}
+
+// values 日付の古い順のリターン
+func (r dailyReturns) values() []float64 {
+	dates := r.sortedDates()
+	values := make([]float64, len(dates))
+	for i, date := range dates {
+		values[i] = r[date]
+	}
+	return values
+}
+
+// portfolioReturns 評価額の構成比で加重した日次リターン（その日にリターンのある銘柄の構成比で按分）
+func portfolioReturns(returns map[string]dailyReturns, weights map[string]float64) dailyReturns {
+	sums := make(map[string]float64)
+	totals := make(map[string]float64)
+	for code, series := range returns {
+		weight := weights[code]
+		for date, r := range series {
+			sums[date] += weight * r
+			totals[date] += weight
+		}
+	}
+
+	result := make(dailyReturns)
+	for date, total := range totals {
+		if total > 0 {
+			result[date] = sums[date] / total
+		}
+	}
+	return result
+}
+
+// calculateRisk 日次リターンからリスク指標を算出
+// market: TOPIXの日次リターン（ない場合はnil）、confidence: VaRの信頼水準（例: 0.95）
+func calculateRisk(returns, market dailyReturns, confidence float64) riskMetrics {
+	values := returns.values()
+	metrics := riskMetrics{Observations: len(values)}
+	if len(values) < minRiskObservations {
+		return metrics
+	}
+
+	growth := 1.0
+	for _, r := range values {
+		growth *= 1 + r
+	}
+	metrics.Return = float64Ptr(helper.Round2((growth - 1) * 100))
+
+	_, sd := helper.MeanStdDev(values)
+	metrics.Volatility = float64Ptr(helper.Round2(sd * math.Sqrt(helper.TradingDaysPerYear) * 100))
+
+	if market != nil {
+		metrics.Beta, metrics.Correlation = betaAndCorrelation(returns, market)
+	}
+
+	metrics.VaR, metrics.CVaR = historicalVaR(values, confidence)
+	metrics.MaxDrawdown = float64Ptr(helper.Round2(maxDrawdown(values)))
+
+	return metrics
+}
+
+// betaAndCorrelation 両方にリターンのある日のみでベータと相関係数を算出
+func betaAndCorrelation(returns, market dailyReturns) (*float64, *float64) {
+	var xs, ys []float64
+	for _, date := range returns.sortedDates() {
+		if m, ok := market[date]; ok {
+			xs = append(xs, m)
+			ys = append(ys, returns[date])
+		}
+	}
+	if len(xs) < minRiskObservations {
+		return nil, nil
+	}
+
+	cov, varX, varY := covariance(xs, ys)
+	if varX == 0 {
+		return nil, nil
+	}
+	beta := helper.Round2(cov / varX)
+	if varY == 0 {
+		return &beta, nil
+	}
+	correlation := helper.Round2(cov / math.Sqrt(varX*varY))
+	return &beta, &correlation
+}
+
+// correlation 両方にリターンのある日のみで相関係数を算出
+func correlation(a, b dailyReturns) *float64 {
+	var xs, ys []float64
+	for _, date := range a.sortedDates() {
+		if v, ok := b[date]; ok {
+			xs = append(xs, a[date])
+			ys = append(ys, v)
+		}
+	}
+	if len(xs) < minRiskObservations {
+		return nil
+	}
+
+	cov, varX, varY := covariance(xs, ys)
+	if varX == 0 || varY == 0 {
+		return nil
+	}
+	return float64Ptr(helper.Round2(cov / math.Sqrt(varX*varY)))
+}
+
+// covariance 標本共分散とそれぞれの標本分散
+func covariance(xs, ys []float64) (float64, float64, float64) {
+	meanX, _ := helper.MeanStdDev(xs)
+	meanY, _ := helper.MeanStdDev(ys)
+
+	var cov, varX, varY float64
+	for i := range xs {
+		dx, dy := xs[i]-meanX, ys[i]-meanY
+		cov += dx * dy
+		varX += dx * dx
+		varY += dy * dy
+	}
+	n := float64(len(xs) - 1)
+	return cov / n, varX / n, varY / n
+}
+
+// historicalVaR 日次リターンの下位(1-confidence)分位点をVaR、それ以下のリターンの平均をCVaRとする（損失を正の値の%で表す）
+func historicalVaR(values []float64, confidence float64) (*float64, *float64) {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	k := int(math.Ceil((1-confidence)*float64(len(sorted)))) - 1
+	if k < 0 {
+		k = 0
+	}
+
+	tail := 0.0
+	for _, r := range sorted[:k+1] {
+		tail += r
+	}
+	return float64Ptr(helper.Round2(-sorted[k] * 100)), float64Ptr(helper.Round2(-tail / float64(k+1) * 100))
+}
+
+// maxDrawdown 日付の古い順のリターンを累積した資産推移の最大ドローダウン(%)
+func maxDrawdown(values []float64) float64 {
+	equity, peak, drawdown := 1.0, 1.0, 0.0
+	for _, r := range values {
+		equity *= 1 + r
+		if equity > peak {
+			peak = equity
+		}
+		if d := (equity/peak - 1) * 100; d < drawdown {
+			drawdown = d
+		}
+	}
+	return drawdown
+}
+
+func float64Ptr(v float64) *float64 {
+	return &v
+}
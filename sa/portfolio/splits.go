@@ -0,0 +1,74 @@
+package portfolio
+
+import (
+	"fmt"
+	"os"
+	"stock-automation/database"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+var splitsCmd = &cobra.Command{
+	Use:   "splits",
+	Short: "未記録の株式分割・併合を表示",
+	Long:  "保有中に権利落ちしたが取引履歴に記録されていない株式分割・併合を、daily_quotesの調整係数から検出して表示します。--applyを指定すると取引履歴に記録し、保有銘柄に反映します",
+	RunE:  showSplits,
+}
+
+func init() {
+	// フラグを追加
+	splitsCmd.Flags().Bool("apply", false, "検出した株式分割・併合を取引履歴に記録して保有銘柄に反映")
+}
+
+func showSplits(cmd *cobra.Command, args []string) error {
+	apply, _ := cmd.Flags().GetBool("apply")
+
+	// データベース接続
+	conn, err := database.NewConnectionFromEnv(false)
+	if err != nil {
+		return fmt.Errorf("データベース接続エラー: %v", err)
+	}
+	defer conn.Close()
+
+	repository := database.NewPortfolioRepository(conn)
+	portfolio, err := getPortfolio(cmd, repository, false)
+	if err != nil {
+		return err
+	}
+
+	pending, err := repository.PendingSplits(portfolio.ID)
+	if err != nil {
+		return fmt.Errorf("データ取得エラー: %v", err)
+	}
+
+	if len(pending) == 0 {
+		fmt.Printf("%s: 未記録の株式分割・併合はありません\n", portfolio.Name)
+		return nil
+	}
+
+	fmt.Printf("\n=== 未記録の株式分割・併合（%s） ===\n\n", portfolio.Name)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "権利落ち日\tコード\t企業名\t分割比率\t反映後の保有株数\t反映後の平均取得単価")
+	fmt.Fprintln(w, "----\t----\t----\t----\t----\t----")
+	for _, e := range pending {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%g\t%d\t%.2f\n",
+			e.TradeDate.Format("2006-01-02"), e.Code, e.CompanyName, *e.SplitRatio, e.Position, e.AverageCost)
+	}
+	w.Flush()
+
+	if !apply {
+		fmt.Printf("\n取引履歴に記録する場合は--applyを指定してください\n")
+		return nil
+	}
+
+	for _, e := range pending {
+		if err := repository.AddTransaction(e.PortfolioTransaction); err != nil {
+			return fmt.Errorf("取引記録エラー (銘柄: %s, 権利落ち日: %s): %v", e.Code, e.TradeDate.Format("2006-01-02"), err)
+		}
+	}
+	fmt.Printf("\n%d件の株式分割・併合を記録しました\n", len(pending))
+
+	return nil
+}
@@ -0,0 +1,97 @@
+package portfolio
+
+import (
+	"fmt"
+	"stock-automation/database"
+	"stock-automation/helper"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var tradeCmd = &cobra.Command{
+	Use:   "trade",
+	Short: "取引を記録",
+	Long: `買い・売り・配当・株式分割・併合を取引履歴に記録します。
+売買と株式分割・併合は保有銘柄にも反映します（買いは手数料込みの単価で平均取得単価を移動平均で再計算）`,
+	RunE: recordTrade,
+}
+
+func init() {
+	// フラグを追加
+	tradeCmd.Flags().String("type", "", "取引の種別（buy, sell, dividend, split、必須）")
+	tradeCmd.Flags().String("code", "", "銘柄コード（必須）")
+	tradeCmd.Flags().StringP("date", "d", "", "約定日（配当は支払日、株式分割・併合は権利落ち日。YYYY-MM-DD形式、指定しない場合は当日）")
+	tradeCmd.Flags().Int64P("quantity", "q", 0, "[buy/sell] 株数、[dividend] 対象株数")
+	tradeCmd.Flags().Float64("price", 0, "[buy/sell] 約定単価（円）")
+	tradeCmd.Flags().Float64("fee", 0, "[buy/sell] 手数料（消費税込み）")
+	tradeCmd.Flags().Float64("amount", 0, "[dividend] 配当金額（税引前）")
+	tradeCmd.Flags().Float64("tax", 0, "[sell/dividend] 源泉徴収税額")
+	tradeCmd.Flags().Float64("ratio", 0, "[split] 1株あたりの分割・併合後の株数（例: 1:2の分割は2、2:1の併合は0.5）")
+	tradeCmd.Flags().String("note", "", "メモ")
+	tradeCmd.MarkFlagRequired("type")
+	tradeCmd.MarkFlagRequired("code")
+}
+
+func recordTrade(cmd *cobra.Command, args []string) error {
+	transactionType, _ := cmd.Flags().GetString("type")
+	code, _ := cmd.Flags().GetString("code")
+	dateFlag, _ := cmd.Flags().GetString("date")
+	quantity, _ := cmd.Flags().GetInt64("quantity")
+	fee, _ := cmd.Flags().GetFloat64("fee")
+	tax, _ := cmd.Flags().GetFloat64("tax")
+	note, _ := cmd.Flags().GetString("note")
+
+	if dateFlag == "" {
+		dateFlag = helper.GetTodayDate()
+	}
+	tradeDate, err := time.ParseInLocation("2006-01-02", dateFlag, time.Local)
+	if err != nil {
+		return fmt.Errorf("日付の形式が正しくありません（YYYY-MM-DD形式で指定してください）: %v", err)
+	}
+
+	transaction := &database.PortfolioTransaction{
+		Code:            helper.NormalizeCode(code),
+		TransactionType: transactionType,
+		TradeDate:       tradeDate,
+		Quantity:        quantity,
+		Fee:             fee,
+		Tax:             tax,
+		Note:            note,
+	}
+	if cmd.Flags().Changed("price") {
+		v, _ := cmd.Flags().GetFloat64("price")
+		transaction.Price = &v
+	}
+	if cmd.Flags().Changed("amount") {
+		v, _ := cmd.Flags().GetFloat64("amount")
+		transaction.Amount = &v
+	}
+	if cmd.Flags().Changed("ratio") {
+		v, _ := cmd.Flags().GetFloat64("ratio")
+		transaction.SplitRatio = &v
+	}
+
+	// データベース接続
+	conn, err := database.NewConnectionFromEnv(false)
+	if err != nil {
+		return fmt.Errorf("データベース接続エラー: %v", err)
+	}
+	defer conn.Close()
+
+	repository := database.NewPortfolioRepository(conn)
+	portfolio, err := getPortfolio(cmd, repository, true)
+	if err != nil {
+		return err
+	}
+	transaction.PortfolioID = portfolio.ID
+
+	if err := repository.AddTransaction(transaction); err != nil {
+		return fmt.Errorf("取引記録エラー: %v", err)
+	}
+
+	fmt.Printf("%s: %s %s の%sを記録しました（ID: %d、約定日: %s、受渡日: %s）\n",
+		portfolio.Name, transaction.Code, transaction.CompanyName, transactionTypeNames[transaction.TransactionType],
+		transaction.ID, transaction.TradeDate.Format("2006-01-02"), transaction.SettlementDate.Format("2006-01-02"))
+	return nil
+}
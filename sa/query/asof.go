@@ -0,0 +1,150 @@
+package query
+
+import (
+	"fmt"
+	"os"
+	"stock-automation/database"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var asofCmd = &cobra.Command{
+	Use:   "asof",
+	Short: "指定時点の財務情報を表示",
+	Long:  "指定した日時の時点で開示済みだった最新の実績・予想を表示します（バックテスト用の時点データ）",
+	RunE:  showAsOf,
+}
+
+func init() {
+	// フラグを追加
+	asofCmd.Flags().String("date", "", "基準日（YYYY-MM-DD形式）")
+	asofCmd.Flags().String("time", "23:59:59", "基準時刻（HH:MM:SS形式、デフォルトは基準日の終わり）")
+	asofCmd.Flags().String("code", "", "銘柄コード（4桁または5桁。--watchlist・--tagを指定しない場合は必須）")
+	addCodeFilterFlags(asofCmd)
+	asofCmd.MarkFlagRequired("date")
+}
+
+func showAsOf(cmd *cobra.Command, args []string) error {
+	date, _ := cmd.Flags().GetString("date")
+	clock, _ := cmd.Flags().GetString("time")
+	code, _ := cmd.Flags().GetString("code")
+
+	asOf, err := time.ParseInLocation("2006-01-02 15:04:05", date+" "+clock, time.Local)
+	if err != nil {
+		return fmt.Errorf("基準日時の形式が不正です: %v", err)
+	}
+
+	// データベース接続
+	conn, err := database.NewConnectionFromEnv(false) // queryでは非verbose
+	if err != nil {
+		return fmt.Errorf("データベース接続エラー: %v", err)
+	}
+	defer conn.Close()
+
+	codes, err := getTargetCodes(conn, code, getCodeFilter(cmd))
+	if err != nil {
+		return err
+	}
+	if len(codes) == 0 {
+		fmt.Println("条件に一致する銘柄がありません")
+		return nil
+	}
+
+	repository := database.NewStatementsRepository(conn)
+	for _, code := range codes {
+		if err := printAsOf(repository, code, asOf); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// printAsOf 銘柄の指定時点の財務情報を表示
+func printAsOf(repository *database.StatementsRepository, code string, asOf time.Time) error {
+	fundamentals, err := repository.GetFundamentalsAsOf(code, asOf)
+	if err != nil {
+		return fmt.Errorf("データ取得エラー: %v", err)
+	}
+
+	fmt.Printf("\n=== 時点財務情報 (銘柄: %s, 基準: %s) ===\n\n", code, asOf.Format("2006-01-02 15:04:05"))
+
+	if fundamentals.LatestActual == nil && fundamentals.Forecast == nil {
+		fmt.Println("データが見つかりませんでした")
+		return nil
+	}
+
+	columns := []*database.PointInTimeStatement{
+		fundamentals.LatestActual,
+		fundamentals.AnnualActual,
+		fundamentals.Forecast,
+	}
+
+	rows := []struct {
+		label string
+		value func(s *database.PointInTimeStatement) string
+	}{
+		{"開示日", func(s *database.PointInTimeStatement) string {
+			return s.DisclosedDate.Format("2006-01-02") + " " + s.DisclosedTime
+		}},
+		{"期間", func(s *database.PointInTimeStatement) string { return s.TypeOfCurrentPeriod }},
+		{"会計年度末", func(s *database.PointInTimeStatement) string { return formatDatePtr(s.FiscalYearEndDate) }},
+		{"売上高", func(s *database.PointInTimeStatement) string { return formatInt64Ptr(s.NetSales) }},
+		{"営業利益", func(s *database.PointInTimeStatement) string { return formatInt64Ptr(s.OperatingProfit) }},
+		{"経常利益", func(s *database.PointInTimeStatement) string { return formatInt64Ptr(s.OrdinaryProfit) }},
+		{"純利益", func(s *database.PointInTimeStatement) string { return formatInt64Ptr(s.Profit) }},
+		{"EPS", func(s *database.PointInTimeStatement) string { return formatFloat64Ptr(s.EPS) }},
+		{"年間配当", func(s *database.PointInTimeStatement) string { return formatFloat64Ptr(s.DividendPerShare) }},
+	}
+
+	// ヘッダーを表示
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "項目\t最新実績\t通期実績\t通期予想")
+	fmt.Fprintln(w, "----\t----\t----\t----")
+
+	for _, row := range rows {
+		fmt.Fprint(w, row.label)
+		for _, column := range columns {
+			if column == nil {
+				fmt.Fprint(w, "\t-")
+				continue
+			}
+			fmt.Fprint(w, "\t"+row.value(column))
+		}
+		fmt.Fprintln(w)
+	}
+
+	w.Flush()
+
+	if fundamentals.Forecast != nil && fundamentals.Forecast.DataType == "next_year_forecast" {
+		fmt.Println("\n※ 通期予想は通期決算で開示された翌期予想です")
+	}
+
+	return nil
+}
+
+// formatInt64Ptr 整数値を表示用に整形（NULLは"-"）
+func formatInt64Ptr(v *int64) string {
+	if v == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%d", *v)
+}
+
+// formatFloat64Ptr 小数値を表示用に整形（NULLは"-"）
+func formatFloat64Ptr(v *float64) string {
+	if v == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%.2f", *v)
+}
+
+// formatDatePtr 日付を表示用に整形（NULLは"-"）
+func formatDatePtr(v *time.Time) string {
+	if v == nil {
+		return "-"
+	}
+	return v.Format("2006-01-02")
+}
@@ -0,0 +1,86 @@
+package query
+
+import (
+	"fmt"
+	"os"
+	"stock-automation/database"
+	"stock-automation/helper"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+var corporateActionsCmd = &cobra.Command{
+	Use:   "corporate_actions",
+	Short: "株式分割・併合を表示",
+	Long:  "daily_quotesの調整係数から検出した株式分割・併合を権利落ち日の新しい順に表示します",
+	RunE:  showCorporateActions,
+}
+
+func init() {
+	// フラグを追加
+	corporateActionsCmd.Flags().String("code", "", "銘柄コード（指定しない場合は全銘柄）")
+	corporateActionsCmd.Flags().String("from", "", "権利落ち日の開始日（YYYY-MM-DD形式）")
+	corporateActionsCmd.Flags().IntP("limit", "l", 50, "表示する行数の上限")
+	corporateActionsCmd.Flags().BoolP("all", "a", false, "全ての行を表示")
+	addCodeFilterFlags(corporateActionsCmd)
+}
+
+func showCorporateActions(cmd *cobra.Command, args []string) error {
+	code, _ := cmd.Flags().GetString("code")
+	from, _ := cmd.Flags().GetString("from")
+	limit, _ := cmd.Flags().GetInt("limit")
+	showAll, _ := cmd.Flags().GetBool("all")
+
+	if code != "" {
+		code = helper.NormalizeCode(code)
+	}
+	if showAll {
+		limit = 0
+	}
+
+	// データベース接続
+	conn, err := database.NewConnectionFromEnv(false) // queryでは非verbose
+	if err != nil {
+		return fmt.Errorf("データベース接続エラー: %v", err)
+	}
+	defer conn.Close()
+
+	repository := database.NewCorporateActionRepository(conn)
+	actions, err := repository.GetCorporateActions(code, from, getCodeFilter(cmd), limit)
+	if err != nil {
+		return fmt.Errorf("データ取得エラー: %v", err)
+	}
+
+	fmt.Printf("\n=== 株式分割・併合 ===\n\n")
+
+	actionNames := map[string]string{
+		database.ActionSplit:        "分割",
+		database.ActionReverseSplit: "併合",
+	}
+
+	// ヘッダーを表示
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "権利落ち日\tコード\t企業名\t種別\t調整係数\t分割比率\t再計算日時")
+	fmt.Fprintln(w, "----\t----\t----\t----\t----\t----\t----")
+
+	for _, a := range actions {
+		readjustedAt := "-"
+		if a.ReadjustedAt != nil {
+			readjustedAt = a.ReadjustedAt.Format("2006-01-02 15:04:05")
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%g\t1:%g\t%s\n",
+			a.ExDate.Format("2006-01-02"), a.Code, a.CompanyName, actionNames[a.ActionType],
+			a.AdjustmentFactor, a.SplitRatio, readjustedAt)
+	}
+
+	w.Flush()
+
+	if len(actions) == 0 {
+		fmt.Println("データが見つかりませんでした")
+	} else {
+		fmt.Printf("\n表示行数: %d\n", len(actions))
+	}
+
+	return nil
+}
@@ -0,0 +1,96 @@
+package query
+
+import (
+	"fmt"
+	"os"
+	"stock-automation/database"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+var dividendsCmd = &cobra.Command{
+	Use:   "dividends",
+	Short: "配当履歴を表示",
+	Long:  "銘柄の年間配当の推移（株式分割調整後）と連続増配・非減配年数、5年CAGRを表示します",
+	RunE:  showDividends,
+}
+
+func init() {
+	// フラグを追加
+	dividendsCmd.Flags().String("code", "", "銘柄コード（--watchlist・--tagを指定しない場合は必須）")
+	dividendsCmd.Flags().IntP("limit", "l", 20, "表示する行数の上限")
+	dividendsCmd.Flags().BoolP("all", "a", false, "全ての行を表示")
+	addCodeFilterFlags(dividendsCmd)
+}
+
+func showDividends(cmd *cobra.Command, args []string) error {
+	code, _ := cmd.Flags().GetString("code")
+	limit, _ := cmd.Flags().GetInt("limit")
+	showAll, _ := cmd.Flags().GetBool("all")
+
+	if showAll {
+		limit = 0
+	}
+
+	// データベース接続
+	conn, err := database.NewConnectionFromEnv(false) // queryでは非verbose
+	if err != nil {
+		return fmt.Errorf("データベース接続エラー: %v", err)
+	}
+	defer conn.Close()
+
+	codes, err := getTargetCodes(conn, code, getCodeFilter(cmd))
+	if err != nil {
+		return err
+	}
+	if len(codes) == 0 {
+		fmt.Println("条件に一致する銘柄がありません")
+		return nil
+	}
+
+	repository := database.NewDividendHistoryRepository(conn)
+	for _, code := range codes {
+		if err := printDividends(repository, code, limit); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// printDividends 銘柄の配当履歴を表示
+func printDividends(repository *database.DividendHistoryRepository, code string, limit int) error {
+	records, err := repository.GetDividendHistory(code, limit)
+	if err != nil {
+		return fmt.Errorf("データ取得エラー: %v", err)
+	}
+
+	fmt.Printf("\n=== 配当履歴 - %s ===\n\n", code)
+
+	// ヘッダーを表示
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "年度末\t区分\t年間配当\t分割調整係数\t調整後配当\t前期比(%)\t連続増配\t連続非減配\t5年CAGR(%)")
+	fmt.Fprintln(w, "----\t----\t----\t----\t----\t----\t----\t----\t----")
+
+	for _, record := range records {
+		kind := "実績"
+		if record.IsForecast {
+			kind = "予想"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%.2f\t%g\t%.2f\t%s\t%d\t%d\t%s\n",
+			formatDatePtr(record.FiscalYearEndDate), kind, record.DividendPerShare,
+			record.AdjustmentRatio, record.AdjustedDividendPerShare, formatFloat64Ptr(record.ChangePct),
+			record.IncreaseStreak, record.NoCutStreak, formatFloat64Ptr(record.DPSCAGR5Y))
+	}
+
+	w.Flush()
+
+	if len(records) == 0 {
+		fmt.Println("データが見つかりませんでした")
+	} else {
+		fmt.Printf("\n表示行数: %d\n", len(records))
+	}
+
+	return nil
+}
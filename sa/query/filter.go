@@ -0,0 +1,51 @@
+package query
+
+import (
+	"fmt"
+	"stock-automation/database"
+	"stock-automation/helper"
+
+	"github.com/spf13/cobra"
+)
+
+// addCodeFilterFlags ウォッチリスト・タグで銘柄を絞り込むフラグを追加
+func addCodeFilterFlags(cmd *cobra.Command) {
+	cmd.Flags().String("watchlist", "", "ウォッチリストの銘柄に絞り込む")
+	cmd.Flags().String("tag", "", "タグを付けた銘柄に絞り込む")
+}
+
+// getCodeFilter フラグからウォッチリスト・タグの絞り込み条件を取得
+func getCodeFilter(cmd *cobra.Command) database.CodeFilter {
+	var filter database.CodeFilter
+	filter.Watchlist, _ = cmd.Flags().GetString("watchlist")
+	filter.Tag, _ = cmd.Flags().GetString("tag")
+	return filter
+}
+
+// getTargetCodes 銘柄ごとに表示するコマンドの対象銘柄コードを取得
+// --codeを指定した場合はその銘柄（ウォッチリスト・タグの条件に一致しない場合は対象なし）、指定しない場合は条件に一致する全銘柄
+func getTargetCodes(conn *database.Connection, code string, filter database.CodeFilter) ([]string, error) {
+	if code != "" {
+		code = helper.NormalizeCode(code)
+	}
+	if filter.IsEmpty() {
+		if code == "" {
+			return nil, fmt.Errorf("--code、--watchlist、--tagのいずれかを指定してください")
+		}
+		return []string{code}, nil
+	}
+
+	codes, err := database.NewWatchlistRepository(conn).GetCodes(filter)
+	if err != nil {
+		return nil, fmt.Errorf("データ取得エラー: %v", err)
+	}
+	if code == "" {
+		return codes, nil
+	}
+	for _, c := range codes {
+		if c == code {
+			return []string{code}, nil
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,135 @@
+package query
+
+import (
+	"fmt"
+	"os"
+	"stock-automation/database"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var indicatorsCmd = &cobra.Command{
+	Use:   "indicators",
+	Short: "テクニカル指標を表示",
+	Long:  "銘柄の移動平均・RSI・MACD・ボリンジャーバンドなどのテクニカル指標を取引日ごとに表示します",
+	RunE:  showIndicators,
+}
+
+func init() {
+	// フラグを追加
+	indicatorsCmd.Flags().String("code", "", "銘柄コード（--watchlist・--tagを指定しない場合は必須）")
+	indicatorsCmd.Flags().String("names", "", "表示する指標名（カンマ区切り、指定しない場合は全指標）")
+	indicatorsCmd.Flags().IntP("limit", "l", 20, "表示する取引日数の上限")
+	indicatorsCmd.Flags().BoolP("all", "a", false, "全ての取引日を表示")
+	addCodeFilterFlags(indicatorsCmd)
+}
+
+func showIndicators(cmd *cobra.Command, args []string) error {
+	code, _ := cmd.Flags().GetString("code")
+	namesFlag, _ := cmd.Flags().GetString("names")
+	limit, _ := cmd.Flags().GetInt("limit")
+	showAll, _ := cmd.Flags().GetBool("all")
+
+	if showAll {
+		limit = 0
+	}
+
+	config, err := database.NewIndicatorConfigFromEnv()
+	if err != nil {
+		return fmt.Errorf("テクニカル指標設定エラー: %v", err)
+	}
+
+	// データベース接続
+	conn, err := database.NewConnectionFromEnv(false) // queryでは非verbose
+	if err != nil {
+		return fmt.Errorf("データベース接続エラー: %v", err)
+	}
+	defer conn.Close()
+
+	codes, err := getTargetCodes(conn, code, getCodeFilter(cmd))
+	if err != nil {
+		return err
+	}
+	if len(codes) == 0 {
+		fmt.Println("条件に一致する銘柄がありません")
+		return nil
+	}
+
+	repository := database.NewIndicatorRepository(conn)
+	for _, code := range codes {
+		if err := printIndicators(repository, config, code, namesFlag, limit); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// printIndicators 銘柄のテクニカル指標を取引日ごとに表示
+func printIndicators(repository *database.IndicatorRepository, config database.IndicatorConfig, code, namesFlag string, limit int) error {
+	values, err := repository.GetIndicators(code, limit)
+	if err != nil {
+		return fmt.Errorf("データ取得エラー: %v", err)
+	}
+
+	// 表示する指標名を決定
+	selected := make(map[string]bool)
+	for _, name := range strings.Split(namesFlag, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			selected[name] = true
+		}
+	}
+
+	// 取引日ごとに指標の値をまとめる（valuesは取引日の新しい順）
+	var dates []time.Time
+	byDate := make(map[time.Time]map[string]float64)
+	seen := make(map[string]bool)
+	var names []string
+	for _, v := range values {
+		if len(selected) > 0 && !selected[v.Name] {
+			continue
+		}
+		if _, ok := byDate[v.TradeDate]; !ok {
+			byDate[v.TradeDate] = make(map[string]float64)
+			dates = append(dates, v.TradeDate)
+		}
+		byDate[v.TradeDate][v.Name] = v.Value
+		if !seen[v.Name] {
+			seen[v.Name] = true
+			names = append(names, v.Name)
+		}
+	}
+	names = database.SortIndicatorNames(names, config)
+
+	fmt.Printf("\n=== テクニカル指標 - %s ===\n\n", code)
+
+	// ヘッダーを表示
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "取引日\t"+strings.Join(names, "\t"))
+	fmt.Fprintln(w, "----"+strings.Repeat("\t----", len(names)))
+
+	for _, date := range dates {
+		row := []string{date.Format("2006-01-02")}
+		for _, name := range names {
+			if v, ok := byDate[date][name]; ok {
+				row = append(row, fmt.Sprintf("%.2f", v))
+			} else {
+				row = append(row, "-")
+			}
+		}
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+
+	w.Flush()
+
+	if len(dates) == 0 {
+		fmt.Println("データが見つかりませんでした")
+	} else {
+		fmt.Printf("\n表示行数: %d\n", len(dates))
+	}
+
+	return nil
+}
@@ -22,6 +22,10 @@ func listTables(cmd *cobra.Command, args []string) error {
 	}{
 		{"listed_info", "上場銘柄情報"},
 		{"market_codes", "市場区分コード"},
+		{"listing_events", "新規上場・上場廃止イベント"},
+		{"valuations", "株価指標"},
+		{"financial_ratios", "財務比率"},
+		{"quality_scores", "財務品質スコア"},
 	}
 
 	for i, table := range tables {
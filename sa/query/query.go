@@ -13,4 +13,13 @@ var QueryCmd = &cobra.Command{
 func init() {
 	QueryCmd.AddCommand(showCmd)
 	QueryCmd.AddCommand(listCmd)
+	QueryCmd.AddCommand(asofCmd)
+	QueryCmd.AddCommand(revisionsCmd)
+	QueryCmd.AddCommand(surprisesCmd)
+	QueryCmd.AddCommand(screenCmd)
+	QueryCmd.AddCommand(dividendsCmd)
+	QueryCmd.AddCommand(indicatorsCmd)
+	QueryCmd.AddCommand(windowsCmd)
+	QueryCmd.AddCommand(corporateActionsCmd)
+	QueryCmd.AddCommand(universeCmd)
 }
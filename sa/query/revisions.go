@@ -0,0 +1,102 @@
+package query
+
+import (
+	"fmt"
+	"os"
+	"stock-automation/database"
+	"stock-automation/helper"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+var revisionsCmd = &cobra.Command{
+	Use:   "revisions",
+	Short: "業績予想修正を表示",
+	Long:  "指定日以降に開示された業績予想修正（上方修正・下方修正）を表示します",
+	RunE:  showRevisions,
+}
+
+func init() {
+	// フラグを追加
+	revisionsCmd.Flags().String("since", helper.SubDate(helper.GetTodayDate(), 7), "開示日の開始日（YYYY-MM-DD形式、デフォルトは7日前）")
+	revisionsCmd.Flags().String("direction", "", "修正の方向（up, down。指定しない場合は両方）")
+	revisionsCmd.Flags().String("metric", "", "指標（net_sales, operating_profit, ordinary_profit, profit, eps, dps_annual）")
+	revisionsCmd.Flags().String("code", "", "銘柄コード")
+	revisionsCmd.Flags().IntP("limit", "l", 50, "表示する行数の上限")
+	revisionsCmd.Flags().BoolP("all", "a", false, "全ての行を表示")
+	addCodeFilterFlags(revisionsCmd)
+}
+
+func showRevisions(cmd *cobra.Command, args []string) error {
+	since, _ := cmd.Flags().GetString("since")
+	direction, _ := cmd.Flags().GetString("direction")
+	metric, _ := cmd.Flags().GetString("metric")
+	code, _ := cmd.Flags().GetString("code")
+	limit, _ := cmd.Flags().GetInt("limit")
+	showAll, _ := cmd.Flags().GetBool("all")
+
+	if direction != "" && direction != database.DirectionUp && direction != database.DirectionDown {
+		return fmt.Errorf("directionはupまたはdownを指定してください: '%s'", direction)
+	}
+	if _, ok := database.MetricNames[metric]; metric != "" && !ok {
+		return fmt.Errorf("サポートされていない指標です: '%s'", metric)
+	}
+	if code != "" {
+		code = helper.NormalizeCode(code)
+	}
+	if showAll {
+		limit = 0
+	}
+
+	// データベース接続
+	conn, err := database.NewConnectionFromEnv(false) // queryでは非verbose
+	if err != nil {
+		return fmt.Errorf("データベース接続エラー: %v", err)
+	}
+	defer conn.Close()
+
+	repository := database.NewForecastRevisionRepository(conn)
+	revisions, err := repository.GetRevisions(since, direction, metric, code, getCodeFilter(cmd), limit)
+	if err != nil {
+		return fmt.Errorf("データ取得エラー: %v", err)
+	}
+
+	fmt.Printf("\n=== 業績予想修正 (%s以降) ===\n\n", since)
+
+	directionNames := map[string]string{
+		database.DirectionUp:   "上方",
+		database.DirectionDown: "下方",
+	}
+
+	// ヘッダーを表示
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "開示日\tコード\t企業名\t対象年度末\t指標\t修正前\t修正後\t変化率(%)\t方向")
+	fmt.Fprintln(w, "----\t----\t----\t----\t----\t----\t----\t----\t----")
+
+	for _, revision := range revisions {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			revision.DisclosedDate.Format("2006-01-02"), revision.LocalCode, revision.CompanyName,
+			formatDatePtr(revision.FiscalYearEndDate), database.MetricNames[revision.Metric],
+			formatMetricValue(revision.Metric, revision.OldValue), formatMetricValue(revision.Metric, revision.NewValue),
+			formatFloat64Ptr(revision.ChangePct), directionNames[revision.Direction])
+	}
+
+	w.Flush()
+
+	if len(revisions) == 0 {
+		fmt.Println("データが見つかりませんでした")
+	} else {
+		fmt.Printf("\n表示行数: %d\n", len(revisions))
+	}
+
+	return nil
+}
+
+// formatMetricValue 指標の値を表示用に整形（1株当たりの指標は小数2桁、それ以外は整数）
+func formatMetricValue(metric string, v float64) string {
+	if metric == database.MetricEPS || metric == database.MetricDPSAnnual {
+		return fmt.Sprintf("%.2f", v)
+	}
+	return fmt.Sprintf("%.0f", v)
+}
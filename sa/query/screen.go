@@ -0,0 +1,162 @@
+package query
+
+import (
+	"fmt"
+	"os"
+	"stock-automation/database"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+var screenCmd = &cobra.Command{
+	Use:   "screen",
+	Short: "条件に合う銘柄を抽出",
+	Long:  "上場中の銘柄を、最新取引日の株価指標（valuations）・テクニカル指標（indicators）、最新年度の財務品質スコア（quality_scores）・連続増配年数（dividend_history）で絞り込みます",
+	RunE:  screenStocks,
+}
+
+func init() {
+	// フラグを追加
+	screenCmd.Flags().Int("min-fscore", 0, "F-scoreの下限（0〜9）")
+	screenCmd.Flags().Float64("min-zscore", 0, "Z-scoreの下限")
+	screenCmd.Flags().Float64("max-per", 0, "予想PERの上限")
+	screenCmd.Flags().Float64("max-pbr", 0, "PBRの上限")
+	screenCmd.Flags().Float64("min-yield", 0, "予想配当利回り(%)の下限")
+	screenCmd.Flags().Int64("min-market-cap", 0, "時価総額（円）の下限")
+	screenCmd.Flags().Int("min-increase-streak", 0, "連続増配年数の下限")
+	screenCmd.Flags().StringSlice("market", nil, "市場区分コード（例: 0111。カンマ区切りで複数指定可）")
+	screenCmd.Flags().StringSlice("scale", nil, "規模区分（例: TOPIX Core30。カンマ区切りで複数指定可）")
+	screenCmd.Flags().StringSlice("sector17", nil, "17業種コード（カンマ区切りで複数指定可）")
+	screenCmd.Flags().StringSlice("sector33", nil, "33業種コード（カンマ区切りで複数指定可）")
+	screenCmd.Flags().StringArray("indicator", nil, "テクニカル指標の条件（例: rsi_14<30, close>sma_200。複数指定可）")
+	screenCmd.Flags().String("sort", "code", "並べ替えキー（code, fscore, zscore, per, pbr, yield, market_cap, streak）")
+	screenCmd.Flags().IntP("limit", "l", 50, "表示する行数の上限")
+	screenCmd.Flags().BoolP("all", "a", false, "全ての行を表示")
+	addCodeFilterFlags(screenCmd)
+}
+
+func screenStocks(cmd *cobra.Command, args []string) error {
+	criteria := database.ScreenCriteria{}
+
+	// 指定されたフラグのみ条件として適用
+	if cmd.Flags().Changed("min-fscore") {
+		v, _ := cmd.Flags().GetInt("min-fscore")
+		criteria.MinFScore = &v
+	}
+	if cmd.Flags().Changed("min-zscore") {
+		v, _ := cmd.Flags().GetFloat64("min-zscore")
+		criteria.MinZScore = &v
+	}
+	if cmd.Flags().Changed("max-per") {
+		v, _ := cmd.Flags().GetFloat64("max-per")
+		criteria.MaxPER = &v
+	}
+	if cmd.Flags().Changed("max-pbr") {
+		v, _ := cmd.Flags().GetFloat64("max-pbr")
+		criteria.MaxPBR = &v
+	}
+	if cmd.Flags().Changed("min-yield") {
+		v, _ := cmd.Flags().GetFloat64("min-yield")
+		criteria.MinDividendYield = &v
+	}
+	if cmd.Flags().Changed("min-market-cap") {
+		v, _ := cmd.Flags().GetInt64("min-market-cap")
+		criteria.MinMarketCap = &v
+	}
+	if cmd.Flags().Changed("min-increase-streak") {
+		v, _ := cmd.Flags().GetInt("min-increase-streak")
+		criteria.MinIncreaseStreak = &v
+	}
+
+	criteria.Universe.MarketCodes, _ = cmd.Flags().GetStringSlice("market")
+	criteria.Universe.ScaleCategories, _ = cmd.Flags().GetStringSlice("scale")
+	criteria.Universe.Sector17Codes, _ = cmd.Flags().GetStringSlice("sector17")
+	criteria.Universe.Sector33Codes, _ = cmd.Flags().GetStringSlice("sector33")
+	criteria.Universe.Codes = getCodeFilter(cmd)
+
+	indicators, _ := cmd.Flags().GetStringArray("indicator")
+	for _, expr := range indicators {
+		condition, err := database.ParseIndicatorCondition(expr)
+		if err != nil {
+			return err
+		}
+		criteria.Indicators = append(criteria.Indicators, condition)
+	}
+
+	criteria.SortBy, _ = cmd.Flags().GetString("sort")
+	if _, ok := database.ScreenSortKeys[criteria.SortBy]; !ok {
+		return fmt.Errorf("サポートされていない並べ替えキーです: '%s'", criteria.SortBy)
+	}
+
+	criteria.Limit, _ = cmd.Flags().GetInt("limit")
+	if showAll, _ := cmd.Flags().GetBool("all"); showAll {
+		criteria.Limit = 0
+	}
+
+	// データベース接続
+	conn, err := database.NewConnectionFromEnv(false) // queryでは非verbose
+	if err != nil {
+		return fmt.Errorf("データベース接続エラー: %v", err)
+	}
+	defer conn.Close()
+
+	repository := database.NewScreenerRepository(conn)
+	results, err := repository.Screen(criteria)
+	if err != nil {
+		return fmt.Errorf("データ取得エラー: %v", err)
+	}
+
+	// 条件に使ったテクニカル指標を列として追加
+	indicatorNames := criteria.IndicatorNames()
+	indicatorHeader := ""
+	for _, name := range indicatorNames {
+		indicatorHeader += "\t" + name
+	}
+
+	fmt.Printf("\n=== スクリーニング結果 ===\n\n")
+
+	// ヘッダーを表示
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "コード\t企業名\t取引日\t終値\t時価総額\t予想PER\tPBR\t予想配当利回り(%)\tスコア年度末\tF-score\tZ-score\t判定\t連続増配"+indicatorHeader)
+	fmt.Fprintln(w, "----\t----\t----\t----\t----\t----\t----\t----\t----\t----\t----\t----\t----"+strings.Repeat("\t----", len(indicatorNames)))
+
+	for _, r := range results {
+		fScore := "-"
+		if r.FScore != nil && r.FScoreEvaluated != nil {
+			fScore = fmt.Sprintf("%d/%d", *r.FScore, *r.FScoreEvaluated)
+		}
+		streak := "-"
+		if r.IncreaseStreak != nil {
+			streak = fmt.Sprintf("%d", *r.IncreaseStreak)
+		}
+		indicatorValues := ""
+		for _, name := range indicatorNames {
+			indicatorValues += "\t" + formatFloat64Ptr(r.Indicators[name])
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s%s\n",
+			r.Code, r.CompanyName, formatDatePtr(r.TradeDate), formatFloat64Ptr(r.Close),
+			formatInt64Ptr(r.MarketCap), formatFloat64Ptr(r.ForecastPER), formatFloat64Ptr(r.PBR),
+			formatFloat64Ptr(r.DividendYield), formatDatePtr(r.FiscalYearEndDate),
+			fScore, formatFloat64Ptr(r.ZScore), zoneNames[r.ZZone], streak, indicatorValues)
+	}
+
+	w.Flush()
+
+	if len(results) == 0 {
+		fmt.Println("条件に合う銘柄が見つかりませんでした")
+	} else {
+		fmt.Printf("\n表示行数: %d\n", len(results))
+	}
+
+	return nil
+}
+
+// zoneNames Z-scoreの判定区分の表示名
+var zoneNames = map[string]string{
+	database.ZoneSafe:     "安全",
+	database.ZoneGrey:     "グレー",
+	database.ZoneDistress: "危険",
+	"":                    "-",
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"stock-automation/database"
+	"stock-automation/helper"
 	"stock-automation/schema"
 	"text/tabwriter"
 
@@ -14,7 +15,7 @@ import (
 var showCmd = &cobra.Command{
 	Use:   "show [table_name]",
 	Short: "テーブル内容を表示",
-	Long:  "指定したテーブルの内容を表示します\n\n利用可能なテーブル:\n  - listed_info: 上場銘柄情報\n  - market_codes: 市場区分コード",
+	Long:  "指定したテーブルの内容を表示します\n\n利用可能なテーブル:\n  - listed_info: 上場銘柄情報\n  - market_codes: 市場区分コード\n  - listing_events: 新規上場・上場廃止イベント\n  - valuations: 株価指標\n  - financial_ratios: 財務比率（--code・--watchlist・--tagのいずれか必須）\n  - quality_scores: 財務品質スコア（--code・--watchlist・--tagのいずれか必須）",
 	Args:  cobra.ExactArgs(1),
 	RunE:  showTable,
 }
@@ -23,6 +24,8 @@ func init() {
 	// フラグを追加
 	showCmd.Flags().IntP("limit", "l", 10, "表示する行数の上限")
 	showCmd.Flags().BoolP("all", "a", false, "全ての行を表示")
+	showCmd.Flags().String("code", "", "銘柄コード（対応するテーブルのみ）")
+	addCodeFilterFlags(showCmd)
 }
 
 func showTable(cmd *cobra.Command, args []string) error {
@@ -30,13 +33,17 @@ func showTable(cmd *cobra.Command, args []string) error {
 
 	// サポートするテーブルを限定
 	supportedTables := map[string]string{
-		"listed_info":  "上場銘柄情報",
-		"market_codes": "市場区分コード",
+		"listed_info":      "上場銘柄情報",
+		"market_codes":     "市場区分コード",
+		"listing_events":   "新規上場・上場廃止イベント",
+		"valuations":       "株価指標",
+		"financial_ratios": "財務比率",
+		"quality_scores":   "財務品質スコア",
 	}
 
 	_, supported := supportedTables[tableName]
 	if !supported {
-		return fmt.Errorf("サポートされていないテーブルです: '%s'\n\n利用可能なテーブル:\n  - listed_info: 上場銘柄情報\n  - market_codes: 市場区分コード", tableName)
+		return fmt.Errorf("サポートされていないテーブルです: '%s'\n\n利用可能なテーブル:\n  - listed_info: 上場銘柄情報\n  - market_codes: 市場区分コード\n  - listing_events: 新規上場・上場廃止イベント\n  - valuations: 株価指標\n  - financial_ratios: 財務比率（--code・--watchlist・--tagのいずれか必須）\n  - quality_scores: 財務品質スコア（--code・--watchlist・--tagのいずれか必須）", tableName)
 	}
 
 	// データベース接続
@@ -51,25 +58,60 @@ func showTable(cmd *cobra.Command, args []string) error {
 	// フラグの値を取得
 	limit, _ := cmd.Flags().GetInt("limit")
 	showAll, _ := cmd.Flags().GetBool("all")
+	code, _ := cmd.Flags().GetString("code")
+	if code != "" {
+		code = helper.NormalizeCode(code)
+	}
+	filter := getCodeFilter(cmd)
 
 	// テーブル固有の表示処理
 	switch tableName {
 	case "listed_info":
-		return showListedInfo(gormDB, limit, showAll)
+		return showListedInfo(gormDB, filter, limit, showAll)
 	case "market_codes":
+		if !filter.IsEmpty() {
+			return fmt.Errorf("market_codesは--watchlist・--tagに対応していません")
+		}
 		return showMarketCodes(gormDB, limit, showAll)
+	case "listing_events":
+		return showListingEvents(conn, filter, limit, showAll)
+	case "valuations":
+		return showValuations(conn, code, filter, limit, showAll)
+	case "financial_ratios", "quality_scores":
+		if code == "" && filter.IsEmpty() {
+			return fmt.Errorf("%sの表示には--code、--watchlist、--tagのいずれかを指定してください", tableName)
+		}
+		codes, err := getTargetCodes(conn, code, filter)
+		if err != nil {
+			return err
+		}
+		if len(codes) == 0 {
+			fmt.Println("条件に一致する銘柄がありません")
+			return nil
+		}
+		for _, code := range codes {
+			if tableName == "financial_ratios" {
+				err = showFinancialRatios(conn, code, limit, showAll)
+			} else {
+				err = showQualityScores(conn, code, limit, showAll)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
 	default:
 		return fmt.Errorf("未実装のテーブル: %s", tableName)
 	}
 }
 
 // listed_info テーブル専用の表示関数（GORM版）
-func showListedInfo(gormDB *gorm.DB, limit int, showAll bool) error {
+func showListedInfo(gormDB *gorm.DB, filter database.CodeFilter, limit int, showAll bool) error {
 	fmt.Printf("\n=== 上場銘柄情報 (listed_info) ===\n\n")
 
 	var listedInfos []schema.ListedInfo
 
-	query := gormDB.Order("code")
+	query := gormDB.Scopes(filter.Scope("code")).Order("code")
 
 	if !showAll {
 		query = query.Limit(limit)
@@ -151,3 +193,173 @@ func showMarketCodes(gormDB *gorm.DB, limit int, showAll bool) error {
 
 	return nil
 }
+
+// listing_events テーブル専用の表示関数
+func showListingEvents(conn *database.Connection, filter database.CodeFilter, limit int, showAll bool) error {
+	fmt.Printf("\n=== 新規上場・上場廃止イベント (listing_events) ===\n\n")
+
+	if showAll {
+		limit = 0
+	}
+
+	repository := database.NewListedInfoRepository(conn)
+	events, err := repository.GetListingEvents("", filter, limit)
+	if err != nil {
+		return fmt.Errorf("データ取得エラー: %v", err)
+	}
+
+	eventTypeNames := map[string]string{
+		database.ListingEventListing:   "新規上場",
+		database.ListingEventDelisting: "上場廃止",
+	}
+
+	// ヘッダーを表示
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "日付\tコード\t企業名\t種別\t市場コード")
+	fmt.Fprintln(w, "----\t----\t----\t----\t----")
+
+	for _, event := range events {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+			event.EventDate.Format("2006-01-02"), event.Code, event.CompanyName,
+			eventTypeNames[event.EventType], event.MarketCode)
+	}
+
+	w.Flush()
+
+	if len(events) == 0 {
+		fmt.Println("データが見つかりませんでした")
+	} else {
+		fmt.Printf("\n表示行数: %d\n", len(events))
+	}
+
+	return nil
+}
+
+// valuations テーブル専用の表示関数（銘柄コード指定時はその銘柄の推移、未指定時は最新取引日の全銘柄）
+func showValuations(conn *database.Connection, code string, filter database.CodeFilter, limit int, showAll bool) error {
+	fmt.Printf("\n=== 株価指標 (valuations) ===\n\n")
+
+	if showAll {
+		limit = 0
+	}
+
+	repository := database.NewValuationRepository(conn)
+	valuations, err := repository.GetValuations(code, filter, limit)
+	if err != nil {
+		return fmt.Errorf("データ取得エラー: %v", err)
+	}
+
+	// ヘッダーを表示
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "取引日\tコード\t企業名\t終値\t時価総額\t実績PER\t予想PER\tPBR\t予想配当利回り(%)")
+	fmt.Fprintln(w, "----\t----\t----\t----\t----\t----\t----\t----\t----")
+
+	for _, v := range valuations {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%.2f\t%s\t%s\t%s\t%s\t%s\n",
+			v.TradeDate.Format("2006-01-02"), v.Code, v.CompanyName, v.Close,
+			formatInt64Ptr(v.MarketCap), formatFloat64Ptr(v.ActualPER), formatFloat64Ptr(v.ForecastPER),
+			formatFloat64Ptr(v.PBR), formatFloat64Ptr(v.ForecastDividendYield))
+	}
+
+	w.Flush()
+
+	if len(valuations) == 0 {
+		fmt.Println("データが見つかりませんでした")
+	} else {
+		fmt.Printf("\n表示行数: %d\n", len(valuations))
+	}
+
+	return nil
+}
+
+// financial_ratios テーブル専用の表示関数（銘柄コード指定必須、会計年度の新しい順）
+func showFinancialRatios(conn *database.Connection, code string, limit int, showAll bool) error {
+	fmt.Printf("\n=== 財務比率 (financial_ratios) - %s ===\n\n", code)
+
+	if showAll {
+		limit = 0
+	}
+
+	repository := database.NewFinancialRatioRepository(conn)
+	ratios, err := repository.GetRatiosByCode(code, limit)
+	if err != nil {
+		return fmt.Errorf("データ取得エラー: %v", err)
+	}
+
+	// ヘッダーを表示
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "年度末\tROE(%)\tROA(%)\t営業利益率(%)\t純利益率(%)\t自己資本比率(%)\t前期差\t配当性向(%)\t売上CAGR3年\t売上CAGR5年\t利益CAGR3年\t利益CAGR5年\tFCF")
+	fmt.Fprintln(w, "----\t----\t----\t----\t----\t----\t----\t----\t----\t----\t----\t----\t----")
+
+	for _, ratio := range ratios {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			formatDatePtr(ratio.FiscalYearEndDate),
+			formatFloat64Ptr(ratio.ROE), formatFloat64Ptr(ratio.ROA),
+			formatFloat64Ptr(ratio.OperatingMargin), formatFloat64Ptr(ratio.NetMargin),
+			formatFloat64Ptr(ratio.EquityRatio), formatFloat64Ptr(ratio.EquityRatioChange),
+			formatFloat64Ptr(ratio.PayoutRatio),
+			formatFloat64Ptr(ratio.NetSalesCAGR3Y), formatFloat64Ptr(ratio.NetSalesCAGR5Y),
+			formatFloat64Ptr(ratio.ProfitCAGR3Y), formatFloat64Ptr(ratio.ProfitCAGR5Y),
+			formatInt64Ptr(ratio.FreeCashFlow))
+	}
+
+	w.Flush()
+
+	if len(ratios) == 0 {
+		fmt.Println("データが見つかりませんでした")
+	} else {
+		fmt.Printf("\n表示行数: %d\n", len(ratios))
+	}
+
+	return nil
+}
+
+// quality_scores テーブル専用の表示関数（銘柄コード指定必須、F-scoreの内訳を○×で表示）
+func showQualityScores(conn *database.Connection, code string, limit int, showAll bool) error {
+	fmt.Printf("\n=== 財務品質スコア (quality_scores) - %s ===\n\n", code)
+
+	if showAll {
+		limit = 0
+	}
+
+	repository := database.NewQualityScoreRepository(conn)
+	scores, err := repository.GetScoresByCode(code, limit)
+	if err != nil {
+		return fmt.Errorf("データ取得エラー: %v", err)
+	}
+
+	// 判定結果を表示用に整形
+	mark := func(v *bool) string {
+		if v == nil {
+			return "-"
+		}
+		if *v {
+			return "○"
+		}
+		return "×"
+	}
+
+	// ヘッダーを表示
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "年度末\tF-score\tROA正\t営業CF正\tROA改善\t発生主義\tレバレッジ\t流動性\t希薄化なし\t利益率改善\t回転率改善\tZ-score\t判定")
+	fmt.Fprintln(w, "----\t----\t----\t----\t----\t----\t----\t----\t----\t----\t----\t----\t----")
+
+	for _, s := range scores {
+		fmt.Fprintf(w, "%s\t%d/%d\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			formatDatePtr(s.FiscalYearEndDate), s.FScore, s.FScoreEvaluated,
+			mark(s.F.ROAPositive), mark(s.F.CFOPositive), mark(s.F.ROAImproved),
+			mark(s.F.Accrual), mark(s.F.LeverageImproved), mark(s.F.LiquidityImproved),
+			mark(s.F.NoDilution), mark(s.F.MarginImproved), mark(s.F.TurnoverImproved),
+			formatFloat64Ptr(s.ZScore), zoneNames[s.ZZone])
+	}
+
+	w.Flush()
+
+	if len(scores) == 0 {
+		fmt.Println("データが見つかりませんでした")
+	} else {
+		fmt.Printf("\n表示行数: %d\n", len(scores))
+	}
+
+	return nil
+}
@@ -0,0 +1,110 @@
+package query
+
+import (
+	"fmt"
+	"os"
+	"stock-automation/database"
+	"stock-automation/helper"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+var surprisesCmd = &cobra.Command{
+	Use:   "surprises",
+	Short: "決算サプライズを表示",
+	Long:  "指定期間に開示された決算のうち、開示前の最新予想を上回った（下回った）銘柄を予想比の大きい順に表示します",
+	RunE:  showSurprises,
+}
+
+func init() {
+	// フラグを追加
+	surprisesCmd.Flags().String("from", helper.SubDate(helper.GetTodayDate(), 7), "開示日の開始日（YYYY-MM-DD形式、デフォルトは7日前）")
+	surprisesCmd.Flags().String("to", helper.GetTodayDate(), "開示日の終了日（YYYY-MM-DD形式、デフォルトは当日）")
+	surprisesCmd.Flags().String("result", "", "予想比の判定（beat, miss, inline。指定しない場合は全て）")
+	surprisesCmd.Flags().String("metric", database.MetricOperatingProfit, "指標（net_sales, operating_profit, ordinary_profit, profit, eps）")
+	surprisesCmd.Flags().String("period", "", "決算期間（2Q, FY。指定しない場合は両方）")
+	surprisesCmd.Flags().IntP("limit", "l", 50, "表示する行数の上限")
+	surprisesCmd.Flags().BoolP("all", "a", false, "全ての行を表示")
+	addCodeFilterFlags(surprisesCmd)
+}
+
+func showSurprises(cmd *cobra.Command, args []string) error {
+	from, _ := cmd.Flags().GetString("from")
+	to, _ := cmd.Flags().GetString("to")
+	result, _ := cmd.Flags().GetString("result")
+	metric, _ := cmd.Flags().GetString("metric")
+	period, _ := cmd.Flags().GetString("period")
+	limit, _ := cmd.Flags().GetInt("limit")
+	showAll, _ := cmd.Flags().GetBool("all")
+
+	switch result {
+	case "", database.SurpriseBeat, database.SurpriseMiss, database.SurpriseInline:
+	default:
+		return fmt.Errorf("resultはbeat, miss, inlineのいずれかを指定してください: '%s'", result)
+	}
+	if metric == database.MetricDPSAnnual {
+		return fmt.Errorf("サポートされていない指標です: '%s'", metric)
+	}
+	if _, ok := database.MetricNames[metric]; !ok {
+		return fmt.Errorf("サポートされていない指標です: '%s'", metric)
+	}
+	if period != "" && period != "2Q" && period != "FY" {
+		return fmt.Errorf("periodは2QまたはFYを指定してください: '%s'", period)
+	}
+	if showAll {
+		limit = 0
+	}
+
+	// データベース接続
+	conn, err := database.NewConnectionFromEnv(false) // queryでは非verbose
+	if err != nil {
+		return fmt.Errorf("データベース接続エラー: %v", err)
+	}
+	defer conn.Close()
+
+	repository := database.NewEarningsSurpriseRepository(conn)
+	surprises, err := repository.GetSurprises(from, to, metric, result, period, getCodeFilter(cmd), limit)
+	if err != nil {
+		return fmt.Errorf("データ取得エラー: %v", err)
+	}
+
+	fmt.Printf("\n=== 決算サプライズ: %s (%s〜%s) ===\n\n", database.MetricNames[metric], from, to)
+
+	resultNames := map[string]string{
+		database.SurpriseBeat:   "上振れ",
+		database.SurpriseMiss:   "下振れ",
+		database.SurpriseInline: "予想通り",
+	}
+
+	// ヘッダーを表示
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "開示日\tコード\t企業名\t対象年度末\t期間\t実績\t予想\t予想比(%)\t判定\t通期予想\t進捗率(%)")
+	fmt.Fprintln(w, "----\t----\t----\t----\t----\t----\t----\t----\t----\t----\t----")
+
+	for _, s := range surprises {
+		forecast, fullYear := "-", "-"
+		if s.ForecastValue != nil {
+			forecast = formatMetricValue(s.Metric, *s.ForecastValue)
+		}
+		if s.FullYearForecastValue != nil {
+			fullYear = formatMetricValue(s.Metric, *s.FullYearForecastValue)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			s.DisclosedDate.Format("2006-01-02"), s.LocalCode, s.CompanyName,
+			formatDatePtr(s.FiscalYearEndDate), s.TypeOfCurrentPeriod,
+			formatMetricValue(s.Metric, s.ActualValue), forecast,
+			formatFloat64Ptr(s.SurprisePct), resultNames[s.Result],
+			fullYear, formatFloat64Ptr(s.ProgressPct))
+	}
+
+	w.Flush()
+
+	if len(surprises) == 0 {
+		fmt.Println("データが見つかりませんでした")
+	} else {
+		fmt.Printf("\n表示行数: %d\n", len(surprises))
+	}
+
+	return nil
+}
@@ -0,0 +1,92 @@
+package query
+
+import (
+	"fmt"
+	"os"
+	"stock-automation/database"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var universeCmd = &cobra.Command{
+	Use:   "universe",
+	Short: "指定日時点のユニバースを表示",
+	Long:  "上場銘柄一覧スナップショットから、指定日時点で上場していた銘柄をその時点の市場区分・規模区分・業種で絞り込んで表示します",
+	RunE:  showUniverse,
+}
+
+func init() {
+	// フラグを追加
+	universeCmd.Flags().StringP("date", "d", "", "基準日（YYYY-MM-DD形式、指定しない場合は当日）")
+	universeCmd.Flags().StringSlice("market", nil, "市場区分コード（例: 0111。カンマ区切りで複数指定可）")
+	universeCmd.Flags().StringSlice("scale", nil, "規模区分（例: TOPIX Core30。カンマ区切りで複数指定可）")
+	universeCmd.Flags().StringSlice("sector17", nil, "17業種コード（カンマ区切りで複数指定可）")
+	universeCmd.Flags().StringSlice("sector33", nil, "33業種コード（カンマ区切りで複数指定可）")
+	universeCmd.Flags().IntP("limit", "l", 50, "表示する行数の上限")
+	universeCmd.Flags().BoolP("all", "a", false, "全ての行を表示")
+	addCodeFilterFlags(universeCmd)
+}
+
+func showUniverse(cmd *cobra.Command, args []string) error {
+	dateFlag, _ := cmd.Flags().GetString("date")
+	limit, _ := cmd.Flags().GetInt("limit")
+	showAll, _ := cmd.Flags().GetBool("all")
+
+	var filter database.UniverseFilter
+	filter.MarketCodes, _ = cmd.Flags().GetStringSlice("market")
+	filter.ScaleCategories, _ = cmd.Flags().GetStringSlice("scale")
+	filter.Sector17Codes, _ = cmd.Flags().GetStringSlice("sector17")
+	filter.Sector33Codes, _ = cmd.Flags().GetStringSlice("sector33")
+	filter.Codes = getCodeFilter(cmd)
+
+	asOf := time.Now()
+	if dateFlag != "" {
+		var err error
+		asOf, err = time.ParseInLocation("2006-01-02", dateFlag, time.Local)
+		if err != nil {
+			return fmt.Errorf("日付の形式が正しくありません（YYYY-MM-DD形式で指定してください）: %v", err)
+		}
+	}
+
+	// データベース接続
+	conn, err := database.NewConnectionFromEnv(false) // queryでは非verbose
+	if err != nil {
+		return fmt.Errorf("データベース接続エラー: %v", err)
+	}
+	defer conn.Close()
+
+	repository := database.NewUniverseRepository(conn)
+	members, err := repository.Universe(asOf, filter)
+	if err != nil {
+		return fmt.Errorf("データ取得エラー: %v", err)
+	}
+
+	fmt.Printf("\n=== ユニバース（%s時点） ===\n\n", asOf.Format("2006-01-02"))
+
+	// ヘッダーを表示
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "コード\t企業名\t市場区分\t規模区分\t17業種\t33業種\t適用日")
+	fmt.Fprintln(w, "----\t----\t----\t----\t----\t----\t----")
+
+	shown := members
+	if !showAll && limit > 0 && len(shown) > limit {
+		shown = shown[:limit]
+	}
+	for _, m := range shown {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			m.Code, m.CompanyName, m.MarketCode, m.ScaleCategory, m.Sector17Code, m.Sector33Code,
+			m.SnapshotDate.Format("2006-01-02"))
+	}
+
+	w.Flush()
+
+	if len(members) == 0 {
+		fmt.Println("データが見つかりませんでした")
+	} else {
+		fmt.Printf("\n表示行数: %d / 銘柄数: %d\n", len(shown), len(members))
+	}
+
+	return nil
+}
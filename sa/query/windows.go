@@ -0,0 +1,93 @@
+package query
+
+import (
+	"fmt"
+	"os"
+	"stock-automation/database"
+	"stock-automation/helper"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+var windowsCmd = &cobra.Command{
+	Use:   "windows",
+	Short: "期間別評価指標を表示",
+	Long:  "期間（例: 1M, 3M, 52W, 3Y）ごとの最高・最低調整終値と乖離率を表示します\n銘柄コードを指定した場合はその銘柄の全期間、指定しない場合は指定した期間の上場中の全銘柄を最高値からの乖離率の大きい順（高値に近い順）に表示します",
+	RunE:  showWindows,
+}
+
+func init() {
+	// フラグを追加
+	windowsCmd.Flags().String("code", "", "銘柄コード")
+	windowsCmd.Flags().String("window", "", "期間（例: 52W）")
+	windowsCmd.Flags().IntP("limit", "l", 50, "表示する行数の上限")
+	windowsCmd.Flags().BoolP("all", "a", false, "全ての行を表示")
+	addCodeFilterFlags(windowsCmd)
+}
+
+func showWindows(cmd *cobra.Command, args []string) error {
+	code, _ := cmd.Flags().GetString("code")
+	windowFlag, _ := cmd.Flags().GetString("window")
+	limit, _ := cmd.Flags().GetInt("limit")
+	showAll, _ := cmd.Flags().GetBool("all")
+
+	filter := getCodeFilter(cmd)
+	if code == "" && windowFlag == "" && filter.IsEmpty() {
+		return fmt.Errorf("--code、--window、--watchlist、--tagのいずれかを指定してください")
+	}
+	if code != "" {
+		code = helper.NormalizeCode(code)
+	}
+
+	windowLabel := ""
+	if windowFlag != "" {
+		window, err := database.ParseAssessmentWindow(windowFlag)
+		if err != nil {
+			return err
+		}
+		windowLabel = window.Label
+	}
+
+	if showAll {
+		limit = 0
+	}
+
+	// データベース接続
+	conn, err := database.NewConnectionFromEnv(false) // queryでは非verbose
+	if err != nil {
+		return fmt.Errorf("データベース接続エラー: %v", err)
+	}
+	defer conn.Close()
+
+	repository := database.NewAssessmentWindowRepository(conn)
+	metrics, err := repository.GetWindowMetrics(code, windowLabel, filter, limit)
+	if err != nil {
+		return fmt.Errorf("データ取得エラー: %v", err)
+	}
+
+	fmt.Printf("\n=== 期間別評価指標 ===\n\n")
+
+	// ヘッダーを表示
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "コード\t企業名\t期間\t開始日\t最終取引日\t調整後終値\t最高値\t最高値日\t最安値\t最安値日\t最高値乖離(%)\t最安値乖離(%)\t取引日数")
+	fmt.Fprintln(w, "----\t----\t----\t----\t----\t----\t----\t----\t----\t----\t----\t----\t----")
+
+	for _, m := range metrics {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%.2f\t%.2f\t%s\t%.2f\t%s\t%s\t%s\t%d\n",
+			m.Code, m.CompanyName, m.WindowLabel,
+			m.WindowStartDate.Format("2006-01-02"), m.LastTradeDate.Format("2006-01-02"), m.LastAdjustmentClose,
+			m.MaxClose, m.MaxCloseDate.Format("2006-01-02"), m.MinClose, m.MinCloseDate.Format("2006-01-02"),
+			formatFloat64Ptr(m.DeviationFromMax), formatFloat64Ptr(m.DeviationFromMin), m.TradingDays)
+	}
+
+	w.Flush()
+
+	if len(metrics) == 0 {
+		fmt.Println("データが見つかりませんでした")
+	} else {
+		fmt.Printf("\n表示行数: %d\n", len(metrics))
+	}
+
+	return nil
+}
@@ -0,0 +1,341 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"stock-automation/database"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var dailyCmd = &cobra.Command{
+	Use:   "daily",
+	Short: "日次の市況レポートを作成",
+	Long: `取引日の市況レポートをHTML（CSSを埋め込んだ単一ファイル）とMarkdownで作成します。
+
+掲載内容:
+  - 騰落状況（値上がり・値下がり銘柄数、ストップ高・安、売買代金、TOPIX）
+  - 値上がり率・値下がり率の上位銘柄、出来高急増銘柄（daily_quotes）
+  - 開示日の決算短信などの開示（statements）
+  - 変化率の大きい業績予想修正（forecast_revisions）
+  - スクリーニング（sa query screenと同じ条件を--screen-*で指定）に一致した銘柄
+  - 有効なアラートルールの条件に一致した銘柄
+  - ウォッチリストの銘柄の値動き
+
+出力先: <output>/daily_YYYY-MM-DD.html, <output>/daily_YYYY-MM-DD.md`,
+	RunE: createDailyReport,
+}
+
+func init() {
+	// フラグを追加
+	dailyCmd.Flags().StringP("date", "d", "", "取引日（YYYY-MM-DD形式、指定しない場合はdaily_quotesの最新取引日）")
+	dailyCmd.Flags().StringP("output", "o", "reports", "出力先ディレクトリ")
+	dailyCmd.Flags().StringSlice("format", []string{"html", "md"}, "出力形式（html, md。カンマ区切りで複数指定可）")
+	dailyCmd.Flags().IntP("top", "n", 10, "値上がり率・値下がり率・出来高急増・予想修正の表示銘柄数")
+	dailyCmd.Flags().Float64("min-turnover", 100000000, "値上がり率・値下がり率・出来高急増の対象とする売買代金（円）の下限")
+	dailyCmd.Flags().Int("volume-days", 20, "出来高急増の基準とする平均出来高の営業日数")
+	dailyCmd.Flags().Float64("volume-ratio", 3, "出来高急増とする平均出来高に対する倍率の下限")
+	dailyCmd.Flags().Int("disclosures", 50, "開示の表示件数の上限（ウォッチリストの銘柄を優先）")
+	addScreenFlags(dailyCmd)
+}
+
+// dailyOptions 日次レポートの作成条件
+type dailyOptions struct {
+	Top            int
+	MinTurnover    float64
+	VolumeDays     int
+	VolumeRatio    float64
+	MaxDisclosures int
+	Screen         *database.ScreenCriteria // スクリーニング条件（指定していない場合はnil）
+	ScreenLabels   []string                 // スクリーニング条件の表示用の文字列
+}
+
+// dailyReport 日次レポートの内容
+type dailyReport struct {
+	Date            time.Time
+	GeneratedAt     time.Time
+	Options         dailyOptions
+	Breadth         *database.MarketBreadth
+	Gainers         []*database.MarketMover
+	Losers          []*database.MarketMover
+	VolumeSpikes    []*database.MarketMover
+	Disclosures     []*database.DisclosureDigest // 表示する開示（ウォッチリストの銘柄を優先）
+	DisclosureCount int                          // 開示日の全ての開示の件数
+	Revisions       []*database.ForecastRevision
+	Screen          *screenSection
+	AlertHits       []*alertHit
+	Watchlists      []*watchlistMoves
+}
+
+// alertHit アラートルールの条件に一致した銘柄
+type alertHit struct {
+	Rule   *database.AlertRule
+	Events []*database.AlertEvent // 表示する銘柄
+	Total  int                    // 一致した銘柄数
+}
+
+// watchlistMoves ウォッチリストの銘柄の値動き
+type watchlistMoves struct {
+	Watchlist *database.Watchlist
+	Items     []*database.WatchlistItem
+}
+
+// reportFormats 出力形式と拡張子・作成関数の対応
+var reportFormats = map[string]struct {
+	ext   string
+	write func(w io.Writer, report *dailyReport) error
+}{
+	"html": {".html", writeDailyHTML},
+	"md":   {".md", writeDailyMarkdown},
+}
+
+func createDailyReport(cmd *cobra.Command, args []string) error {
+	date, _ := cmd.Flags().GetString("date")
+	output, _ := cmd.Flags().GetString("output")
+	formats, _ := cmd.Flags().GetStringSlice("format")
+
+	var options dailyOptions
+	options.Top, _ = cmd.Flags().GetInt("top")
+	options.MinTurnover, _ = cmd.Flags().GetFloat64("min-turnover")
+	options.VolumeDays, _ = cmd.Flags().GetInt("volume-days")
+	options.VolumeRatio, _ = cmd.Flags().GetFloat64("volume-ratio")
+	options.MaxDisclosures, _ = cmd.Flags().GetInt("disclosures")
+
+	for i, format := range formats {
+		formats[i] = strings.ToLower(strings.TrimSpace(format))
+		if _, ok := reportFormats[formats[i]]; !ok {
+			return fmt.Errorf("サポートされていない出力形式です（html, md）: '%s'", format)
+		}
+	}
+	if options.Top <= 0 || options.VolumeDays <= 0 {
+		return fmt.Errorf("--topと--volume-daysは1以上を指定してください")
+	}
+
+	var err error
+	if options.Screen, options.ScreenLabels, err = screenCriteriaFromFlags(cmd); err != nil {
+		return err
+	}
+
+	// データベース接続
+	conn, err := database.NewConnectionFromEnv(false)
+	if err != nil {
+		return fmt.Errorf("データベース接続エラー: %v", err)
+	}
+	defer conn.Close()
+
+	tradeDate, err := resolveTradeDate(database.NewMarketReportRepository(conn), date)
+	if err != nil {
+		return err
+	}
+
+	report, err := collectDailyReport(conn, tradeDate, options)
+	if err != nil {
+		return fmt.Errorf("データ取得エラー: %v", err)
+	}
+
+	if err := os.MkdirAll(output, 0755); err != nil {
+		return fmt.Errorf("出力先ディレクトリ作成エラー: %v", err)
+	}
+	for _, format := range formats {
+		path := filepath.Join(output, "daily_"+tradeDate.Format("2006-01-02")+reportFormats[format].ext)
+		if err := writeReportFile(path, report, reportFormats[format].write); err != nil {
+			return fmt.Errorf("レポート出力エラー (%s): %v", path, err)
+		}
+		fmt.Printf("レポートを作成しました: %s\n", path)
+	}
+
+	return nil
+}
+
+// resolveTradeDate レポートの取引日を決定（株価データがない日はエラー）
+func resolveTradeDate(repository *database.MarketReportRepository, date string) (time.Time, error) {
+	if date == "" {
+		latest, err := repository.GetLatestTradeDate()
+		if err != nil {
+			return time.Time{}, err
+		}
+		if latest == nil {
+			return time.Time{}, fmt.Errorf("株価データがありません")
+		}
+		return *latest, nil
+	}
+
+	t, err := time.ParseInLocation("2006-01-02", date, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("日付の形式が正しくありません（YYYY-MM-DD）: %s", date)
+	}
+	exists, err := repository.HasQuotes(t)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if !exists {
+		return time.Time{}, fmt.Errorf("%sの株価データがありません（休業日または未取得）", date)
+	}
+	return t, nil
+}
+
+// collectDailyReport 日次レポートの各項目のデータを取得
+func collectDailyReport(conn *database.Connection, date time.Time, options dailyOptions) (*dailyReport, error) {
+	report := &dailyReport{Date: date, GeneratedAt: time.Now(), Options: options}
+	day := date.Format("2006-01-02")
+	var err error
+
+	market := database.NewMarketReportRepository(conn)
+	if report.Breadth, err = market.GetBreadth(date); err != nil {
+		return nil, err
+	}
+	if report.Gainers, err = market.GetMovers(date, true, options.MinTurnover, options.Top); err != nil {
+		return nil, err
+	}
+	if report.Losers, err = market.GetMovers(date, false, options.MinTurnover, options.Top); err != nil {
+		return nil, err
+	}
+	if report.VolumeSpikes, err = market.GetVolumeSpikes(date, options.VolumeDays, options.VolumeRatio, options.MinTurnover, options.Top); err != nil {
+		return nil, err
+	}
+
+	digests, err := database.NewDisclosureRepository(conn).GetDigestsByDate(day)
+	if err != nil {
+		return nil, err
+	}
+	report.DisclosureCount = len(digests)
+	report.Disclosures = selectDisclosures(digests, options.MaxDisclosures)
+
+	if report.Revisions, err = database.NewForecastRevisionRepository(conn).GetLargestRevisions(day, nil, options.Top); err != nil {
+		return nil, err
+	}
+
+	if options.Screen != nil {
+		criteria := *options.Screen
+		criteria.Limit = options.Top
+		results, err := database.NewScreenerRepository(conn).Screen(criteria)
+		if err != nil {
+			return nil, err
+		}
+		report.Screen = &screenSection{Criteria: &criteria, Conditions: options.ScreenLabels, Results: results}
+	}
+
+	if report.AlertHits, err = collectAlertHits(database.NewAlertRepository(conn), date, options.Top); err != nil {
+		return nil, err
+	}
+
+	if report.Watchlists, err = collectWatchlistMoves(database.NewWatchlistRepository(conn), date); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// selectDisclosures ウォッチリストの銘柄の開示を先に、それ以外は開示時刻順に上限まで選ぶ
+func selectDisclosures(digests []*database.DisclosureDigest, limit int) []*database.DisclosureDigest {
+	selected := make([]*database.DisclosureDigest, len(digests))
+	copy(selected, digests)
+	sort.SliceStable(selected, func(i, j int) bool {
+		return len(selected[i].Watchlists) > 0 && len(selected[j].Watchlists) == 0
+	})
+	if limit > 0 && len(selected) > limit {
+		selected = selected[:limit]
+	}
+	return selected
+}
+
+// collectAlertHits 有効なアラートルールを取引日で評価し、一致した銘柄を値の順に取得（発生履歴への保存・通知は行わない）
+func collectAlertHits(repository *database.AlertRepository, date time.Time, limit int) ([]*alertHit, error) {
+	rules, err := repository.GetRules(true)
+	if err != nil {
+		return nil, err
+	}
+
+	var hits []*alertHit
+	for _, rule := range rules {
+		events, err := repository.Evaluate(rule, date)
+		if err != nil {
+			// 1件のルールの誤りでレポート全体を止めない
+			log.Printf("アラートルール評価エラー（%s）: %v", rule.Name, err)
+			continue
+		}
+		if len(events) == 0 {
+			continue
+		}
+
+		// しきい値を下回る条件は値の小さい順、上回る条件は値の大きい順
+		ascending := strings.HasPrefix(rule.Operator, "<")
+		sort.SliceStable(events, func(i, j int) bool {
+			if ascending {
+				return events[i].Value < events[j].Value
+			}
+			return events[i].Value > events[j].Value
+		})
+
+		hit := &alertHit{Rule: rule, Events: events, Total: len(events)}
+		if limit > 0 && len(events) > limit {
+			hit.Events = events[:limit]
+		}
+		hits = append(hits, hit)
+	}
+
+	return hits, nil
+}
+
+// collectWatchlistMoves ウォッチリストごとに銘柄の取引日時点の値動きを前日比の大きい順に取得
+// 取引日より後に追加した銘柄は除き、取引日の株価がない銘柄の前日比は表示しない
+func collectWatchlistMoves(repository *database.WatchlistRepository, date time.Time) ([]*watchlistMoves, error) {
+	watchlists, err := repository.GetWatchlists()
+	if err != nil {
+		return nil, err
+	}
+
+	nextDay := date.AddDate(0, 0, 1)
+	var moves []*watchlistMoves
+	for _, watchlist := range watchlists {
+		items, err := repository.GetItemsAsOf(watchlist.ID, date.Format("2006-01-02"))
+		if err != nil {
+			return nil, err
+		}
+
+		var visible []*database.WatchlistItem
+		for _, item := range items {
+			if !item.AddedAt.Before(nextDay) {
+				continue
+			}
+			if item.LastTradeDate == nil || !item.LastTradeDate.Equal(date) {
+				item.DayChangePct = nil
+			}
+			visible = append(visible, item)
+		}
+		if len(visible) == 0 {
+			continue
+		}
+
+		sort.SliceStable(visible, func(i, j int) bool {
+			a, b := visible[i].DayChangePct, visible[j].DayChangePct
+			if a == nil || b == nil {
+				return a != nil
+			}
+			return *a > *b
+		})
+		moves = append(moves, &watchlistMoves{Watchlist: watchlist, Items: visible})
+	}
+
+	return moves, nil
+}
+
+// writeReportFile レポートをファイルに出力
+func writeReportFile(path string, report *dailyReport, write func(w io.Writer, report *dailyReport) error) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if err := write(file, report); err != nil {
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,196 @@
+package report
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"stock-automation/database"
+	"strings"
+	"time"
+)
+
+// reportFuncs HTML・Markdownのテンプレートで共通に使う関数
+var reportFuncs = map[string]interface{}{
+	"pct":    formatChangePct,
+	"num":    formatNumber,
+	"float":  formatFloat64Ptr,
+	"oku":    formatOku,
+	"okuPtr": formatOkuPtr,
+	"cls":    changeClass,
+	"date": func(t *time.Time) string {
+		if t == nil {
+			return "-"
+		}
+		return t.Format("2006-01-02")
+	},
+	"volume": func(v *float64) string {
+		if v == nil {
+			return "-"
+		}
+		return formatNumber(*v, 0)
+	},
+	"ratio": func(v *float64) string {
+		if v == nil {
+			return "-"
+		}
+		return formatNumber(*v, 1) + "倍"
+	},
+	"hhmm": func(s string) string {
+		if len(s) >= 5 {
+			return s[:5]
+		}
+		return s
+	},
+	"document": func(d *database.DisclosureDigest) string {
+		return d.DocumentName(database.LangJapanese)
+	},
+	"forecastChange": func(d *database.DisclosureDigest) string {
+		if d.ForecastOperatingProfit == nil {
+			return "-"
+		}
+		if d.ForecastInitial {
+			return "新規"
+		}
+		return formatChangePct(d.ForecastOperatingProfitChange)
+	},
+	"marketCap": func(v *int64) string {
+		if v == nil {
+			return "-"
+		}
+		return formatOku(float64(*v))
+	},
+	"fscore": func(r *database.ScreenResult) string {
+		if r.FScore == nil || r.FScoreEvaluated == nil {
+			return "-"
+		}
+		return fmt.Sprintf("%d/%d", *r.FScore, *r.FScoreEvaluated)
+	},
+	"intPtr": func(v *int) string {
+		if v == nil {
+			return "-"
+		}
+		return fmt.Sprintf("%d", *v)
+	},
+	"metricName":  func(metric string) string { return database.MetricNames[metric] },
+	"metricValue": formatMetricValue,
+	"join":        strings.Join,
+	"sub":         func(a, b int) int { return a - b },
+}
+
+var dailyHTMLTemplate = template.Must(template.New("daily").Funcs(reportFuncs).Parse(`<!DOCTYPE html>
+<html lang="ja">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>日次市況レポート {{.Date.Format "2006-01-02"}}</title>
+<style>
+body { font-family: -apple-system, "Hiragino Sans", "Noto Sans JP", Meiryo, sans-serif; margin: 2em auto; max-width: 1100px; padding: 0 1em; color: #222; }
+h1 { border-bottom: 2px solid #333; padding-bottom: .3em; }
+h2 { margin-top: 2em; border-left: 5px solid #3b6ea5; padding-left: .5em; }
+h3 { margin-top: 1.5em; }
+table { border-collapse: collapse; width: 100%; margin: .5em 0; font-size: .9em; }
+th, td { border: 1px solid #ddd; padding: .35em .6em; }
+th { background: #f3f5f8; text-align: left; }
+td.num { text-align: right; font-variant-numeric: tabular-nums; white-space: nowrap; }
+.up { color: #c62828; }
+.down { color: #1565c0; }
+.summary { display: flex; flex-wrap: wrap; gap: 1em; }
+.summary div { background: #f3f5f8; border-radius: 6px; padding: .6em 1em; min-width: 8em; }
+.summary .label { font-size: .8em; color: #666; }
+.summary .value { font-size: 1.3em; font-weight: bold; }
+.note, footer { color: #666; font-size: .85em; }
+.watched { background: #fffbe6; }
+</style>
+</head>
+<body>
+<h1>日次市況レポート {{.Date.Format "2006-01-02"}}</h1>
+{{with .Breadth}}
+<h2>騰落状況</h2>
+<div class="summary">
+<div><div class="label">値上がり</div><div class="value up">{{.Advancers}}</div></div>
+<div><div class="label">値下がり</div><div class="value down">{{.Decliners}}</div></div>
+<div><div class="label">変わらず</div><div class="value">{{.Unchanged}}</div></div>
+<div><div class="label">騰落レシオ</div><div class="value">{{float .AdvanceDeclineRatio}}</div></div>
+<div><div class="label">ストップ高 / 安</div><div class="value">{{.LimitUp}} / {{.LimitDown}}</div></div>
+<div><div class="label">売買代金</div><div class="value">{{oku .TurnoverValue}}</div></div>
+{{if .TopixClose}}<div><div class="label">TOPIX</div><div class="value">{{float .TopixClose}} <span class="{{cls .TopixChangePct}}">{{pct .TopixChangePct}}</span></div></div>{{end}}
+</div>
+<p class="note">前営業日（{{date .PrevTradeDate}}）の調整後終値との比較</p>
+{{end}}
+
+<h2>値上がり率上位</h2>
+{{template "movers" .Gainers}}
+<h2>値下がり率上位</h2>
+{{template "movers" .Losers}}
+<p class="note">売買代金{{oku .Options.MinTurnover}}以上の銘柄が対象</p>
+
+<h2>出来高急増</h2>
+{{if .VolumeSpikes}}
+<table>
+<tr><th>コード</th><th>企業名</th><th>終値</th><th>前日比</th><th>出来高</th><th>平均出来高</th><th>倍率</th><th>売買代金</th></tr>
+{{range .VolumeSpikes}}<tr><td>{{.Code}}</td><td>{{.CompanyName}}</td><td class="num">{{num .Close 1}}</td><td class="num {{cls .ChangePct}}">{{pct .ChangePct}}</td><td class="num">{{num .Volume 0}}</td><td class="num">{{volume .AvgVolume}}</td><td class="num">{{ratio .VolumeRatio}}</td><td class="num">{{oku .TurnoverValue}}</td></tr>
+{{end}}</table>
+{{else}}<p>該当銘柄はありません</p>{{end}}
+<p class="note">直前{{.Options.VolumeDays}}営業日の平均出来高の{{num .Options.VolumeRatio 1}}倍以上</p>
+
+<h2>開示（{{.DisclosureCount}}件）</h2>
+{{if .Disclosures}}
+<table>
+<tr><th>時刻</th><th>コード</th><th>企業名</th><th>書類</th><th>売上高</th><th>前年同期比</th><th>営業利益</th><th>前年同期比</th><th>通期営業利益予想</th><th>前回予想比</th><th>ウォッチリスト</th></tr>
+{{range .Disclosures}}<tr{{if .Watchlists}} class="watched"{{end}}><td>{{hhmm .DisclosedTime}}</td><td>{{.LocalCode}}</td><td>{{.CompanyName}}</td><td>{{document .}}</td><td class="num">{{okuPtr .NetSales}}</td><td class="num {{cls .NetSalesYoY}}">{{pct .NetSalesYoY}}</td><td class="num">{{okuPtr .OperatingProfit}}</td><td class="num {{cls .OperatingProfitYoY}}">{{pct .OperatingProfitYoY}}</td><td class="num">{{okuPtr .ForecastOperatingProfit}}</td><td class="num {{cls .ForecastOperatingProfitChange}}">{{forecastChange .}}</td><td>{{join .Watchlists ", "}}</td></tr>
+{{end}}</table>
+{{if gt .DisclosureCount (len .Disclosures)}}<p class="note">ほか{{sub .DisclosureCount (len .Disclosures)}}件</p>{{end}}
+{{else}}<p>開示はありません</p>{{end}}
+
+<h2>業績予想修正（変化率上位）</h2>
+{{if .Revisions}}
+<table>
+<tr><th>コード</th><th>企業名</th><th>年度末</th><th>指標</th><th>修正前</th><th>修正後</th><th>変化率</th></tr>
+{{range .Revisions}}<tr><td>{{.LocalCode}}</td><td>{{.CompanyName}}</td><td>{{date .FiscalYearEndDate}}</td><td>{{metricName .Metric}}</td><td class="num">{{metricValue .Metric .OldValue}}</td><td class="num">{{metricValue .Metric .NewValue}}</td><td class="num {{cls .ChangePct}}">{{pct .ChangePct}}</td></tr>
+{{end}}</table>
+{{else}}<p>業績予想修正はありません</p>{{end}}
+
+<h2>スクリーニング</h2>
+{{with .Screen}}
+<p class="note">条件: {{join .Conditions ", "}}（最新取引日の株価指標・テクニカル指標、最新年度の財務品質スコアで判定）</p>
+{{if .Results}}{{$names := .Criteria.IndicatorNames}}
+<table>
+<tr><th>コード</th><th>企業名</th><th>終値</th><th>時価総額</th><th>予想PER</th><th>PBR</th><th>予想配当利回り</th><th>F-score</th><th>Z-score</th><th>連続増配</th>{{range $names}}<th>{{.}}</th>{{end}}</tr>
+{{range .Results}}{{$r := .}}<tr><td>{{.Code}}</td><td>{{.CompanyName}}</td><td class="num">{{float .Close}}</td><td class="num">{{marketCap .MarketCap}}</td><td class="num">{{float .ForecastPER}}</td><td class="num">{{float .PBR}}</td><td class="num">{{float .DividendYield}}</td><td class="num">{{fscore .}}</td><td class="num">{{float .ZScore}}</td><td class="num">{{intPtr .IncreaseStreak}}</td>{{range $names}}<td class="num">{{float (index $r.Indicators .)}}</td>{{end}}</tr>
+{{end}}</table>
+{{else}}<p>条件に合う銘柄はありません</p>{{end}}
+{{else}}<p class="note">スクリーニング条件が指定されていません（--screen-*で指定）</p>{{end}}
+
+<h2>アラートルールの一致</h2>
+{{if .AlertHits}}{{range .AlertHits}}
+<h3>{{.Rule.Name}}（{{.Rule.Condition}}、対象: {{.Rule.Target}}）: {{.Total}}銘柄</h3>
+<table>
+<tr><th>コード</th><th>企業名</th><th>{{.Rule.MetricLabel}}</th></tr>
+{{range .Events}}<tr><td>{{.Code}}</td><td>{{.CompanyName}}</td><td class="num">{{num .Value 2}}</td></tr>
+{{end}}</table>
+{{end}}{{else}}<p>条件に一致した銘柄はありません</p>{{end}}
+
+<h2>ウォッチリスト</h2>
+{{if .Watchlists}}{{range .Watchlists}}
+<h3>{{.Watchlist.Name}}</h3>
+<table>
+<tr><th>コード</th><th>企業名</th><th>終値</th><th>前日比</th><th>追加日からの騰落率</th><th>タグ</th></tr>
+{{range .Items}}<tr><td>{{.Code}}</td><td>{{.CompanyName}}</td><td class="num">{{float .LastClose}}</td><td class="num {{cls .DayChangePct}}">{{pct .DayChangePct}}</td><td class="num {{cls .SinceAddedPct}}">{{pct .SinceAddedPct}}</td><td>{{join .Tags ", "}}</td></tr>
+{{end}}</table>
+{{end}}{{else}}<p>ウォッチリストの銘柄はありません</p>{{end}}
+
+<footer><p>作成日時: {{.GeneratedAt.Format "2006-01-02 15:04:05"}}</p></footer>
+</body>
+</html>
+{{define "movers"}}{{if .}}
+<table>
+<tr><th>コード</th><th>企業名</th><th>終値</th><th>前日比</th><th>出来高</th><th>売買代金</th></tr>
+{{range .}}<tr><td>{{.Code}}</td><td>{{.CompanyName}}</td><td class="num">{{num .Close 1}}</td><td class="num {{cls .ChangePct}}">{{pct .ChangePct}}</td><td class="num">{{num .Volume 0}}</td><td class="num">{{oku .TurnoverValue}}</td></tr>
+{{end}}</table>
+{{else}}<p>該当銘柄はありません</p>{{end}}{{end}}
+`))
+
+// writeDailyHTML 日次レポートをCSSを埋め込んだ単一のHTMLとして出力
+func writeDailyHTML(w io.Writer, report *dailyReport) error {
+	return dailyHTMLTemplate.Execute(w, report)
+}
@@ -0,0 +1,106 @@
+package report
+
+import (
+	"io"
+	"strings"
+	"text/template"
+)
+
+// markdownCell Markdownの表のセルで使えない文字を置き換える
+func markdownCell(s string) string {
+	return strings.NewReplacer("|", "\\|", "<", "&lt;", "\n", " ").Replace(s)
+}
+
+var dailyMarkdownTemplate = template.Must(template.New("daily").Funcs(reportFuncs).Funcs(template.FuncMap{
+	"cell": markdownCell,
+}).Parse(`# 日次市況レポート {{.Date.Format "2006-01-02"}}
+{{with .Breadth}}
+## 騰落状況
+
+| 値上がり | 値下がり | 変わらず | 騰落レシオ | ストップ高 | ストップ安 | 売買代金 | TOPIX |
+|---:|---:|---:|---:|---:|---:|---:|---:|
+| {{.Advancers}} | {{.Decliners}} | {{.Unchanged}} | {{float .AdvanceDeclineRatio}} | {{.LimitUp}} | {{.LimitDown}} | {{oku .TurnoverValue}} | {{if .TopixClose}}{{float .TopixClose}} ({{pct .TopixChangePct}}){{else}}-{{end}} |
+
+前営業日（{{date .PrevTradeDate}}）の調整後終値との比較
+{{end}}
+## 値上がり率上位
+{{template "movers" .Gainers}}
+## 値下がり率上位
+{{template "movers" .Losers}}
+売買代金{{oku .Options.MinTurnover}}以上の銘柄が対象
+
+## 出来高急増
+{{if .VolumeSpikes}}
+| コード | 企業名 | 終値 | 前日比 | 出来高 | 平均出来高 | 倍率 | 売買代金 |
+|---|---|---:|---:|---:|---:|---:|---:|
+{{range .VolumeSpikes}}| {{.Code}} | {{cell .CompanyName}} | {{num .Close 1}} | {{pct .ChangePct}} | {{num .Volume 0}} | {{volume .AvgVolume}} | {{ratio .VolumeRatio}} | {{oku .TurnoverValue}} |
+{{end}}{{else}}
+該当銘柄はありません
+{{end}}
+直前{{.Options.VolumeDays}}営業日の平均出来高の{{num .Options.VolumeRatio 1}}倍以上
+
+## 開示（{{.DisclosureCount}}件）
+{{if .Disclosures}}
+| 時刻 | コード | 企業名 | 書類 | 売上高 | 前年同期比 | 営業利益 | 前年同期比 | 通期営業利益予想 | 前回予想比 | ウォッチリスト |
+|---|---|---|---|---:|---:|---:|---:|---:|---:|---|
+{{range .Disclosures}}| {{hhmm .DisclosedTime}} | {{.LocalCode}} | {{cell .CompanyName}} | {{document .}} | {{okuPtr .NetSales}} | {{pct .NetSalesYoY}} | {{okuPtr .OperatingProfit}} | {{pct .OperatingProfitYoY}} | {{okuPtr .ForecastOperatingProfit}} | {{forecastChange .}} | {{cell (join .Watchlists ", ")}} |
+{{end}}{{if gt .DisclosureCount (len .Disclosures)}}
+ほか{{sub .DisclosureCount (len .Disclosures)}}件
+{{end}}{{else}}
+開示はありません
+{{end}}
+## 業績予想修正（変化率上位）
+{{if .Revisions}}
+| コード | 企業名 | 年度末 | 指標 | 修正前 | 修正後 | 変化率 |
+|---|---|---|---|---:|---:|---:|
+{{range .Revisions}}| {{.LocalCode}} | {{cell .CompanyName}} | {{date .FiscalYearEndDate}} | {{metricName .Metric}} | {{metricValue .Metric .OldValue}} | {{metricValue .Metric .NewValue}} | {{pct .ChangePct}} |
+{{end}}{{else}}
+業績予想修正はありません
+{{end}}
+## スクリーニング
+{{with .Screen}}
+条件: {{join .Conditions ", "}}（最新取引日の株価指標・テクニカル指標、最新年度の財務品質スコアで判定）
+{{if .Results}}{{$names := .Criteria.IndicatorNames}}
+| コード | 企業名 | 終値 | 時価総額 | 予想PER | PBR | 予想配当利回り | F-score | Z-score | 連続増配 |{{range $names}} {{cell .}} |{{end}}
+|---|---|---:|---:|---:|---:|---:|---:|---:|---:|{{range $names}}---:|{{end}}
+{{range .Results}}{{$r := .}}| {{.Code}} | {{cell .CompanyName}} | {{float .Close}} | {{marketCap .MarketCap}} | {{float .ForecastPER}} | {{float .PBR}} | {{float .DividendYield}} | {{fscore .}} | {{float .ZScore}} | {{intPtr .IncreaseStreak}} |{{range $names}} {{float (index $r.Indicators .)}} |{{end}}
+{{end}}{{else}}
+条件に合う銘柄はありません
+{{end}}{{else}}
+スクリーニング条件が指定されていません（--screen-*で指定）
+{{end}}
+## アラートルールの一致
+{{if .AlertHits}}{{range .AlertHits}}
+### {{.Rule.Name}}（{{.Rule.Condition}}、対象: {{.Rule.Target}}）: {{.Total}}銘柄
+
+| コード | 企業名 | {{cell .Rule.MetricLabel}} |
+|---|---|---:|
+{{range .Events}}| {{.Code}} | {{cell .CompanyName}} | {{num .Value 2}} |
+{{end}}{{end}}{{else}}
+条件に一致した銘柄はありません
+{{end}}
+## ウォッチリスト
+{{if .Watchlists}}{{range .Watchlists}}
+### {{.Watchlist.Name}}
+
+| コード | 企業名 | 終値 | 前日比 | 追加日からの騰落率 | タグ |
+|---|---|---:|---:|---:|---|
+{{range .Items}}| {{.Code}} | {{cell .CompanyName}} | {{float .LastClose}} | {{pct .DayChangePct}} | {{pct .SinceAddedPct}} | {{cell (join .Tags ", ")}} |
+{{end}}{{end}}{{else}}
+ウォッチリストの銘柄はありません
+{{end}}
+---
+
+作成日時: {{.GeneratedAt.Format "2006-01-02 15:04:05"}}
+{{define "movers"}}{{if .}}
+| コード | 企業名 | 終値 | 前日比 | 出来高 | 売買代金 |
+|---|---|---:|---:|---:|---:|
+{{range .}}| {{.Code}} | {{cell .CompanyName}} | {{num .Close 1}} | {{pct .ChangePct}} | {{num .Volume 0}} | {{oku .TurnoverValue}} |
+{{end}}{{else}}
+該当銘柄はありません
+{{end}}{{end}}`))
+
+// writeDailyMarkdown 日次レポートをMarkdownとして出力
+func writeDailyMarkdown(w io.Writer, report *dailyReport) error {
+	return dailyMarkdownTemplate.Execute(w, report)
+}
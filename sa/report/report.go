@@ -0,0 +1,87 @@
+package report
+
+import (
+	"fmt"
+	"math"
+	"stock-automation/database"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var ReportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "レポート作成",
+	Long:  "保存済みのデータから市況などのレポートをHTML・Markdownで作成する機能を提供します",
+}
+
+func init() {
+	ReportCmd.AddCommand(dailyCmd)
+}
+
+// formatChangePct 騰落率(%)を符号付きで表示（NULLは"-"）
+func formatChangePct(v *float64) string {
+	if v == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%+.2f%%", *v)
+}
+
+// formatNumber 小数点以下の桁数を指定して整数部を3桁区切りで表示
+func formatNumber(v float64, digits int) string {
+	s := strconv.FormatFloat(math.Abs(v), 'f', digits, 64)
+	intPart, fracPart := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		intPart, fracPart = s[:i], s[i:]
+	}
+	for i := len(intPart) - 3; i > 0; i -= 3 {
+		intPart = intPart[:i] + "," + intPart[i:]
+	}
+	if v < 0 && strings.Trim(s, "0.") != "" {
+		intPart = "-" + intPart
+	}
+	return intPart + fracPart
+}
+
+// formatFloat64Ptr 小数値を3桁区切り・小数2桁で表示（NULLは"-"）
+func formatFloat64Ptr(v *float64) string {
+	if v == nil {
+		return "-"
+	}
+	return formatNumber(*v, 2)
+}
+
+// formatOku 金額（円）を億円単位で表示
+func formatOku(v float64) string {
+	return formatNumber(v/1e8, 1) + "億円"
+}
+
+// formatOkuPtr 金額（円）のポインタを億円単位で表示（NULLは"-"）
+func formatOkuPtr(v *float64) string {
+	if v == nil {
+		return "-"
+	}
+	return formatOku(*v)
+}
+
+// formatMetricValue 業績予想の指標の値を表示（1株当たりの指標は円、それ以外は億円）
+func formatMetricValue(metric string, v float64) string {
+	if metric == database.MetricEPS || metric == database.MetricDPSAnnual {
+		return formatNumber(v, 2) + "円"
+	}
+	return formatOku(v)
+}
+
+// changeClass 騰落の表示区分（HTMLのクラス名）
+func changeClass(v *float64) string {
+	switch {
+	case v == nil:
+		return ""
+	case *v > 0:
+		return "up"
+	case *v < 0:
+		return "down"
+	}
+	return ""
+}
@@ -0,0 +1,98 @@
+package report
+
+import (
+	"fmt"
+	"stock-automation/database"
+	"strconv"
+
+	"github.com/spf13/cobra"
+)
+
+// addScreenFlags スクリーニング（sa query screenと同じ条件）のフラグを追加
+func addScreenFlags(cmd *cobra.Command) {
+	cmd.Flags().Int("screen-min-fscore", 0, "[スクリーニング] F-scoreの下限（0〜9）")
+	cmd.Flags().Float64("screen-min-zscore", 0, "[スクリーニング] Z-scoreの下限")
+	cmd.Flags().Float64("screen-max-per", 0, "[スクリーニング] 予想PERの上限")
+	cmd.Flags().Float64("screen-max-pbr", 0, "[スクリーニング] PBRの上限")
+	cmd.Flags().Float64("screen-min-yield", 0, "[スクリーニング] 予想配当利回り(%)の下限")
+	cmd.Flags().Int64("screen-min-market-cap", 0, "[スクリーニング] 時価総額（円）の下限")
+	cmd.Flags().Int("screen-min-increase-streak", 0, "[スクリーニング] 連続増配年数の下限")
+	cmd.Flags().StringArray("screen-indicator", nil, "[スクリーニング] テクニカル指標の条件（例: rsi_14<30, close>sma_200。複数指定可）")
+	cmd.Flags().String("screen-sort", "code", "[スクリーニング] 並べ替えキー（code, fscore, zscore, per, pbr, yield, market_cap, streak）")
+}
+
+// screenSection レポートのスクリーニングの条件と結果
+type screenSection struct {
+	Criteria   *database.ScreenCriteria
+	Conditions []string // 条件の表示用の文字列
+	Results    []*database.ScreenResult
+}
+
+// screenCriteriaFromFlags フラグからスクリーニング条件を作成（条件を指定していない場合はnil）
+func screenCriteriaFromFlags(cmd *cobra.Command) (*database.ScreenCriteria, []string, error) {
+	criteria := &database.ScreenCriteria{}
+	var conditions []string
+
+	// 指定されたフラグのみ条件として適用
+	if cmd.Flags().Changed("screen-min-fscore") {
+		v, _ := cmd.Flags().GetInt("screen-min-fscore")
+		criteria.MinFScore = &v
+		conditions = append(conditions, fmt.Sprintf("F-score>=%d", v))
+	}
+	if cmd.Flags().Changed("screen-min-zscore") {
+		v, _ := cmd.Flags().GetFloat64("screen-min-zscore")
+		criteria.MinZScore = &v
+		conditions = append(conditions, "Z-score>="+formatCondition(v))
+	}
+	if cmd.Flags().Changed("screen-max-per") {
+		v, _ := cmd.Flags().GetFloat64("screen-max-per")
+		criteria.MaxPER = &v
+		conditions = append(conditions, "予想PER<="+formatCondition(v))
+	}
+	if cmd.Flags().Changed("screen-max-pbr") {
+		v, _ := cmd.Flags().GetFloat64("screen-max-pbr")
+		criteria.MaxPBR = &v
+		conditions = append(conditions, "PBR<="+formatCondition(v))
+	}
+	if cmd.Flags().Changed("screen-min-yield") {
+		v, _ := cmd.Flags().GetFloat64("screen-min-yield")
+		criteria.MinDividendYield = &v
+		conditions = append(conditions, "予想配当利回り>="+formatCondition(v)+"%")
+	}
+	if cmd.Flags().Changed("screen-min-market-cap") {
+		v, _ := cmd.Flags().GetInt64("screen-min-market-cap")
+		criteria.MinMarketCap = &v
+		conditions = append(conditions, "時価総額>="+formatOku(float64(v)))
+	}
+	if cmd.Flags().Changed("screen-min-increase-streak") {
+		v, _ := cmd.Flags().GetInt("screen-min-increase-streak")
+		criteria.MinIncreaseStreak = &v
+		conditions = append(conditions, fmt.Sprintf("連続増配>=%d年", v))
+	}
+
+	indicators, _ := cmd.Flags().GetStringArray("screen-indicator")
+	for _, expr := range indicators {
+		condition, err := database.ParseIndicatorCondition(expr)
+		if err != nil {
+			return nil, nil, err
+		}
+		criteria.Indicators = append(criteria.Indicators, condition)
+		conditions = append(conditions, condition.String())
+	}
+
+	criteria.SortBy, _ = cmd.Flags().GetString("screen-sort")
+	if _, ok := database.ScreenSortKeys[criteria.SortBy]; !ok {
+		return nil, nil, fmt.Errorf("サポートされていない並べ替えキーです: '%s'", criteria.SortBy)
+	}
+
+	// 条件がない場合は全銘柄が一致するため、スクリーニングを行わない
+	if len(conditions) == 0 {
+		return nil, nil, nil
+	}
+	return criteria, conditions, nil
+}
+
+// formatCondition 条件の数値を表示（不要な小数点以下の0は表示しない）
+func formatCondition(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
@@ -0,0 +1,50 @@
+package watch
+
+import (
+	"fmt"
+	"stock-automation/database"
+
+	"github.com/spf13/cobra"
+)
+
+var addCmd = &cobra.Command{
+	Use:   "add",
+	Short: "ウォッチリストに銘柄を追加",
+	Long:  "ウォッチリストに銘柄を追加します。ウォッチリストが存在しない場合は作成し、追加済みの銘柄は読み飛ばします",
+	RunE:  addItems,
+}
+
+func init() {
+	// フラグを追加
+	addCmd.Flags().StringSlice("code", nil, "銘柄コード（カンマ区切りで複数指定可、必須）")
+	addCmd.MarkFlagRequired("code")
+}
+
+func addItems(cmd *cobra.Command, args []string) error {
+	codeFlags, _ := cmd.Flags().GetStringSlice("code")
+	codes := normalizeCodes(codeFlags)
+	if len(codes) == 0 {
+		return fmt.Errorf("銘柄コードを指定してください")
+	}
+
+	// データベース接続
+	conn, err := database.NewConnectionFromEnv(false)
+	if err != nil {
+		return fmt.Errorf("データベース接続エラー: %v", err)
+	}
+	defer conn.Close()
+
+	repository := database.NewWatchlistRepository(conn)
+	watchlist, err := getWatchlist(cmd, repository, true)
+	if err != nil {
+		return err
+	}
+
+	added, err := repository.AddItems(watchlist.ID, codes)
+	if err != nil {
+		return fmt.Errorf("ウォッチリスト銘柄追加エラー: %v", err)
+	}
+
+	fmt.Printf("%s: %d銘柄を追加しました（追加済み: %d銘柄）\n", watchlist.Name, added, len(codes)-added)
+	return nil
+}
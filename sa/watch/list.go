@@ -0,0 +1,95 @@
+package watch
+
+import (
+	"fmt"
+	"os"
+	"stock-automation/database"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "ウォッチリストの銘柄を表示",
+	Long:  "ウォッチリストの銘柄を最新の調整後終値・前日比・追加日からの騰落率・タグとともに表示します。--watchlistsを指定した場合はウォッチリストの一覧を表示します",
+	RunE:  listItems,
+}
+
+func init() {
+	// フラグを追加
+	listCmd.Flags().Bool("watchlists", false, "ウォッチリストの一覧を表示")
+}
+
+func listItems(cmd *cobra.Command, args []string) error {
+	showWatchlists, _ := cmd.Flags().GetBool("watchlists")
+
+	// データベース接続
+	conn, err := database.NewConnectionFromEnv(false)
+	if err != nil {
+		return fmt.Errorf("データベース接続エラー: %v", err)
+	}
+	defer conn.Close()
+
+	repository := database.NewWatchlistRepository(conn)
+
+	if showWatchlists {
+		watchlists, err := repository.GetWatchlists()
+		if err != nil {
+			return fmt.Errorf("データ取得エラー: %v", err)
+		}
+
+		fmt.Printf("\n=== ウォッチリスト一覧 ===\n\n")
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "名前\t銘柄数\t説明\t作成日時")
+		fmt.Fprintln(w, "----\t----\t----\t----")
+		for _, l := range watchlists {
+			fmt.Fprintf(w, "%s\t%d\t%s\t%s\n", l.Name, l.Items, l.Description, l.CreatedAt.Format("2006-01-02 15:04:05"))
+		}
+		w.Flush()
+
+		if len(watchlists) == 0 {
+			fmt.Println("データが見つかりませんでした")
+		}
+		return nil
+	}
+
+	watchlist, err := getWatchlist(cmd, repository, false)
+	if err != nil {
+		return err
+	}
+
+	items, err := repository.GetItems(watchlist.ID)
+	if err != nil {
+		return fmt.Errorf("データ取得エラー: %v", err)
+	}
+
+	fmt.Printf("\n=== ウォッチリスト（%s） ===\n\n", watchlist.Name)
+
+	// ヘッダーを表示
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "コード\t企業名\t追加日\t終値日\t調整後終値\t前日比(%)\t追加時終値\t追加来(%)\tタグ")
+	fmt.Fprintln(w, "----\t----\t----\t----\t----\t----\t----\t----\t----")
+
+	for _, item := range items {
+		lastTradeDate := "-"
+		if item.LastTradeDate != nil {
+			lastTradeDate = item.LastTradeDate.Format("2006-01-02")
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			item.Code, item.CompanyName, item.AddedAt.Format("2006-01-02"), lastTradeDate,
+			formatFloat64Ptr(item.LastClose), formatFloat64Ptr(item.DayChangePct),
+			formatFloat64Ptr(item.AddedClose), formatFloat64Ptr(item.SinceAddedPct), strings.Join(item.Tags, ","))
+	}
+
+	w.Flush()
+
+	if len(items) == 0 {
+		fmt.Println("データが見つかりませんでした")
+	} else {
+		fmt.Printf("\n銘柄数: %d\n", len(items))
+	}
+
+	return nil
+}
@@ -0,0 +1,121 @@
+package watch
+
+import (
+	"fmt"
+	"os"
+	"stock-automation/database"
+	"stock-automation/helper"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+var NoteCmd = &cobra.Command{
+	Use:   "note",
+	Short: "銘柄メモ管理",
+	Long:  "銘柄ごとの調査メモを記録・表示する機能を提供します",
+}
+
+var noteAddCmd = &cobra.Command{
+	Use:   "add",
+	Short: "銘柄にメモを追加",
+	Long:  "銘柄に調査メモを追加します（メモは追加日時とともに記録され、上書きされません）",
+	RunE:  addNote,
+}
+
+var noteListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "銘柄メモを表示",
+	Long:  "銘柄メモを新しい順に表示します。--watchlist・--tagを指定した場合はその銘柄のメモのみ表示します",
+	RunE:  listNotes,
+}
+
+func init() {
+	// フラグを追加
+	noteAddCmd.Flags().String("code", "", "銘柄コード（必須）")
+	noteAddCmd.Flags().String("text", "", "メモ（必須）")
+	noteAddCmd.MarkFlagRequired("code")
+	noteAddCmd.MarkFlagRequired("text")
+
+	noteListCmd.Flags().String("code", "", "銘柄コード（指定しない場合は全銘柄）")
+	noteListCmd.Flags().String("watchlist", "", "ウォッチリストの銘柄に絞り込む")
+	noteListCmd.Flags().String("tag", "", "タグを付けた銘柄に絞り込む")
+	noteListCmd.Flags().IntP("limit", "l", 20, "表示する行数の上限")
+	noteListCmd.Flags().BoolP("all", "a", false, "全ての行を表示")
+
+	NoteCmd.AddCommand(noteAddCmd)
+	NoteCmd.AddCommand(noteListCmd)
+}
+
+func addNote(cmd *cobra.Command, args []string) error {
+	code, _ := cmd.Flags().GetString("code")
+	text, _ := cmd.Flags().GetString("text")
+
+	// データベース接続
+	conn, err := database.NewConnectionFromEnv(false)
+	if err != nil {
+		return fmt.Errorf("データベース接続エラー: %v", err)
+	}
+	defer conn.Close()
+
+	note, err := database.NewCodeNoteRepository(conn).AddNote(helper.NormalizeCode(code), text)
+	if err != nil {
+		return fmt.Errorf("メモ追加エラー: %v", err)
+	}
+
+	fmt.Printf("%s %s にメモを追加しました\n", note.Code, note.CompanyName)
+	return nil
+}
+
+func listNotes(cmd *cobra.Command, args []string) error {
+	code, _ := cmd.Flags().GetString("code")
+	limit, _ := cmd.Flags().GetInt("limit")
+	showAll, _ := cmd.Flags().GetBool("all")
+
+	var filter database.CodeFilter
+	filter.Watchlist, _ = cmd.Flags().GetString("watchlist")
+	filter.Tag, _ = cmd.Flags().GetString("tag")
+
+	if code != "" {
+		code = helper.NormalizeCode(code)
+	}
+	if showAll {
+		limit = 0
+	}
+
+	// データベース接続
+	conn, err := database.NewConnectionFromEnv(false)
+	if err != nil {
+		return fmt.Errorf("データベース接続エラー: %v", err)
+	}
+	defer conn.Close()
+
+	notes, err := database.NewCodeNoteRepository(conn).GetNotes(code, filter, limit)
+	if err != nil {
+		return fmt.Errorf("データ取得エラー: %v", err)
+	}
+
+	fmt.Printf("\n=== 銘柄メモ ===\n\n")
+
+	// ヘッダーを表示
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "日時\tコード\t企業名\tメモ")
+	fmt.Fprintln(w, "----\t----\t----\t----")
+
+	for _, n := range notes {
+		// 複数行のメモは1行にまとめて表示
+		text := strings.Join(strings.Fields(n.Note), " ")
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", n.CreatedAt.Format("2006-01-02 15:04"), n.Code, n.CompanyName, text)
+	}
+
+	w.Flush()
+
+	if len(notes) == 0 {
+		fmt.Println("データが見つかりませんでした")
+	} else {
+		fmt.Printf("\n表示行数: %d\n", len(notes))
+	}
+
+	return nil
+}
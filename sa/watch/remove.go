@@ -0,0 +1,60 @@
+package watch
+
+import (
+	"fmt"
+	"stock-automation/database"
+
+	"github.com/spf13/cobra"
+)
+
+var removeCmd = &cobra.Command{
+	Use:   "remove",
+	Short: "ウォッチリストから銘柄を削除",
+	Long:  "ウォッチリストから銘柄を削除します。--deleteを指定した場合はウォッチリスト自体を削除します",
+	RunE:  removeItems,
+}
+
+func init() {
+	// フラグを追加
+	removeCmd.Flags().StringSlice("code", nil, "銘柄コード（カンマ区切りで複数指定可）")
+	removeCmd.Flags().Bool("delete", false, "ウォッチリストを銘柄ごと削除")
+}
+
+func removeItems(cmd *cobra.Command, args []string) error {
+	codeFlags, _ := cmd.Flags().GetStringSlice("code")
+	deleteWatchlist, _ := cmd.Flags().GetBool("delete")
+
+	codes := normalizeCodes(codeFlags)
+	if len(codes) == 0 && !deleteWatchlist {
+		return fmt.Errorf("--codeまたは--deleteを指定してください")
+	}
+
+	// データベース接続
+	conn, err := database.NewConnectionFromEnv(false)
+	if err != nil {
+		return fmt.Errorf("データベース接続エラー: %v", err)
+	}
+	defer conn.Close()
+
+	repository := database.NewWatchlistRepository(conn)
+	watchlist, err := getWatchlist(cmd, repository, false)
+	if err != nil {
+		return err
+	}
+
+	if deleteWatchlist {
+		if err := repository.DeleteWatchlist(watchlist.ID); err != nil {
+			return err
+		}
+		fmt.Printf("%s: ウォッチリストを削除しました（%d銘柄）\n", watchlist.Name, watchlist.Items)
+		return nil
+	}
+
+	removed, err := repository.RemoveItems(watchlist.ID, codes)
+	if err != nil {
+		return fmt.Errorf("ウォッチリスト銘柄削除エラー: %v", err)
+	}
+
+	fmt.Printf("%s: %d銘柄を削除しました\n", watchlist.Name, removed)
+	return nil
+}
@@ -0,0 +1,165 @@
+package watch
+
+import (
+	"fmt"
+	"os"
+	"stock-automation/database"
+	"stock-automation/helper"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+var TagCmd = &cobra.Command{
+	Use:   "tag",
+	Short: "銘柄タグ管理",
+	Long:  "銘柄に任意のタグ（テーマ・分類など）を付け、タグで銘柄を絞り込めるようにする機能を提供します",
+}
+
+var tagAddCmd = &cobra.Command{
+	Use:   "add",
+	Short: "銘柄にタグを追加",
+	Long:  "銘柄にタグを追加します（追加済みのタグは読み飛ばします）",
+	RunE:  addTags,
+}
+
+var tagRemoveCmd = &cobra.Command{
+	Use:   "remove",
+	Short: "銘柄からタグを削除",
+	Long:  "銘柄からタグを削除します",
+	RunE:  removeTags,
+}
+
+var tagListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "タグを表示",
+	Long:  "タグごとの銘柄数を表示します。--codeを指定した場合はその銘柄のタグ、--tagを指定した場合はそのタグを付けた銘柄を表示します",
+	RunE:  listTags,
+}
+
+func init() {
+	// フラグを追加
+	for _, cmd := range []*cobra.Command{tagAddCmd, tagRemoveCmd} {
+		cmd.Flags().String("code", "", "銘柄コード（必須）")
+		cmd.Flags().StringSlice("tag", nil, "タグ（カンマ区切りで複数指定可、必須）")
+		cmd.MarkFlagRequired("code")
+		cmd.MarkFlagRequired("tag")
+	}
+
+	tagListCmd.Flags().String("code", "", "銘柄コード")
+	tagListCmd.Flags().String("tag", "", "タグ")
+
+	TagCmd.AddCommand(tagAddCmd)
+	TagCmd.AddCommand(tagRemoveCmd)
+	TagCmd.AddCommand(tagListCmd)
+}
+
+func addTags(cmd *cobra.Command, args []string) error {
+	code, _ := cmd.Flags().GetString("code")
+	tagFlags, _ := cmd.Flags().GetStringSlice("tag")
+	tags := normalizeTags(tagFlags)
+	if len(tags) == 0 {
+		return fmt.Errorf("タグを指定してください")
+	}
+	code = helper.NormalizeCode(code)
+
+	// データベース接続
+	conn, err := database.NewConnectionFromEnv(false)
+	if err != nil {
+		return fmt.Errorf("データベース接続エラー: %v", err)
+	}
+	defer conn.Close()
+
+	added, err := database.NewCodeNoteRepository(conn).AddTags(code, tags)
+	if err != nil {
+		return fmt.Errorf("タグ追加エラー: %v", err)
+	}
+
+	fmt.Printf("%s: %d件のタグを追加しました（追加済み: %d件）\n", code, added, len(tags)-added)
+	return nil
+}
+
+func removeTags(cmd *cobra.Command, args []string) error {
+	code, _ := cmd.Flags().GetString("code")
+	tagFlags, _ := cmd.Flags().GetStringSlice("tag")
+	tags := normalizeTags(tagFlags)
+	if len(tags) == 0 {
+		return fmt.Errorf("タグを指定してください")
+	}
+	code = helper.NormalizeCode(code)
+
+	// データベース接続
+	conn, err := database.NewConnectionFromEnv(false)
+	if err != nil {
+		return fmt.Errorf("データベース接続エラー: %v", err)
+	}
+	defer conn.Close()
+
+	removed, err := database.NewCodeNoteRepository(conn).RemoveTags(code, tags)
+	if err != nil {
+		return fmt.Errorf("タグ削除エラー: %v", err)
+	}
+
+	fmt.Printf("%s: %d件のタグを削除しました\n", code, removed)
+	return nil
+}
+
+func listTags(cmd *cobra.Command, args []string) error {
+	code, _ := cmd.Flags().GetString("code")
+	tag, _ := cmd.Flags().GetString("tag")
+	if code != "" {
+		code = helper.NormalizeCode(code)
+	}
+
+	// データベース接続
+	conn, err := database.NewConnectionFromEnv(false)
+	if err != nil {
+		return fmt.Errorf("データベース接続エラー: %v", err)
+	}
+	defer conn.Close()
+
+	repository := database.NewCodeNoteRepository(conn)
+
+	if code == "" && tag == "" {
+		counts, err := repository.GetTagCounts()
+		if err != nil {
+			return fmt.Errorf("データ取得エラー: %v", err)
+		}
+
+		fmt.Printf("\n=== タグ一覧 ===\n\n")
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "タグ\t銘柄数")
+		fmt.Fprintln(w, "----\t----")
+		for _, c := range counts {
+			fmt.Fprintf(w, "%s\t%d\n", c.Tag, c.Codes)
+		}
+		w.Flush()
+
+		if len(counts) == 0 {
+			fmt.Println("データが見つかりませんでした")
+		}
+		return nil
+	}
+
+	tags, err := repository.GetTags(code, tag)
+	if err != nil {
+		return fmt.Errorf("データ取得エラー: %v", err)
+	}
+
+	fmt.Printf("\n=== 銘柄タグ ===\n\n")
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "コード\t企業名\tタグ\t追加日時")
+	fmt.Fprintln(w, "----\t----\t----\t----")
+	for _, t := range tags {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", t.Code, t.CompanyName, t.Tag, t.CreatedAt.Format("2006-01-02 15:04:05"))
+	}
+	w.Flush()
+
+	if len(tags) == 0 {
+		fmt.Println("データが見つかりませんでした")
+	} else {
+		fmt.Printf("\n表示行数: %d\n", len(tags))
+	}
+
+	return nil
+}
@@ -0,0 +1,88 @@
+package watch
+
+import (
+	"fmt"
+	"stock-automation/database"
+	"stock-automation/helper"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var WatchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "ウォッチリスト管理",
+	Long:  "監視対象の銘柄をウォッチリストごとに登録し、直近の値動きを表示する機能を提供します",
+}
+
+func init() {
+	// 全サブコマンド共通のフラグを追加
+	WatchCmd.PersistentFlags().StringP("watchlist", "w", database.DefaultWatchlistName, "ウォッチリスト名")
+
+	WatchCmd.AddCommand(addCmd)
+	WatchCmd.AddCommand(removeCmd)
+	WatchCmd.AddCommand(listCmd)
+}
+
+// getWatchlist フラグで指定したウォッチリストを取得（create=trueの場合は存在しなければ作成）
+func getWatchlist(cmd *cobra.Command, repository *database.WatchlistRepository, create bool) (*database.Watchlist, error) {
+	name, _ := cmd.Flags().GetString("watchlist")
+	if name == "" {
+		return nil, fmt.Errorf("ウォッチリスト名を指定してください")
+	}
+
+	if create {
+		return repository.GetOrCreateWatchlist(name)
+	}
+
+	watchlist, err := repository.GetWatchlist(name)
+	if err != nil {
+		return nil, err
+	}
+	if watchlist == nil {
+		return nil, fmt.Errorf("ウォッチリストが見つかりません: %s", name)
+	}
+	return watchlist, nil
+}
+
+// normalizeCodes 銘柄コードを正規化し、重複を除く
+func normalizeCodes(codes []string) []string {
+	var result []string
+	seen := make(map[string]bool)
+	for _, code := range codes {
+		code = strings.TrimSpace(code)
+		if code == "" {
+			continue
+		}
+		code = helper.NormalizeCode(code)
+		if seen[code] {
+			continue
+		}
+		seen[code] = true
+		result = append(result, code)
+	}
+	return result
+}
+
+// normalizeTags タグの前後の空白を除き、重複を除く
+func normalizeTags(tags []string) []string {
+	var result []string
+	seen := make(map[string]bool)
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		result = append(result, tag)
+	}
+	return result
+}
+
+// formatFloat64Ptr float64ポインタを文字列に変換
+func formatFloat64Ptr(v *float64) string {
+	if v == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%.2f", *v)
+}
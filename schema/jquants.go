@@ -251,3 +251,46 @@ type FinancialStatement struct {
 func (FinancialStatement) TableName() string {
 	return "statements"
 }
+
+// TradingCalendarResponse 取引カレンダーレスポンス
+// https://api.jquants.com/v1/markets/trading_calendar
+type TradingCalendarResponse struct {
+	TradingCalendar []TradingCalendar `json:"trading_calendar"`
+}
+
+// TradingCalendar 取引カレンダー1レコード
+// HolidayDivision: 0=非営業日, 1=営業日, 2=東証半日立会日, 3=非営業日(祝日取引あり)
+type TradingCalendar struct {
+	Date            string    `json:"Date" gorm:"column:calendar_date;primaryKey"`
+	HolidayDivision string    `json:"HolidayDivision" gorm:"column:holiday_division"`
+	CreatedAt       time.Time `json:"CreatedAt" gorm:"column:created_at"`
+	UpdatedAt       time.Time `json:"UpdatedAt" gorm:"column:updated_at"`
+}
+
+// TableName GORMのテーブル名を指定
+func (TradingCalendar) TableName() string {
+	return "trading_calendar"
+}
+
+// TopixResponse TOPIX指数四本値レスポンス
+// https://api.jquants.com/v1/indices/topix
+type TopixResponse struct {
+	Topix         []Topix `json:"topix"`
+	PaginationKey string  `json:"pagination_key"`
+}
+
+// Topix TOPIX指数四本値1レコード
+type Topix struct {
+	Date      string    `json:"Date" gorm:"column:trade_date;primaryKey"`
+	Open      float64   `json:"Open" gorm:"column:open"`
+	High      float64   `json:"High" gorm:"column:high"`
+	Low       float64   `json:"Low" gorm:"column:low"`
+	Close     float64   `json:"Close" gorm:"column:close"`
+	CreatedAt time.Time `json:"CreatedAt" gorm:"column:created_at"`
+	UpdatedAt time.Time `json:"UpdatedAt" gorm:"column:updated_at"`
+}
+
+// TableName GORMのテーブル名を指定
+func (Topix) TableName() string {
+	return "topix"
+}